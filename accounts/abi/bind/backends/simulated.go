@@ -0,0 +1,732 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package backends
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	dece "github.com/dece-cash/go-dece"
+	"github.com/dece-cash/go-dece/accounts/abi/bind"
+	"github.com/dece-cash/go-dece/accounts/keystore"
+	"github.com/dece-cash/go-dece/common"
+	"github.com/dece-cash/go-dece/common/math"
+	"github.com/dece-cash/go-dece/consensus"
+	"github.com/dece-cash/go-dece/consensus/ethash"
+	"github.com/dece-cash/go-dece/core"
+	"github.com/dece-cash/go-dece/core/bloombits"
+	"github.com/dece-cash/go-dece/core/rawdb"
+	"github.com/dece-cash/go-dece/core/state"
+	"github.com/dece-cash/go-dece/core/types"
+	"github.com/dece-cash/go-dece/core/vm"
+	"github.com/dece-cash/go-dece/czero/c_type"
+	"github.com/dece-cash/go-dece/czero/deceparam"
+	"github.com/dece-cash/go-dece/czero/superzk"
+	"github.com/dece-cash/go-dece/dece/filters"
+	"github.com/dece-cash/go-dece/decedb"
+	"github.com/dece-cash/go-dece/event"
+	"github.com/dece-cash/go-dece/params"
+	"github.com/dece-cash/go-dece/rpc"
+	"github.com/dece-cash/go-dece/zero/localdb"
+	"github.com/dece-cash/go-dece/zero/stake"
+	"github.com/dece-cash/go-dece/zero/txs/assets"
+	"github.com/dece-cash/go-dece/zero/txs/stx"
+	"github.com/dece-cash/go-dece/zero/txtool"
+	"github.com/dece-cash/go-dece/zero/txtool/flight"
+	"github.com/dece-cash/go-dece/zero/txtool/prepare"
+	"github.com/dece-cash/go-dece/zero/txtool/verify"
+	"github.com/dece-cash/go-dece/zero/utils"
+)
+
+// This nil assignment ensures compile time that SimulatedBackend implements bind.ContractBackend.
+var _ bind.ContractBackend = (*SimulatedBackend)(nil)
+
+var errBlockNumberUnsupported = errors.New("SimulatedBackend cannot access blocks other than the latest block")
+
+// SimulatedBackend implements bind.ContractBackend, simulating a DECE chain in
+// memory. Its main purpose is to allow easily testing contract bindings: the
+// accounts are funded with Fund, the transactions sent through the bindings
+// are gathered in a pending block and Commit mines it.
+//
+// The backend runs the chain in developer mode and registers itself as the
+// chain used by the transaction builder, so only one of them should be used
+// at a time.
+type SimulatedBackend struct {
+	database   decedb.Database  // In memory database to store our testing data
+	blockchain *core.BlockChain // DECE blockchain to handle the consensus
+	engine     *simulatedEngine // Fake engine crediting the funded accounts
+	config     *params.ChainConfig
+
+	mu           sync.Mutex
+	pendingBlock *types.Block   // Currently pending block that will be imported on request
+	pendingState *state.StateDB // Currently pending state that will be the active on on request
+
+	accounts map[c_type.Uint512]c_type.Tk // Tks of the accounts whose outs can be spent
+
+	events *filters.EventSystem // Event system for filtering log events live
+}
+
+// NewSimulatedBackend creates a new binding backend using a simulated blockchain
+// for testing purposes, the blocks having the given gas limit.
+func NewSimulatedBackend(gasLimit uint64) *SimulatedBackend {
+	superzk.ZeroInit_NoCircuit()
+	deceparam.Init_Dev(true)
+	deceparam.InitComfirmedBlock(0)
+
+	database := decedb.NewMemDatabase()
+	genesis := core.Genesis{Config: params.DevnetChainConfig, GasLimit: gasLimit, Difficulty: big.NewInt(1)}
+	genesis.MustCommit(database)
+
+	engine := &simulatedEngine{Engine: ethash.NewFaker(), funds: make(map[uint64][]funding)}
+	blockchain, _ := core.NewBlockChain(database, nil, genesis.Config, engine, vm.Config{}, nil)
+	txtool.Ref_inst.SetBC(&core.State1BlockChain{Bc: blockchain})
+
+	backend := &SimulatedBackend{
+		database:   database,
+		blockchain: blockchain,
+		engine:     engine,
+		config:     genesis.Config,
+		accounts:   make(map[c_type.Uint512]c_type.Tk),
+	}
+	backend.events = filters.NewEventSystem(new(event.TypeMux), &filterBackend{database, blockchain}, false)
+	backend.rollback()
+	return backend
+}
+
+// Commit imports all the pending transactions and fundings as a single block
+// and starts a fresh new state.
+func (b *SimulatedBackend) Commit() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, err := b.blockchain.InsertChain([]*types.Block{b.pendingBlock}); err != nil {
+		return err
+	}
+	b.rollback()
+	return nil
+}
+
+// Rollback aborts all pending transactions and fundings, reverting to the last
+// committed state.
+func (b *SimulatedBackend) Rollback() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.engine.drop(b.blockchain.CurrentBlock().NumberU64() + 1)
+	b.rollback()
+}
+
+func (b *SimulatedBackend) rollback() {
+	if err := b.generatePending(nil); err != nil {
+		panic(err)
+	}
+}
+
+// generatePending rebuilds the pending block on top of the current head with
+// the given transactions the way the miner does, a transaction failing to apply
+// is reported as an error and leaves the pending block unchanged.
+func (b *SimulatedBackend) generatePending(txs []*types.Transaction) error {
+	parent := b.blockchain.CurrentBlock()
+	statedb, err := b.blockchain.StateAt(parent.Header())
+	if err != nil {
+		return err
+	}
+	tstamp := time.Now().Unix()
+	if parent.Time().Cmp(new(big.Int).SetInt64(tstamp)) >= 0 {
+		tstamp = parent.Time().Int64() + 1
+	}
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number(), common.Big1),
+		GasLimit:   parent.GasLimit(),
+		Time:       big.NewInt(tstamp),
+	}
+	if err := b.engine.Prepare(b.blockchain, header); err != nil {
+		return err
+	}
+	if err := stake.NewStakeState(statedb).ProcessBeforeApply(b.blockchain, header); err != nil {
+		return err
+	}
+	var (
+		gp        = new(core.GasPool).AddGas(header.GasLimit)
+		receipts  []*types.Receipt
+		gasReward uint64
+	)
+	for i, tx := range txs {
+		statedb.Prepare(tx.Hash(), common.Hash{}, i)
+		receipt, gas, err := core.ApplyTransaction(b.config, b.blockchain, nil, gp, statedb, header, tx, &header.GasUsed, vm.Config{})
+		if err != nil {
+			return err
+		}
+		gasReward += new(big.Int).Mul(new(big.Int).SetUint64(gas), tx.GasPrice()).Uint64()
+		receipts = append(receipts, receipt)
+	}
+	block, err := b.engine.Finalize(b.blockchain, header, statedb, txs, receipts, gasReward)
+	if err != nil {
+		return err
+	}
+	b.pendingBlock = block
+	b.pendingState = statedb
+	return nil
+}
+
+// Fund credits the main PKr of the key with the amount of the currency when
+// the pending block is committed, the key being remembered to spend its outs.
+func (b *SimulatedBackend) Fund(key *keystore.Key, currency string, amount *big.Int) error {
+	return b.FundPKr(key, bind.GetMainPkr(key), currency, amount)
+}
+
+// FundPKr credits the PKr of the key with the amount of the currency when the
+// pending block is committed.
+func (b *SimulatedBackend) FundPKr(key *keystore.Key, pkr c_type.PKr, currency string, amount *big.Int) error {
+	if amount == nil || amount.Sign() <= 0 {
+		return errors.New("funding amount must be positive")
+	}
+	tk := key.Tk.ToTk()
+	if !superzk.IsMyPKr(&tk, &pkr) {
+		return errors.New("the pkr does not belong to the key")
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.accounts[key.Address.ToUint512()] = tk
+	asset := assets.Asset{Tkn: &assets.Token{
+		Currency: utils.CurrencyToUint256(currency),
+		Value:    utils.U256(*amount),
+	}}
+	number := b.blockchain.CurrentBlock().NumberU64() + 1
+	b.engine.fund(number, common.BytesToAddress(pkr[:]), asset)
+	if err := b.generatePending(b.pendingBlock.Transactions()); err != nil {
+		b.engine.unfund(number)
+		return err
+	}
+	return nil
+}
+
+// BalanceAt returns the amount of the currency the key can spend at the last
+// committed block.
+func (b *SimulatedBackend) BalanceAt(ctx context.Context, key *keystore.Key, currency string) (*big.Int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	tk := key.Tk.ToTk()
+	balance := new(big.Int)
+	for _, utxo := range b.utxos(&tk) {
+		if utxo.Asset.Tkn != nil && utils.Uint256ToCurrency(&utxo.Asset.Tkn.Currency) == currency {
+			balance.Add(balance, utxo.Asset.Tkn.Value.ToIntRef())
+		}
+	}
+	return balance, nil
+}
+
+// CodeAt returns the code associated with a certain account in the blockchain.
+func (b *SimulatedBackend) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if blockNumber != nil && blockNumber.Cmp(b.blockchain.CurrentBlock().Number()) != 0 {
+		return nil, errBlockNumberUnsupported
+	}
+	statedb, _ := b.blockchain.State()
+	return statedb.GetCode(contract), nil
+}
+
+// StorageAt returns the value of key in the storage of an account in the blockchain.
+func (b *SimulatedBackend) StorageAt(ctx context.Context, contract common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if blockNumber != nil && blockNumber.Cmp(b.blockchain.CurrentBlock().Number()) != 0 {
+		return nil, errBlockNumberUnsupported
+	}
+	statedb, _ := b.blockchain.State()
+	val := statedb.GetState(contract, key)
+	return val[:], nil
+}
+
+// TransactionReceipt returns the receipt of a transaction.
+func (b *SimulatedBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	receipt, _, _, _ := rawdb.ReadReceipt(b.database, txHash)
+	return receipt, nil
+}
+
+// PendingCodeAt returns the code associated with an account in the pending state.
+func (b *SimulatedBackend) PendingCodeAt(ctx context.Context, contract common.Address) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.pendingState.GetCode(contract), nil
+}
+
+// CallContract executes a contract call.
+func (b *SimulatedBackend) CallContract(ctx context.Context, call dece.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if blockNumber != nil && blockNumber.Cmp(b.blockchain.CurrentBlock().Number()) != 0 {
+		return nil, errBlockNumberUnsupported
+	}
+	statedb, err := b.blockchain.State()
+	if err != nil {
+		return nil, err
+	}
+	rval, _, _, err := b.callContract(ctx, call, b.blockchain.CurrentBlock(), statedb)
+	return rval, err
+}
+
+// PendingCallContract executes a contract call on the pending state.
+func (b *SimulatedBackend) PendingCallContract(ctx context.Context, call dece.CallMsg) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	rval, _, _, err := b.callContract(ctx, call, b.pendingBlock, b.pendingCopy())
+	return rval, err
+}
+
+// SuggestGasPrice implements ContractTransactor.SuggestGasPrice. Since the simulated
+// chain doesn't have miners, we just return a gas price of 1 ta for any call.
+func (b *SimulatedBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1), nil
+}
+
+// EstimateGas executes the requested code against the currently pending block/state and
+// returns the used amount of gas.
+func (b *SimulatedBackend) EstimateGas(ctx context.Context, call dece.CallMsg) (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// Determine the highest gas limit can be used during the estimation.
+	var (
+		lo  uint64 = params.TxGas - 1
+		hi  uint64
+		cap uint64
+	)
+	if call.Gas >= params.TxGas {
+		hi = call.Gas
+	} else {
+		hi = b.pendingBlock.GasLimit()
+	}
+	cap = hi
+
+	// Create a helper to check if a gas allowance results in an executable transaction
+	executable := func(gas uint64) bool {
+		call.Gas = gas
+
+		_, _, failed, err := b.callContract(ctx, call, b.pendingBlock, b.pendingCopy())
+		if err != nil || failed {
+			return false
+		}
+		return true
+	}
+	// Execute the binary search and hone in on an executable gas limit
+	for lo+1 < hi {
+		mid := (hi + lo) / 2
+		if !executable(mid) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	// Reject the transaction as invalid if it still fails at the highest allowance
+	if hi == cap {
+		if !executable(hi) {
+			return 0, fmt.Errorf("gas required exceeds allowance or always failing transaction")
+		}
+	}
+	return hi, nil
+}
+
+// callContract implements common code between normal and pending contract calls.
+// state is modified during execution, make sure to copy it if necessary.
+func (b *SimulatedBackend) callContract(ctx context.Context, call dece.CallMsg, block *types.Block, statedb *state.StateDB) ([]byte, uint64, bool, error) {
+	// Ensure message is initialized properly.
+	if call.GasPrice == nil {
+		call.GasPrice = big.NewInt(1)
+	}
+	if call.Gas == 0 {
+		call.Gas = 50000000
+	}
+	var from common.Address
+	if call.FromPKr != nil {
+		from = common.BytesToAddress(call.FromPKr[:])
+	}
+	fee := assets.Token{
+		Currency: utils.CurrencyToUint256(params.DefaultCurrency),
+		Value:    utils.U256(*new(big.Int).Mul(call.GasPrice, new(big.Int).SetUint64(call.Gas))),
+	}
+	msg := types.NewMessage(from, call.To, 0, callAsset(call), fee, call.GasPrice, call.Data)
+
+	evmContext := core.NewEVMContext(msg, block.Header(), b.blockchain, nil)
+	// Create a new environment which holds all relevant information
+	// about the transaction and calling mechanisms.
+	vmenv := vm.NewEVM(evmContext, statedb, b.config, vm.Config{})
+	gaspool := new(core.GasPool).AddGas(math.MaxUint64)
+	return core.ApplyMessage(vmenv, msg, gaspool)
+}
+
+// GenContractTx builds the unsigned transaction of a contract call or creation,
+// spending the outs of the funded account the call is made from.
+func (b *SimulatedBackend) GenContractTx(ctx context.Context, call dece.CallMsg) (*txtool.GTxParam, error) {
+	if call.FromPKr == nil {
+		return nil, errors.New("from is nil")
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	gasPrice := call.GasPrice
+	if gasPrice == nil {
+		gasPrice = big.NewInt(1)
+	}
+	contract := &stx.ContractCmd{Asset: callAsset(call), Data: call.Data}
+	if call.To != nil {
+		var to c_type.PKr
+		copy(to[:], call.To[:])
+		contract.To = &to
+	}
+	param := prepare.PreTxParam{
+		From:     call.From.ToUint512(),
+		RefundTo: call.FromPKr,
+		Cmds:     prepare.Cmds{Contract: contract},
+		Fee: assets.Token{
+			Currency: utils.CurrencyToUint256(params.DefaultCurrency),
+			Value:    utils.U256(*new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(call.Gas))),
+		},
+		GasPrice: gasPrice,
+	}
+	txParam, err := prepare.GenTxParam(&param, b, &prepare.DefaultTxParamState{})
+	if err != nil {
+		return nil, err
+	}
+	txParam.Gas = call.Gas
+	return txParam, nil
+}
+
+// CommitTx adds the signed transaction to the pending block.
+func (b *SimulatedBackend) CommitTx(ctx context.Context, gtx *txtool.GTx) error {
+	gasPrice := big.Int(gtx.GasPrice)
+	return b.SendTransaction(ctx, types.NewTxWithGTx(uint64(gtx.Gas), &gasPrice, &gtx.Tx))
+}
+
+// SendTransaction updates the pending block to include the given transaction.
+// It returns an error if the transaction is invalid.
+func (b *SimulatedBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	num := b.pendingBlock.NumberU64()
+	if err := verify.VerifyWithoutState(tx.Ehash().NewRef(), tx.GetZZSTX(), num); err != nil {
+		return err
+	}
+	if err := verify.VerifyWithState(tx.GetZZSTX(), b.pendingCopy().NextZState(), num); err != nil {
+		return err
+	}
+	return b.generatePending(append(b.pendingBlock.Transactions(), tx))
+}
+
+// pendingCopy returns a copy of the pending state, the calls done on it not
+// changing the pending block.
+func (b *SimulatedBackend) pendingCopy() *state.StateDB {
+	return b.pendingState.Copy()
+}
+
+// FilterLogs executes a log filter operation, blocking during execution and
+// returning all the results in one batch.
+func (b *SimulatedBackend) FilterLogs(ctx context.Context, query dece.FilterQuery) ([]types.Log, error) {
+	var filter *filters.Filter
+	if query.BlockHash != nil {
+		// Block filter requested, construct a single-shot filter
+		filter = filters.NewBlockFilter(&filterBackend{b.database, b.blockchain}, *query.BlockHash, query.Addresses, query.Topics)
+	} else {
+		// Initialize unset filter boundaried to run from genesis to chain head
+		from := int64(0)
+		if query.FromBlock != nil {
+			from = query.FromBlock.Int64()
+		}
+		to := int64(-1)
+		if query.ToBlock != nil {
+			to = query.ToBlock.Int64()
+		}
+		// Construct the range filter
+		filter = filters.NewRangeFilter(&filterBackend{b.database, b.blockchain}, from, to, query.Addresses, query.Topics)
+	}
+	// Run the filter and return all the logs
+	logs, err := filter.Logs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]types.Log, len(logs))
+	for i, log := range logs {
+		res[i] = *log
+	}
+	return res, nil
+}
+
+// SubscribeFilterLogs creates a background log filtering operation, returning a
+// subscription immediately, which can be used to stream the found events.
+func (b *SimulatedBackend) SubscribeFilterLogs(ctx context.Context, query dece.FilterQuery, ch chan<- types.Log) (dece.Subscription, error) {
+	// Subscribe to contract events
+	sink := make(chan []*types.Log)
+
+	sub, err := b.events.SubscribeLogs(query, sink)
+	if err != nil {
+		return nil, err
+	}
+	// Since we're getting logs in batches, we need to flatten them into a plain stream
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case logs := <-sink:
+				for _, log := range logs {
+					select {
+					case ch <- *log:
+					case err := <-sub.Err():
+						return err
+					case <-quit:
+						return nil
+					}
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// callAsset returns the asset sent along with the call, in DECE if the call
+// does not name its currency.
+func callAsset(call dece.CallMsg) (asset assets.Asset) {
+	if call.Value == nil || call.Value.Sign() == 0 {
+		return
+	}
+	currency := call.Currency
+	if currency == "" {
+		currency = params.DefaultCurrency
+	}
+	asset.Tkn = &assets.Token{
+		Currency: utils.CurrencyToUint256(currency),
+		Value:    utils.U256(*call.Value),
+	}
+	return
+}
+
+// utxos returns the unspent outs of the tk at the last committed block.
+func (b *SimulatedBackend) utxos(tk *c_type.Tk) (utxos prepare.Utxos) {
+	var (
+		roots []c_type.Uint256
+		dels  = make(map[c_type.Uint256]bool)
+	)
+	for num := uint64(0); num <= b.blockchain.CurrentBlock().NumberU64(); num++ {
+		hash := rawdb.ReadCanonicalHash(b.database, num)
+		if block := localdb.GetBlock(b.database, num, hash.HashToUint256()); block != nil {
+			roots = append(roots, block.Roots...)
+			for _, del := range block.Dels {
+				dels[del] = true
+			}
+		}
+	}
+	for _, root := range roots {
+		rs := localdb.GetRoot(b.database, &root)
+		if rs == nil || !superzk.IsMyPKr(tk, rs.OS.ToPKr()) {
+			continue
+		}
+		douts := flight.DecOut(tk, []txtool.Out{{Root: root, State: *rs}})
+		if len(douts) == 0 || len(douts[0].Nils) == 0 || dels[douts[0].Nils[0]] {
+			continue
+		}
+		utxos = append(utxos, prepare.Utxo{Root: root, Asset: douts[0].Asset})
+	}
+	return
+}
+
+// FindRoots implements prepare.TxParamGenerator.
+func (b *SimulatedBackend) FindRoots(pk *c_type.Uint512, currency string, amount *big.Int) (roots prepare.Utxos, remain big.Int) {
+	remain.Set(amount)
+	tk, ok := b.accounts[*pk]
+	if !ok {
+		return
+	}
+	for _, utxo := range b.utxos(&tk) {
+		if remain.Sign() <= 0 {
+			break
+		}
+		if utxo.Asset.Tkn != nil && utils.Uint256ToCurrency(&utxo.Asset.Tkn.Currency) == currency {
+			roots = append(roots, utxo)
+			remain.Sub(&remain, utxo.Asset.Tkn.Value.ToIntRef())
+		}
+	}
+	return
+}
+
+// FindRootsByTicket implements prepare.TxParamGenerator.
+func (b *SimulatedBackend) FindRootsByTicket(pk *c_type.Uint512, tickets []assets.Ticket) (roots prepare.Utxos, remain map[c_type.Uint256]c_type.Uint256) {
+	remain = make(map[c_type.Uint256]c_type.Uint256)
+	for _, ticket := range tickets {
+		remain[ticket.Value] = ticket.Category
+	}
+	tk, ok := b.accounts[*pk]
+	if !ok {
+		return
+	}
+	for _, utxo := range b.utxos(&tk) {
+		if utxo.Asset.Tkt == nil {
+			continue
+		}
+		if category, ok := remain[utxo.Asset.Tkt.Value]; ok && category == utxo.Asset.Tkt.Category {
+			roots = append(roots, utxo)
+			delete(remain, utxo.Asset.Tkt.Value)
+		}
+	}
+	return
+}
+
+// GetRoot implements prepare.TxParamGenerator.
+func (b *SimulatedBackend) GetRoot(root *c_type.Uint256) (utxo *prepare.Utxo) {
+	for _, tk := range b.accounts {
+		for _, u := range b.utxos(&tk) {
+			if u.Root == *root {
+				return &u
+			}
+		}
+	}
+	return nil
+}
+
+// DefaultRefundTo implements prepare.TxParamGenerator.
+func (b *SimulatedBackend) DefaultRefundTo(pk *c_type.Uint512) (ret *c_type.PKr) {
+	pkr := superzk.Pk2PKr(pk, nil)
+	return &pkr
+}
+
+// funding is an out created by the simulated engine.
+type funding struct {
+	addr  common.Address
+	asset assets.Asset
+}
+
+// simulatedEngine is the fake ethash engine of the simulated chain, adding the
+// fundings of a block when it is finalized so that generating and importing
+// the block give the same state.
+type simulatedEngine struct {
+	consensus.Engine
+
+	lock  sync.Mutex
+	funds map[uint64][]funding
+}
+
+// Finalize implements consensus.Engine, creating the funded outs before the
+// rewards are accumulated.
+func (e *simulatedEngine) Finalize(chain consensus.ChainReader, header *types.Header, statedb *state.StateDB, txs []*types.Transaction, receipts []*types.Receipt, gasReward uint64) (*types.Block, error) {
+	e.lock.Lock()
+	for _, f := range e.funds[header.Number.Uint64()] {
+		statedb.NextZState().AddTxOut(f.addr, f.asset, common.Hash{})
+	}
+	e.lock.Unlock()
+	return e.Engine.Finalize(chain, header, statedb, txs, receipts, gasReward)
+}
+
+func (e *simulatedEngine) fund(number uint64, addr common.Address, asset assets.Asset) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.funds[number] = append(e.funds[number], funding{addr, asset})
+}
+
+func (e *simulatedEngine) unfund(number uint64) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if funds := e.funds[number]; len(funds) > 0 {
+		e.funds[number] = funds[:len(funds)-1]
+	}
+}
+
+func (e *simulatedEngine) drop(number uint64) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	delete(e.funds, number)
+}
+
+// filterBackend implements filters.Backend to support filtering for logs without
+// taking bloom-bits acceleration structures into account.
+type filterBackend struct {
+	db decedb.Database
+	bc *core.BlockChain
+}
+
+func (fb *filterBackend) ChainDb() decedb.Database { return fb.db }
+func (fb *filterBackend) EventMux() *event.TypeMux { panic("not supported") }
+
+func (fb *filterBackend) HeaderByNumber(ctx context.Context, block rpc.BlockNumber) (*types.Header, error) {
+	if block == rpc.LatestBlockNumber {
+		return fb.bc.CurrentHeader(), nil
+	}
+	return fb.bc.GetHeaderByNumber(uint64(block.Int64())), nil
+}
+
+func (fb *filterBackend) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	return fb.bc.GetHeaderByHash(hash), nil
+}
+
+func (fb *filterBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
+	number := rawdb.ReadHeaderNumber(fb.db, hash)
+	if number == nil {
+		return nil, nil
+	}
+	return rawdb.ReadReceipts(fb.db, hash, *number), nil
+}
+
+func (fb *filterBackend) GetLogs(ctx context.Context, hash common.Hash) ([][]*types.Log, error) {
+	number := rawdb.ReadHeaderNumber(fb.db, hash)
+	if number == nil {
+		return nil, nil
+	}
+	receipts := rawdb.ReadReceipts(fb.db, hash, *number)
+	if receipts == nil {
+		return nil, nil
+	}
+	logs := make([][]*types.Log, len(receipts))
+	for i, receipt := range receipts {
+		logs[i] = receipt.Logs
+	}
+	return logs, nil
+}
+
+func (fb *filterBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	})
+}
+func (fb *filterBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription {
+	return fb.bc.SubscribeChainEvent(ch)
+}
+func (fb *filterBackend) SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription {
+	return fb.bc.SubscribeRemovedLogsEvent(ch)
+}
+func (fb *filterBackend) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription {
+	return fb.bc.SubscribeLogsEvent(ch)
+}
+
+func (fb *filterBackend) BloomStatus() (uint64, uint64) { return 4096, 0 }
+func (fb *filterBackend) ServiceFilter(ctx context.Context, ms *bloombits.MatcherSession) {
+	panic("not supported")
+}
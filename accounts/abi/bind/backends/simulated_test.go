@@ -0,0 +1,133 @@
+package backends
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/dece-cash/go-dece/accounts/abi"
+	"github.com/dece-cash/go-dece/accounts/abi/bind"
+	"github.com/dece-cash/go-dece/accounts/keystore"
+	"github.com/dece-cash/go-dece/common/hexutil"
+	"github.com/dece-cash/go-dece/crypto"
+)
+
+// getterABI and getterCode are a contract whose get method returns 42.
+const getterABI = `[{"constant":true,"inputs":[],"name":"get","outputs":[{"name":"","type":"uint256"}],"payable":false,"stateMutability":"view","type":"function"}]`
+
+var getterCode = hexutil.MustDecode("0x600a600c600039600a6000f3602a60005260206000f3")
+
+// issuerCode is the creation code of a contract issuing 1000 COIN to itself
+// through the issueToken log, the registration fee being paid from the DECE
+// sent with the deployment.
+var issuerCode = hexutil.MustDecode("0x60406000526103e860205260046040527f434f494e000000000000000000000000000000000000000000000000000000006060527f3be6bf24d822bcd6f6348f6f5a5c2d3108f04991ee63e80cde49a8c4746a0ef360406000a16001609ff3")
+
+func newTestKey(t *testing.T) *keystore.Key {
+	priv, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tk := crypto.PrivkeyToTk(priv)
+	return &keystore.Key{Address: tk.ToPk(), Tk: tk, PrivateKey: priv}
+}
+
+func TestSimulatedBackend(t *testing.T) {
+	sim := NewSimulatedBackend(50000000)
+	key := newTestKey(t)
+	ctx := context.Background()
+
+	if err := sim.Fund(key, "DECE", big.NewInt(1e18)); err != nil {
+		t.Fatalf("fund DECE: %v", err)
+	}
+	if err := sim.Fund(key, "ABC", big.NewInt(100)); err != nil {
+		t.Fatalf("fund ABC: %v", err)
+	}
+	if balance, _ := sim.BalanceAt(ctx, key, "DECE"); balance.Sign() != 0 {
+		t.Fatalf("balance before commit: have %v, want 0", balance)
+	}
+	if err := sim.Commit(); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if balance, _ := sim.BalanceAt(ctx, key, "ABC"); balance.Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("ABC balance: have %v, want 100", balance)
+	}
+
+	parsed, _ := abi.JSON(strings.NewReader(getterABI))
+	opts := bind.NewKeyedTransactor(key, bind.GetMainPkr(key), nil)
+	opts.GasLimit = 1000000
+	_, tx, _, err := bind.DeployContract(opts, parsed, getterCode, sim)
+	if err != nil {
+		t.Fatalf("deploy: %v", err)
+	}
+	sim.Commit()
+	addr, err := bind.WaitDeployed(ctx, sim, tx)
+	if err != nil {
+		t.Fatalf("wait deployed: %v", err)
+	}
+
+	contract := bind.NewBoundContract(addr, parsed, sim, sim, sim)
+	var out *big.Int
+	if err := contract.Call(&bind.CallOpts{}, &out, "get"); err != nil {
+		t.Fatalf("call: %v", err)
+	}
+	if out.Uint64() != 42 {
+		t.Fatalf("get: have %v, want 42", out)
+	}
+
+	before, _ := sim.BalanceAt(ctx, key, "DECE")
+	opts.Value = big.NewInt(5)
+	if _, err := contract.Transfer(opts); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	sim.Rollback()
+	if balance, _ := sim.BalanceAt(ctx, key, "DECE"); balance.Cmp(before) != 0 {
+		t.Fatalf("balance after rollback: have %v, want %v", balance, before)
+	}
+	if _, err := contract.Transfer(opts); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	sim.Commit()
+	if balance, _ := sim.BalanceAt(ctx, key, "DECE"); balance.Cmp(new(big.Int).Sub(before, big.NewInt(5))) >= 0 {
+		t.Fatalf("balance after transfer: have %v, want below %v", balance, before)
+	}
+}
+
+func TestSimulatedBackendIssueToken(t *testing.T) {
+	sim := NewSimulatedBackend(50000000)
+	key := newTestKey(t)
+	ctx := context.Background()
+
+	funds := new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))
+	if err := sim.Fund(key, "DECE", funds); err != nil {
+		t.Fatalf("fund DECE: %v", err)
+	}
+	sim.Commit()
+
+	parsed, _ := abi.JSON(strings.NewReader(getterABI))
+	opts := bind.NewKeyedTransactor(key, bind.GetMainPkr(key), nil)
+	opts.GasLimit = 1000000
+	opts.Value = new(big.Int).Mul(big.NewInt(500), big.NewInt(1e18))
+	_, tx, _, err := bind.DeployContract(opts, parsed, issuerCode, sim)
+	if err != nil {
+		t.Fatalf("deploy: %v", err)
+	}
+	sim.Commit()
+	addr, err := bind.WaitDeployed(ctx, sim, tx)
+	if err != nil {
+		t.Fatalf("wait deployed: %v", err)
+	}
+	statedb, err := sim.blockchain.State()
+	if err != nil {
+		t.Fatalf("state: %v", err)
+	}
+	if balance := statedb.GetBalance(addr, "COIN"); balance.Cmp(big.NewInt(1000)) != 0 {
+		t.Fatalf("issued COIN balance: have %v, want 1000", balance)
+	}
+	if owner := statedb.GetContrctAddressByToken("COIN"); owner != addr {
+		t.Fatalf("COIN registered to %x, want %x", owner, addr)
+	}
+	if balance := statedb.GetBalance(addr, "DECE"); balance.Cmp(opts.Value) >= 0 {
+		t.Fatalf("contract DECE balance %v, want the registration fee taken from %v", balance, opts.Value)
+	}
+}
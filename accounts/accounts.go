@@ -23,6 +23,7 @@ import (
 	"github.com/dece-cash/go-dece/common"
 	"github.com/dece-cash/go-dece/common/address"
 	"github.com/dece-cash/go-dece/event"
+	"github.com/dece-cash/go-dece/zero/txtool"
 	"github.com/dece-cash/go-dece/zero/utils"
 )
 
@@ -132,6 +133,13 @@ type Wallet interface {
 	GetSeedWithPassphrase(passphrase string) (*address.Seed, error)
 }
 
+// TxSigner is implemented by the wallets signing the txs without releasing the
+// seed of their account, like the hardware wallets.
+type TxSigner interface {
+	// SignTx generates the tx of param signed by the account of the wallet.
+	SignTx(param *txtool.GTxParam) (txtool.GTx, error)
+}
+
 // Backend is a "wallet provider" that may contain a batch of accounts they can
 // sign transactions with and upon request, do so.
 type Backend interface {
@@ -17,11 +17,17 @@
 package accounts
 
 import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"math"
 	"math/big"
 	"strings"
+
+	"github.com/dece-cash/go-dece/crypto"
 )
 
 // DefaultRootDerivationPath is the root path to which custom derivation endpoints
@@ -133,3 +139,52 @@ func (path DerivationPath) String() string {
 	}
 	return result
 }
+
+// DeriveKey derives the private key at path from a BIP-39 seed, following the
+// private parent to private child derivation of BIP-32. The key is used as the
+// seed of a DECE account, from which its sk and tk are generated.
+func DeriveKey(seed []byte, path DerivationPath) (*ecdsa.PrivateKey, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, fmt.Errorf("invalid seed length %d, want [16, 64]", len(seed))
+	}
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+
+	key, err := crypto.ToECDSA(sum[:32])
+	if err != nil {
+		return nil, errors.New("invalid master key, use another seed")
+	}
+	chainCode := sum[32:]
+
+	for _, component := range path {
+		data := make([]byte, 0, 37)
+		if component >= 0x80000000 {
+			data = append(data, 0)
+			data = append(data, crypto.FromECDSA(key)...)
+		} else {
+			data = append(data, crypto.CompressPubkey(&key.PublicKey)...)
+		}
+		var index [4]byte
+		binary.BigEndian.PutUint32(index[:], component)
+		data = append(data, index[:]...)
+
+		mac := hmac.New(sha512.New, chainCode)
+		mac.Write(data)
+		sum := mac.Sum(nil)
+
+		// The child is invalid for a tweak not below the curve order or a zero
+		// key, with a probability lower than 1 in 2^127
+		tweak := new(big.Int).SetBytes(sum[:32])
+		if tweak.Cmp(crypto.S256().Params().N) >= 0 {
+			return nil, fmt.Errorf("invalid child key at %v, use the next index", component)
+		}
+		child := tweak.Add(tweak, key.D)
+		child.Mod(child, crypto.S256().Params().N)
+		if key, err = crypto.ToECDSA(child.FillBytes(make([]byte, 32))); err != nil {
+			return nil, fmt.Errorf("invalid child key at %v, use the next index", component)
+		}
+		chainCode = sum[32:]
+	}
+	return key, nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2019 The dece.cash Authors
+// This file is part of the go-dece library.
+//
+// The go-dece library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dece library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dece library. If not, see <http://www.gnu.org/licenses/>.
+
+package accounts
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/dece-cash/go-dece/crypto"
+)
+
+// Tests that keys are derived as the test vectors of BIP-32.
+func TestDeriveKey(t *testing.T) {
+	tests := []struct {
+		seed string
+		path string
+		key  string
+	}{
+		{"000102030405060708090a0b0c0d0e0f", "m", "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35"},
+		{"000102030405060708090a0b0c0d0e0f", "m/0'", "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea"},
+		{"000102030405060708090a0b0c0d0e0f", "m/0'/1", "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368"},
+		{"000102030405060708090a0b0c0d0e0f", "m/0'/1/2'/2/1000000000", "471b76e389e528d6de6d816857e012c5455051cad6660850e58372a6c3e6e7c8"},
+	}
+	for i, tt := range tests {
+		seed, _ := hex.DecodeString(tt.seed)
+		var path DerivationPath
+		if tt.path != "m" {
+			var err error
+			if path, err = ParseDerivationPath(tt.path); err != nil {
+				t.Fatalf("test %d: path %s: %v", i, tt.path, err)
+			}
+		}
+		key, err := DeriveKey(seed, path)
+		if err != nil {
+			t.Fatalf("test %d: derive %s: %v", i, tt.path, err)
+		}
+		if have := hex.EncodeToString(crypto.FromECDSA(key)); have != tt.key {
+			t.Errorf("test %d: key at %s mismatch: have %s, want %s", i, tt.path, have, tt.key)
+		}
+	}
+	if _, err := DeriveKey([]byte{1, 2, 3}, DefaultBaseDerivationPath); err == nil {
+		t.Error("derived a key from a short seed")
+	}
+}
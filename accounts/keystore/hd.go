@@ -0,0 +1,161 @@
+// Copyright 2019 The dece.cash Authors
+// This file is part of the go-dece library.
+//
+// The go-dece library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-dece library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-dece library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"errors"
+
+	"github.com/tyler-smith/go-bip39"
+
+	"github.com/dece-cash/go-dece/accounts"
+	"github.com/dece-cash/go-dece/czero/c_type"
+	"github.com/dece-cash/go-dece/czero/superzk"
+)
+
+// HDGapLimit is the number of unused accounts following the last used one
+// after which the discovery of the accounts of a mnemonic stops.
+const HDGapLimit = 20
+
+// HDScanFunc calls match with the PKr of every output on the chain from the
+// block of the accounts.
+type HDScanFunc func(match func(pkr *c_type.PKr)) error
+
+// deriveHDKey derives the key at path from a BIP-39 mnemonic protected by the
+// optional mnemonic password.
+func deriveHDKey(mnemonic string, password string, path accounts.DerivationPath, at uint64) (*Key, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, password)
+	if err != nil {
+		return nil, err
+	}
+	priv, err := accounts.DeriveKey(seed, path)
+	if err != nil {
+		return nil, err
+	}
+	return newKeyFromECDSA(priv, at), nil
+}
+
+// ImportHDMnemonic stores the account at path of a BIP-39 mnemonic into the key
+// directory, encrypting it with the passphrase.
+func (ks *KeyStore) ImportHDMnemonic(mnemonic string, password string, path accounts.DerivationPath, passphrase string, at uint64) (accounts.Account, error) {
+	key, err := deriveHDKey(mnemonic, password, path, at)
+	if err != nil {
+		return accounts.Account{}, err
+	}
+	if ks.cache.hasAddress(key.Address) {
+		return accounts.Account{}, errors.New("account already exists")
+	}
+	return ks.importKey(key, passphrase)
+}
+
+// DeriveHDAccounts discovers the accounts of a BIP-39 mnemonic, incrementing
+// the last component of the base path. The chain is scanned once, the accounts
+// being derived up to HDGapLimit past the last used one as the scan goes. All
+// of them up to the last used one are stored, the first one always. The
+// accounts already in the key directory are returned too.
+func (ks *KeyStore) DeriveHDAccounts(mnemonic string, password string, base accounts.DerivationPath, scan HDScanFunc, passphrase string, at uint64) ([]accounts.Account, error) {
+	if len(base) == 0 {
+		return nil, errors.New("empty derivation path")
+	}
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, password)
+	if err != nil {
+		return nil, err
+	}
+	path := make(accounts.DerivationPath, len(base))
+	copy(path, base)
+
+	var (
+		keys []*Key
+		tks  []c_type.Tk
+		last = -1
+
+		// The PKrs scanned so far, checked again by the accounts derived later
+		pkrs []c_type.PKr
+		next int
+	)
+	// extend derives the accounts up to the gap past the last used one, and
+	// checks the new ones against the PKrs already scanned.
+	extend := func() error {
+		for {
+			for len(keys) < last+1+HDGapLimit {
+				priv, err := accounts.DeriveKey(seed, path)
+				if err != nil {
+					return err
+				}
+				key := newKeyFromECDSA(priv, at)
+				keys = append(keys, key)
+				tks = append(tks, key.Tk.ToTk())
+				path[len(path)-1]++
+			}
+			if next == len(keys) {
+				return nil
+			}
+			for ; next < len(keys); next++ {
+				for i := range pkrs {
+					if superzk.IsMyPKr(&tks[next], &pkrs[i]) {
+						last = next
+						break
+					}
+				}
+			}
+		}
+	}
+	if err := extend(); err != nil {
+		return nil, err
+	}
+	var derr error
+	err = scan(func(pkr *c_type.PKr) {
+		if derr != nil {
+			return
+		}
+		pkrs = append(pkrs, *pkr)
+		// The accounts up to the last used one can not move it further
+		for i := len(keys) - 1; i > last; i-- {
+			if superzk.IsMyPKr(&tks[i], pkr) {
+				last = i
+				break
+			}
+		}
+		derr = extend()
+	})
+	if err == nil {
+		err = derr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if last < 0 {
+		last = 0
+	}
+	var result []accounts.Account
+	for _, key := range keys[:last+1] {
+		if ks.cache.hasAddress(key.Address) {
+			account, err := ks.Find(accounts.Account{Address: key.Address})
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, account)
+			continue
+		}
+		account, err := ks.importKey(key, passphrase)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, account)
+	}
+	return result, nil
+}
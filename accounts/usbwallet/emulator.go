@@ -0,0 +1,144 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+package usbwallet
+
+import (
+	"sync"
+
+	"github.com/tyler-smith/go-bip39"
+
+	"github.com/dece-cash/go-dece/accounts"
+	"github.com/dece-cash/go-dece/crypto"
+	"github.com/dece-cash/go-dece/czero/c_superzk"
+	"github.com/dece-cash/go-dece/czero/c_type"
+	"github.com/dece-cash/go-dece/czero/superzk"
+)
+
+// Emulator is a software device running the DECE application, to use the
+// hardware wallet backend without a device. The seed is kept in memory, it is
+// not meant for accounts holding value.
+type Emulator struct {
+	seed []byte
+	lock sync.Mutex
+
+	// Confirm stands for the user confirming a signature on the device, all of
+	// them are confirmed when it is nil.
+	Confirm func(hash *c_type.Uint256, pkr *c_type.PKr) bool
+}
+
+// NewEmulator creates an emulator with the seed of a BIP-39 mnemonic, deriving
+// the same accounts as the keystore importing the mnemonic.
+func NewEmulator(mnemonic string, password string) (*Emulator, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, password)
+	if err != nil {
+		return nil, err
+	}
+	return &Emulator{seed: seed}, nil
+}
+
+func (self *Emulator) Exchange(apdu []byte) ([]byte, error) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	reply, sw := self.handle(apdu)
+	return append(reply, byte(sw>>8), byte(sw)), nil
+}
+
+func (self *Emulator) Close() error {
+	return nil
+}
+
+func (self *Emulator) handle(apdu []byte) ([]byte, uint16) {
+	if len(apdu) < 5 || len(apdu) != 5+int(apdu[4]) {
+		return nil, swInvalidData
+	}
+	if apdu[0] != apduCLA {
+		return nil, swClaNotSupported
+	}
+	path, rest, err := decodePath(apdu[5:])
+
+	switch apdu[1] {
+	case insGetVersion:
+		return []byte{1, 0, 0}, swOK
+
+	case insGetTk:
+		if err != nil || len(rest) != 0 {
+			return nil, swInvalidData
+		}
+		sk, err := self.sk(path)
+		if err != nil {
+			return nil, swInvalidData
+		}
+		tk, err := superzk.Sk2Tk(&sk)
+		if err != nil {
+			return nil, swInvalidData
+		}
+		return tk[:], swOK
+
+	case insSign:
+		var hash, a c_type.Uint256
+		var pkr c_type.PKr
+		switch {
+		case err != nil:
+			return nil, swInvalidData
+		case apdu[2] == signPKr && len(rest) == len(hash)+len(pkr):
+			copy(hash[:], rest)
+			copy(pkr[:], rest[len(hash):])
+		case apdu[2] == signZPKa && len(rest) == len(hash)+len(a)+len(pkr):
+			copy(hash[:], rest)
+			copy(a[:], rest[len(hash):])
+			copy(pkr[:], rest[len(hash)+len(a):])
+		default:
+			return nil, swInvalidData
+		}
+		sk, err := self.sk(path)
+		if err != nil {
+			return nil, swInvalidData
+		}
+		if tk, err := superzk.Sk2Tk(&sk); err != nil || !superzk.IsMyPKr(&tk, &pkr) {
+			return nil, swInvalidData
+		}
+		if self.Confirm != nil && !self.Confirm(&hash, &pkr) {
+			return nil, swDenied
+		}
+		var sign c_type.Uint512
+		if apdu[2] == signZPKa {
+			sign, err = c_superzk.SignZPKa(&sk, &hash, &a, &pkr)
+		} else {
+			sign, err = c_superzk.SignPKr_X(&sk, &hash, &pkr)
+		}
+		if err != nil {
+			return nil, swInvalidData
+		}
+		return sign[:], swOK
+
+	default:
+		return nil, swInsNotSupported
+	}
+}
+
+// sk derives the sk of the account at path.
+func (self *Emulator) sk(path accounts.DerivationPath) (sk c_type.Uint512, e error) {
+	priv, err := accounts.DeriveKey(self.seed, path)
+	if err != nil {
+		e = err
+		return
+	}
+	var seed c_type.Uint256
+	copy(seed[:], crypto.FromECDSA(priv))
+	sk = superzk.Seed2Sk(&seed)
+	return
+}
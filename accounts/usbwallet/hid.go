@@ -0,0 +1,113 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package usbwallet
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+)
+
+const (
+	hidReportSize = 64
+	hidChannel    = 0x0101
+	hidTag        = 0x05
+	hidHeaderSize = 5
+)
+
+// VendorID and ProductID identify the USB devices running the DECE application.
+var (
+	VendorID  uint16 = 0x1209
+	ProductID uint16 = 0xdece
+)
+
+// wrapAPDU frames apdu in HID reports.
+func wrapAPDU(apdu []byte) (reports [][]byte) {
+	payload := make([]byte, 2+len(apdu))
+	binary.BigEndian.PutUint16(payload, uint16(len(apdu)))
+	copy(payload[2:], apdu)
+
+	for seq := 0; len(payload) > 0; seq++ {
+		report := make([]byte, hidReportSize)
+		binary.BigEndian.PutUint16(report, hidChannel)
+		report[2] = hidTag
+		binary.BigEndian.PutUint16(report[3:], uint16(seq))
+		n := copy(report[hidHeaderSize:], payload)
+		payload = payload[n:]
+		reports = append(reports, report)
+	}
+	return
+}
+
+// unwrapAPDU reads the HID reports of an apdu.
+func unwrapAPDU(read func() ([]byte, error)) ([]byte, error) {
+	var (
+		apdu   []byte
+		length = -1
+	)
+	for seq := 0; length < 0 || len(apdu) < length; seq++ {
+		report, err := read()
+		if err != nil {
+			return nil, err
+		}
+		if len(report) < hidHeaderSize+2 {
+			return nil, errors.New("hid report too short")
+		}
+		if binary.BigEndian.Uint16(report) != hidChannel || report[2] != hidTag {
+			return nil, errors.New("hid report on an unknown channel")
+		}
+		if int(binary.BigEndian.Uint16(report[3:])) != seq {
+			return nil, errors.New("hid report out of sequence")
+		}
+		payload := report[hidHeaderSize:]
+		if seq == 0 {
+			length = int(binary.BigEndian.Uint16(payload))
+			payload = payload[2:]
+		}
+		apdu = append(apdu, payload...)
+	}
+	return apdu[:length], nil
+}
+
+// hidTransport exchanges the APDUs framed in the reports of a HID device.
+type hidTransport struct {
+	device io.ReadWriteCloser
+	lock   sync.Mutex
+}
+
+func (self *hidTransport) Exchange(apdu []byte) ([]byte, error) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	for _, report := range wrapAPDU(apdu) {
+		// The reports are written after the report number, 0 for the devices
+		// without numbered reports
+		if _, err := self.device.Write(append([]byte{0x00}, report...)); err != nil {
+			return nil, err
+		}
+	}
+	return unwrapAPDU(func() ([]byte, error) {
+		report := make([]byte, hidReportSize)
+		n, err := self.device.Read(report)
+		return report[:n], err
+	})
+}
+
+func (self *hidTransport) Close() error {
+	return self.device.Close()
+}
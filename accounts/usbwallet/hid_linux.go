@@ -0,0 +1,61 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// +build linux
+
+package usbwallet
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// enumerateHID lists the hidraw nodes of the devices with the vendor and product
+// ids.
+func enumerateHID(vendorID uint16, productID uint16) ([]string, error) {
+	uevents, err := filepath.Glob("/sys/class/hidraw/*/device/uevent")
+	if err != nil {
+		return nil, err
+	}
+	id := fmt.Sprintf("HID_ID=%04X:%08X:%08X", 0x03, vendorID, productID)
+
+	var paths []string
+	for _, uevent := range uevents {
+		blob, err := ioutil.ReadFile(uevent)
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(blob), "\n") {
+			if strings.EqualFold(strings.TrimSpace(line), id) {
+				node := filepath.Base(filepath.Dir(filepath.Dir(uevent)))
+				paths = append(paths, filepath.Join("/dev", node))
+				break
+			}
+		}
+	}
+	return paths, nil
+}
+
+func openHID(path string) (Transport, error) {
+	device, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &hidTransport{device: device}, nil
+}
@@ -0,0 +1,294 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+package usbwallet
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dece-cash/go-dece/accounts"
+	"github.com/dece-cash/go-dece/common/address"
+	"github.com/dece-cash/go-dece/event"
+	"github.com/dece-cash/go-dece/log"
+)
+
+const (
+	// USBScheme is the URL scheme of the accounts of the USB devices.
+	USBScheme = "usb"
+
+	// EmulatorScheme is the URL scheme of the accounts of the emulators.
+	EmulatorScheme = "emulator"
+)
+
+// refreshCycle is the maximum time between wallet refreshes (if USB hotplug
+// notifications don't work).
+const refreshCycle = time.Second
+
+// refreshThrottling is the minimum time between wallet refreshes to avoid USB
+// trashing.
+const refreshThrottling = 500 * time.Millisecond
+
+// Hub is a accounts.Backend that can find and handle the hardware wallets
+// running the DECE application. Each account of a device, at the default base
+// derivation path and the pinned ones, is a wallet of its own.
+type Hub struct {
+	scheme    string                               // URL scheme of the accounts
+	enumerate func() ([]string, error)             // Lists the paths of the present devices
+	open      func(path string) (Transport, error) // Opens the device at a path
+
+	paths     []accounts.DerivationPath // Derivation paths of the accounts of each device
+	devices   map[string]*device        // Opened devices by their path
+	wallets   []accounts.Wallet         // List of wallets currently tracked
+	refreshed time.Time                 // Time instance when the list of wallets was last refreshed
+
+	updateFeed  event.Feed              // Event feed to notify wallet additions/removals
+	updateScope event.SubscriptionScope // Subscription scope tracking current live listeners
+	updating    bool                    // Whether the event notification loop is running
+	quit        chan chan error
+
+	lock sync.RWMutex // Protects the internals of the hub from racey access
+}
+
+// NewHub creates a hub of the USB HID devices with VendorID and ProductID.
+func NewHub() *Hub {
+	return newHub(USBScheme, func() ([]string, error) {
+		return enumerateHID(VendorID, ProductID)
+	}, openHID)
+}
+
+// NewEmulatorHub creates a hub of emulated devices, the i-th one found at the
+// path "emulator-i".
+func NewEmulatorHub(emulators ...*Emulator) *Hub {
+	paths := make([]string, len(emulators))
+	for i := range emulators {
+		paths[i] = fmt.Sprintf("emulator-%d", i)
+	}
+	return newHub(EmulatorScheme, func() ([]string, error) {
+		return paths, nil
+	}, func(path string) (Transport, error) {
+		for i := range paths {
+			if paths[i] == path {
+				return emulators[i], nil
+			}
+		}
+		return nil, fmt.Errorf("unknown emulator %s", path)
+	})
+}
+
+func newHub(scheme string, enumerate func() ([]string, error), open func(path string) (Transport, error)) *Hub {
+	hub := &Hub{
+		scheme:    scheme,
+		enumerate: enumerate,
+		open:      open,
+		paths:     []accounts.DerivationPath{accounts.DefaultBaseDerivationPath},
+		devices:   make(map[string]*device),
+		quit:      make(chan chan error),
+	}
+	hub.refreshWallets()
+	return hub
+}
+
+// Wallets implements accounts.Backend, returning all the currently tracked USB
+// devices that appear to be hardware wallets.
+func (hub *Hub) Wallets() []accounts.Wallet {
+	// Make sure the list of wallets is up to date
+	hub.refreshWallets()
+
+	hub.lock.RLock()
+	defer hub.lock.RUnlock()
+
+	cpy := make([]accounts.Wallet, len(hub.wallets))
+	copy(cpy, hub.wallets)
+	return cpy
+}
+
+// refreshWallets scans the USB devices attached to the machine and updates the
+// list of wallets based on the found devices.
+func (hub *Hub) refreshWallets() {
+	// Don't scan the USB like crazy it the user fetches wallets in a loop
+	hub.lock.RLock()
+	elapsed := time.Since(hub.refreshed)
+	hub.lock.RUnlock()
+
+	if elapsed < refreshThrottling {
+		return
+	}
+	paths, err := hub.enumerate()
+	if err != nil {
+		log.Debug("Failed to enumerate USB devices", "scheme", hub.scheme, "err", err)
+		return
+	}
+	sort.Strings(paths)
+
+	hub.lock.Lock()
+
+	// Open the arrived devices and close the departed ones
+	present := make(map[string]bool)
+	for _, path := range paths {
+		present[path] = true
+		if _, ok := hub.devices[path]; ok {
+			continue
+		}
+		transport, err := hub.open(path)
+		if err != nil {
+			log.Debug("Failed to open USB device", "path", path, "err", err)
+			continue
+		}
+		hub.devices[path] = &device{transport: transport}
+	}
+	for path, dev := range hub.devices {
+		if !present[path] {
+			dev.transport.Close()
+			delete(hub.devices, path)
+		}
+	}
+
+	// Derive the accounts of the devices, keeping the known ones
+	known := make(map[accounts.URL]accounts.Wallet)
+	for _, wallet := range hub.wallets {
+		known[wallet.URL()] = wallet
+	}
+	var wallets []accounts.Wallet
+	for _, path := range paths {
+		dev := hub.devices[path]
+		if dev == nil {
+			continue
+		}
+		for _, derivationPath := range hub.paths {
+			url := hub.accountURL(path, derivationPath)
+			if wallet, ok := known[url]; ok {
+				wallets = append(wallets, wallet)
+				delete(known, url)
+				continue
+			}
+			account, err := hub.deriveAccount(dev, path, derivationPath)
+			if err != nil {
+				log.Debug("Failed to derive USB wallet account", "url", url, "err", err)
+				continue
+			}
+			wallets = append(wallets, &wallet{hub: hub, device: dev, devicePath: path, path: derivationPath, account: account})
+		}
+	}
+	sort.Slice(wallets, func(i, j int) bool {
+		return wallets[i].URL().Cmp(wallets[j].URL()) < 0
+	})
+
+	events := []accounts.WalletEvent{}
+	for _, wallet := range wallets {
+		if _, ok := known[wallet.URL()]; !ok && !hub.tracked(wallet) {
+			events = append(events, accounts.WalletEvent{Wallet: wallet, Kind: accounts.WalletArrived})
+		}
+	}
+	for _, wallet := range known {
+		events = append(events, accounts.WalletEvent{Wallet: wallet, Kind: accounts.WalletDropped})
+	}
+	hub.refreshed = time.Now()
+	hub.wallets = wallets
+	hub.lock.Unlock()
+
+	// Fire all wallet events and return
+	for _, event := range events {
+		hub.updateFeed.Send(event)
+	}
+}
+
+// tracked returns whether the wallet was tracked before the refresh.
+func (hub *Hub) tracked(wallet accounts.Wallet) bool {
+	for _, w := range hub.wallets {
+		if w == wallet {
+			return true
+		}
+	}
+	return false
+}
+
+// accountURL returns the URL of the account at path of a device, the path of
+// the device followed by the derivation path.
+func (hub *Hub) accountURL(devicePath string, path accounts.DerivationPath) accounts.URL {
+	return accounts.URL{Scheme: hub.scheme, Path: devicePath + "/" + path.String()}
+}
+
+// deriveAccount asks the device for the tk of the account at path.
+func (hub *Hub) deriveAccount(dev *device, devicePath string, path accounts.DerivationPath) (accounts.Account, error) {
+	tk, err := dev.tk(path)
+	if err != nil {
+		return accounts.Account{}, err
+	}
+	var tkAddress address.TKAddress
+	copy(tkAddress[:], tk[:])
+	return accounts.Account{
+		Address: tkAddress.ToPk(),
+		Tk:      tkAddress,
+		URL:     hub.accountURL(devicePath, path),
+	}, nil
+}
+
+// pin tracks the account at path of each device in a wallet.
+func (hub *Hub) pin(path accounts.DerivationPath) {
+	hub.lock.Lock()
+	for _, known := range hub.paths {
+		if known.String() == path.String() {
+			hub.lock.Unlock()
+			return
+		}
+	}
+	hub.paths = append(hub.paths, path)
+	hub.refreshed = time.Time{}
+	hub.lock.Unlock()
+
+	hub.refreshWallets()
+}
+
+// Subscribe implements accounts.Backend, creating an async subscription to
+// receive notifications on the addition or removal of USB wallets.
+func (hub *Hub) Subscribe(sink chan<- accounts.WalletEvent) event.Subscription {
+	// We need the mutex to reliably start/stop the update loop
+	hub.lock.Lock()
+	defer hub.lock.Unlock()
+
+	// Subscribe the caller and track the subscriber count
+	sub := hub.updateScope.Track(hub.updateFeed.Subscribe(sink))
+
+	// Subscribers require an active notification loop, start it
+	if !hub.updating {
+		hub.updating = true
+		go hub.updater()
+	}
+	return sub
+}
+
+// updater is responsible for maintaining an up-to-date list of wallets managed
+// by the USB hub, and for firing wallet addition/removal events.
+func (hub *Hub) updater() {
+	for {
+		// Wait for a refresh timeout, the devices are not notified
+		time.Sleep(refreshCycle)
+
+		// Run the wallet refresher
+		hub.refreshWallets()
+
+		// If all our subscribers left, stop the updater
+		hub.lock.Lock()
+		if hub.updateScope.Count() == 0 {
+			hub.updating = false
+			hub.lock.Unlock()
+			return
+		}
+		hub.lock.Unlock()
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package usbwallet implements the support for the USB hardware wallets keeping
+// the seeds of DECE accounts on the device.
+//
+// The DECE application of a device is driven by ISO 7816-4 APDUs:
+//
+//   CLA | INS | P1 | P2 | Lc | data
+//
+// and answers with its data followed by the status word SW1 | SW2, 0x9000 on
+// success. The CLA is 0xe0 and the instructions are:
+//
+//   INS   P1    data                         reply
+//   0x01  0x00  -                            major | minor | patch
+//   0x02  0x00  path                         tk (64 bytes)
+//   0x04  0x00  path | hash | pkr            PKr signature (64 bytes)
+//   0x04  0x01  path | hash | a | pkr        ZPKa signature (64 bytes)
+//
+// A path is the number of its components followed by each of them as 4 big
+// endian bytes, the hash is the 32 bytes the tx signs and the pkr the 96 bytes
+// of the address signed for. The device derives the seed of the account at path
+// from its BIP-39 mnemonic as in BIP-32, the sk and the tk of the account from
+// the seed, and never returns the seed nor the sk. It shows the hash and the
+// pkr of a signature and waits for the user to confirm it.
+//
+// Over USB HID, an APDU is carried by reports of 64 bytes, each one starting
+// with the channel 0x0101, the tag 0x05 and the big endian sequence number of
+// the report. The first report follows with the big endian length of the APDU,
+// and the last one is padded with zeros. The reply is framed the same way.
+package usbwallet
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/dece-cash/go-dece/accounts"
+	"github.com/dece-cash/go-dece/czero/c_type"
+)
+
+const (
+	apduCLA = 0xe0
+
+	insGetVersion = 0x01
+	insGetTk      = 0x02
+	insSign       = 0x04
+
+	signPKr  = 0x00
+	signZPKa = 0x01
+
+	maxPathLen = 10
+)
+
+// The status words answered by the device.
+const (
+	swOK              = 0x9000
+	swDenied          = 0x6985
+	swInvalidData     = 0x6a80
+	swInsNotSupported = 0x6d00
+	swClaNotSupported = 0x6e00
+)
+
+var (
+	// ErrDenied is returned when the user rejects a signature on the device.
+	ErrDenied = errors.New("signature denied on the device")
+
+	// ErrSeedOnDevice is returned when the seed of a hardware wallet account is
+	// requested.
+	ErrSeedOnDevice = errors.New("seed is kept on the hardware wallet")
+)
+
+// Transport exchanges the APDUs with a device.
+type Transport interface {
+	Exchange(apdu []byte) ([]byte, error)
+	Close() error
+}
+
+func encodePath(path accounts.DerivationPath) ([]byte, error) {
+	if len(path) == 0 || len(path) > maxPathLen {
+		return nil, fmt.Errorf("derivation path length %d not in [1, %d]", len(path), maxPathLen)
+	}
+	data := []byte{byte(len(path))}
+	for _, component := range path {
+		var bs [4]byte
+		binary.BigEndian.PutUint32(bs[:], component)
+		data = append(data, bs[:]...)
+	}
+	return data, nil
+}
+
+func decodePath(data []byte) (path accounts.DerivationPath, rest []byte, e error) {
+	if len(data) == 0 || data[0] == 0 || data[0] > maxPathLen || len(data) < 1+4*int(data[0]) {
+		e = errors.New("invalid derivation path")
+		return
+	}
+	for i := 0; i < int(data[0]); i++ {
+		path = append(path, binary.BigEndian.Uint32(data[1+4*i:]))
+	}
+	rest = data[1+4*int(data[0]):]
+	return
+}
+
+// device is the client of the DECE application of a device.
+type device struct {
+	transport Transport
+}
+
+func (self *device) exchange(ins byte, p1 byte, data []byte) ([]byte, error) {
+	if len(data) > 255 {
+		return nil, fmt.Errorf("apdu data length %d too large", len(data))
+	}
+	apdu := append([]byte{apduCLA, ins, p1, 0x00, byte(len(data))}, data...)
+	reply, err := self.transport.Exchange(apdu)
+	if err != nil {
+		return nil, err
+	}
+	if len(reply) < 2 {
+		return nil, errors.New("reply without status word")
+	}
+	sw := binary.BigEndian.Uint16(reply[len(reply)-2:])
+	switch sw {
+	case swOK:
+		return reply[:len(reply)-2], nil
+	case swDenied:
+		return nil, ErrDenied
+	default:
+		return nil, fmt.Errorf("device status word %#04x", sw)
+	}
+}
+
+func (self *device) version() (ret [3]byte, e error) {
+	reply, err := self.exchange(insGetVersion, 0x00, nil)
+	if err != nil {
+		e = err
+		return
+	}
+	if len(reply) != len(ret) {
+		e = errors.New("invalid version reply")
+		return
+	}
+	copy(ret[:], reply)
+	return
+}
+
+func (self *device) tk(path accounts.DerivationPath) (tk c_type.Tk, e error) {
+	data, err := encodePath(path)
+	if err != nil {
+		e = err
+		return
+	}
+	reply, err := self.exchange(insGetTk, 0x00, data)
+	if err != nil {
+		e = err
+		return
+	}
+	if len(reply) != len(tk) {
+		e = errors.New("invalid tk reply")
+		return
+	}
+	copy(tk[:], reply)
+	return
+}
+
+func (self *device) sign(path accounts.DerivationPath, hash *c_type.Uint256, a *c_type.Uint256, pkr *c_type.PKr) (sign c_type.Uint512, e error) {
+	data, err := encodePath(path)
+	if err != nil {
+		e = err
+		return
+	}
+	p1 := byte(signPKr)
+	data = append(data, hash[:]...)
+	if a != nil {
+		p1 = signZPKa
+		data = append(data, a[:]...)
+	}
+	data = append(data, pkr[:]...)
+	reply, err := self.exchange(insSign, p1, data)
+	if err != nil {
+		e = err
+		return
+	}
+	if len(reply) != len(sign) {
+		e = errors.New("invalid signature reply")
+		return
+	}
+	copy(sign[:], reply)
+	return
+}
@@ -0,0 +1,190 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+package usbwallet
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/tyler-smith/go-bip39"
+
+	"github.com/dece-cash/go-dece/accounts"
+	"github.com/dece-cash/go-dece/crypto"
+	"github.com/dece-cash/go-dece/czero/c_superzk"
+	"github.com/dece-cash/go-dece/czero/c_type"
+)
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestMain(m *testing.M) {
+	c_superzk.InitParams_NoCircuit()
+	os.Exit(m.Run())
+}
+
+func newTestWallet(t *testing.T) (*Emulator, *Hub, *wallet) {
+	emulator, err := NewEmulator(testMnemonic, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hub := NewEmulatorHub(emulator)
+	wallets := hub.Wallets()
+	if len(wallets) != 1 {
+		t.Fatalf("wallets of the emulator: have %d, want 1", len(wallets))
+	}
+	return emulator, hub, wallets[0].(*wallet)
+}
+
+// Tests that the device derives the accounts of the keystore importing the
+// same mnemonic.
+func TestEmulatorAccounts(t *testing.T) {
+	_, hub, w := newTestWallet(t)
+
+	seed := bip39.NewSeed(testMnemonic, "")
+	for i := uint32(0); i < 3; i++ {
+		path := append(accounts.DerivationPath{}, accounts.DefaultBaseDerivationPath...)
+		path[len(path)-1] += i
+
+		priv, err := accounts.DeriveKey(seed, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		account, err := w.Derive(path, i == 2)
+		if err != nil {
+			t.Fatalf("derive %v: %v", path, err)
+		}
+		if account.Tk != crypto.PrivkeyToTk(priv) {
+			t.Errorf("tk at %v mismatch", path)
+		}
+	}
+	wallets := hub.Wallets()
+	if len(wallets) != 2 {
+		t.Fatalf("wallets after pinning: have %d, want 2", len(wallets))
+	}
+	if url := wallets[1].URL().String(); url != "emulator://emulator-0/m/44'/60'/0'/0/2" {
+		t.Errorf("pinned wallet url: %s", url)
+	}
+	if _, err := w.GetSeed(); err != ErrSeedOnDevice {
+		t.Errorf("seed of the device: have %v, want %v", err, ErrSeedOnDevice)
+	}
+}
+
+func TestEmulatorSign(t *testing.T) {
+	emulator, _, w := newTestWallet(t)
+
+	hash := c_type.RandUint256()
+	pkr := w.account.GetDefaultPkr(1)
+	sign, err := w.SignPKr(&hash, &pkr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c_superzk.VerifyPKr_X(&hash, &sign, &pkr) {
+		t.Error("signature of the device does not verify")
+	}
+
+	path := append(accounts.DerivationPath{}, accounts.DefaultBaseDerivationPath...)
+	path[len(path)-1]++
+	other, err := w.Derive(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	foreign := other.GetDefaultPkr(1)
+	if _, err := w.SignPKr(&hash, &foreign); err == nil {
+		t.Error("signed for the pkr of another account")
+	}
+
+	emulator.Confirm = func(*c_type.Uint256, *c_type.PKr) bool { return false }
+	if _, err := w.SignPKr(&hash, &pkr); err != ErrDenied {
+		t.Errorf("rejected signature: have %v, want %v", err, ErrDenied)
+	}
+}
+
+// hidEmulator stands for a HID device running the emulator.
+type hidEmulator struct {
+	emulator *Emulator
+	written  [][]byte
+	replies  [][]byte
+}
+
+func (self *hidEmulator) Write(report []byte) (int, error) {
+	self.written = append(self.written, report[1:])
+	reports := self.written
+	apdu, err := unwrapAPDU(func() ([]byte, error) {
+		if len(reports) == 0 {
+			return nil, errIncomplete
+		}
+		report := reports[0]
+		reports = reports[1:]
+		return report, nil
+	})
+	if err == errIncomplete {
+		return len(report), nil
+	}
+	self.written = nil
+	if err != nil {
+		return 0, err
+	}
+	reply, _ := self.emulator.Exchange(apdu)
+	self.replies = append(self.replies, wrapAPDU(reply)...)
+	return len(report), nil
+}
+
+func (self *hidEmulator) Read(report []byte) (int, error) {
+	n := copy(report, self.replies[0])
+	self.replies = self.replies[1:]
+	return n, nil
+}
+
+func (self *hidEmulator) Close() error {
+	return nil
+}
+
+var errIncomplete = errors.New("incomplete apdu")
+
+func TestHIDTransport(t *testing.T) {
+	emulator, err := NewEmulator(testMnemonic, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dev := &device{transport: &hidTransport{device: &hidEmulator{emulator: emulator}}}
+	if version, err := dev.version(); err != nil || version != [3]byte{1, 0, 0} {
+		t.Fatalf("version: have %v %v", version, err)
+	}
+	// The tk and the signature replies span two reports
+	tk, err := dev.tk(accounts.DefaultBaseDerivationPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	direct, err := (&device{transport: emulator}).tk(accounts.DefaultBaseDerivationPath)
+	if err != nil || tk != direct {
+		t.Fatalf("tk over hid mismatch: %v", err)
+	}
+
+	apdu := bytes.Repeat([]byte{0xde, 0xce}, 150)
+	reports := wrapAPDU(apdu)
+	if len(reports) != 6 {
+		t.Fatalf("reports of %d bytes: have %d, want 6", len(apdu), len(reports))
+	}
+	unwrapped, err := unwrapAPDU(func() ([]byte, error) {
+		report := reports[0]
+		reports = reports[1:]
+		return report, nil
+	})
+	if err != nil || !bytes.Equal(unwrapped, apdu) {
+		t.Fatalf("unwrapped apdu mismatch: %v", err)
+	}
+}
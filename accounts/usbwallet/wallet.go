@@ -0,0 +1,126 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+package usbwallet
+
+import (
+	"fmt"
+
+	"github.com/dece-cash/go-dece/accounts"
+	"github.com/dece-cash/go-dece/common/address"
+	"github.com/dece-cash/go-dece/czero/c_type"
+	"github.com/dece-cash/go-dece/czero/superzk"
+	"github.com/dece-cash/go-dece/zero/txtool"
+	"github.com/dece-cash/go-dece/zero/txtool/flight"
+)
+
+// wallet implements accounts.Wallet for the account at a derivation path of a
+// hardware wallet, the device signing the txs of the account.
+type wallet struct {
+	hub        *Hub
+	device     *device
+	devicePath string
+	path       accounts.DerivationPath
+	account    accounts.Account
+}
+
+// URL implements accounts.Wallet, returning the URL of the device and the
+// derivation path of the account.
+func (w *wallet) URL() accounts.URL {
+	return w.account.URL
+}
+
+// Status implements accounts.Wallet, returning the version of the DECE
+// application running on the device.
+func (w *wallet) Status() (string, error) {
+	version, err := w.device.version()
+	if err != nil {
+		return "Offline", err
+	}
+	return fmt.Sprintf("Online, DECE app v%d.%d.%d", version[0], version[1], version[2]), nil
+}
+
+// Open implements accounts.Wallet, but is a noop since the device is opened by
+// the hub when it arrives.
+func (w *wallet) Open(passphrase string) error { return nil }
+
+// Close implements accounts.Wallet, but is a noop since the device is closed by
+// the hub when it departs.
+func (w *wallet) Close() error { return nil }
+
+// Accounts implements accounts.Wallet, returning the account at the derivation
+// path of the wallet.
+func (w *wallet) Accounts() []accounts.Account {
+	return []accounts.Account{w.account}
+}
+
+// Contains implements accounts.Wallet, returning whether a particular account is
+// or is not wrapped by this wallet instance.
+func (w *wallet) Contains(account accounts.Account) bool {
+	return account.Address == w.account.Address && (account.URL == (accounts.URL{}) || account.URL == w.account.URL)
+}
+
+// Derive implements accounts.Wallet, deriving the account at path on the
+// device. A pinned account is tracked by the hub in a wallet of its own.
+func (w *wallet) Derive(path accounts.DerivationPath, pin bool) (accounts.Account, error) {
+	account, err := w.hub.deriveAccount(w.device, w.devicePath, path)
+	if err != nil {
+		return accounts.Account{}, err
+	}
+	if pin {
+		w.hub.pin(path)
+	}
+	return account, nil
+}
+
+func (w *wallet) IsMine(pkr c_type.PKr) bool {
+	tk := w.account.Tk.ToTk()
+	return superzk.IsMyPKr(&tk, &pkr)
+}
+
+// AddressUnlocked implements accounts.Wallet, the accounts of a device are
+// always available as the user confirms each signature on it.
+func (w *wallet) AddressUnlocked(account accounts.Account) (bool, error) {
+	if !w.Contains(account) {
+		return false, accounts.ErrUnknownAccount
+	}
+	return true, nil
+}
+
+func (w *wallet) GetSeed() (*address.Seed, error) {
+	return nil, ErrSeedOnDevice
+}
+
+func (w *wallet) GetSeedWithPassphrase(passphrase string) (*address.Seed, error) {
+	return nil, ErrSeedOnDevice
+}
+
+// SignTx implements accounts.TxSigner, generating the tx of param with the
+// signatures made on the device.
+func (w *wallet) SignTx(param *txtool.GTxParam) (txtool.GTx, error) {
+	return flight.GenTxWithSigner(param, w)
+}
+
+func (w *wallet) Tk() c_type.Tk {
+	return w.account.Tk.ToTk()
+}
+
+func (w *wallet) SignPKr(data *c_type.Uint256, pkr *c_type.PKr) (c_type.Uint512, error) {
+	return w.device.sign(w.path, data, nil, pkr)
+}
+
+func (w *wallet) SignZPKa(data *c_type.Uint256, a *c_type.Uint256, pkr *c_type.PKr) (c_type.Uint512, error) {
+	return w.device.sign(w.path, data, a, pkr)
+}
@@ -0,0 +1,154 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dece-cash/go-dece/accounts/keystore"
+	"github.com/dece-cash/go-dece/common/address"
+	"github.com/dece-cash/go-dece/core"
+	"github.com/dece-cash/go-dece/czero/c_superzk"
+	"github.com/dece-cash/go-dece/czero/c_type"
+	"github.com/dece-cash/go-dece/czero/superzk"
+)
+
+func TestMain(m *testing.M) {
+	c_superzk.InitParams_NoCircuit()
+	scryptN, scryptP = keystore.LightScryptN, keystore.LightScryptP
+	os.Exit(m.Run())
+}
+
+func randomPK(t *testing.T) address.PKAddress {
+	seed := c_type.RandUint256()
+	sk := superzk.Seed2Sk(&seed)
+	tk, err := superzk.Sk2Tk(&sk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk, err := superzk.Tk2Pk(&tk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return address.PKAddress(pk)
+}
+
+func TestParseAmounts(t *testing.T) {
+	amount, err := parseDece("1.5")
+	if err != nil || amount.Cmp(big.NewInt(1500000000000000000)) != 0 {
+		t.Fatalf("1.5 DECE: have %v %v", amount, err)
+	}
+	if _, err := parseDece("0.0000000000000000001"); err == nil {
+		t.Fatal("parsed an amount below a ta")
+	}
+	if _, err := parseAlloc("notanaddress=1"); err == nil {
+		t.Fatal("parsed an allocation to an invalid address")
+	}
+	sips, err := parseSIPs("5, 6")
+	if err != nil || sips != [sipCount]uint64{5, 6} {
+		t.Fatalf("sips: have %v %v", sips, err)
+	}
+	if _, err := parseSIPs("1,2,3,4,5,6,7,8"); err == nil {
+		t.Fatal("parsed more SIP blocks than forks")
+	}
+}
+
+func TestSubnet(t *testing.T) {
+	spec := newNetwork()
+	spec.Subnet = "10.0.0.0/28"
+	spec.Miners = 6
+	if _, err := spec.ips(); err == nil {
+		t.Fatal("placed 6 miners in a /28 from the host 10")
+	}
+	spec.Miners = 5
+	ips, err := spec.ips()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ips.Bootnode.String() != "10.0.0.2" || ips.Miners[4].String() != "10.0.0.14" {
+		t.Fatalf("ips: have bootnode %v, last miner %v", ips.Bootnode, ips.Miners[4])
+	}
+}
+
+func TestDeploy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "decenet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	pk := randomPK(t)
+	spec := newNetwork()
+	spec.Name = "testnet"
+	spec.NetworkId = 4242
+	spec.Miners = 3
+	spec.StatsSecret = "secret"
+	spec.MinerAlloc = big.NewInt(10)
+	spec.Alloc[pk.String()] = big.NewInt(1000)
+	spec.SIPs[6] = 100
+	spec.Stake.PayWindow = 10
+	spec.Stake.BasePrice = new(big.Int)
+	if err := deploy(spec, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	blob, err := ioutil.ReadFile(filepath.Join(dir, "genesis.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	genesis := new(core.Genesis)
+	if err := json.Unmarshal(blob, genesis); err != nil {
+		t.Fatal(err)
+	}
+	if genesis.Config.ChainID.Uint64() != 4242 || genesis.Config.SIP1Block.Sign() != 0 || genesis.Config.SIP7Block.Uint64() != 100 {
+		t.Fatalf("genesis config: %v", genesis.Config)
+	}
+	if stake := genesis.Config.Stake; stake == nil || stake.PayWindow != 10 || stake.BasePrice != nil {
+		t.Fatalf("genesis stake config: %v", stake)
+	}
+	if len(genesis.Alloc) != 4 {
+		t.Fatalf("genesis allocations: have %d, want 4", len(genesis.Alloc))
+	}
+	total := new(big.Int)
+	for _, account := range genesis.Alloc {
+		total.Add(total, account.Balance)
+	}
+	if total.Cmp(big.NewInt(1030)) != 0 {
+		t.Fatalf("genesis allocated %v, want 1030", total)
+	}
+
+	compose, err := ioutil.ReadFile(filepath.Join(dir, "docker-compose.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"miner2:", "@172.29.0.2:40405", "--networkid 4242", "WS_SECRET=secret", "\"8547:8545\"", "- subnet: 172.29.0.0/24"} {
+		if !strings.Contains(string(compose), want) {
+			t.Errorf("docker-compose.yml misses %q", want)
+		}
+	}
+	for _, file := range []string{"bootnode/boot.key", "miner0/password", "miner2/keystore"} {
+		if _, err := os.Stat(filepath.Join(dir, file)); err != nil {
+			t.Errorf("missing %s: %v", file, err)
+		}
+	}
+}
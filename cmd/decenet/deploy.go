@@ -0,0 +1,193 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/dece-cash/go-dece/accounts/keystore"
+	"github.com/dece-cash/go-dece/common/address"
+	"github.com/dece-cash/go-dece/crypto"
+	"github.com/dece-cash/go-dece/p2p/discover"
+)
+
+// bootnodePort is the discovery port of the bootnode.
+const bootnodePort = 40405
+
+// The scrypt parameters encrypting the miner keys.
+var (
+	scryptN = keystore.StandardScryptN
+	scryptP = keystore.StandardScryptP
+)
+
+// composeFile is the docker-compose file running a bootnode, the miners and
+// optionally a stats server in a bridge network with fixed addresses, the
+// enode of the bootnode needing an IP.
+var composeFile = `version: "3"
+
+services:
+  bootnode:
+    image: {{.Image}}
+    entrypoint: bootnode
+    command: -nodekey /decenet/boot.key -addr :{{.BootnodePort}}
+    volumes:
+      - ./bootnode:/decenet
+    networks:
+      {{.Name}}:
+        ipv4_address: {{.IPs.Bootnode}}
+    restart: always
+{{- if .StatsSecret}}
+
+  stats:
+    image: {{.StatsImage}}
+    environment:
+      - WS_SECRET={{.StatsSecret}}
+    ports:
+      - "{{.StatsPort}}:3000"
+    networks:
+      {{.Name}}:
+        ipv4_address: {{.IPs.Stats}}
+    restart: always
+{{- end}}
+{{- range $i, $ip := .IPs.Miners}}
+
+  miner{{$i}}:
+    image: {{$.Image}}
+    entrypoint: /bin/sh
+    command: -c "gece --datadir /root/.dece init /decenet/genesis.json && exec gece --datadir /root/.dece --keystore /decenet/miner/keystore --networkid {{$.NetworkId}} --bootnodes {{$.Enode}} --nat extip:{{$ip}} --mine{{if $.RPCPort}} --rpc --rpcaddr 0.0.0.0 --rpcvhosts '*'{{end}}{{if $.StatsSecret}} --decestats miner{{$i}}:{{$.StatsSecret}}@{{$.IPs.Stats}}:3000{{end}}"
+    volumes:
+      - ./genesis.json:/decenet/genesis.json:ro
+      - ./miner{{$i}}:/decenet/miner
+      - miner{{$i}}-data:/root/.dece
+{{- if $.RPCPort}}
+    ports:
+      - "{{rpcPort $i}}:8545"
+{{- end}}
+    networks:
+      {{$.Name}}:
+        ipv4_address: {{$ip}}
+    depends_on:
+      - bootnode
+    restart: always
+{{- end}}
+
+volumes:
+{{- range $i, $ip := .IPs.Miners}}
+  miner{{$i}}-data:
+{{- end}}
+
+networks:
+  {{.Name}}:
+    driver: bridge
+    ipam:
+      config:
+        - subnet: {{.Subnet}}
+`
+
+// deploy writes the genesis, the keys and the docker-compose file of the
+// network to dir.
+func deploy(spec *network, dir string) error {
+	if err := spec.validate(); err != nil {
+		return err
+	}
+	ips, err := spec.ips()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "bootnode"), 0700); err != nil {
+		return err
+	}
+
+	// The bootnode key
+	bootKey, err := crypto.GenerateKey()
+	if err != nil {
+		return err
+	}
+	if err := crypto.SaveECDSA(filepath.Join(dir, "bootnode", "boot.key"), bootKey); err != nil {
+		return err
+	}
+	enode := discover.NewNode(discover.PubkeyID(&bootKey.PublicKey), ips.Bootnode, bootnodePort, bootnodePort)
+
+	// The accounts mining the blocks
+	var miners []address.PKAddress
+	for i := 0; i < spec.Miners; i++ {
+		minerDir := filepath.Join(dir, fmt.Sprintf("miner%d", i))
+		ks := keystore.NewKeyStore(filepath.Join(minerDir, "keystore"), scryptN, scryptP)
+		account, err := ks.NewAccount(spec.Password, 0)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filepath.Join(minerDir, "password"), []byte(spec.Password), 0600); err != nil {
+			return err
+		}
+		miners = append(miners, account.Address)
+		fmt.Printf("miner%d account %s\n", i, account.Address.String())
+	}
+
+	genesis, err := spec.makeGenesis(miners)
+	if err != nil {
+		return err
+	}
+	blob, err := json.MarshalIndent(genesis, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "genesis.json"), blob, 0644); err != nil {
+		return err
+	}
+
+	compose, err := renderCompose(spec, ips, enode.String())
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "docker-compose.yml"), compose, 0644); err != nil {
+		return err
+	}
+	fmt.Printf("genesis hash %s\n", genesis.ToBlock(nil).Hash().Hex())
+	fmt.Printf("bootnode %s\n", enode.String())
+	return nil
+}
+
+func renderCompose(spec *network, ips networkIPs, enode string) ([]byte, error) {
+	tmpl, err := template.New("compose").Funcs(template.FuncMap{
+		"rpcPort": func(i int) int { return spec.RPCPort + i },
+	}).Parse(composeFile)
+	if err != nil {
+		return nil, err
+	}
+	out := new(bytes.Buffer)
+	err = tmpl.Execute(out, map[string]interface{}{
+		"Name":         spec.Name,
+		"NetworkId":    spec.NetworkId,
+		"Subnet":       spec.Subnet,
+		"Image":        spec.Image,
+		"StatsImage":   spec.StatsImage,
+		"StatsSecret":  spec.StatsSecret,
+		"StatsPort":    spec.StatsPort,
+		"RPCPort":      spec.RPCPort,
+		"BootnodePort": bootnodePort,
+		"IPs":          ips,
+		"Enode":        enode,
+	})
+	return out.Bytes(), err
+}
@@ -0,0 +1,95 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+// decenet bootstraps a private DECE network: the genesis with its allocations,
+// forks and stake economics, the bootnode key, the miner accounts and the
+// docker-compose file running them.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/dece-cash/go-dece/czero/superzk"
+	"github.com/dece-cash/go-dece/log"
+)
+
+func main() {
+	spec := newNetwork()
+	var (
+		out         = flag.String("out", "decenet", "directory to write the network to")
+		interactive = flag.Bool("wizard", false, "ask the specification of the network on the terminal")
+		alloc       = flag.String("alloc", "", "comma separated pre-funded PK or PKr, as address=DECE")
+		minerAlloc  = flag.String("alloc.miners", "0", "DECE pre-funding each miner account")
+		sips        = flag.String("sips", "", "comma separated switch blocks of SIP1 to SIP7 (default = 0)")
+		difficulty  = flag.Uint64("difficulty", spec.Difficulty.Uint64(), "difficulty of the genesis block")
+		threshold   = flag.String("stake.threshold", "0", "DECE locked to register a stake pool (0 = main network)")
+		basePrice   = flag.String("stake.price", "0", "DECE price of the first share (0 = main network)")
+		verbosity   = flag.Int("verbosity", int(log.LvlWarn), "log verbosity (0-9)")
+	)
+	flag.StringVar(&spec.Name, "name", spec.Name, "name of the network and of its docker network")
+	flag.Uint64Var(&spec.NetworkId, "networkid", spec.NetworkId, "network id, also the chain id")
+	flag.IntVar(&spec.Miners, "miners", spec.Miners, "number of mining nodes")
+	flag.StringVar(&spec.Password, "password", spec.Password, "password encrypting the miner accounts")
+	flag.StringVar(&spec.Subnet, "subnet", spec.Subnet, "subnet of the docker network")
+	flag.StringVar(&spec.Image, "image", spec.Image, "docker image running gece and bootnode")
+	flag.StringVar(&spec.StatsImage, "stats.image", spec.StatsImage, "docker image running the stats server")
+	flag.StringVar(&spec.StatsSecret, "stats.secret", spec.StatsSecret, "secret of the stats server (empty = no stats server)")
+	flag.IntVar(&spec.StatsPort, "stats.port", spec.StatsPort, "host port of the stats server")
+	flag.IntVar(&spec.RPCPort, "rpcport", spec.RPCPort, "host port of the RPC of the first miner, the next ones follow (0 = no RPC)")
+	flag.Uint64Var(&spec.GasLimit, "gaslimit", spec.GasLimit, "gas limit of the genesis block")
+	flag.Uint64Var(&spec.Stake.LockingBlockNum, "stake.locking", 0, "blocks a closed stake pool stays locked (0 = main network)")
+	flag.Uint64Var(&spec.Stake.OutOfDateWindow, "stake.outofdate", 0, "blocks after which a share expires (0 = main network)")
+	flag.Uint64Var(&spec.Stake.MissVotedWindow, "stake.missvoted", 0, "blocks after which a share missing its votes expires (0 = main network)")
+	flag.Uint64Var(&spec.Stake.PayWindow, "stake.pay", 0, "blocks between the payments of the share rewards (0 = main network)")
+	flag.Uint64Var(&spec.Stake.StatisticsMissWindow, "stake.statsmiss", 0, "blocks of the statistics of the missed votes (0 = main network)")
+	flag.Parse()
+
+	log.Root().SetHandler(log.LvlFilterHandler(log.Lvl(*verbosity), log.StreamHandler(os.Stderr, log.TerminalFormat(true))))
+	superzk.ZeroInit_NoCircuit()
+
+	var err error
+	if spec.Alloc, err = parseAlloc(*alloc); err != nil {
+		fatalf("%v", err)
+	}
+	if spec.MinerAlloc, err = parseDece(*minerAlloc); err != nil {
+		fatalf("%v", err)
+	}
+	if spec.SIPs, err = parseSIPs(*sips); err != nil {
+		fatalf("%v", err)
+	}
+	spec.Difficulty.SetUint64(*difficulty)
+	if spec.Stake.PoolValueThreshold, err = parseDece(*threshold); err != nil {
+		fatalf("%v", err)
+	}
+	if spec.Stake.BasePrice, err = parseDece(*basePrice); err != nil {
+		fatalf("%v", err)
+	}
+
+	if *interactive {
+		newWizard(os.Stdin, os.Stdout).run(spec)
+	}
+	if err := deploy(spec, *out); err != nil {
+		fatalf("%v", err)
+	}
+	fmt.Printf("network %s written to %s, start it with docker-compose up -d\n", spec.Name, *out)
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "Fatal: "+format+"\n", args...)
+	os.Exit(1)
+}
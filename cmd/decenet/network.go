@@ -0,0 +1,276 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dece-cash/go-dece/common"
+	"github.com/dece-cash/go-dece/common/address"
+	"github.com/dece-cash/go-dece/core"
+	"github.com/dece-cash/go-dece/params"
+)
+
+// sipCount is the number of the SIP forks scheduled in a genesis.
+const sipCount = 7
+
+// network is the specification of a private network.
+type network struct {
+	Name        string
+	NetworkId   uint64
+	Miners      int
+	Subnet      string
+	Image       string
+	StatsImage  string
+	StatsSecret string
+	StatsPort   int
+	RPCPort     int
+	Password    string
+
+	Alloc      map[string]*big.Int // Balances in ta keyed by PK or PKr
+	MinerAlloc *big.Int            // Balance in ta of each miner account
+
+	SIPs       [sipCount]uint64
+	Stake      params.StakeConfig
+	Difficulty *big.Int
+	GasLimit   uint64
+}
+
+func newNetwork() *network {
+	return &network{
+		Name:       "decenet",
+		NetworkId:  uint64(time.Now().Unix() % 65536),
+		Miners:     2,
+		Subnet:     "172.29.0.0/24",
+		Image:      "dececash/gece:latest",
+		StatsImage: "puppeth/ethstats:latest",
+		StatsPort:  3000,
+		RPCPort:    8545,
+		Alloc:      make(map[string]*big.Int),
+		MinerAlloc: new(big.Int),
+		Difficulty: big.NewInt(131072),
+		GasLimit:   5000000,
+	}
+}
+
+// parseDece parses an amount of DECE into ta.
+func parseDece(s string) (*big.Int, error) {
+	amount, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok || amount.Sign() < 0 {
+		return nil, fmt.Errorf("invalid amount of DECE %q", s)
+	}
+	amount.Mul(amount, new(big.Rat).SetInt(big.NewInt(params.Ether)))
+	if !amount.IsInt() {
+		return nil, fmt.Errorf("amount of DECE %q is below a ta", s)
+	}
+	return amount.Num(), nil
+}
+
+// parseAddress checks s is the base58 of a PK or a PKr.
+func parseAddress(s string) (addr address.MixBase58Adrress, e error) {
+	if err := addr.UnmarshalText([]byte(strings.TrimSpace(s))); err != nil {
+		e = fmt.Errorf("invalid PK or PKr %q: %v", s, err)
+	}
+	return
+}
+
+// parseAlloc parses the comma separated address=amount allocations.
+func parseAlloc(s string) (map[string]*big.Int, error) {
+	alloc := make(map[string]*big.Int)
+	for _, item := range strings.Split(s, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("allocation %q is not address=amount", item)
+		}
+		if _, err := parseAddress(parts[0]); err != nil {
+			return nil, err
+		}
+		amount, err := parseDece(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		alloc[strings.TrimSpace(parts[0])] = amount
+	}
+	return alloc, nil
+}
+
+// parseSIPs parses the comma separated switch blocks of the SIP forks from
+// SIP1, the missing ones switch at the genesis.
+func parseSIPs(s string) (sips [sipCount]uint64, e error) {
+	if strings.TrimSpace(s) == "" {
+		return
+	}
+	items := strings.Split(s, ",")
+	if len(items) > sipCount {
+		e = fmt.Errorf("%d SIP blocks given, there are %d forks", len(items), sipCount)
+		return
+	}
+	for i, item := range items {
+		if sips[i], e = strconv.ParseUint(strings.TrimSpace(item), 10, 64); e != nil {
+			e = fmt.Errorf("invalid SIP%d block %q", i+1, item)
+			return
+		}
+	}
+	return
+}
+
+func (self *network) validate() error {
+	if self.Name == "" || strings.ContainsAny(self.Name, " /:") {
+		return fmt.Errorf("invalid network name %q", self.Name)
+	}
+	if self.NetworkId == 0 {
+		return errors.New("network id can not be zero")
+	}
+	if self.Miners < 1 {
+		return errors.New("the network needs at least one miner")
+	}
+	if self.Difficulty == nil || self.Difficulty.Sign() <= 0 {
+		return errors.New("genesis difficulty must be positive")
+	}
+	if _, err := self.ips(); err != nil {
+		return err
+	}
+	for addr := range self.Alloc {
+		if _, err := parseAddress(addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hostIP returns the address of the host n of the subnet.
+func (self *network) hostIP(n int) (net.IP, error) {
+	ip, subnet, err := net.ParseCIDR(self.Subnet)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subnet %q: %v", self.Subnet, err)
+	}
+	ip = ip.To4()
+	if ip == nil {
+		return nil, fmt.Errorf("subnet %q is not IPv4", self.Subnet)
+	}
+	host := make(net.IP, len(ip))
+	copy(host, ip.Mask(subnet.Mask))
+	for i, carry := len(host)-1, n; i >= 0 && carry > 0; i-- {
+		sum := int(host[i]) + carry
+		host[i] = byte(sum)
+		carry = sum >> 8
+	}
+	broadcast := make(net.IP, len(host))
+	for i := range broadcast {
+		broadcast[i] = host[i] | ^subnet.Mask[i]
+	}
+	if !subnet.Contains(host) || host.Equal(broadcast) {
+		return nil, fmt.Errorf("subnet %q too small for %d miners", self.Subnet, self.Miners)
+	}
+	return host, nil
+}
+
+// The hosts of the subnet: the gateway, the bootnode, the stats server and
+// then the miners.
+type networkIPs struct {
+	Bootnode net.IP
+	Stats    net.IP
+	Miners   []net.IP
+}
+
+func (self *network) ips() (ips networkIPs, e error) {
+	if ips.Bootnode, e = self.hostIP(2); e != nil {
+		return
+	}
+	if ips.Stats, e = self.hostIP(3); e != nil {
+		return
+	}
+	for i := 0; i < self.Miners; i++ {
+		var ip net.IP
+		if ip, e = self.hostIP(10 + i); e != nil {
+			return
+		}
+		ips.Miners = append(ips.Miners, ip)
+	}
+	return
+}
+
+// makeGenesis creates the genesis of the network, paying the allocations and
+// the miner accounts.
+func (self *network) makeGenesis(miners []address.PKAddress) (*core.Genesis, error) {
+	config := &params.ChainConfig{
+		ChainID:             new(big.Int).SetUint64(self.NetworkId),
+		AutumnTwilightBlock: big.NewInt(0),
+		Ethash:              new(params.EthashConfig),
+	}
+	sips := []**big.Int{
+		&config.SIP1Block, &config.SIP2Block, &config.SIP3Block, &config.SIP4Block,
+		&config.SIP5Block, &config.SIP6Block, &config.SIP7Block,
+	}
+	for i, num := range self.SIPs {
+		*sips[i] = new(big.Int).SetUint64(num)
+	}
+	// The zero amounts keep the economics of the main network as the zero
+	// windows do
+	stake := self.Stake
+	if stake.PoolValueThreshold != nil && stake.PoolValueThreshold.Sign() == 0 {
+		stake.PoolValueThreshold = nil
+	}
+	if stake.BasePrice != nil && stake.BasePrice.Sign() == 0 {
+		stake.BasePrice = nil
+	}
+	if stake != (params.StakeConfig{}) {
+		config.Stake = &stake
+	}
+
+	genesis := &core.Genesis{
+		Config:     config,
+		Timestamp:  uint64(time.Now().Unix()),
+		ExtraData:  []byte(self.Name),
+		GasLimit:   self.GasLimit,
+		Difficulty: new(big.Int).Set(self.Difficulty),
+		Alloc:      make(core.GenesisAlloc),
+	}
+	credit := func(addr address.MixBase58Adrress, amount *big.Int) {
+		if amount == nil || amount.Sign() <= 0 {
+			return
+		}
+		pkr := addr.ToPkr()
+		key := common.BytesToAddress(pkr[:])
+		balance := new(big.Int).Set(amount)
+		if account, ok := genesis.Alloc[key]; ok {
+			balance.Add(balance, account.Balance)
+		}
+		genesis.Alloc[key] = core.GenesisAccount{Balance: balance}
+	}
+	for s, amount := range self.Alloc {
+		addr, err := parseAddress(s)
+		if err != nil {
+			return nil, err
+		}
+		credit(addr, amount)
+	}
+	for _, miner := range miners {
+		pk := miner.ToUint512()
+		credit(address.MixBase58Adrress(pk[:]), self.MinerAlloc)
+	}
+	return genesis, nil
+}
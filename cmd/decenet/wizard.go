@@ -0,0 +1,140 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// wizard asks the specification of a network on a terminal.
+type wizard struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func newWizard(in io.Reader, out io.Writer) *wizard {
+	return &wizard{bufio.NewReader(in), out}
+}
+
+// read reads a line, empty at the end of the input.
+func (self *wizard) read() string {
+	fmt.Fprint(self.out, "> ")
+	text, _ := self.in.ReadString('\n')
+	return strings.TrimSpace(text)
+}
+
+func (self *wizard) readDefaultString(def string) string {
+	if text := self.read(); text != "" {
+		return text
+	}
+	return def
+}
+
+func (self *wizard) readDefaultUint64(def uint64) uint64 {
+	for {
+		text := self.read()
+		if text == "" {
+			return def
+		}
+		val, err := strconv.ParseUint(text, 10, 64)
+		if err == nil {
+			return val
+		}
+		fmt.Fprintf(self.out, "Invalid number %q: %v\n", text, err)
+	}
+}
+
+func (self *wizard) readDefaultDece(def *big.Int) *big.Int {
+	for {
+		text := self.read()
+		if text == "" {
+			return def
+		}
+		val, err := parseDece(text)
+		if err == nil {
+			return val
+		}
+		fmt.Fprintln(self.out, err)
+	}
+}
+
+// run fills spec with the answers.
+func (self *wizard) run(spec *network) {
+	fmt.Fprintf(self.out, "Which name should the network have? (default = %s)\n", spec.Name)
+	spec.Name = self.readDefaultString(spec.Name)
+
+	fmt.Fprintf(self.out, "Which network id? (default = %d)\n", spec.NetworkId)
+	spec.NetworkId = self.readDefaultUint64(spec.NetworkId)
+
+	fmt.Fprintf(self.out, "How many miners should run? (default = %d)\n", spec.Miners)
+	spec.Miners = int(self.readDefaultUint64(uint64(spec.Miners)))
+
+	fmt.Fprintln(self.out, "Which password should encrypt the miner accounts? (default = none)")
+	spec.Password = self.readDefaultString(spec.Password)
+
+	fmt.Fprintln(self.out, "How many DECE should each miner account be funded with? (default = 0)")
+	spec.MinerAlloc = self.readDefaultDece(spec.MinerAlloc)
+
+	for {
+		fmt.Fprintln(self.out, "Which PK or PKr should be pre-funded? (empty to continue)")
+		text := self.read()
+		if text == "" {
+			break
+		}
+		if _, err := parseAddress(text); err != nil {
+			fmt.Fprintln(self.out, err)
+			continue
+		}
+		fmt.Fprintln(self.out, "How many DECE? (default = 0)")
+		spec.Alloc[text] = self.readDefaultDece(new(big.Int))
+	}
+
+	for i := range spec.SIPs {
+		fmt.Fprintf(self.out, "At which block should SIP%d switch? (default = %d)\n", i+1, spec.SIPs[i])
+		spec.SIPs[i] = self.readDefaultUint64(spec.SIPs[i])
+	}
+
+	fmt.Fprintln(self.out, "Should the stake economics differ from the main network? (y/n, default = n)")
+	if strings.HasPrefix(strings.ToLower(self.read()), "y") {
+		fmt.Fprintln(self.out, "How many DECE should a stake pool lock? (default = main network)")
+		spec.Stake.PoolValueThreshold = self.readDefaultDece(spec.Stake.PoolValueThreshold)
+		fmt.Fprintln(self.out, "For how many blocks should a closed pool stay locked? (default = main network)")
+		spec.Stake.LockingBlockNum = self.readDefaultUint64(spec.Stake.LockingBlockNum)
+		fmt.Fprintln(self.out, "How many DECE should the first share cost? (default = main network)")
+		spec.Stake.BasePrice = self.readDefaultDece(spec.Stake.BasePrice)
+		fmt.Fprintln(self.out, "After how many blocks should a share expire? (default = main network)")
+		spec.Stake.OutOfDateWindow = self.readDefaultUint64(spec.Stake.OutOfDateWindow)
+		fmt.Fprintln(self.out, "After how many blocks should a share missing its votes expire? (default = main network)")
+		spec.Stake.MissVotedWindow = self.readDefaultUint64(spec.Stake.MissVotedWindow)
+		fmt.Fprintln(self.out, "Every how many blocks should the share rewards be paid? (default = main network)")
+		spec.Stake.PayWindow = self.readDefaultUint64(spec.Stake.PayWindow)
+	}
+
+	fmt.Fprintf(self.out, "Which subnet should the containers use? (default = %s)\n", spec.Subnet)
+	spec.Subnet = self.readDefaultString(spec.Subnet)
+
+	fmt.Fprintf(self.out, "Which docker image runs gece and bootnode? (default = %s)\n", spec.Image)
+	spec.Image = self.readDefaultString(spec.Image)
+
+	fmt.Fprintln(self.out, "Which secret should the stats server use? (default = no stats server)")
+	spec.StatsSecret = self.readDefaultString(spec.StatsSecret)
+}
@@ -17,7 +17,9 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"runtime"
 	"strconv"
@@ -28,6 +30,9 @@ import (
 	"github.com/dece-cash/go-dece/common"
 	"github.com/dece-cash/go-dece/console"
 	"github.com/dece-cash/go-dece/core"
+	"github.com/dece-cash/go-dece/core/rawdb"
+	"github.com/dece-cash/go-dece/core/types"
+	"github.com/dece-cash/go-dece/core/vm"
 	"github.com/dece-cash/go-dece/event"
 	"github.com/dece-cash/go-dece/log"
 	"github.com/dece-cash/go-dece/dece/downloader"
@@ -37,7 +42,26 @@ import (
 	"gopkg.in/urfave/cli.v1"
 )
 
+// checkSupplyBatchSize is the number of blocks replayed at once by checksupply.
+const checkSupplyBatchSize = 2500
+
 var (
+	initCommand = cli.Command{
+		Action:    utils.MigrateFlags(initGenesis),
+		Name:      "init",
+		Usage:     "Bootstrap and initialize a new genesis block",
+		ArgsUsage: "<genesisPath>",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+		},
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `
+The init command initializes a new genesis block and definition for the network.
+This is a destructive action and changes the network in which you will be
+participating.
+
+It expects the genesis file as argument.`,
+	}
 	importCommand = cli.Command{
 		Action:    utils.MigrateFlags(importChain),
 		Name:      "import",
@@ -119,6 +143,28 @@ The export-preimages command export hash preimages to an RLP encoded stream`,
 		Category: "BLOCKCHAIN COMMANDS",
 		Description: `
 The first argument must be the directory containing the blockchain to download from`,
+	}
+	checkSupplyCommand = cli.Command{
+		Action:    utils.MigrateFlags(checkSupply),
+		Name:      "checksupply",
+		Usage:     "Recompute the currency supply from the genesis and check the ledger",
+		ArgsUsage: "[<genesisPath>]",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.CacheFlag,
+			utils.FakePoWFlag,
+			utils.AlphanetFlag,
+			utils.DeveloperFlag,
+		},
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `
+The checksupply command replays the canonical chain from the genesis into a
+temporary database and compares the supply of the currencies issued by every
+block with the supply ledger of the node. The blocks imported before the node
+kept a ledger get the recomputed supply recorded.
+
+The genesis is the one of the network selected by the flags, unless a genesis
+JSON file is given.`,
 	}
 	removedbCommand = cli.Command{
 		Action:    utils.MigrateFlags(removeDB),
@@ -151,37 +197,37 @@ Remove blockchain and state databases`,
 
 // initGenesis will initialise the given JSON format genesis file and writes it as
 // the zero'd block (i.e. genesis) or will fail hard if it can't succeed.
-//func initGenesis(ctx *cli.Context) error {
-//	// Make sure we have a valid genesis JSON
-//	genesisPath := ctx.Args().First()
-//	if len(genesisPath) == 0 {
-//		utils.Fatalf("Must supply path to genesis JSON file")
-//	}
-//	file, err := os.Open(genesisPath)
-//	if err != nil {
-//		utils.Fatalf("Failed to read genesis file: %v", err)
-//	}
-//	defer file.Close()
-//
-//	genesis := new(core.Genesis)
-//	if err := json.NewDecoder(file).Decode(genesis); err != nil {
-//		utils.Fatalf("invalid genesis file: %v", err)
-//	}
-//	// Open an initialise both full and light databases
-//	stack := makeFullNode(ctx)
-//	for _, name := range []string{"chaindata"} {
-//		chaindb, err := stack.OpenDatabase(name, 0, 0)
-//		if err != nil {
-//			utils.Fatalf("Failed to open database: %v", err)
-//		}
-//		_, hash, err := core.SetupGenesisBlock(chaindb, genesis)
-//		if err != nil {
-//			utils.Fatalf("Failed to write genesis block: %v", err)
-//		}
-//		log.Info("Successfully wrote genesis state", "database", name, "hash", hash)
-//	}
-//	return nil
-//}
+func initGenesis(ctx *cli.Context) error {
+	// Make sure we have a valid genesis JSON
+	genesisPath := ctx.Args().First()
+	if len(genesisPath) == 0 {
+		utils.Fatalf("Must supply path to genesis JSON file")
+	}
+	file, err := os.Open(genesisPath)
+	if err != nil {
+		utils.Fatalf("Failed to read genesis file: %v", err)
+	}
+	defer file.Close()
+
+	genesis := new(core.Genesis)
+	if err := json.NewDecoder(file).Decode(genesis); err != nil {
+		utils.Fatalf("invalid genesis file: %v", err)
+	}
+	// Open an initialise both full and light databases
+	stack := makeFullNode(ctx)
+	for _, name := range []string{"chaindata"} {
+		chaindb, err := stack.OpenDatabase(name, 0, 0)
+		if err != nil {
+			utils.Fatalf("Failed to open database: %v", err)
+		}
+		_, hash, err := core.SetupGenesisBlock(chaindb, genesis)
+		if err != nil {
+			utils.Fatalf("Failed to write genesis block: %v", err)
+		}
+		log.Info("Successfully wrote genesis state", "database", name, "hash", hash)
+	}
+	return nil
+}
 
 func importChain(ctx *cli.Context) error {
 	if len(ctx.Args()) < 1 {
@@ -224,7 +270,7 @@ func importChain(ctx *cli.Context) error {
 	fmt.Printf("Import done in %v.\n\n", time.Since(start))
 
 	// Output pre-compaction stats mostly to see the import trashing
-	db := chainDb.(*decedb.LDBDatabase)
+	db := rawdb.KeyValueStore(chainDb)
 
 	stats, err := db.LDB().GetProperty("leveldb.stats")
 	if err != nil {
@@ -315,7 +361,7 @@ func importPreimages(ctx *cli.Context) error {
 		utils.Fatalf("This command requires an argument.")
 	}
 	stack := makeFullNode(ctx)
-	diskdb := utils.MakeChainDatabase(ctx, stack).(*decedb.LDBDatabase)
+	diskdb := rawdb.KeyValueStore(utils.MakeChainDatabase(ctx, stack))
 
 	start := time.Now()
 	if err := utils.ImportPreimages(diskdb, ctx.Args().First()); err != nil {
@@ -331,7 +377,7 @@ func exportPreimages(ctx *cli.Context) error {
 		utils.Fatalf("This command requires an argument.")
 	}
 	stack := makeFullNode(ctx)
-	diskdb := utils.MakeChainDatabase(ctx, stack).(*decedb.LDBDatabase)
+	diskdb := rawdb.KeyValueStore(utils.MakeChainDatabase(ctx, stack))
 
 	start := time.Now()
 	if err := utils.ExportPreimages(diskdb, ctx.Args().First()); err != nil {
@@ -381,7 +427,7 @@ func copyDb(ctx *cli.Context) error {
 	// Compact the entire database to remove any sync overhead
 	start = time.Now()
 	fmt.Println("Compacting entire database...")
-	if err = chainDb.(*decedb.LDBDatabase).LDB().CompactRange(util.Range{}); err != nil {
+	if err = rawdb.KeyValueStore(chainDb).LDB().CompactRange(util.Range{}); err != nil {
 		utils.Fatalf("Compaction failed: %v", err)
 	}
 	fmt.Printf("Compaction done in %v.\n\n", time.Since(start))
@@ -389,6 +435,94 @@ func copyDb(ctx *cli.Context) error {
 	return nil
 }
 
+func checkSupply(ctx *cli.Context) error {
+	var genesis *core.Genesis
+	if genesisPath := ctx.Args().First(); len(genesisPath) > 0 {
+		file, err := os.Open(genesisPath)
+		if err != nil {
+			utils.Fatalf("Failed to read genesis file: %v", err)
+		}
+		defer file.Close()
+
+		genesis = new(core.Genesis)
+		if err := json.NewDecoder(file).Decode(genesis); err != nil {
+			utils.Fatalf("invalid genesis file: %v", err)
+		}
+	} else {
+		genesis = utils.MakeGenesis(ctx)
+	}
+	stack := makeFullNode(ctx)
+	chain, chainDb := utils.MakeChain(ctx, stack)
+	defer chainDb.Close()
+	defer chain.Stop()
+
+	// Replay the chain into a scratch database to recompute its supply
+	dir, err := ioutil.TempDir("", "gece-checksupply")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	db, err := decedb.NewLDBDatabase(dir, 0, 0)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	config, hash, err := core.SetupGenesisBlock(db, genesis)
+	if err != nil {
+		utils.Fatalf("Failed to write genesis block: %v", err)
+	}
+	if hash != chain.Genesis().Hash() {
+		utils.Fatalf("Genesis mismatch: %x, chain has %x", hash, chain.Genesis().Hash())
+	}
+	replay, err := core.NewBlockChain(db, nil, config, chain.Engine(), vm.Config{}, nil)
+	if err != nil {
+		utils.Fatalf("Can't create BlockChain: %v", err)
+	}
+	defer replay.Stop()
+
+	var recorded uint64
+	check := func(block *types.Block) {
+		want := rawdb.ReadSupply(db, block.Hash(), block.NumberU64())
+		if want == nil {
+			utils.Fatalf("No supply recomputed for block %d", block.NumberU64())
+		}
+		switch have := rawdb.ReadSupply(chainDb, block.Hash(), block.NumberU64()); {
+		case have == nil:
+			rawdb.WriteSupply(chainDb, block.Hash(), block.NumberU64(), want)
+			recorded++
+		case !have.Equal(want):
+			utils.Fatalf("Supply mismatch at block %d: have %v, want %v", block.NumberU64(), have, want)
+		}
+	}
+	start := time.Now()
+	check(chain.Genesis())
+
+	head := chain.CurrentBlock().NumberU64()
+	for first := uint64(1); first <= head; first += checkSupplyBatchSize {
+		var blocks types.Blocks
+		for number := first; number < first+checkSupplyBatchSize && number <= head; number++ {
+			block := chain.GetBlockByNumber(number)
+			if block == nil {
+				utils.Fatalf("Block %d not found", number)
+			}
+			blocks = append(blocks, block)
+		}
+		if _, err := replay.InsertChain(blocks); err != nil {
+			utils.Fatalf("Failed to replay blocks: %v", err)
+		}
+		for _, block := range blocks {
+			check(block)
+		}
+		log.Info("Checked supply", "number", blocks[len(blocks)-1].NumberU64(), "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	fmt.Printf("Supply of %d blocks checked in %v, %d recorded.\n", head+1, time.Since(start), recorded)
+	for _, supply := range rawdb.ReadSupply(chainDb, chain.CurrentBlock().Hash(), head) {
+		fmt.Printf("%s: %v\n", supply.Currency, supply.Total())
+	}
+	return nil
+}
+
 func removeDB(ctx *cli.Context) error {
 	stack, _ := makeConfigNode(ctx)
 
@@ -140,6 +140,16 @@ func makeFullNode(ctx *cli.Context) *node.Node {
 
 	utils.RegisterEthService(stack, &cfg.Sero)
 
+	// Add the Dece stats daemon if requested
+	if cfg.Serostats.URL != "" {
+		utils.RegisterSeroStatsService(stack, cfg.Serostats.URL)
+	}
+
+	// Add the GraphQL server if requested
+	if endpoint := cfg.Node.GraphQLEndpoint(); endpoint != "" {
+		utils.RegisterGraphQLService(stack, endpoint, cfg.Node.GraphQLCors, cfg.Node.GraphQLVirtualHosts, cfg.Node.HTTPTimeouts)
+	}
+
 	if ctx.GlobalBool(utils.DashboardEnabledFlag.Name) {
 		// utils.RegisterDashboardService(stack, &cfg.Dashboard, gitCommit)
 	}
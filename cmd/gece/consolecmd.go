@@ -17,6 +17,8 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/signal"
@@ -32,7 +34,7 @@ import (
 )
 
 var (
-	consoleFlags = []cli.Flag{utils.JSpathFlag, utils.ExecFlag, utils.PreloadJSFlag}
+	consoleFlags = []cli.Flag{utils.JSpathFlag, utils.ExecFlag, utils.ExecFileFlag, utils.PreloadJSFlag}
 
 	consoleCommand = cli.Command{
 		Action:   utils.MigrateFlags(localConsole),
@@ -50,26 +52,45 @@ See https://github.com/ethereum/go-ethereum/wiki/JavaScript-Console.`,
 		Action:    utils.MigrateFlags(remoteConsole),
 		Name:      "attach",
 		Usage:     "Start an interactive JavaScript environment (connect to node)",
-		ArgsUsage: "[endpoint]",
-		Flags:     append(consoleFlags, utils.DataDirFlag),
+		ArgsUsage: "[endpoint] [arg...]",
+		Flags:     append(consoleFlags, utils.DataDirFlag, utils.AuthKeyFlag),
 		Category:  "CONSOLE COMMANDS",
 		Description: `
 The gece console is an interactive shell for the JavaScript runtime environment
 which exposes a node admin interface as well as the Ðapp JavaScript API.
 See https://github.com/ethereum/go-ethereum/wiki/JavaScript-Console.
-This command allows to open a console on a running gece node.`,
+This command allows to open a console on a running gece node. With
+--exec-file the arguments following the endpoint are passed to the script,
+see "gece js --help".`,
 	}
 
 	javascriptCommand = cli.Command{
 		Action:    utils.MigrateFlags(ephemeralConsole),
 		Name:      "js",
 		Usage:     "Execute the specified JavaScript files",
-		ArgsUsage: "<jsfile> [jsfile...]",
+		ArgsUsage: "<jsfile> [jsfile...] | --exec-file <jsfile> [arg...]",
 		Flags:     append(nodeFlags, consoleFlags...),
 		Category:  "CONSOLE COMMANDS",
 		Description: `
 The JavaScript VM exposes a node admin interface as well as the Ðapp
-JavaScript API. See https://github.com/ethereum/go-ethereum/wiki/JavaScript-Console`,
+JavaScript API. See https://github.com/ethereum/go-ethereum/wiki/JavaScript-Console
+
+With --exec-file the file is run as a script, the arguments being available
+to it as script.args. Besides web3, the script object offers the helpers:
+
+    script.output(value)                    write value to stdout as a line of JSON
+    script.exit([code])                     end the script with the exit code
+    script.fail(message)                    end the script with the exit code 1
+    script.sleep(seconds)                   wait for the number of seconds
+    script.waitTx(hash[, confs, timeout])   wait for the transaction to confirm
+    script.toAmount(amount[, currency])     convert whole coins to the smallest unit
+    script.fromAmount(value[, currency])    convert the smallest unit to whole coins
+    script.decimals([currency])             number of decimals of the currency
+    script.toHex(address)                   convert a base58 address to hex
+    script.toBase58(hex)                    convert a hex address to base58
+
+An uncaught exception ends the script with the exit code 1. The failures are
+written to stderr as JSON.`,
 	}
 )
 
@@ -99,6 +120,15 @@ func localConsole(ctx *cli.Context) error {
 	}
 	defer console.Stop(false)
 
+	// If a script was requested, run it and exit with its status
+	if file := ctx.GlobalString(utils.ExecFileFlag.Name); file != "" {
+		if code := executeScript(console, file, ctx.Args()); code != 0 {
+			console.Stop(false)
+			node.Stop()
+			os.Exit(code)
+		}
+		return nil
+	}
 	// If only a short execution was requested, evaluate and return
 	if script := ctx.GlobalString(utils.ExecFlag.Name); script != "" {
 		console.Evaluate(script)
@@ -137,6 +167,11 @@ func remoteConsole(ctx *cli.Context) error {
 	if err != nil {
 		utils.Fatalf("Unable to attach to remote gece: %v", err)
 	}
+	if key := ctx.GlobalString(utils.AuthKeyFlag.Name); key != "" {
+		if err := client.Authenticate(context.Background(), key); err != nil {
+			utils.Fatalf("Unable to authenticate to remote gece: %v", err)
+		}
+	}
 	config := console.Config{
 		DataDir: utils.MakeDataDir(ctx),
 		DocRoot: ctx.GlobalString(utils.JSpathFlag.Name),
@@ -150,6 +185,13 @@ func remoteConsole(ctx *cli.Context) error {
 	}
 	defer console.Stop(false)
 
+	if file := ctx.GlobalString(utils.ExecFileFlag.Name); file != "" {
+		if code := executeScript(console, file, ctx.Args().Tail()); code != 0 {
+			console.Stop(false)
+			os.Exit(code)
+		}
+		return nil
+	}
 	if script := ctx.GlobalString(utils.ExecFlag.Name); script != "" {
 		console.Evaluate(script)
 		return nil
@@ -203,6 +245,15 @@ func ephemeralConsole(ctx *cli.Context) error {
 	}
 	defer console.Stop(false)
 
+	// Run the script if one was requested, exiting with its status
+	if file := ctx.GlobalString(utils.ExecFileFlag.Name); file != "" {
+		if code := executeScript(console, file, ctx.Args()); code != 0 {
+			console.Stop(false)
+			node.Stop()
+			os.Exit(code)
+		}
+		return nil
+	}
 	// Evaluate each of the specified JavaScript files
 	for _, file := range ctx.Args() {
 		if err = console.Execute(file); err != nil {
@@ -221,3 +272,25 @@ func ephemeralConsole(ctx *cli.Context) error {
 
 	return nil
 }
+
+// executeScript runs the JavaScript file as a script, returning the exit code
+// of the process. The failures are written to stderr as JSON.
+func executeScript(c *console.Console, file string, args []string) int {
+	err := c.ExecuteScript(file, args)
+	if err == nil {
+		return 0
+	}
+	failure := struct {
+		Code  int    `json:"code"`
+		Error string `json:"error,omitempty"`
+	}{Code: 1, Error: err.Error()}
+
+	if serr, ok := err.(*console.ScriptError); ok {
+		failure.Code, failure.Error = serr.Code, serr.Message
+	}
+	if failure.Error != "" {
+		out, _ := json.Marshal(failure)
+		fmt.Fprintln(os.Stderr, string(out))
+	}
+	return failure.Code
+}
@@ -0,0 +1,141 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dece-cash/go-dece/cmd/utils"
+	"github.com/dece-cash/go-dece/common"
+	"github.com/dece-cash/go-dece/core/rawdb"
+	"github.com/dece-cash/go-dece/czero/c_type"
+	"github.com/dece-cash/go-dece/decedb"
+	"github.com/dece-cash/go-dece/log"
+	"github.com/dece-cash/go-dece/zero/localdb"
+	"github.com/dece-cash/go-dece/zero/stake"
+	"github.com/dece-cash/go-dece/zero/wallet/exchange"
+	"github.com/dece-cash/go-dece/zero/wallet/light"
+	"github.com/dece-cash/go-dece/zero/wallet/stakeservice"
+	"github.com/dece-cash/go-dece/zero/zconfig"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	dbCommand = cli.Command{
+		Name:      "db",
+		Usage:     "Low level database operations",
+		ArgsUsage: "",
+		Category:  "DATABASE COMMANDS",
+		Subcommands: []cli.Command{
+			{
+				Name:   "inspect",
+				Usage:  "Report the size and the key count of every known key prefix",
+				Action: utils.MigrateFlags(inspectDB),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.CacheFlag,
+				},
+				Description: `
+    gece db inspect
+
+Walks the chain database and the exchange, light and stake service databases
+and groups their keys by the prefixes of the schemas stored in them (rawdb,
+localdb, stake). Counts, key sizes and value sizes are reported per group,
+together with the keys no schema accounts for.
+
+The localdb records are also checked against the canonical chain: roots whose
+block record does not list them and block records of non canonical blocks are
+reported as anomalies.
+
+The node must not be running while the databases are inspected.`,
+			},
+		},
+	}
+)
+
+func inspectDB(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	chainDb := utils.MakeChainDatabase(ctx, stack)
+	defer chainDb.Close()
+	kvdb := rawdb.KeyValueStore(chainDb)
+
+	start := time.Now()
+
+	groups := rawdb.ChainKeyGroups()
+	groups = append(groups, localdb.KeyGroups()...)
+	groups = append(groups, stake.KeyGroups()...)
+	fmt.Printf("Database %s\n\n", kvdb.Path())
+	if err := inspectLDB(kvdb, groups); err != nil {
+		utils.Fatalf("Failed to inspect chain database: %v", err)
+	}
+	if ancients := rawdb.AncientKeyStats(chainDb); len(ancients) > 0 {
+		fmt.Printf("Ancient store (%d blocks)\n\n", rawdb.Ancients(chainDb))
+		decedb.WriteKeyStats(os.Stdout, ancients)
+		fmt.Println()
+	}
+
+	anomalies := localdb.InspectAnomalies(chainDb, kvdb, func(num uint64) (ret c_type.Uint256, ok bool) {
+		hash := rawdb.ReadCanonicalHash(chainDb, num)
+		if hash == (common.Hash{}) {
+			return
+		}
+		return *hash.HashToUint256(), true
+	})
+	fmt.Println("Localdb anomalies:")
+	fmt.Printf("  Orphaned roots:   %d / %d\n", anomalies.OrphanedRoots, anomalies.Roots)
+	fmt.Printf("  Stale blocks:     %d / %d\n", anomalies.StaleBlocks, anomalies.Blocks)
+	fmt.Printf("  Corrupt records:  %d\n\n", anomalies.CorruptRecords)
+
+	services := []struct {
+		path   string
+		groups []decedb.KeyGroup
+	}{
+		{zconfig.Exchange_dir(), exchange.KeyGroups()},
+		{zconfig.Light_dir(), light.KeyGroups()},
+		{zconfig.Stake_dir(), stakeservice.KeyGroups()},
+	}
+	for _, service := range services {
+		if _, err := os.Stat(service.path); err != nil {
+			log.Info("Database doesn't exist, skipping", "path", service.path)
+			continue
+		}
+		db, err := decedb.NewLDBDatabase(service.path, ctx.GlobalInt(utils.CacheFlag.Name), 16)
+		if err != nil {
+			utils.Fatalf("Could not open database %s: %v", service.path, err)
+		}
+		fmt.Printf("Database %s\n\n", service.path)
+		err = inspectLDB(db, service.groups)
+		db.Close()
+		if err != nil {
+			utils.Fatalf("Failed to inspect database %s: %v", service.path, err)
+		}
+	}
+	fmt.Printf("Inspection done in %v\n", time.Since(start))
+	return nil
+}
+
+func inspectLDB(db *decedb.LDBDatabase, groups []decedb.KeyGroup) error {
+	inspector := decedb.NewInspector(groups)
+	if err := inspector.AddIterator(db.NewIterator()); err != nil {
+		return err
+	}
+	inspector.Write(os.Stdout)
+	fmt.Println()
+	return nil
+}
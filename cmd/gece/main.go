@@ -68,6 +68,7 @@ var (
 		utils.DataDirFlag,
 		utils.KeyStoreDirFlag,
 		utils.NoUSBFlag,
+		utils.USBFlag,
 		utils.DashboardEnabledFlag,
 		// utils.DashboardAddrFlag,
 		// utils.DashboardPortFlag,
@@ -79,7 +80,10 @@ var (
 		utils.EthashDatasetsInMemoryFlag,
 		utils.EthashDatasetsOnDiskFlag,
 		utils.TxPoolNoLocalsFlag,
+		utils.TxPoolJournalFlag,
+		utils.TxPoolRejournalFlag,
 		utils.TxPoolPriceLimitFlag,
+		utils.TxPoolPriceBumpFlag,
 		utils.TxPoolAccountSlotsFlag,
 		utils.TxPoolGlobalSlotsFlag,
 		utils.TxPoolAccountQueueFlag,
@@ -89,6 +93,8 @@ var (
 		utils.GCModeFlag,
 		utils.CacheFlag,
 		utils.CacheDatabaseFlag,
+		utils.AncientFlag,
+		utils.AncientDepthFlag,
 		utils.CacheGCFlag,
 		utils.TrieCacheGenFlag,
 		utils.ListenPortFlag,
@@ -164,6 +170,11 @@ var (
 		utils.WSPortFlag,
 		utils.WSApiFlag,
 		utils.WSAllowedOriginsFlag,
+		utils.GraphQLEnabledFlag,
+		utils.GraphQLListenAddrFlag,
+		utils.GraphQLPortFlag,
+		utils.GraphQLCORSDomainFlag,
+		utils.GraphQLVirtualHostsFlag,
 		utils.IPCDisabledFlag,
 		utils.IPCPathFlag,
 	}
@@ -185,13 +196,17 @@ func init() {
 	app.Copyright = "Copyright 2013-2018 The go-dece Authors"
 	app.Commands = []cli.Command{
 		// See chaincmd.go:
+		initCommand,
 		importCommand,
 		exportCommand,
 		importPreimagesCommand,
 		exportPreimagesCommand,
 		copydbCommand,
+		checkSupplyCommand,
 		removedbCommand,
 		//dumpCommand,
+		// See dbcmd.go:
+		dbCommand,
 		// See monitorcmd.go:
 		monitorCommand,
 		// See accountcmd.go:
@@ -48,12 +48,15 @@ import (
 
 	"github.com/dece-cash/go-dece/consensus/ethash"
 	"github.com/dece-cash/go-dece/core"
+	"github.com/dece-cash/go-dece/core/rawdb"
 	"github.com/dece-cash/go-dece/core/state"
 	"github.com/dece-cash/go-dece/core/vm"
 	"github.com/dece-cash/go-dece/crypto"
 	"github.com/dece-cash/go-dece/dece"
 	"github.com/dece-cash/go-dece/dece/downloader"
 	"github.com/dece-cash/go-dece/dece/gasprice"
+	"github.com/dece-cash/go-dece/decestats"
+	"github.com/dece-cash/go-dece/graphql"
 	"github.com/dece-cash/go-dece/decedb"
 	"github.com/dece-cash/go-dece/log"
 	"github.com/dece-cash/go-dece/metrics"
@@ -126,6 +129,14 @@ var (
 		Usage: "Data directory for the databases and keystore",
 		Value: DirectoryString{node.DefaultDataDir()},
 	}
+	AncientFlag = DirectoryFlag{
+		Name:  "datadir.ancient",
+		Usage: "Data directory for ancient chain segments (default = inside chaindata)",
+	}
+	AncientDepthFlag = cli.Uint64Flag{
+		Name:  "ancient.depth",
+		Usage: "Number of recent blocks kept in the key-value database, older ones are moved to the ancient store (0 = disabled)",
+	}
 	KeyStoreDirFlag = DirectoryFlag{
 		Name:  "keystore",
 		Usage: "Directory for the keystore (default = inside the datadir)",
@@ -134,6 +145,10 @@ var (
 		Name:  "nousb",
 		Usage: "Disables monitoring for and managing USB hardware wallets",
 	}
+	USBFlag = cli.BoolFlag{
+		Name:  "usb",
+		Usage: "Enables monitoring for and managing USB hardware wallets",
+	}
 	NetworkIdFlag = cli.Uint64Flag{
 		Name:  "networkid",
 		Usage: "Network identifier (integer, 1=Frontier, 2=Morden (disused), 3=Ropsten, 4=Rinkeby)",
@@ -172,7 +187,7 @@ var (
 	}
 	DeveloperPeriodFlag = cli.IntFlag{
 		Name:  "dev.period",
-		Usage: "Block period to use in developer mode (0 = mine only if transaction pending)",
+		Usage: "Block period to use in developer mode (0 = seal blocks instantly)",
 	}
 	IdentityFlag = cli.StringFlag{
 		Name:  "identity",
@@ -249,11 +264,26 @@ var (
 		Name:  "txpool.nolocals",
 		Usage: "Disables price exemptions for locally submitted transactions",
 	}
+	TxPoolJournalFlag = cli.StringFlag{
+		Name:  "txpool.journal",
+		Usage: "Disk journal for local transaction to survive node restarts",
+		Value: core.DefaultTxPoolConfig.Journal,
+	}
+	TxPoolRejournalFlag = cli.DurationFlag{
+		Name:  "txpool.rejournal",
+		Usage: "Time interval to regenerate the local transaction journal",
+		Value: core.DefaultTxPoolConfig.Rejournal,
+	}
 	TxPoolPriceLimitFlag = cli.Uint64Flag{
 		Name:  "txpool.pricelimit",
 		Usage: "Minimum gas price limit to enforce for acceptance into the pool",
 		Value: dece.DefaultConfig.TxPool.PriceLimit,
 	}
+	TxPoolPriceBumpFlag = cli.Uint64Flag{
+		Name:  "txpool.pricebump",
+		Usage: "Price bump percentage to replace an already existing transaction spending the same inputs",
+		Value: dece.DefaultConfig.TxPool.PriceBump,
+	}
 	TxPoolAccountSlotsFlag = cli.Uint64Flag{
 		Name:  "txpool.accountslots",
 		Usage: "Minimum number of executable transaction slots guaranteed per account",
@@ -503,14 +533,46 @@ var (
 		Usage: "Origins from which to accept websockets requests",
 		Value: "",
 	}
+	GraphQLEnabledFlag = cli.BoolFlag{
+		Name:  "graphql",
+		Usage: "Enable the GraphQL server (authenticated with the RPC access keys if configured)",
+	}
+	GraphQLListenAddrFlag = cli.StringFlag{
+		Name:  "graphql.addr",
+		Usage: "GraphQL server listening interface",
+		Value: node.DefaultGraphQLHost,
+	}
+	GraphQLPortFlag = cli.IntFlag{
+		Name:  "graphql.port",
+		Usage: "GraphQL server listening port",
+		Value: node.DefaultGraphQLPort,
+	}
+	GraphQLCORSDomainFlag = cli.StringFlag{
+		Name:  "graphql.corsdomain",
+		Usage: "Comma separated list of domains from which to accept cross origin requests (browser enforced)",
+		Value: "",
+	}
+	GraphQLVirtualHostsFlag = cli.StringFlag{
+		Name:  "graphql.vhosts",
+		Usage: "Comma separated list of virtual hostnames from which to accept requests (server enforced). Accepts '*' wildcard.",
+		Value: strings.Join(node.DefaultConfig.GraphQLVirtualHosts, ","),
+	}
 	ExecFlag = cli.StringFlag{
 		Name:  "exec",
 		Usage: "Execute JavaScript statement",
 	}
+	ExecFileFlag = cli.StringFlag{
+		Name:  "exec-file",
+		Usage: "Run the JavaScript file as a script and exit with its status, the remaining arguments being passed to it",
+	}
 	PreloadJSFlag = cli.StringFlag{
 		Name:  "preload",
 		Usage: "Comma separated list of JavaScript files to preload into the console",
 	}
+	AuthKeyFlag = cli.StringFlag{
+		Name:  "authkey",
+		Usage: "API key or JWT the console authenticates with to the node",
+	}
 
 	// Network Settings
 	MaxPeersFlag = cli.IntFlag{
@@ -829,6 +891,26 @@ func setWS(ctx *cli.Context, cfg *node.Config) {
 	}
 }
 
+// setGraphQL creates the GraphQL listener interface string from the set
+// command line flags, returning empty if the GraphQL endpoint is disabled.
+func setGraphQL(ctx *cli.Context, cfg *node.Config) {
+	if ctx.GlobalBool(GraphQLEnabledFlag.Name) && cfg.GraphQLHost == "" {
+		cfg.GraphQLHost = "127.0.0.1"
+		if ctx.GlobalIsSet(GraphQLListenAddrFlag.Name) {
+			cfg.GraphQLHost = ctx.GlobalString(GraphQLListenAddrFlag.Name)
+		}
+	}
+	if ctx.GlobalIsSet(GraphQLPortFlag.Name) {
+		cfg.GraphQLPort = ctx.GlobalInt(GraphQLPortFlag.Name)
+	}
+	if ctx.GlobalIsSet(GraphQLCORSDomainFlag.Name) {
+		cfg.GraphQLCors = splitAndTrim(ctx.GlobalString(GraphQLCORSDomainFlag.Name))
+	}
+	if ctx.GlobalIsSet(GraphQLVirtualHostsFlag.Name) {
+		cfg.GraphQLVirtualHosts = splitAndTrim(ctx.GlobalString(GraphQLVirtualHostsFlag.Name))
+	}
+}
+
 // setIPC creates an IPC path configuration from the set command line flags,
 // returning an empty string if IPC was explicitly disabled, or the set path.
 func setIPC(ctx *cli.Context, cfg *node.Config) {
@@ -1021,6 +1103,7 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	setIPC(ctx, cfg)
 	setHTTP(ctx, cfg)
 	setWS(ctx, cfg)
+	setGraphQL(ctx, cfg)
 	setNodeUserIdent(ctx, cfg)
 
 	switch {
@@ -1035,6 +1118,12 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	if ctx.GlobalIsSet(KeyStoreDirFlag.Name) {
 		cfg.KeyStoreDir = ctx.GlobalString(KeyStoreDirFlag.Name)
 	}
+	if ctx.GlobalIsSet(USBFlag.Name) {
+		cfg.USB = ctx.GlobalBool(USBFlag.Name)
+	}
+	if ctx.GlobalIsSet(NoUSBFlag.Name) {
+		cfg.NoUSB = ctx.GlobalBool(NoUSBFlag.Name)
+	}
 	if ctx.GlobalIsSet(TestForkFlag.Name) {
 		zconfig.Init_TestFork()
 		if ctx.GlobalIsSet(TestStartBlockFlag.Name) {
@@ -1063,9 +1152,18 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolNoLocalsFlag.Name) {
 		cfg.NoLocals = ctx.GlobalBool(TxPoolNoLocalsFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolJournalFlag.Name) {
+		cfg.Journal = ctx.GlobalString(TxPoolJournalFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolRejournalFlag.Name) {
+		cfg.Rejournal = ctx.GlobalDuration(TxPoolRejournalFlag.Name)
+	}
 	if ctx.GlobalIsSet(TxPoolPriceLimitFlag.Name) {
 		cfg.PriceLimit = ctx.GlobalUint64(TxPoolPriceLimitFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolPriceBumpFlag.Name) {
+		cfg.PriceBump = ctx.GlobalUint64(TxPoolPriceBumpFlag.Name)
+	}
 	if ctx.GlobalIsSet(TxPoolAccountSlotsFlag.Name) {
 		cfg.AccountSlots = ctx.GlobalUint64(TxPoolAccountSlotsFlag.Name)
 	}
@@ -1206,6 +1304,12 @@ func SetSeroConfig(ctx *cli.Context, stack *node.Node, cfg *dece.Config) {
 		cfg.DatabaseCache = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheDatabaseFlag.Name) / 100
 	}
 	cfg.DatabaseHandles = makeDatabaseHandles()
+	if ctx.GlobalIsSet(AncientFlag.Name) {
+		cfg.DatabaseFreezer = ctx.GlobalString(AncientFlag.Name)
+	}
+	if ctx.GlobalIsSet(AncientDepthFlag.Name) {
+		cfg.FreezerDepth = ctx.GlobalUint64(AncientDepthFlag.Name)
+	}
 
 	if gcmode := ctx.GlobalString(GCModeFlag.Name); gcmode != "full" && gcmode != "archive" {
 		Fatalf("--%s must be either 'full' or 'archive'", GCModeFlag.Name)
@@ -1271,6 +1375,8 @@ func SetSeroConfig(ctx *cli.Context, stack *node.Node, cfg *dece.Config) {
 		}
 
 		cfg.Genesis = core.DeveloperGenesisBlock()
+		cfg.Ethash.PowMode = ethash.ModeDev
+		cfg.Ethash.DevPeriod = uint64(ctx.GlobalInt(DeveloperPeriodFlag.Name))
 	}
 	// TODO(fjl): move trie cache generations into config
 	if gen := ctx.GlobalInt(TrieCacheGenFlag.Name); gen > 0 {
@@ -1337,6 +1443,26 @@ func RegisterEthService(stack *node.Node, cfg *dece.Config) {
 	}
 }
 
+// RegisterSeroStatsService configures the Dece stats daemon and adds it to
+// the given node.
+func RegisterSeroStatsService(stack *node.Node, url string) {
+	if err := stack.Register(func(ctx *node.ServiceContext) (node.Service, error) {
+		var deceServ *dece.Dece
+		ctx.Service(&deceServ)
+		return decestats.New(url, deceServ)
+	}); err != nil {
+		Fatalf("Failed to register the Dece stats service: %v", err)
+	}
+}
+
+// RegisterGraphQLService adds the GraphQL server, answering the queries from
+// the chain of the Dece service, to the given node.
+func RegisterGraphQLService(stack *node.Node, endpoint string, cors, vhosts []string, timeouts rpc.HTTPTimeouts) {
+	if err := graphql.RegisterGraphQLService(stack, endpoint, cors, vhosts, timeouts); err != nil {
+		Fatalf("Failed to register the GraphQL service: %v", err)
+	}
+}
+
 // RegisterDashboardService adds a dashboard to the stack.
 // func RegisterDashboardService(stack *node.Node, cfg *dashboard.Config, commit string) {
 // 	stack.Register(func(ctx *node.ServiceContext) (node.Service, error) {
@@ -1382,6 +1508,17 @@ func MakeChainDatabase(ctx *cli.Context, stack *node.Node) decedb.Database {
 	if err != nil {
 		Fatalf("Could not open database: %v", err)
 	}
+	ldb, ok := chainDb.(*decedb.LDBDatabase)
+	if !ok {
+		return chainDb
+	}
+	freezer := filepath.Join(ldb.Path(), "ancient")
+	if ctx.GlobalIsSet(AncientFlag.Name) {
+		freezer = stack.ResolvePath(ctx.GlobalString(AncientFlag.Name))
+	}
+	if chainDb, err = rawdb.NewDatabaseWithFreezer(ldb, freezer, ctx.GlobalUint64(AncientDepthFlag.Name)); err != nil {
+		Fatalf("Could not open ancient database: %v", err)
+	}
 	return chainDb
 }
 
@@ -1407,8 +1544,12 @@ func MakeChain(ctx *cli.Context, stack *node.Node) (chain *core.BlockChain, chai
 	}
 	var engine consensus.Engine
 
-	engine = ethash.NewFaker()
-	if !ctx.GlobalBool(FakePoWFlag.Name) {
+	switch {
+	case ctx.GlobalBool(DeveloperFlag.Name):
+		engine = ethash.NewDeveloper(uint64(ctx.GlobalInt(DeveloperPeriodFlag.Name)))
+	case ctx.GlobalBool(FakePoWFlag.Name):
+		engine = ethash.NewFaker()
+	default:
 		engine = ethash.New(ethash.Config{
 			CacheDir:       stack.ResolvePath(dece.DefaultConfig.Ethash.CacheDir),
 			CachesInMem:    dece.DefaultConfig.Ethash.CachesInMem,
@@ -182,7 +182,8 @@ func (ethash *Ethash) verifyHeader(chain consensus.ChainReader, header, parent *
 		return consensus.ErrFutureBlock
 	}
 
-	if header.Time.Cmp(parent.Time) <= 0 {
+	// Blocks sealed instantly in developer mode may share their parent's second
+	if cmp := header.Time.Cmp(parent.Time); cmp < 0 || (cmp == 0 && ethash.config.PowMode != ModeDev) {
 		return errZeroBlockTime
 	}
 	// Verify the block's difficulty based in it's timestamp and parent's difficulty
@@ -333,7 +334,7 @@ func calcDifficultyFrontier(time uint64, parent *types.Header) *big.Int {
 // the PoW difficulty requirements.
 func (ethash *Ethash) VerifySeal(chain consensus.ChainReader, header *types.Header) error {
 	// If we're running a fake PoW, accept any seal as valid
-	if ethash.config.PowMode == ModeFake || ethash.config.PowMode == ModeFullFake {
+	if ethash.config.PowMode == ModeFake || ethash.config.PowMode == ModeFullFake || ethash.config.PowMode == ModeDev {
 		time.Sleep(ethash.fakeDelay)
 		if ethash.fakeFail == header.Number.Uint64() {
 			return errInvalidPoW
@@ -382,6 +383,14 @@ func (ethash *Ethash) Prepare(chain consensus.ChainReader, header *types.Header)
 	if parent == nil {
 		return consensus.ErrUnknownAncestor
 	}
+	// In developer mode, the block is due a period after its parent, or now if
+	// the chain has been idle for longer
+	if ethash.config.PowMode == ModeDev {
+		header.Time = new(big.Int).Add(parent.Time, new(big.Int).SetUint64(ethash.config.DevPeriod))
+		if now := big.NewInt(time.Now().Unix()); header.Time.Cmp(now) < 0 {
+			header.Time = now
+		}
+	}
 	header.Difficulty = ethash.CalcDifficulty(chain, header.Time.Uint64(), parent)
 	return nil
 }
@@ -440,10 +449,15 @@ func (ethash *Ethash) Finalize(chain consensus.ChainReader, header *types.Header
 var (
 	oneDece = big.NewInt(1e+18)
 
+	blockReward    = new(big.Int).Mul(big.NewInt(24), oneDece)
+	devBlockReward = new(big.Int).Mul(big.NewInt(10000), oneDece)
+
 	interval    = big.NewInt(8294400)
 	halveNimber = big.NewInt(3057600)
 
-	communityRewardPool = common.BytesToAddress(crypto.Keccak512([]byte{1}))
+	// CommunityRewardPool is the account holding the community rewards until
+	// they are paid out to the community address every 5000 blocks.
+	CommunityRewardPool = common.BytesToAddress(crypto.Keccak512([]byte{1}))
 	communityAddress    = common.Base58ToAddress("NKmU94DaV9fd9U6L8Nu2XPkQe6qg5Y7DR1f9N881ZhKZPArkbi4vXxn6Mi8HteyDhkJsk4srdPQXwRViq1SkqvjiS14mnbKGoPNM2kjpRqkGg8EgrDTeuD31HjpZLxiPth7")
 )
 
@@ -452,18 +466,43 @@ func Halve(blockNumber *big.Int) *big.Int {
 	return new(big.Int).Exp(big2, i, nil)
 }
 
+// BlockRewards returns the rewards minted by the block of the given number, the
+// fees of its transactions excluded: the one of its coinbase and the one of the
+// community pool.
+func BlockRewards(number *big.Int) (coinbase, community *big.Int) {
+	if deceparam.Is_Dev() {
+		return new(big.Int).Set(devBlockReward), new(big.Int)
+	}
+	return new(big.Int), new(big.Int).Set(blockReward)
+}
+
+// BlockRewardsRange returns the rewards minted by the blocks from from to to,
+// both included, as BlockRewards does for a single block. The genesis block
+// mints none. The reward of a block does not depend on its number, the sum is
+// the one of a block times the count of the blocks.
+func BlockRewardsRange(from, to uint64) (coinbase, community *big.Int) {
+	if from == 0 {
+		from = 1
+	}
+	if to < from {
+		return new(big.Int), new(big.Int)
+	}
+	count := new(big.Int).SetUint64(to - from + 1)
+	coinbase, community = BlockRewards(new(big.Int).SetUint64(from))
+	return coinbase.Mul(coinbase, count), community.Mul(community, count)
+}
+
 // AccumulateRewards credits the coinbase of the given block with the mining
 // reward. The total reward consists of the static block reward .
 func accumulateRewards(config *params.ChainConfig, statedb *state.StateDB, header *types.Header, gasReward uint64) {
-
-	reward := new(big.Int).Mul(oneDece, big.NewInt(24))
-	if deceparam.Is_Dev() {
-		reward = new(big.Int).Set(new(big.Int).Mul(big.NewInt(10000), oneDece))
-	}
+	coinbase, community := BlockRewards(header.Number)
 	// log.Info(fmt.Sprintf("BlockNumber = %v, gasLimie = %v, gasUsed = %v, reward = %v", header.Number.Uint64(), header.GasLimit, header.GasUsed, reward))
-	reward.Add(reward, new(big.Int).SetUint64(gasReward))
+	fees := new(big.Int).SetUint64(gasReward)
+	issued := new(big.Int).Add(coinbase, community)
+	statedb.AddIssued("DECE", issued.Add(issued, fees))
 
 	if deceparam.Is_Dev() {
+		reward := coinbase.Add(coinbase, fees)
 		asset := assets.Asset{Tkn: &assets.Token{
 			Currency: *common.BytesToHash(common.LeftPadBytes([]byte("DECE"), 32)).HashToUint256(),
 			Value:    utils.U256(*reward),
@@ -471,10 +510,10 @@ func accumulateRewards(config *params.ChainConfig, statedb *state.StateDB, heade
 		}
 		statedb.NextZState().AddTxOut(header.Coinbase, asset, common.BytesToHash([]byte{1}))
 	} else {
-		statedb.AddBalance(communityRewardPool, "DECE", reward)
+		statedb.AddBalance(CommunityRewardPool, "DECE", community.Add(community, fees))
 		if header.Number.Uint64()%5000 == 0 {
-			balance := statedb.GetBalance(communityRewardPool, "DECE")
-			statedb.SubBalance(communityRewardPool, "DECE", balance)
+			balance := statedb.GetBalance(CommunityRewardPool, "DECE")
+			statedb.SubBalance(CommunityRewardPool, "DECE", balance)
 			assetCommunity := assets.Asset{Tkn: &assets.Token{
 				Currency: *common.BytesToHash(common.LeftPadBytes([]byte("DECE"), 32)).HashToUint256(),
 				Value:    utils.U256(*balance),
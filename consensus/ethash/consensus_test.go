@@ -244,7 +244,8 @@ func print(number int64, difficulty int64) {
 		Number:     big.NewInt(number),
 		Difficulty: big.NewInt(difficulty),
 	}
-	v2 := accumulateRewardsV4(nil, header)
+	coinbase, community := BlockRewards(header.Number)
+	v2 := new(big.Int).Add(coinbase, community)
 	fmt.Println(number, difficulty)
 	fmt.Println(v2)
 	fmt.Println(new(big.Float).Quo(new(big.Float).SetInt(v2), big.NewFloat(1e+18)))
@@ -48,7 +48,7 @@ var (
 	maxUint256 = new(big.Int).Exp(big.NewInt(2), big.NewInt(256), big.NewInt(0))
 
 	// sharedEthash is a full instance that can be shared between multiple users.
-	sharedEthash = New(Config{"", 3, 0, "", 1, 0, ModeNormal})
+	sharedEthash = New(Config{"", 3, 0, "", 1, 0, ModeNormal, 0})
 
 	// algorithmRevision is the data structure version used for file naming.
 	algorithmRevision = 23
@@ -393,6 +393,7 @@ const (
 	ModeTest
 	ModeFake
 	ModeFullFake
+	ModeDev
 )
 
 // Config are the configuration parameters of the ethash.
@@ -404,6 +405,10 @@ type Config struct {
 	DatasetsInMem  int
 	DatasetsOnDisk int
 	PowMode        Mode
+
+	// DevPeriod is the number of seconds between the blocks sealed in
+	// developer mode, zero sealing them as soon as they are assembled.
+	DevPeriod uint64
 }
 
 // Ethash is a consensus engine based on proof-of-work implementing the ethash
@@ -500,6 +505,22 @@ func NewFullFaker() *Ethash {
 	}
 }
 
+// NewDeveloper creates an ethash consensus engine for development networks. It
+// seals blocks without any PoW, every period seconds or instantly if the period
+// is zero, and accepts all blocks' seal as valid, though they still have to
+// conform to the Ethereum consensus rules. The stake of the blocks, their votes
+// and rewards are processed as usual.
+func NewDeveloper(period uint64) *Ethash {
+	return &Ethash{
+		config: Config{
+			PowMode:   ModeDev,
+			DevPeriod: period,
+		},
+		update:   make(chan struct{}),
+		hashrate: metrics.NewMeter(),
+	}
+}
+
 // NewShared creates a full sized ethash PoW shared between all requesters running
 // in the same process.
 func NewShared() *Ethash {
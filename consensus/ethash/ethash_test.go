@@ -23,8 +23,12 @@ import (
 	"os"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/dece-cash/go-dece/common"
+	"github.com/dece-cash/go-dece/consensus"
 	"github.com/dece-cash/go-dece/core/types"
+	"github.com/dece-cash/go-dece/params"
 )
 
 // Tests that ethash works correctly in test mode.
@@ -43,6 +47,118 @@ func TestTestMode(t *testing.T) {
 	}
 }
 
+// devChain is a chain reader holding only the parent of the sealed block.
+type devChain struct {
+	consensus.ChainReader
+	parent *types.Header
+}
+
+func (c *devChain) Config() *params.ChainConfig { return params.TestChainConfig }
+
+func (c *devChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if hash == c.parent.Hash() && number == c.parent.Number.Uint64() {
+		return c.parent
+	}
+	return nil
+}
+
+func devHeader(parent *types.Header) *types.Header {
+	return &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number, big.NewInt(1)),
+		GasLimit:   parent.GasLimit,
+	}
+}
+
+// Tests that developer mode dates the blocks a period after their parent, or
+// now if the chain has been idle for longer, and seals them when they are due.
+func TestDevModePeriod(t *testing.T) {
+	now := time.Now().Unix()
+	parent := &types.Header{Number: big.NewInt(1), Time: big.NewInt(now), Difficulty: big.NewInt(100), GasLimit: params.GenesisGasLimit}
+	chain := &devChain{parent: parent}
+
+	ethash := NewDeveloper(2)
+	header := devHeader(parent)
+	if err := ethash.Prepare(chain, header); err != nil {
+		t.Fatalf("failed to prepare block: %v", err)
+	}
+	if header.Time.Int64() != now+2 {
+		t.Fatalf("block time mismatch: have %v, want %v", header.Time, now+2)
+	}
+	block, err := ethash.Seal(chain, types.NewBlockWithHeader(header), nil)
+	if err != nil {
+		t.Fatalf("failed to seal block: %v", err)
+	}
+	if sealed := time.Now().Unix(); sealed < header.Time.Int64() {
+		t.Errorf("block sealed at %v, before its time %v", sealed, header.Time)
+	}
+	if err := ethash.VerifyHeader(chain, block.Header(), true); err != nil {
+		t.Errorf("sealed block rejected: %v", err)
+	}
+
+	// An idle chain seals its next block now
+	parent.Time = big.NewInt(now - 60)
+	header = devHeader(parent)
+	if err := ethash.Prepare(chain, header); err != nil {
+		t.Fatalf("failed to prepare block: %v", err)
+	}
+	if header.Time.Int64() < now {
+		t.Errorf("idle block time mismatch: have %v, want at least %v", header.Time, now)
+	}
+}
+
+// Tests that developer mode accepts blocks sealed instantly in the second of
+// their parent, and that the other modes still reject them.
+func TestDevModeSameTime(t *testing.T) {
+	parent := &types.Header{Number: big.NewInt(1), Time: big.NewInt(time.Now().Unix()), Difficulty: big.NewInt(100), GasLimit: params.GenesisGasLimit}
+	chain := &devChain{parent: parent}
+
+	ethash := NewDeveloper(0)
+	header := devHeader(parent)
+	if err := ethash.Prepare(chain, header); err != nil {
+		t.Fatalf("failed to prepare block: %v", err)
+	}
+	header.Time = new(big.Int).Set(parent.Time)
+	header.Difficulty = ethash.CalcDifficulty(chain, header.Time.Uint64(), parent)
+	block, err := ethash.Seal(chain, types.NewBlockWithHeader(header), nil)
+	if err != nil {
+		t.Fatalf("failed to seal block: %v", err)
+	}
+	if err := ethash.VerifyHeader(chain, block.Header(), true); err != nil {
+		t.Errorf("block in its parent's second rejected: %v", err)
+	}
+	if err := NewFaker().VerifyHeader(chain, block.Header(), true); err != errZeroBlockTime {
+		t.Errorf("fake mode error mismatch: have %v, want %v", err, errZeroBlockTime)
+	}
+
+	header.Time = new(big.Int).Sub(parent.Time, big.NewInt(1))
+	header.Difficulty = ethash.CalcDifficulty(chain, header.Time.Uint64(), parent)
+	if err := ethash.VerifyHeader(chain, header, false); err != errZeroBlockTime {
+		t.Errorf("block before its parent: error mismatch: have %v, want %v", err, errZeroBlockTime)
+	}
+}
+
+// Tests that developer mode stops waiting for a block's time when sealing is
+// aborted.
+func TestDevModeStop(t *testing.T) {
+	header := &types.Header{Number: big.NewInt(1), Time: big.NewInt(time.Now().Add(time.Hour).Unix())}
+
+	stop := make(chan struct{})
+	close(stop)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if block, err := NewDeveloper(3600).Seal(nil, types.NewBlockWithHeader(header), stop); block != nil || err != nil {
+			t.Errorf("aborted seal mismatch: have block %v, error %v", block, err)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("seal not aborted")
+	}
+}
+
 // This test checks that cache lru logic doesn't crash under load.
 // It reproduces https://github.com/dece-cash/go-dece/issues/14943
 func TestCacheFileEvict(t *testing.T) {
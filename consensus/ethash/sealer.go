@@ -23,6 +23,7 @@ import (
 	"math/rand"
 	"runtime"
 	"sync"
+	"time"
 
 	"github.com/dece-cash/go-dece/common"
 	"github.com/dece-cash/go-dece/consensus"
@@ -33,10 +34,25 @@ import (
 // Seal implements consensus.Engine, attempting to find a nonce that satisfies
 // the block's difficulty requirements.
 func (ethash *Ethash) Seal(chain consensus.ChainReader, block *types.Block, stop <-chan struct{}) (*types.Block, error) {
-	// If we're running a fake PoW, simply return a 0 nonce immediately
+	// If we're running a fake PoW, simply return a 0 nonce immediately. The mix
+	// digest still differs between blocks, the lottery of the votes being
+	// drawn from the seal.
 	if ethash.config.PowMode == ModeFake || ethash.config.PowMode == ModeFullFake {
 		header := block.Header()
-		header.Nonce, header.MixDigest = types.BlockNonce{}, common.Hash{}
+		header.Nonce, header.MixDigest = types.BlockNonce{}, header.HashPow()
+		return block.WithSeal(header), nil
+	}
+	// In developer mode, seal the same way once the block's time has come
+	if ethash.config.PowMode == ModeDev {
+		header := block.Header()
+		header.Nonce, header.MixDigest = types.BlockNonce{}, header.HashPow()
+
+		delay := time.Until(time.Unix(header.Time.Int64(), 0))
+		select {
+		case <-stop:
+			return nil, nil
+		case <-time.After(delay):
+		}
 		return block.WithSeal(header), nil
 	}
 	// If we're running a shared PoW, delegate sealing to it
@@ -66,11 +66,14 @@ type Config struct {
 type Console struct {
 	client   *rpc.Client  // RPC client to execute Ethereum requests through
 	jsre     *jsre.JSRE   // JavaScript runtime environment running the interpreter
+	docRoot  string       // Filesystem path from where to load JavaScript files from
 	prompt   string       // Input prompt prefix string
 	prompter UserPrompter // Input prompter to allow interactive user feedback
 	histPath string       // Absolute path to the console scrollback history
 	history  []string     // Scroll history maintained by the console
 	printer  io.Writer    // Output writer to serialize any display strings to
+
+	scripting bool // Whether a script is being run by ExecuteScript
 }
 
 // New initializes a JavaScript interpreted runtime environment and sets defaults
@@ -90,6 +93,7 @@ func New(config Config) (*Console, error) {
 	console := &Console{
 		client:   config.Client,
 		jsre:     jsre.New(config.DocRoot, config.Printer),
+		docRoot:  config.DocRoot,
 		prompt:   config.Prompt,
 		prompter: config.Prompter,
 		printer:  config.Printer,
@@ -252,6 +256,10 @@ func (c *Console) init(preload []string) error {
 		obj.Set("sleep", bridge.Sleep)
 		obj.Set("clearHistory", c.clearHistory)
 	}
+	// The script helpers are offered by the console too.
+	if err := c.initScript(bridge); err != nil {
+		return err
+	}
 
 	// Preload any JavaScript files before starting the console
 	for _, path := range preload {
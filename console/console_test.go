@@ -20,13 +20,14 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
-	"github.com/dece-cash/go-dece/czero/cpt"
-	"github.com/dece-cash/go-dece/common"
+	"github.com/dece-cash/go-dece/accounts/keystore"
 	"github.com/dece-cash/go-dece/consensus/ethash"
 	"github.com/dece-cash/go-dece/core"
+	"github.com/dece-cash/go-dece/crypto"
+	"github.com/dece-cash/go-dece/czero/cpt"
+	"github.com/dece-cash/go-dece/dece"
 	"github.com/dece-cash/go-dece/internal/jsre"
 	"github.com/dece-cash/go-dece/node"
-	"github.com/dece-cash/go-dece/dece"
 	"io/ioutil"
 	"os"
 	"strings"
@@ -36,11 +37,12 @@ import (
 
 const (
 	testInstance = "console-tester"
-	testAddress  = "4yn9wDedx4B5k9RYmwavvmJ49UKn6VRoa8vubB5xkzanr74FPtaUM9XrFGCsB3erNyFPV7HY4U7TH8UjaswT6h3P"
+	testKey      = "b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291"
+	testAddress  = "5i8qZTF52UjJK17tiEgAUcTctgcvawVbCHMtW8xXsnmaq6WtGVyahiYYr6qQwh8wAuKtkL8Aw9VNVpUiUL3ydZnQ"
 )
 
-func init(){
-	cpt.ZeroInit(cpt.NET_Dev)
+func init() {
+	cpt.ZeroInit_NoCircuit()
 }
 
 // hookedPrompter implements UserPrompter to simulate use input via channels.
@@ -99,8 +101,14 @@ func newTester(t *testing.T, confOverride func(*dece.Config)) *tester {
 	if err != nil {
 		t.Fatalf("failed to create node: %v", err)
 	}
+	// Import the coinbase account, the first one of the keystore
+	key, _ := crypto.HexToECDSA(testKey)
+	ks := stack.AccountManager().Backends(keystore.KeyStoreType)[0].(*keystore.KeyStore)
+	if _, err := ks.ImportECDSA(key, "", 0); err != nil {
+		t.Fatalf("failed to import the coinbase account: %v", err)
+	}
 	ethConf := &dece.Config{
-		Genesis:   core.DeveloperGenesisBlock(),
+		Genesis: core.DeveloperGenesisBlock(),
 		Ethash: ethash.Config{
 			PowMode: ethash.ModeTest,
 		},
@@ -251,6 +259,29 @@ func TestExecute(t *testing.T) {
 	}
 }
 
+// Tests that scripts receive their arguments, write JSON lines and end with the
+// exit code they request, even from within a try block.
+func TestExecuteScript(t *testing.T) {
+	tester := newTester(t, nil)
+	defer tester.Close(t)
+
+	if err := tester.console.ExecuteScript("script.js", []string{"0"}); err != nil {
+		t.Fatalf("script failed: %v", err)
+	}
+	want := `{"amount":"12500000000000000000","args":["0"]}` + "\n"
+	if output := tester.output.String(); output != want {
+		t.Fatalf("script output mismatch: have %s, want %s", output, want)
+	}
+	err := tester.console.ExecuteScript("script.js", []string{"3"})
+	if serr, ok := err.(*ScriptError); !ok || serr.Code != 3 {
+		t.Fatalf("script exit mismatch: have %v, want code 3", err)
+	}
+	err = tester.console.ExecuteScript("missing.js", nil)
+	if err == nil {
+		t.Fatalf("missing script executed")
+	}
+}
+
 // Tests that the JavaScript objects returned by statement executions are properly
 // pretty printed instead of just displaing "[object]".
 func TestPrettyPrint(t *testing.T) {
@@ -0,0 +1,228 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package console
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/dece-cash/go-dece/common"
+	"github.com/robertkrimen/otto"
+)
+
+// scriptJS is the helper library of the scripts, offered as the script object.
+// The amounts are handled as decimal strings, in the smallest unit of their
+// currency or in whole coins, to never lose precision to the JavaScript numbers.
+const scriptJS = `
+var script = (function () {
+	var decimals = function (currency) {
+		if (!currency || currency.toUpperCase() === 'DECE') {
+			return 18;
+		}
+		return dece.getDecimal(currency);
+	};
+	var unit = function (currency) {
+		return new web3.BigNumber(10).pow(decimals(currency));
+	};
+	return {
+		// args are the arguments following the script on the command line.
+		args: [],
+
+		// decimals returns the number of decimals of a currency, DECE by default.
+		decimals: decimals,
+
+		// toAmount converts an amount in whole coins, such as '12.5', to the
+		// smallest unit of the currency.
+		toAmount: function (amount, currency) {
+			var value = new web3.BigNumber(amount).times(unit(currency));
+			if (!value.isInteger()) {
+				throw new Error('amount ' + amount + ' has more decimals than ' + (currency || 'DECE'));
+			}
+			return value.toFixed();
+		},
+
+		// fromAmount converts a value in the smallest unit of the currency to
+		// whole coins.
+		fromAmount: function (value, currency) {
+			return new web3.BigNumber(value).dividedBy(unit(currency)).toFixed();
+		},
+
+		// toHex converts a base58 address to hex.
+		toHex: function (address) {
+			return web3.base58ToHex(address);
+		},
+
+		// toBase58 converts a hex address to base58.
+		toBase58: function (hex) {
+			return web3.hexToBase58(hex);
+		},
+
+		// waitTx blocks until the transaction is included with the number of
+		// confirmations, 1 by default, and returns its receipt. It throws if the
+		// transaction failed or if it is still unconfirmed after the timeout in
+		// seconds, 600 by default.
+		waitTx: function (hash, confirmations, timeout) {
+			confirmations = confirmations || 1;
+			timeout = timeout || 600;
+
+			var deadline = Date.now() + timeout * 1000;
+			for (;;) {
+				var receipt = dece.getTransactionReceipt(hash);
+				if (receipt && receipt.blockNumber !== null) {
+					if (web3.toDecimal(receipt.status) === 0) {
+						throw new Error('transaction ' + hash + ' failed in block ' + receipt.blockNumber);
+					}
+					if (dece.blockNumber - receipt.blockNumber + 1 >= confirmations) {
+						return receipt;
+					}
+				}
+				if (Date.now() >= deadline) {
+					throw new Error('transaction ' + hash + ' not confirmed after ' + timeout + ' seconds');
+				}
+				script.sleep(1);
+			}
+		}
+	};
+})();
+`
+
+// ScriptError is returned by ExecuteScript when the script failed or exited
+// with a non-zero code.
+type ScriptError struct {
+	Code    int    // Exit code the process should end with
+	Message string // Failure of the script, empty when it called script.exit
+}
+
+func (e *ScriptError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("script exited with code %d", e.Code)
+	}
+	return e.Message
+}
+
+// scriptExit is panicked by script.exit and script.fail to unwind the script
+// up to ExecuteScript, past any try and catch of the script.
+type scriptExit struct {
+	code int
+	msg  string
+}
+
+// initScript loads the script helper library into the JavaScript runtime.
+func (c *Console) initScript(bridge *bridge) error {
+	if err := c.jsre.Compile("script.js", scriptJS); err != nil {
+		return fmt.Errorf("script.js: %v", err)
+	}
+	script, err := c.jsre.Get("script")
+	if err != nil {
+		return err
+	}
+	obj := script.Object()
+	obj.Set("sleep", bridge.Sleep)
+	obj.Set("output", c.scriptOutput)
+	obj.Set("exit", c.scriptExit)
+	obj.Set("fail", c.scriptFail)
+	return nil
+}
+
+// ExecuteScript runs the JavaScript file specified as the argument as a script,
+// the args being available to it as script.args. It returns nil if the script
+// completed or called script.exit(0), a *ScriptError otherwise. Callbacks still
+// pending when the script returns are not waited for.
+func (c *Console) ExecuteScript(path string, args []string) error {
+	src, err := ioutil.ReadFile(common.AbsolutePath(c.docRoot, path))
+	if err != nil {
+		return err
+	}
+	if args == nil {
+		args = []string{}
+	}
+	// Pass the arguments as a JavaScript array rather than a wrapped Go slice
+	encoded, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+	c.jsre.Do(func(vm *otto.Otto) {
+		c.scripting = true
+		defer func() {
+			c.scripting = false
+			if caught := recover(); caught != nil {
+				exit, ok := caught.(*scriptExit)
+				if !ok {
+					panic(caught)
+				}
+				err = nil
+				if exit.code != 0 {
+					err = &ScriptError{Code: exit.code, Message: exit.msg}
+				}
+			}
+		}()
+		if _, err = vm.Run("script.args = " + string(encoded) + ";"); err != nil {
+			return
+		}
+		var script *otto.Script
+		if script, err = vm.Compile(path, src); err == nil {
+			_, err = vm.Run(script)
+		}
+		if err != nil {
+			err = &ScriptError{Code: 1, Message: err.Error()}
+		}
+	})
+	return err
+}
+
+// scriptOutput writes its argument to the output as a line of JSON.
+func (c *Console) scriptOutput(call otto.FunctionCall) otto.Value {
+	JSON, _ := call.Otto.Object("JSON")
+	out, err := JSON.Call("stringify", call.Argument(0))
+	if err != nil {
+		throwJSException(err.Error())
+	}
+	if out.IsUndefined() {
+		fmt.Fprintln(c.printer, "null")
+	} else {
+		fmt.Fprintln(c.printer, out.String())
+	}
+	return otto.UndefinedValue()
+}
+
+// scriptExit ends the script with the given exit code, 0 by default.
+func (c *Console) scriptExit(call otto.FunctionCall) otto.Value {
+	if !c.scripting {
+		throwJSException("script.exit is only available to scripts")
+	}
+	code := int64(0)
+	if arg := call.Argument(0); !arg.IsUndefined() {
+		if !arg.IsNumber() {
+			throwJSException("usage: exit([code])")
+		}
+		code, _ = arg.ToInteger()
+	}
+	panic(&scriptExit{code: int(code)})
+}
+
+// scriptFail ends the script with the exit code 1, reporting the given failure.
+func (c *Console) scriptFail(call otto.FunctionCall) otto.Value {
+	if !c.scripting {
+		throwJSException("script.fail is only available to scripts")
+	}
+	msg := "script failed"
+	if arg := call.Argument(0); !arg.IsUndefined() {
+		msg = arg.String()
+	}
+	panic(&scriptExit{code: 1, msg: msg})
+}
@@ -0,0 +1,6 @@
+script.output({args: script.args, amount: script.toAmount("12.5")});
+try {
+	script.exit(Number(script.args[0]));
+} catch (e) {
+	script.output("caught");
+}
@@ -167,6 +167,10 @@ func NewBlockChain(db decedb.Database, cacheConfig *CacheConfig, chainConfig *pa
 			TrieTimeLimit: 5 * time.Minute,
 		}
 	}
+	// The forks and the stake economics are read from the global params
+	deceparam.InitSIPs(chainConfig.SIPBlocks())
+	stake.InitConfig(chainConfig.Stake)
+
 	bodyCache, _ := lru.New(bodyCacheLimit)
 	bodyRLPCache, _ := lru.New(bodyCacheLimit)
 	blockCache, _ := lru.New(blockCacheLimit)
@@ -1019,6 +1023,12 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 
 	state.NextZState().RecordBlock(batch, blockhash.HashToUint256())
 
+	// The supply ledger follows the one of the parent, the chains synced before
+	// it existed get theirs from the checksupply command.
+	if supply := rawdb.ReadSupply(bc.db, block.ParentHash(), block.NumberU64()-1); supply != nil {
+		rawdb.WriteSupply(batch, blockhash, block.NumberU64(), supply.Apply(state.Issuance()))
+	}
+
 	root, err := state.Commit(true)
 	if root != block.Root() {
 		log.Info("WiriteBlockWithState root not equal Error", "root", root, "block.root", block.Root())
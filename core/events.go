@@ -24,6 +24,13 @@ import (
 // NewTxsEvent is posted when a batch of transactions enter the transaction pool.
 type NewTxsEvent struct{ Txs []*types.Transaction }
 
+// ReplacedTxEvent is posted when a transaction in the pool is replaced by a
+// higher priced one spending the same nils.
+type ReplacedTxEvent struct {
+	Old *types.Transaction
+	New *types.Transaction
+}
+
 // PendingLogsEvent is posted pre mining and notifies of pending logs.
 type PendingLogsEvent struct {
 	Logs []*types.Log
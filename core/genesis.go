@@ -25,6 +25,7 @@ import (
 	"github.com/dece-cash/go-dece/zero/txs/assets"
 	"github.com/dece-cash/go-dece/zero/utils"
 	"math/big"
+	"sort"
 	"strings"
 
 	"github.com/dece-cash/go-dece/common"
@@ -33,6 +34,7 @@ import (
 	"github.com/dece-cash/go-dece/core/rawdb"
 	"github.com/dece-cash/go-dece/core/state"
 	"github.com/dece-cash/go-dece/core/types"
+	"github.com/dece-cash/go-dece/czero/c_superzk"
 	"github.com/dece-cash/go-dece/decedb"
 	"github.com/dece-cash/go-dece/log"
 	"github.com/dece-cash/go-dece/params"
@@ -199,7 +201,7 @@ func SetupGenesisBlock(db decedb.Database, genesis *Genesis) (*params.ChainConfi
 		return newcfg, stored, fmt.Errorf("missing block number for head header hash")
 	}
 	compatErr := storedcfg.CheckCompatible(newcfg, *height)
-	if compatErr != nil && *height != 0 && compatErr.RewindTo != 0 {
+	if compatErr != nil && *height != 0 {
 		return newcfg, stored, compatErr
 	}
 	rawdb.WriteChainConfig(db, stored, newcfg)
@@ -220,7 +222,9 @@ func (g *Genesis) configOrDefault(ghash common.Hash) *params.ChainConfig {
 }
 
 // ToBlock creates the genesis block and writes state of a genesis specification
-// to the given database (or discards it if nil).
+// to the given database (or discards it if nil). The allocations are keyed by
+// the PKr receiving their balance in DECE, a genesis without any gets the
+// premine of the main network.
 func (g *Genesis) ToBlock(db decedb.Database) *types.Block {
 	if db == nil {
 		db = decedb.NewMemDatabase()
@@ -229,13 +233,40 @@ func (g *Genesis) ToBlock(db decedb.Database) *types.Block {
 	statedb.RegisterToken(state.EmptyAddress, "DECE")
 
 	dece := common.BytesToHash(common.LeftPadBytes([]byte("DECE"), 32))
-	asset := assets.Asset{Tkn: &assets.Token{
-		Currency: *dece.HashToUint256(),
-		Value:    utils.U256(*new(big.Int).Mul(big.NewInt(450000000), big.NewInt(1000000000000000000))),
-	},
+	// allocate pays DECE to the PKr of an address, the payments to anything
+	// else than a super zk PKr being dropped by the zstate
+	allocate := func(addr common.Address, value *big.Int) {
+		asset := assets.Asset{Tkn: &assets.Token{
+			Currency: *dece.HashToUint256(),
+			Value:    utils.U256(*value),
+		},
+		}
+		statedb.NextZState().AddTxOut(addr, asset, common.Hash{})
+		if c_superzk.IsSzkPKr(addr.ToPKr()) {
+			statedb.AddIssued("DECE", value)
+		}
+	}
+	if len(g.Alloc) == 0 {
+		allocate(common.Base58ToAddress("NKmU94DaV9fd9U6L8Nu2XPkQe6qg5Y7DR1f9N881ZhKZPArkbi4vXxn6Mi8HteyDhkJsk4srdPQXwRViq1SkqvjiS14mnbKGoPNM2kjpRqkGg8EgrDTeuD31HjpZLxiPth7"),
+			new(big.Int).Mul(big.NewInt(450000000), big.NewInt(1000000000000000000)))
+	} else {
+		// The allocations are paid in DECE to their PKr, in a stable order
+		// to keep the hash of the block
+		addrs := make([]common.Address, 0, len(g.Alloc))
+		for addr := range g.Alloc {
+			addrs = append(addrs, addr)
+		}
+		sort.Slice(addrs, func(i, j int) bool {
+			return bytes.Compare(addrs[i][:], addrs[j][:]) < 0
+		})
+		for _, addr := range addrs {
+			account := g.Alloc[addr]
+			if account.Balance == nil || account.Balance.Sign() <= 0 {
+				continue
+			}
+			allocate(addr, account.Balance)
+		}
 	}
-	statedb.NextZState().AddTxOut(common.Base58ToAddress("NKmU94DaV9fd9U6L8Nu2XPkQe6qg5Y7DR1f9N881ZhKZPArkbi4vXxn6Mi8HteyDhkJsk4srdPQXwRViq1SkqvjiS14mnbKGoPNM2kjpRqkGg8EgrDTeuD31HjpZLxiPth7"),
-		asset, common.Hash{})
 
 	root := statedb.IntermediateRoot(false)
 	head := &types.Header{
@@ -265,6 +296,7 @@ func (g *Genesis) ToBlock(db decedb.Database) *types.Block {
 	blockhash := block.Hash()
 	statedb.GetStakeCons().Record(block.Header(), db)
 	statedb.NextZState().RecordBlock(db, blockhash.HashToUint256())
+	rawdb.WriteSupply(db, blockhash, block.NumberU64(), types.Supply{}.Apply(statedb.Issuance()))
 
 	return block
 }
@@ -0,0 +1,174 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/dece-cash/go-dece/consensus/ethash"
+	"github.com/dece-cash/go-dece/core/rawdb"
+	"github.com/dece-cash/go-dece/core/types"
+	"github.com/dece-cash/go-dece/core/vm"
+	"github.com/dece-cash/go-dece/czero/cpt"
+	"github.com/dece-cash/go-dece/czero/deceparam"
+	"github.com/dece-cash/go-dece/decedb"
+	"github.com/dece-cash/go-dece/params"
+	"github.com/dece-cash/go-dece/zero/stake"
+)
+
+// testGenesis returns a genesis with the given SIP1 block and stake payment
+// window.
+func testGenesis(sip1 int64, payWindow uint64) *Genesis {
+	return &Genesis{
+		Config: &params.ChainConfig{
+			ChainID:   big.NewInt(1),
+			SIP1Block: big.NewInt(sip1),
+			Stake:     &params.StakeConfig{PayWindow: payWindow},
+			Ethash:    new(params.EthashConfig),
+		},
+	}
+}
+
+// setHead moves the head header of db to a header at number, as if the chain
+// had grown up to it.
+func setHead(db decedb.Database, number int64) {
+	header := &types.Header{Number: big.NewInt(number), Difficulty: new(big.Int)}
+	rawdb.WriteHeader(db, header)
+	rawdb.WriteHeadHeaderHash(db, header.Hash())
+}
+
+func TestSetupGenesisBlock(t *testing.T) {
+	cpt.ZeroInit_NoCircuit()
+
+	tests := []struct {
+		name    string
+		head    int64
+		genesis *Genesis
+		wantErr *params.ConfigCompatError
+	}{
+		{
+			name:    "unchanged config",
+			head:    100,
+			genesis: testGenesis(10, 5),
+		},
+		{
+			name:    "stake config changed at genesis",
+			head:    0,
+			genesis: testGenesis(10, 10),
+		},
+		{
+			name:    "SIP fork rescheduled ahead of the head",
+			head:    5,
+			genesis: testGenesis(20, 5),
+		},
+		{
+			name:    "passed SIP fork rescheduled",
+			head:    100,
+			genesis: testGenesis(20, 5),
+			wantErr: &params.ConfigCompatError{
+				What:         "SIP1 fork block",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    big.NewInt(20),
+				RewindTo:     9,
+			},
+		},
+		{
+			// Rewinds to the genesis block, still refused past it
+			name:    "stake config changed past genesis",
+			head:    1,
+			genesis: testGenesis(10, 10),
+			wantErr: &params.ConfigCompatError{
+				What:         "stake config",
+				StoredConfig: big.NewInt(1),
+				NewConfig:    big.NewInt(1),
+				RewindTo:     0,
+			},
+		},
+	}
+	for _, test := range tests {
+		db := decedb.NewMemDatabase()
+		stored := testGenesis(10, 5)
+		ghash := stored.MustCommit(db).Hash()
+		setHead(db, test.head)
+
+		config, hash, err := SetupGenesisBlock(db, test.genesis)
+		if hash != ghash {
+			t.Errorf("%s: returned hash %x, want %x", test.name, hash, ghash)
+		}
+		if test.wantErr == nil {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", test.name, err)
+				continue
+			}
+			if !reflect.DeepEqual(rawdb.ReadChainConfig(db, ghash), test.genesis.Config) {
+				t.Errorf("%s: new config not stored", test.name)
+			}
+		} else {
+			if !reflect.DeepEqual(err, test.wantErr) {
+				t.Errorf("%s: error mismatch: have %v, want %v", test.name, err, test.wantErr)
+				continue
+			}
+			if !reflect.DeepEqual(rawdb.ReadChainConfig(db, ghash), stored.Config) {
+				t.Errorf("%s: stored config overwritten", test.name)
+			}
+		}
+		if !reflect.DeepEqual(config, test.genesis.Config) {
+			t.Errorf("%s: returned config %v, want %v", test.name, config, test.genesis.Config)
+		}
+	}
+}
+
+// Tests that the forks and stake economics of a chain don't leak into the next
+// one opened.
+func TestBlockChainConfigReset(t *testing.T) {
+	cpt.ZeroInit_NoCircuit()
+
+	sip1, locking := deceparam.SIP1(), stake.GetLockingBlockNum()
+
+	db := decedb.NewMemDatabase()
+	gspec := testGenesis(int64(sip1)+1, 0)
+	gspec.Config.Stake.LockingBlockNum = locking + 1
+	gspec.MustCommit(db)
+	chain, err := NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create the chain: %v", err)
+	}
+	chain.Stop()
+	if have := deceparam.SIP1(); have != sip1+1 {
+		t.Errorf("SIP1 mismatch: have %d, want %d", have, sip1+1)
+	}
+	if have := stake.GetLockingBlockNum(); have != locking+1 {
+		t.Errorf("locking period mismatch: have %d, want %d", have, locking+1)
+	}
+
+	db = decedb.NewMemDatabase()
+	gspec = &Genesis{Config: params.TestChainConfig}
+	gspec.MustCommit(db)
+	chain, err = NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create the chain: %v", err)
+	}
+	chain.Stop()
+	if have := deceparam.SIP1(); have != sip1 {
+		t.Errorf("SIP1 not reset: have %d, want %d", have, sip1)
+	}
+	if have := stake.GetLockingBlockNum(); have != locking {
+		t.Errorf("locking period not reset: have %d, want %d", have, locking)
+	}
+}
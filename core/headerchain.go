@@ -465,6 +465,9 @@ func (hc *HeaderChain) SetHead(head uint64, delFn DeleteCallback) {
 	}
 	batch.Write()
 
+	// The frozen blocks above the new head are not canonical anymore
+	rawdb.TruncateAncients(hc.chainDb, head+1)
+
 	// Clear out any stale content from the caches
 	hc.headerCache.Purge()
 	hc.tdCache.Purge()
@@ -324,6 +324,39 @@ func DeleteReceipts(db DatabaseDeleter, hash common.Hash, number uint64) {
 	}
 }
 
+// ReadSupply retrieves the currency supply of the chain up to the block, nil
+// if it was not recorded.
+func ReadSupply(db DatabaseReader, hash common.Hash, number uint64) types.Supply {
+	data, _ := db.Get(blockSupplyKey(number, hash))
+	if len(data) == 0 {
+		return nil
+	}
+	supply := types.Supply{}
+	if err := rlp.DecodeBytes(data, &supply); err != nil {
+		log.Error("Invalid block supply RLP", "hash", hash, "err", err)
+		return nil
+	}
+	return supply
+}
+
+// WriteSupply stores the currency supply of the chain up to the block.
+func WriteSupply(db DatabaseWriter, hash common.Hash, number uint64, supply types.Supply) {
+	data, err := rlp.EncodeToBytes(supply)
+	if err != nil {
+		log.Crit("Failed to RLP encode block supply", "err", err)
+	}
+	if err := db.Put(blockSupplyKey(number, hash), data); err != nil {
+		log.Crit("Failed to store block supply", "err", err)
+	}
+}
+
+// DeleteSupply removes the currency supply recorded for a block.
+func DeleteSupply(db DatabaseDeleter, hash common.Hash, number uint64) {
+	if err := db.Delete(blockSupplyKey(number, hash)); err != nil {
+		log.Crit("Failed to delete block supply", "err", err)
+	}
+}
+
 // ReadBlock retrieves an entire block corresponding to the hash, assembling it
 // back from the stored header and body. If either the header or body could not
 // be retrieved nil is returned.
@@ -352,6 +385,7 @@ func WriteBlock(db DatabaseWriter, block *types.Block) {
 // DeleteBlock removes all block data associated with a hash.
 func DeleteBlock(db DatabaseDeleter, hash common.Hash, number uint64) {
 	DeleteReceipts(db, hash, number)
+	DeleteSupply(db, hash, number)
 	DeleteHeader(db, hash, number)
 	DeleteBody(db, hash, number)
 	DeleteTd(db, hash, number)
@@ -204,6 +204,29 @@ func TestTdStorage(t *testing.T) {
 	}
 }
 
+// Tests supply storage and retrieval operations.
+func TestSupplyStorage(t *testing.T) {
+	db := decedb.NewMemDatabase()
+
+	hash := common.BytesToHash([]byte{0x03, 0x14})
+	supply := types.Supply{}.Apply(map[string]*big.Int{"DECE": big.NewInt(314)}, nil)
+	if entry := ReadSupply(db, hash, 0); entry != nil {
+		t.Fatalf("Non existent supply returned: %v", entry)
+	}
+	// Write and verify the supply in the database
+	WriteSupply(db, hash, 0, supply)
+	if entry := ReadSupply(db, hash, 0); entry == nil {
+		t.Fatalf("Stored supply not found")
+	} else if !entry.Equal(supply) {
+		t.Fatalf("Retrieved supply mismatch: have %v, want %v", entry, supply)
+	}
+	// Delete the supply and verify the execution
+	DeleteSupply(db, hash, 0)
+	if entry := ReadSupply(db, hash, 0); entry != nil {
+		t.Fatalf("Deleted supply returned: %v", entry)
+	}
+}
+
 // Tests that canonical numbers can be mapped to hashes and retrieved.
 func TestCanonicalMappingStorage(t *testing.T) {
 	db := decedb.NewMemDatabase()
@@ -0,0 +1,165 @@
+package rawdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+
+	"github.com/dece-cash/go-dece/common"
+	"github.com/dece-cash/go-dece/decedb"
+	"github.com/dece-cash/go-dece/log"
+	"github.com/dece-cash/go-dece/zero/localdb"
+)
+
+// freezerdb is a database wrapper that enables the freezer data retrievals.
+// The blocks moved into the freezer are served back through Get and Has so
+// the accessors and the callers don't have to know about the freezer.
+type freezerdb struct {
+	*decedb.LDBDatabase
+	*freezer
+}
+
+// NewDatabaseWithFreezer wraps the key-value store with an append-only store
+// of finalized blocks kept in dir. If depth is not zero, the canonical blocks
+// deeper than depth are moved into the freezer in the background, the whole
+// history being migrated on the first run.
+func NewDatabaseWithFreezer(db *decedb.LDBDatabase, dir string, depth uint64) (decedb.Database, error) {
+	frdb, err := newFreezer(dir)
+	if err != nil {
+		return nil, err
+	}
+	if depth > 0 {
+		if depth < MinFreezerDepth {
+			log.Warn("Freezer depth too low, raising", "provided", depth, "updated", MinFreezerDepth)
+			depth = MinFreezerDepth
+		}
+		frdb.wg.Add(1)
+		go frdb.freeze(db, depth)
+	}
+	return &freezerdb{db, frdb}, nil
+}
+
+// KeyValueStore returns the LevelDB store backing db, if any.
+func KeyValueStore(db decedb.Database) *decedb.LDBDatabase {
+	switch db := db.(type) {
+	case *freezerdb:
+		return db.LDBDatabase
+	case *decedb.LDBDatabase:
+		return db
+	}
+	return nil
+}
+
+// Ancients returns the number of blocks kept in the freezer of db.
+func Ancients(db decedb.Database) uint64 {
+	if db, ok := db.(*freezerdb); ok {
+		return db.Ancients()
+	}
+	return 0
+}
+
+// TruncateAncients drops the frozen blocks at and above the given number,
+// the chain being rewound below them.
+func TruncateAncients(db decedb.Database, items uint64) {
+	if db, ok := db.(*freezerdb); ok && items < db.Ancients() {
+		if err := db.TruncateAncients(items); err != nil {
+			log.Crit("Failed to truncate ancient database", "items", items, "err", err)
+		}
+	}
+}
+
+// AncientKeyStats returns the item count and the size of every freezer table,
+// sorted by table name.
+func AncientKeyStats(db decedb.Database) (ret []decedb.KeyStat) {
+	if db, ok := db.(*freezerdb); ok {
+		names := make([]string, 0, len(freezerNoSnappy))
+		for name := range freezerNoSnappy {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			size, _ := db.AncientSize(name)
+			ret = append(ret, decedb.KeyStat{
+				Schema:    "ancient",
+				Name:      name,
+				Count:     db.tables[name].Items(),
+				ValueSize: size,
+			})
+		}
+	}
+	return
+}
+
+// Get retrieves the given key from the key-value store, falling back to the
+// freezer for the block data which has been moved there.
+func (db *freezerdb) Get(key []byte) ([]byte, error) {
+	data, err := db.LDBDatabase.Get(key)
+	if err == nil {
+		return data, nil
+	}
+	if data := db.ancientByKey(key); len(data) > 0 {
+		return data, nil
+	}
+	return nil, err
+}
+
+// Has checks the presence of the given key in the key-value store and in the
+// freezer.
+func (db *freezerdb) Has(key []byte) (bool, error) {
+	if has, err := db.LDBDatabase.Has(key); err != nil || has {
+		return has, err
+	}
+	return len(db.ancientByKey(key)) > 0, nil
+}
+
+// Close closes both the freezer and the key-value store.
+func (db *freezerdb) Close() {
+	if err := db.freezer.Close(); err != nil {
+		log.Error("Failed to close ancient database", "err", err)
+	}
+	db.LDBDatabase.Close()
+}
+
+// ancientByKey maps a key of the chain schema to the frozen data it refers to.
+func (db *freezerdb) ancientByKey(key []byte) []byte {
+	var (
+		kind   string
+		number uint64
+		hash   []byte
+	)
+	numberAt := func(pos int) uint64 {
+		return binary.BigEndian.Uint64(key[pos : pos+8])
+	}
+	switch {
+	case len(key) == len(headerPrefix)+8+len(headerHashSuffix) && key[0] == headerPrefix[0] && key[len(key)-1] == headerHashSuffix[0]:
+		kind, number = freezerHashTable, numberAt(1)
+	case len(key) == len(headerPrefix)+8+common.HashLength && key[0] == headerPrefix[0]:
+		kind, number, hash = freezerHeaderTable, numberAt(1), key[9:]
+	case len(key) == len(headerPrefix)+8+common.HashLength+len(headerTDSuffix) && key[0] == headerPrefix[0] && key[len(key)-1] == headerTDSuffix[0]:
+		kind, number, hash = freezerDifficultyTable, numberAt(1), key[9:9+common.HashLength]
+	case len(key) == len(blockBodyPrefix)+8+common.HashLength && key[0] == blockBodyPrefix[0]:
+		kind, number, hash = freezerBodiesTable, numberAt(1), key[9:]
+	case len(key) == len(blockReceiptsPrefix)+8+common.HashLength && key[0] == blockReceiptsPrefix[0]:
+		kind, number, hash = freezerReceiptTable, numberAt(1), key[9:]
+	default:
+		num, h, ok := localdb.ParseBlockKey(key)
+		if !ok {
+			return nil
+		}
+		kind, number, hash = freezerZBlockTable, num, h[:]
+	}
+	if !db.HasAncient(kind, number) {
+		return nil
+	}
+	if hash != nil {
+		if canonical, err := db.Ancient(freezerHashTable, number); err != nil || !bytes.Equal(canonical, hash) {
+			return nil
+		}
+	}
+	data, err := db.Ancient(kind, number)
+	if err != nil {
+		log.Error("Failed to read ancient data", "kind", kind, "number", number, "err", err)
+		return nil
+	}
+	return data
+}
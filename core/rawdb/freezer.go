@@ -0,0 +1,395 @@
+package rawdb
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dece-cash/go-dece/common"
+	"github.com/dece-cash/go-dece/decedb"
+	"github.com/dece-cash/go-dece/log"
+	"github.com/dece-cash/go-dece/zero/localdb"
+)
+
+// The list of table names of the chain freezer.
+const (
+	// freezerHeaderTable indicates the name of the freezer header table.
+	freezerHeaderTable = "headers"
+
+	// freezerHashTable indicates the name of the freezer canonical hash table.
+	freezerHashTable = "hashes"
+
+	// freezerBodiesTable indicates the name of the freezer block body table.
+	freezerBodiesTable = "bodies"
+
+	// freezerReceiptTable indicates the name of the freezer receipts table.
+	freezerReceiptTable = "receipts"
+
+	// freezerDifficultyTable indicates the name of the freezer total difficulty table.
+	freezerDifficultyTable = "diffs"
+
+	// freezerZBlockTable indicates the name of the freezer localdb block record table.
+	freezerZBlockTable = "zblocks"
+)
+
+// freezerNoSnappy configures whether compression is disabled for the tables.
+var freezerNoSnappy = map[string]bool{
+	freezerHeaderTable:     false,
+	freezerHashTable:       true,
+	freezerBodiesTable:     false,
+	freezerReceiptTable:    false,
+	freezerDifficultyTable: true,
+	freezerZBlockTable:     false,
+}
+
+const (
+	// MinFreezerDepth is the smallest distance from the head a block must
+	// have before it is moved into the freezer. The chain is not expected to
+	// reorganise deeper than that.
+	MinFreezerDepth = 4096
+
+	// freezerRecheckInterval is the frequency to check the key-value database for
+	// chain progression that might permit new blocks to be frozen into immutable
+	// storage.
+	freezerRecheckInterval = time.Minute
+
+	// freezerBatchLimit is the maximum number of blocks to freeze in one batch
+	// before doing an fsync and deleting it from the key-value store.
+	freezerBatchLimit = 30000
+)
+
+var errUnknownTable = errors.New("unknown table")
+
+// freezer is an append-only store of the finalized part of the chain. The
+// blocks are stored by number in one flat file table per kind of data, only
+// the canonical chain is kept.
+type freezer struct {
+	frozen uint64 // Number of blocks already frozen, accessed atomically
+
+	tables map[string]*freezerTable
+	lock   sync.Mutex // Serializes the appends and the truncations
+	quit   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// newFreezer opens the freezer tables in dir and aligns them to the shortest
+// one, dropping the blocks half written by an unclean shutdown.
+func newFreezer(dir string) (*freezer, error) {
+	if info, err := os.Lstat(dir); !os.IsNotExist(err) {
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("freezer path %s is not a directory", dir)
+		}
+	}
+	f := &freezer{
+		tables: make(map[string]*freezerTable),
+		quit:   make(chan struct{}),
+	}
+	for name, disableSnappy := range freezerNoSnappy {
+		table, err := newFreezerTable(dir, name, !disableSnappy)
+		if err != nil {
+			f.closeTables()
+			return nil, err
+		}
+		f.tables[name] = table
+	}
+	items := uint64(math.MaxUint64)
+	for _, table := range f.tables {
+		if table.Items() < items {
+			items = table.Items()
+		}
+	}
+	if err := f.truncate(items); err != nil {
+		f.closeTables()
+		return nil, err
+	}
+	f.frozen = items
+	log.Info("Opened ancient database", "path", dir, "frozen", items)
+	return f, nil
+}
+
+func (f *freezer) closeTables() {
+	for _, table := range f.tables {
+		table.Close()
+	}
+}
+
+// HasAncient returns an indicator whether the specified ancient data exists.
+func (f *freezer) HasAncient(kind string, number uint64) bool {
+	if table := f.tables[kind]; table != nil && number < atomic.LoadUint64(&f.frozen) {
+		return true
+	}
+	return false
+}
+
+// Ancient retrieves an ancient binary blob from the append-only immutable files.
+func (f *freezer) Ancient(kind string, number uint64) ([]byte, error) {
+	if table := f.tables[kind]; table != nil {
+		return table.Retrieve(number)
+	}
+	return nil, errUnknownTable
+}
+
+// Ancients returns the number of blocks frozen.
+func (f *freezer) Ancients() uint64 {
+	return atomic.LoadUint64(&f.frozen)
+}
+
+// AncientSize returns the number of bytes used on disk by the given table.
+func (f *freezer) AncientSize(kind string) (uint64, error) {
+	if table := f.tables[kind]; table != nil {
+		return table.Size(), nil
+	}
+	return 0, errUnknownTable
+}
+
+// AppendAncient injects all the data of a block at the end of the freezer.
+// Either all the tables are updated or none of them is.
+func (f *freezer) AppendAncient(number uint64, hash, header, body, receipts, td, zblock []byte) (err error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	defer func() {
+		if err != nil {
+			if rerr := f.truncate(number); rerr != nil {
+				log.Error("Failed to rollback ancient database", "number", number, "err", rerr)
+			}
+		}
+	}()
+	blobs := map[string][]byte{
+		freezerHashTable:       hash,
+		freezerHeaderTable:     header,
+		freezerBodiesTable:     body,
+		freezerReceiptTable:    receipts,
+		freezerDifficultyTable: td,
+		freezerZBlockTable:     zblock,
+	}
+	for name, blob := range blobs {
+		if err = f.tables[name].Append(number, blob); err != nil {
+			return err
+		}
+	}
+	atomic.AddUint64(&f.frozen, 1)
+	return nil
+}
+
+// TruncateAncients discards all the blocks at and above the given number.
+func (f *freezer) TruncateAncients(items uint64) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return f.truncate(items)
+}
+
+func (f *freezer) truncate(items uint64) error {
+	for _, table := range f.tables {
+		if err := table.truncate(items); err != nil {
+			return err
+		}
+	}
+	if items < atomic.LoadUint64(&f.frozen) {
+		atomic.StoreUint64(&f.frozen, items)
+	}
+	return nil
+}
+
+// Sync flushes all the tables to disk.
+func (f *freezer) Sync() error {
+	var errs []error
+	for _, table := range f.tables {
+		if err := table.Sync(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if errs != nil {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// Close terminates the background freezing and closes all the tables.
+func (f *freezer) Close() error {
+	select {
+	case <-f.quit:
+	default:
+		close(f.quit)
+	}
+	f.wg.Wait()
+
+	var errs []error
+	for _, table := range f.tables {
+		if err := table.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if errs != nil {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// freeze is a background thread that periodically checks the blockchain for
+// any import progress and moves the blocks deeper than depth from the
+// key-value database into the freezer. The first run migrates all the
+// existing history of the node.
+func (f *freezer) freeze(db decedb.Database, depth uint64) {
+	defer f.wg.Done()
+
+	for {
+		select {
+		case <-f.quit:
+			log.Info("Freezer shutting down")
+			return
+		default:
+		}
+		backoff := false
+
+		limit, ok := f.freezeLimit(db, depth)
+		if !ok {
+			backoff = true
+		} else {
+			// Keep going without pause while migrating a long history
+			if frozen, err := f.freezeRange(db, limit); err != nil {
+				log.Error("Failed to freeze blocks", "err", err)
+				backoff = true
+			} else if frozen < freezerBatchLimit {
+				backoff = true
+			}
+		}
+		if backoff {
+			select {
+			case <-time.NewTimer(freezerRecheckInterval).C:
+			case <-f.quit:
+				return
+			}
+		}
+	}
+}
+
+// freezeLimit returns the last block number which can be frozen in this batch.
+func (f *freezer) freezeLimit(db DatabaseReader, depth uint64) (uint64, bool) {
+	hash := ReadHeadBlockHash(db)
+	if hash == (common.Hash{}) {
+		return 0, false
+	}
+	number := ReadHeaderNumber(db, hash)
+	if number == nil || *number < depth {
+		return 0, false
+	}
+	limit := *number - depth
+	frozen := atomic.LoadUint64(&f.frozen)
+	if limit < frozen {
+		return 0, false
+	}
+	if limit-frozen >= freezerBatchLimit {
+		limit = frozen + freezerBatchLimit - 1
+	}
+	return limit, true
+}
+
+// freezeRange moves the canonical blocks up to limit into the freezer and
+// wipes them, and any side chain at the same heights, from the key-value store.
+func (f *freezer) freezeRange(db decedb.Database, limit uint64) (int, error) {
+	var (
+		start    = time.Now()
+		first    = atomic.LoadUint64(&f.frozen)
+		ancients []common.Hash
+	)
+	for number := first; number <= limit; number++ {
+		hash := ReadCanonicalHash(db, number)
+		if hash == (common.Hash{}) {
+			log.Error("Canonical hash missing, can't freeze", "number", number)
+			break
+		}
+		header := ReadHeaderRLP(db, hash, number)
+		body := ReadBodyRLP(db, hash, number)
+		receipts, _ := db.Get(blockReceiptsKey(number, hash))
+		td, _ := db.Get(headerTDKey(number, hash))
+		if len(header) == 0 || len(body) == 0 || len(receipts) == 0 || len(td) == 0 {
+			log.Error("Block data missing, can't freeze", "number", number, "hash", hash)
+			break
+		}
+		zblock, _ := db.Get(localdb.BlockKey(number, hash.HashToUint256()))
+		if err := f.AppendAncient(number, hash[:], header, body, receipts, td, zblock); err != nil {
+			return len(ancients), err
+		}
+		ancients = append(ancients, hash)
+	}
+	if len(ancients) == 0 {
+		return 0, nil
+	}
+	if err := f.Sync(); err != nil {
+		log.Crit("Failed to flush frozen tables", "err", err)
+	}
+	batch := db.NewBatch()
+	for i, hash := range ancients {
+		number := first + uint64(i)
+		batch.Delete(headerHashKey(number))
+		batch.Delete(headerKey(number, hash))
+		batch.Delete(headerTDKey(number, hash))
+		batch.Delete(blockBodyKey(number, hash))
+		batch.Delete(blockReceiptsKey(number, hash))
+		batch.Delete(localdb.BlockKey(number, hash.HashToUint256()))
+		if batch.ValueSize() > decedb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return len(ancients), err
+			}
+			batch.Reset()
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return len(ancients), err
+	}
+	var dangling int
+	if it, ok := db.(decedb.Iteratee); ok {
+		for i, hash := range ancients {
+			dangling += deleteSideChains(it, db, first+uint64(i), hash)
+		}
+	}
+	log.Info("Deep froze chain segment", "blocks", len(ancients), "dangling", dangling,
+		"number", first+uint64(len(ancients))-1, "hash", ancients[len(ancients)-1], "elapsed", common.PrettyDuration(time.Since(start)))
+	return len(ancients), nil
+}
+
+// deleteSideChains wipes the non canonical blocks at the given height which
+// can not become canonical anymore once the canonical one is frozen.
+func deleteSideChains(it decedb.Iteratee, db decedb.Database, number uint64, canonical common.Hash) (deleted int) {
+	batch := db.NewBatch()
+	prefix := append(append([]byte{}, headerPrefix...), encodeBlockNumber(number)...)
+	iter := it.NewIteratorWithPrefix(prefix)
+	for iter.Next() {
+		if len(iter.Key()) != len(prefix)+common.HashLength {
+			continue
+		}
+		hash := common.BytesToHash(iter.Key()[len(prefix):])
+		if hash == canonical {
+			continue
+		}
+		batch.Delete(headerKey(number, hash))
+		batch.Delete(headerNumberKey(hash))
+		batch.Delete(headerTDKey(number, hash))
+		batch.Delete(blockBodyKey(number, hash))
+		batch.Delete(blockReceiptsKey(number, hash))
+		deleted++
+	}
+	iter.Release()
+
+	iter = it.NewIteratorWithPrefix(localdb.BlockKeyPrefix(number))
+	for iter.Next() {
+		if num, _, ok := localdb.ParseBlockKey(iter.Key()); ok && num == number {
+			batch.Delete(common.CopyBytes(iter.Key()))
+		}
+	}
+	iter.Release()
+
+	if err := batch.Write(); err != nil {
+		log.Error("Failed to delete dangling side blocks", "number", number, "err", err)
+	}
+	return
+}
@@ -0,0 +1,102 @@
+package rawdb
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dece-cash/go-dece/core/types"
+	"github.com/dece-cash/go-dece/decedb"
+)
+
+// Tests that frozen blocks are wiped from the key-value store and still served
+// through the database wrapper, and that truncation rewinds the freezer.
+func TestFreezerReadThrough(t *testing.T) {
+	dir, err := ioutil.TempDir("", "freezer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ldb, err := decedb.NewLDBDatabase(filepath.Join(dir, "chaindata"), 16, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := NewDatabaseWithFreezer(ldb, filepath.Join(dir, "ancient"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var headers []*types.Header
+	for i := 0; i < 10; i++ {
+		header := &types.Header{Number: big.NewInt(int64(i)), Extra: []byte("test header")}
+		if i > 0 {
+			header.ParentHash = headers[i-1].Hash()
+		}
+		headers = append(headers, header)
+		WriteHeader(db, header)
+		WriteBody(db, header.Hash(), uint64(i), &types.Body{})
+		WriteReceipts(db, header.Hash(), uint64(i), nil)
+		WriteTd(db, header.Hash(), uint64(i), big.NewInt(int64(i)))
+		WriteCanonicalHash(db, header.Hash(), uint64(i))
+	}
+	WriteHeadBlockHash(db, headers[9].Hash())
+
+	frdb := db.(*freezerdb)
+	if n, err := frdb.freezeRange(ldb, 5); err != nil || n != 6 {
+		t.Fatalf("freeze: have %d blocks (%v), want 6", n, err)
+	}
+	if frozen := Ancients(db); frozen != 6 {
+		t.Fatalf("ancients: have %d, want 6", frozen)
+	}
+	if HasHeader(ldb, headers[3].Hash(), 3) {
+		t.Fatalf("frozen header still in the key-value store")
+	}
+	for i, header := range headers {
+		if hash := ReadCanonicalHash(db, uint64(i)); hash != header.Hash() {
+			t.Fatalf("block %d: canonical hash mismatch: have %x, want %x", i, hash, header.Hash())
+		}
+		if entry := ReadHeader(db, header.Hash(), uint64(i)); entry == nil || entry.Hash() != header.Hash() {
+			t.Fatalf("block %d: header not found", i)
+		}
+		if td := ReadTd(db, header.Hash(), uint64(i)); td == nil || td.Int64() != int64(i) {
+			t.Fatalf("block %d: td mismatch: have %v", i, td)
+		}
+		if !HasBody(db, header.Hash(), uint64(i)) {
+			t.Fatalf("block %d: body not found", i)
+		}
+	}
+	stats := AncientKeyStats(db)
+	if len(stats) != len(freezerNoSnappy) {
+		t.Fatalf("ancient stats: have %d tables, want %d", len(stats), len(freezerNoSnappy))
+	}
+	for i := 1; i < len(stats); i++ {
+		if stats[i-1].Name >= stats[i].Name {
+			t.Fatalf("ancient stats not sorted: %s before %s", stats[i-1].Name, stats[i].Name)
+		}
+	}
+	TruncateAncients(db, 4)
+	if frozen := Ancients(db); frozen != 4 {
+		t.Fatalf("ancients after truncation: have %d, want 4", frozen)
+	}
+	if ReadHeader(db, headers[4].Hash(), 4) != nil {
+		t.Fatalf("truncated header still served")
+	}
+	db.Close()
+
+	// Reopen and check the frozen blocks survived
+	if ldb, err = decedb.NewLDBDatabase(filepath.Join(dir, "chaindata"), 16, 16); err != nil {
+		t.Fatal(err)
+	}
+	if db, err = NewDatabaseWithFreezer(ldb, filepath.Join(dir, "ancient"), 0); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if frozen := Ancients(db); frozen != 4 {
+		t.Fatalf("ancients after reopen: have %d, want 4", frozen)
+	}
+	if entry := ReadHeader(db, headers[2].Hash(), 2); entry == nil {
+		t.Fatalf("frozen header lost after reopen")
+	}
+}
@@ -0,0 +1,52 @@
+package rawdb
+
+import (
+	"github.com/dece-cash/go-dece/common"
+	"github.com/dece-cash/go-dece/decedb"
+)
+
+func group(name string, prefix []byte, match func(k []byte) bool) decedb.KeyGroup {
+	return decedb.KeyGroup{Schema: "rawdb", Name: name, Prefix: prefix, Match: match}
+}
+
+func keyLen(n int) func(k []byte) bool {
+	return func(k []byte) bool {
+		return len(k) == n
+	}
+}
+
+func headerTDMatch(k []byte) bool {
+	return len(k) == len(headerPrefix)+8+common.HashLength+len(headerTDSuffix) &&
+		string(k[len(k)-len(headerTDSuffix):]) == string(headerTDSuffix)
+}
+
+func headerHashMatch(k []byte) bool {
+	return len(k) == len(headerPrefix)+8+len(headerHashSuffix) &&
+		string(k[len(k)-len(headerHashSuffix):]) == string(headerHashSuffix)
+}
+
+// ChainKeyGroups returns the key families of the chain database schema,
+// including the state trie nodes which are stored under their bare hash.
+func ChainKeyGroups() []decedb.KeyGroup {
+	return []decedb.KeyGroup{
+		group("Headers", headerPrefix, keyLen(len(headerPrefix)+8+common.HashLength)),
+		group("Total difficulties", headerPrefix, headerTDMatch),
+		group("Canonical hashes", headerPrefix, headerHashMatch),
+		group("Header numbers", headerNumberPrefix, keyLen(len(headerNumberPrefix)+common.HashLength)),
+		group("Bodies", blockBodyPrefix, keyLen(len(blockBodyPrefix)+8+common.HashLength)),
+		group("Receipts", blockReceiptsPrefix, keyLen(len(blockReceiptsPrefix)+8+common.HashLength)),
+		group("Supply", blockSupplyPrefix, keyLen(len(blockSupplyPrefix)+8+common.HashLength)),
+		group("Tx lookups", txLookupPrefix, keyLen(len(txLookupPrefix)+common.HashLength)),
+		group("Bloom bits", bloomBitsPrefix, keyLen(len(bloomBitsPrefix)+10+common.HashLength)),
+		group("Bloom bits index", BloomBitsIndexPrefix, nil),
+		group("Block index", indexPrefix, nil),
+		group("Preimages", preimagePrefix, nil),
+		group("Chain config", configPrefix, nil),
+		group("Trie nodes / code", nil, keyLen(common.HashLength)),
+		group("Metadata", databaseVerisionKey, keyLen(len(databaseVerisionKey))),
+		group("Metadata", headHeaderKey, keyLen(len(headHeaderKey))),
+		group("Metadata", headBlockKey, keyLen(len(headBlockKey))),
+		group("Metadata", headFastBlockKey, keyLen(len(headFastBlockKey))),
+		group("Metadata", fastTrieProgressKey, keyLen(len(fastTrieProgressKey))),
+	}
+}
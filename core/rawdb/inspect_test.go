@@ -0,0 +1,50 @@
+package rawdb
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/dece-cash/go-dece/common"
+	"github.com/dece-cash/go-dece/core/types"
+	"github.com/dece-cash/go-dece/decedb"
+)
+
+// Tests that the chain schema keys sharing the header prefix are told apart.
+func TestInspectChainKeys(t *testing.T) {
+	db := decedb.NewMemDatabase()
+
+	header := &types.Header{Number: big.NewInt(42), Extra: []byte("test header")}
+	WriteHeader(db, header)
+	WriteTd(db, header.Hash(), 42, big.NewInt(7))
+	WriteCanonicalHash(db, header.Hash(), 42)
+	WriteHeadBlockHash(db, header.Hash())
+	db.Put(common.Hash{1}.Bytes(), []byte{0xc0})
+	db.Put([]byte("unknown-key"), []byte{1, 2, 3})
+
+	inspector := decedb.NewInspector(ChainKeyGroups())
+	for _, key := range db.Keys() {
+		value, _ := db.Get(key)
+		inspector.Add(key, value)
+	}
+	counts := make(map[string]uint64)
+	for _, stat := range inspector.Stats() {
+		counts[stat.Name] = stat.Count
+	}
+	want := map[string]uint64{
+		"Headers":            1,
+		"Header numbers":     1,
+		"Total difficulties": 1,
+		"Canonical hashes":   1,
+		"Metadata":           1,
+		"Trie nodes / code":  1,
+		"Unaccounted":        1,
+	}
+	for name, count := range want {
+		if counts[name] != count {
+			t.Errorf("%s: have %d keys, want %d", name, counts[name], count)
+		}
+	}
+	if total := inspector.Total(); total.Count != uint64(db.Len()) {
+		t.Errorf("total: have %d keys, want %d", total.Count, db.Len())
+	}
+}
@@ -134,6 +134,11 @@ type (
 		prev      bool
 		prevDirty bool
 	}
+	issuanceChange struct {
+		burned   bool
+		currency string
+		amount   *big.Int
+	}
 )
 
 func (tn ticketNonceChange) revert(s *StateDB) {
@@ -237,3 +242,15 @@ func (ch addPreimageChange) revert(s *StateDB) {
 func (ch addPreimageChange) dirtied() *common.Address {
 	return nil
 }
+
+func (ch issuanceChange) revert(s *StateDB) {
+	ledger := s.minted
+	if ch.burned {
+		ledger = s.burned
+	}
+	ledger[ch.currency].Sub(ledger[ch.currency], ch.amount)
+}
+
+func (ch issuanceChange) dirtied() *common.Address {
+	return nil
+}
@@ -96,6 +96,10 @@ type StateDB struct {
 
 	preimages map[common.Hash][]byte
 
+	// The amounts of the currencies minted and burned by the block, these are
+	// not part of the consensus state.
+	minted, burned map[string]*big.Int
+
 	// Journal of state modifications. This is the backbone of
 	// Snapshot and RevertToSnapshot.
 	journal        *journal
@@ -370,6 +374,7 @@ func (self *StateDB) Reset(root common.Hash) error {
 	self.logs = make(map[common.Hash][]*types.Log)
 	self.logSize = 0
 	self.preimages = make(map[common.Hash][]byte)
+	self.minted, self.burned = nil, nil
 	self.clearJournalAndRefund()
 	return nil
 }
@@ -432,6 +437,41 @@ func (self *StateDB) Preimages() map[common.Hash][]byte {
 	return self.preimages
 }
 
+// AddIssued records an amount of a currency created by the state transition.
+func (self *StateDB) AddIssued(currency string, amount *big.Int) {
+	self.addIssuance(false, currency, amount)
+}
+
+// AddBurned records an amount of a currency destroyed by the state transition.
+func (self *StateDB) AddBurned(currency string, amount *big.Int) {
+	self.addIssuance(true, currency, amount)
+}
+
+func (self *StateDB) addIssuance(burned bool, currency string, amount *big.Int) {
+	if amount == nil || amount.Sign() == 0 {
+		return
+	}
+	if self.minted == nil {
+		self.minted = make(map[string]*big.Int)
+		self.burned = make(map[string]*big.Int)
+	}
+	ledger := self.minted
+	if burned {
+		ledger = self.burned
+	}
+	if ledger[currency] == nil {
+		ledger[currency] = new(big.Int)
+	}
+	self.journal.append(issuanceChange{burned: burned, currency: currency, amount: new(big.Int).Set(amount)})
+	ledger[currency].Add(ledger[currency], amount)
+}
+
+// Issuance returns the amounts of the currencies minted and burned since the
+// state was opened.
+func (self *StateDB) Issuance() (minted, burned map[string]*big.Int) {
+	return self.minted, self.burned
+}
+
 func (self *StateDB) AddRefund(gas uint64) {
 	self.journal.append(refundChange{prev: self.refund})
 	self.refund += gas
@@ -619,6 +659,13 @@ func (self *StateDB) Suicide(addr common.Address, toAddr common.Address) bool {
 	//	}
 	//}
 
+	// The balances of the account are destroyed with it
+	for _, book := range books {
+		if book.Balance != nil && book.Balance.Sign() > 0 {
+			self.AddBurned(book.Currency, book.Balance)
+		}
+	}
+
 	stateObject.markSuicided()
 	stateObject.data.Books = []*Book{}
 	stateObject.data.bookMap = map[string]*Book{}
@@ -795,6 +842,16 @@ func (self *StateDB) Copy() *StateDB {
 	for hash, preimage := range self.preimages {
 		state.preimages[hash] = preimage
 	}
+	if self.minted != nil {
+		state.minted = make(map[string]*big.Int, len(self.minted))
+		state.burned = make(map[string]*big.Int, len(self.burned))
+		for currency, amount := range self.minted {
+			state.minted[currency] = new(big.Int).Set(amount)
+		}
+		for currency, amount := range self.burned {
+			state.burned[currency] = new(big.Int).Set(amount)
+		}
+	}
 	return state
 }
 
@@ -20,7 +20,6 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
-	"github.com/dece-cash/go-dece/czero/cpt"
 	"math"
 	"math/big"
 	"math/rand"
@@ -398,7 +397,7 @@ func (test *snapshotTest) checkEqual(state, checkstate *StateDB) error {
 	return nil
 }
 
-func (s *StateSuite) TestTouchDelete(c *check.C) {
+func (s *StateSuite) TestTouchDelete(c *checker.C) {
 	s.state.GetOrNewStateObject(common.Address{})
 	root, _ := s.state.Commit(false)
 	s.state.Reset(root)
@@ -429,3 +428,41 @@ func TestCopyOfCopy(t *testing.T) {
 		t.Fatalf("2nd copy fail, expected 42, got %v", got)
 	}
 }
+
+// TestSuicideBurnsBalances tests that the balances of a destroyed contract are
+// recorded as burned by the supply ledger, and dropped again on revert.
+func TestSuicideBurnsBalances(t *testing.T) {
+	sdb, _ := New(NewDatabase(decedb.NewMemDatabase()), nil)
+	addr := common.BytesToAddress([]byte{1})
+	sdb.AddBalance(addr, "DECE", big.NewInt(42))
+	sdb.AddBalance(addr, "TOKEN", big.NewInt(7))
+
+	snapshot := sdb.Snapshot()
+	if !sdb.Suicide(addr, common.BytesToAddress([]byte{2})) {
+		t.Fatal("contract not destroyed")
+	}
+	_, burned := sdb.Issuance()
+	if burned["DECE"] == nil || burned["DECE"].Cmp(big.NewInt(42)) != 0 {
+		t.Errorf("DECE burned mismatch: have %v, want 42", burned["DECE"])
+	}
+	if burned["TOKEN"] == nil || burned["TOKEN"].Cmp(big.NewInt(7)) != 0 {
+		t.Errorf("TOKEN burned mismatch: have %v, want 7", burned["TOKEN"])
+	}
+	minted := map[string]*big.Int{"DECE": big.NewInt(100), "TOKEN": big.NewInt(7)}
+	supply := types.Supply{}.Apply(minted, burned)
+	if total := supply.Get("DECE").Total(); total.Cmp(big.NewInt(58)) != 0 {
+		t.Errorf("DECE supply mismatch: have %v, want 58", total)
+	}
+	if total := supply.Get("TOKEN").Total(); total.Sign() != 0 {
+		t.Errorf("TOKEN supply mismatch: have %v, want 0", total)
+	}
+
+	sdb.RevertToSnapshot(snapshot)
+	_, burned = sdb.Issuance()
+	if burned["DECE"].Sign() != 0 || burned["TOKEN"].Sign() != 0 {
+		t.Errorf("burned amounts kept on revert: %v", burned)
+	}
+	if balance := sdb.GetBalance(addr, "DECE"); balance.Cmp(big.NewInt(42)) != 0 {
+		t.Errorf("DECE balance mismatch on revert: have %v, want 42", balance)
+	}
+}
@@ -41,6 +41,7 @@ type StateTransition struct {
 	gas        uint64
 	gasPrice   *big.Int
 	initialGas uint64
+	fee        *big.Int // DECE taken for the gas
 	asset      *assets.Asset
 	data       []byte
 	state      vm.StateDB
@@ -155,10 +156,11 @@ func (st *StateTransition) preCheck() error {
 		}
 		st.state.AddBalance(*to, curency, st.msg.Fee().Value.ToRef().ToRef().ToIntRef())
 		st.state.SubBalance(*to, "DECE", taval)
-		gas = new(big.Int).Div(taval, st.msg.GasPrice()).Uint64()
+		st.fee = taval
 	} else {
-		gas = new(big.Int).Div(st.msg.Fee().Value.ToRef().ToIntRef(), st.msg.GasPrice()).Uint64()
+		st.fee = st.msg.Fee().Value.ToRef().ToIntRef()
 	}
+	gas = new(big.Int).Div(st.fee, st.msg.GasPrice()).Uint64()
 	if err := st.gp.SubGas(gas); err != nil {
 		return err
 	}
@@ -266,6 +268,10 @@ func (st *StateTransition) refundGas() {
 		}
 	}
 
+	// The DECE not returned is burned, the fees are minted again to the
+	// block reward by the consensus engine.
+	st.state.AddBurned("DECE", new(big.Int).Sub(st.fee, remaining))
+
 	// Also return remaining gas to the block gas counter so it is
 	// available for the next transaction.
 	st.gp.AddGas(st.gas)
@@ -128,7 +128,7 @@ func (journal *txJournal) insert(tx *types.Transaction) error {
 
 // rotate regenerates the transaction journal based on the current contents of
 // the transaction pool.
-func (journal *txJournal) rotate() error {
+func (journal *txJournal) rotate(all types.Transactions) error {
 	// Close the current journal (if any is open)
 	if journal.writer != nil {
 		if err := journal.writer.Close(); err != nil {
@@ -141,7 +141,12 @@ func (journal *txJournal) rotate() error {
 	if err != nil {
 		return err
 	}
-
+	for _, tx := range all {
+		if err = rlp.Encode(replacement, tx); err != nil {
+			replacement.Close()
+			return err
+		}
+	}
 	replacement.Close()
 
 	// Replace the live journal with the newly generated one
@@ -153,7 +158,7 @@ func (journal *txJournal) rotate() error {
 		return err
 	}
 	journal.writer = sink
-	log.Info("Regenerated local transaction journal", "transactions", "accounts")
+	log.Info("Regenerated local transaction journal", "transactions", len(all))
 
 	return nil
 }
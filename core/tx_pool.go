@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"math"
 	"math/big"
+	"runtime"
 	"runtime/debug"
 	"sync"
 	"time"
@@ -35,6 +36,7 @@ import (
 	"github.com/dece-cash/go-dece/crypto"
 	"github.com/dece-cash/go-dece/zero/stake"
 	"github.com/dece-cash/go-dece/zero/txs/stx"
+	"github.com/dece-cash/go-dece/zero/txs/zstate"
 
 	"github.com/dece-cash/go-dece/zero/txtool/verify"
 
@@ -62,6 +64,10 @@ var (
 	// configured for the transaction pool.
 	ErrUnderpriced = errors.New("transaction underpriced")
 
+	// ErrReplaceUnderpriced is returned if a transaction is attempted to be replaced
+	// with a different one without the required price bump.
+	ErrReplaceUnderpriced = errors.New("replacement transaction underpriced")
+
 	// ErrIntrinsicGas is returned if the transaction is specified to use less gas
 	// than required to start the invocation.
 	ErrIntrinsicGas = errors.New("intrinsic gas too low")
@@ -87,6 +93,12 @@ var (
 	// General tx metrics
 	invalidTxCounter     = metrics.NewRegisteredCounter("txpool/invalid", nil)
 	underpricedTxCounter = metrics.NewRegisteredCounter("txpool/underpriced", nil)
+	replacedTxCounter    = metrics.NewRegisteredCounter("txpool/replaced", nil)
+	admittedTxCounter    = metrics.NewRegisteredCounter("txpool/admitted", nil)
+
+	// Admission verification metrics
+	statelessVerifyTimer = metrics.NewRegisteredTimer("txpool/verify/stateless", nil)
+	statefulVerifyTimer  = metrics.NewRegisteredTimer("txpool/verify/stateful", nil)
 )
 
 // TxStatus is the current status of a transaction as seen by the pool.
@@ -110,9 +122,12 @@ type blockChain interface {
 
 // TxPoolConfig are the configuration parameters of the transaction pool.
 type TxPoolConfig struct {
-	NoLocals bool // Whether local transaction handling should be disabled
+	NoLocals  bool          // Whether local transaction handling should be disabled
+	Journal   string        // Journal of local transactions to survive node restarts
+	Rejournal time.Duration // Time interval to regenerate the local transaction journal
 
 	PriceLimit uint64 // Minimum gas priced to enforce for acceptance into the pool
+	PriceBump  uint64 // Minimum price bump percentage to replace a transaction spending the same nils
 
 	AccountSlots uint64 // Number of executable transaction slots guaranteed per account
 	GlobalSlots  uint64 // Maximum number of executable transaction slots for all accounts
@@ -127,8 +142,11 @@ type TxPoolConfig struct {
 // DefaultTxPoolConfig contains the default configurations for the transaction
 // pool.
 var DefaultTxPoolConfig = TxPoolConfig{
+	Journal:   "transactions.rlp",
+	Rejournal: time.Hour,
 
 	PriceLimit:   params.Gta,
+	PriceBump:    10,
 	AccountSlots: 16,
 	GlobalSlots:  4096,
 	AccountQueue: 64,
@@ -146,6 +164,14 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 		log.Warn("Sanitizing invalid txpool priced limit", "provided", conf.PriceLimit, "updated", DefaultTxPoolConfig.PriceLimit)
 		conf.PriceLimit = DefaultTxPoolConfig.PriceLimit
 	}
+	if conf.PriceBump < 1 {
+		log.Warn("Sanitizing invalid txpool price bump", "provided", conf.PriceBump, "updated", DefaultTxPoolConfig.PriceBump)
+		conf.PriceBump = DefaultTxPoolConfig.PriceBump
+	}
+	if conf.Rejournal < time.Second {
+		log.Warn("Sanitizing invalid txpool journal time", "provided", conf.Rejournal, "updated", time.Second)
+		conf.Rejournal = time.Second
+	}
 	return conf
 }
 
@@ -315,18 +341,21 @@ type TxPool struct {
 	chain        blockChain
 	gasPrice     *big.Int
 	txFeed       event.Feed
+	replaceFeed  event.Feed
 	scope        event.SubscriptionScope
 	chainHeadCh  chan ChainHeadEvent
 	chainHeadSub event.Subscription
 	//abi       types.Signer
 	mu sync.RWMutex
 
+	head          *types.Header       // Head of the chain the pool was last reset on
 	currentState  *state.StateDB      // Current state in the blockchain head
 	pendingState  *state.ManagedState // Pending state tracking virtual nonces
 	currentMaxGas uint64              // Current gas limit for transaction caps
 
-	locals *accountSet // Set of local transaction to exempt from eviction rules
-	//journal *txJournal  // Journal of local transaction to back up to disk
+	locals   *accountSet // Set of local transaction to exempt from eviction rules
+	localTxs *txLookup   // Local transactions still backed up in the journal
+	journal  *txJournal  // Journal of local transaction to back up to disk
 
 	all        *txLookup     // All transactions to allow lookups
 	priced     *txPricedList // All transactions sorted by priced
@@ -335,6 +364,9 @@ type TxPool struct {
 	beats      map[common.Hash]time.Time
 	faileds    map[common.Hash]time.Time
 
+	spends   map[c_type.Uint256]common.Hash // Pool transactions indexed by the nils and roots they spend
+	replaced map[common.Hash]replacement    // Recently replaced transactions and their replacements
+
 	wg sync.WaitGroup // for shutdown sync
 
 	pkrTxOuts PKrTxOuts
@@ -355,7 +387,10 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		chain:       chain,
 		beats:       make(map[common.Hash]time.Time),
 		faileds:     make(map[common.Hash]time.Time),
+		spends:      make(map[c_type.Uint256]common.Hash),
+		replaced:    make(map[common.Hash]replacement),
 		all:         newTxLookup(),
+		localTxs:    newTxLookup(),
 		chainHeadCh: make(chan ChainHeadEvent, chainHeadChanSize),
 		gasPrice:    new(big.Int).SetUint64(config.PriceLimit),
 	}
@@ -366,6 +401,20 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 	pool.newPending = newTxPricedList(newTxLookup())
 	pool.reset(nil, chain.CurrentBlock().Header())
 
+	// If local transactions and journaling is enabled, load from disk. Every
+	// journaled transaction goes through validateTx again, so the ones whose
+	// inputs were spent while the node was down are dropped here.
+	if !config.NoLocals && config.Journal != "" {
+		pool.journal = newTxJournal(config.Journal)
+
+		if err := pool.journal.load(pool.AddLocals); err != nil {
+			log.Warn("Failed to load transaction journal", "err", err)
+		}
+		if err := pool.journal.rotate(pool.local()); err != nil {
+			log.Warn("Failed to rotate transaction journal", "err", err)
+		}
+	}
+
 	// Subscribe events from blockchain
 	pool.chainHeadSub = pool.chain.SubscribeChainHeadEvent(pool.chainHeadCh)
 
@@ -391,8 +440,8 @@ func (pool *TxPool) loop() {
 	evict := time.NewTicker(evictionInterval)
 	defer evict.Stop()
 
-	// Track the previous head headers for transaction reorgs
-	head := pool.chain.CurrentBlock()
+	journal := time.NewTicker(pool.config.Rejournal)
+	defer journal.Stop()
 
 	// Keep waiting for and reacting to the various events
 	for {
@@ -401,10 +450,7 @@ func (pool *TxPool) loop() {
 		case ev := <-pool.chainHeadCh:
 			if ev.Block != nil {
 				pool.mu.Lock()
-
-				pool.reset(head.Header(), ev.Block.Header())
-				head = ev.Block
-
+				pool.resetHead()
 				pool.mu.Unlock()
 			}
 			// Be unsubscribed due to system stopped
@@ -438,7 +484,22 @@ func (pool *TxPool) loop() {
 			for _, h := range dropFaileds {
 				delete(pool.faileds, h)
 			}
+			for hash, r := range pool.replaced {
+				if time.Since(r.time) > pool.config.Lifetime {
+					delete(pool.replaced, hash)
+				}
+			}
 			pool.mu.Unlock()
+
+			// Handle local transaction journal rotation
+		case <-journal.C:
+			if pool.journal != nil {
+				pool.mu.Lock()
+				if err := pool.journal.rotate(pool.local()); err != nil {
+					log.Warn("Failed to rotate local tx journal", "err", err)
+				}
+				pool.mu.Unlock()
+			}
 		}
 	}
 }
@@ -452,6 +513,38 @@ func (pool *TxPool) RemoveTxs(txs types.Transactions) {
 	}
 }
 
+// Sync resets the pool on the current head of the chain, which it otherwise
+// does in the background once notified of the new head. The transactions sent
+// right after a block is imported are then validated against it.
+func (pool *TxPool) Sync() {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pool.resetHead()
+}
+
+// pendingNumber returns the number of the block the pooled transactions are
+// validated for, the one following the head the pool was last reset on, as the
+// block import validates the transactions of a block with its own number.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) pendingNumber() uint64 {
+	head := pool.head
+	if head == nil {
+		head = pool.chain.CurrentBlock().Header()
+	}
+	return head.Number.Uint64() + 1
+}
+
+// resetHead resets the pool on the current head of the chain, the events of
+// the heads it already caught up with being skipped.
+func (pool *TxPool) resetHead() {
+	head := pool.chain.CurrentBlock().Header()
+	if pool.head == nil || head.Hash() != pool.head.Hash() {
+		pool.reset(pool.head, head)
+	}
+}
+
 // lockedReset is a wrapper around reset to allow calling it in a thread safe
 // manner. This method is only ever used in the tester!
 func (pool *TxPool) lockedReset(oldHead, newHead *types.Header) {
@@ -540,6 +633,7 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 		log.Error("Failed to reset txpool state", "err", err)
 		return
 	}
+	pool.head = newHead
 	pool.currentState = statedb
 	pool.pendingState = state.ManageState(statedb)
 	pool.currentMaxGas = newHead.GasLimit
@@ -561,7 +655,8 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	// Inject any transactions discarded due to reorgs
 	log.Debug("Reinjecting stale transactions", "count", len(reinject))
 	if len(reinject) > 0 {
-		pool.addTxsLocked(reinject, false)
+		// Their proofs were already verified when the dropped blocks got imported
+		pool.addTxsLocked(reinject, make([]error, len(reinject)), false)
 		for _, tx := range reinject {
 			log.Info("reinject tx", "hash", tx.Hash())
 		}
@@ -579,6 +674,10 @@ func (pool *TxPool) Stop() {
 	pool.chainHeadSub.Unsubscribe()
 	pool.wg.Wait()
 
+	if pool.journal != nil {
+		pool.journal.close()
+	}
+
 	log.Info("Transaction pool stopped")
 }
 
@@ -588,6 +687,22 @@ func (pool *TxPool) SubscribeNewTxsEvent(ch chan<- NewTxsEvent) event.Subscripti
 	return pool.scope.Track(pool.txFeed.Subscribe(ch))
 }
 
+// SubscribeReplacedTxEvent registers a subscription of ReplacedTxEvent and
+// starts sending event to the given channel.
+func (pool *TxPool) SubscribeReplacedTxEvent(ch chan<- ReplacedTxEvent) event.Subscription {
+	return pool.scope.Track(pool.replaceFeed.Subscribe(ch))
+}
+
+// Replacement returns the hash of the transaction that replaced the given one
+// in the pool, if it was replaced recently.
+func (pool *TxPool) Replacement(hash common.Hash) (common.Hash, bool) {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	r, ok := pool.replaced[hash]
+	return r.hash, ok
+}
+
 // SetGasPrice updates the minimum priced required by the transaction pool for a
 // new transaction, and drops all transactions below this threshold.
 func (pool *TxPool) SetGasPrice(price *big.Int) {
@@ -601,6 +716,14 @@ func (pool *TxPool) SetGasPrice(price *big.Int) {
 	log.Info("Transaction pool priced threshold updated", "priced", pool.gasPrice)
 }
 
+// GasPrice returns the current gas price enforced by the transaction pool.
+func (pool *TxPool) GasPrice() *big.Int {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	return new(big.Int).Set(pool.gasPrice)
+}
+
 // State returns the virtual managed state of the transaction pool.
 func (pool *TxPool) State() *state.ManagedState {
 	pool.mu.RLock()
@@ -668,12 +791,13 @@ func (pool *TxPool) Pending() (types.Transactions, error) {
 	return pool.newPending.Flatten(), nil
 }
 
-// validateTx checks whether a transaction is valid according to the consensus
-// rules and adheres to some heuristic limits of the local node (priced and size).
-func (pool *TxPool) validateTx(tx *types.Transaction, local bool) (e error) {
+// validateStateless checks the parts of a transaction that don't depend on the
+// chain state: its size, signatures and zero-knowledge proofs. It is the costly
+// half of the validation and is safe to run without holding the pool lock.
+func (pool *TxPool) validateStateless(tx *types.Transaction, num uint64) (e error) {
 	defer func() {
 		if r := recover(); r != nil {
-			log.Error("validateTx error : ", "hash", tx.Hash().Hex(), "recover", r)
+			log.Error("validateStateless error : ", "hash", tx.Hash().Hex(), "recover", r)
 			debug.PrintStack()
 			e = errors.New(fmt.Sprintf("%v", r))
 		}
@@ -684,58 +808,135 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) (e error) {
 		return ErrOversizedData
 	}
 
-	// Ensure the transaction doesn't exceed the current block limit gas.
-	var gaslimit uint64
-	if gaslimit, e = pool.currentState.GetTxGasLimit(tx); e != nil {
-		return
-	}
-	if pool.currentMaxGas < gaslimit {
-		return ErrGasLimit
-	}
-
-	num := pool.chain.CurrentBlock().NumberU64()
+	defer statelessVerifyTimer.UpdateSince(time.Now())
 	if err := verify.VerifyWithoutState(tx.Ehash().NewRef(), tx.GetZZSTX(), num); err != nil {
 		log.Error("validateTx verify without state error", "hash", tx.Hash().Hex(), "verify stx err", err)
-		pool.faileds[tx.Hash()] = time.Now()
 		return ErrVerifyError
 	}
+	return nil
+}
+
+// verifyStateless runs validateStateless over a batch of transactions on all
+// the available cores. Transactions already known to the pool are skipped, add
+// rejects them cheaply later on.
+//
+// The proofs of the batch are still verified one by one: the superzk library
+// only exports the verification of single proofs and signatures, not the
+// pairings a batch verification would need to combine.
+func (pool *TxPool) verifyStateless(txs []*types.Transaction) []error {
+	errs := make([]error, len(txs))
+
+	pool.mu.RLock()
+	num := pool.pendingNumber()
+	tasks := make([]int, 0, len(txs))
+	for i, tx := range txs {
+		hash := tx.Hash()
+		if _, ok := pool.faileds[hash]; ok || pool.all.Get(hash) != nil {
+			continue
+		}
+		tasks = append(tasks, i)
+	}
+	pool.mu.RUnlock()
+
+	workers := runtime.GOMAXPROCS(0)
+	if len(tasks) < workers {
+		workers = len(tasks)
+	}
+	var (
+		wg     sync.WaitGroup
+		inputs = make(chan int)
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range inputs {
+				errs[index] = pool.validateStateless(txs[index], num)
+			}
+		}()
+	}
+	for _, index := range tasks {
+		inputs <- index
+	}
+	close(inputs)
+	wg.Wait()
+
+	return errs
+}
+
+// validateTx checks whether a transaction is valid against the current state
+// and adheres to some heuristic limits of the local node (priced and gas). The
+// transaction must already have passed validateStateless.
+func (pool *TxPool) validateTx(tx *types.Transaction, local bool) (e error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error("validateTx error : ", "hash", tx.Hash().Hex(), "recover", r)
+			debug.PrintStack()
+			e = errors.New(fmt.Sprintf("%v", r))
+		}
+	}()
+
+	// Ensure the fee pays for the transaction within the current block limit gas,
+	// dropping non-local transactions under our own minimal accepted gas priced
+	var minPrice *big.Int
+	if !local {
+		minPrice = pool.gasPrice
+	}
+	if _, err := ValidateFee(tx, pool.currentState, pool.currentMaxGas, minPrice); err != nil {
+		return err
+	}
+	if !tx.IsOpContract() {
+		if len(tx.Data()) > 0 {
+			return errors.New(`not create or call crontract tx playdata must be nil`)
+		}
+	}
 
+	num := pool.pendingNumber()
 	copyState := pool.currentState.CopyWithNoZState()
-	if err := pool.checkDescCmd(tx.GetZZSTX(), copyState); err != nil {
+	if err := CheckDescCmd(tx.GetZZSTX(), copyState, num); err != nil {
 		return err
 	}
 
 	state := copyState.NextZState()
+	start := time.Now()
 	err := verify.VerifyWithState(tx.GetZZSTX(), state, num)
+	statefulVerifyTimer.UpdateSince(start)
 	//err := verify.Verify(tx.GetZZSTX(), pool.currentState.Copy().GetZState())
 	if err != nil {
 		log.Error("validateTx error", "hash", tx.Hash().Hex(), "verify stx err", err)
 		pool.faileds[tx.Hash()] = time.Now()
 		return ErrVerifyError
 	}
+	return nil
+}
 
-	// Drop non-local transactions under our own minimal accepted gas priced
-	if !local && pool.gasPrice.Cmp(tx.GasPrice()) > 0 {
-		return ErrUnderpriced
+// ValidateFee checks the fee of a transaction against the given state: it must
+// be convertible to DECE and buy the intrinsic gas of the transaction, no more
+// than maxGas, at a gas price of at least minPrice unless minPrice is nil. It
+// returns the gas limit bought by the fee.
+func ValidateFee(tx *types.Transaction, statedb *state.StateDB, maxGas uint64, minPrice *big.Int) (gaslimit uint64, err error) {
+	if gaslimit, err = statedb.GetTxGasLimit(tx); err != nil {
+		return 0, err
 	}
-
-	if !tx.IsOpContract() {
-		if len(tx.Data()) > 0 {
-			return errors.New(`not create or call crontract tx playdata must be nil`)
-		}
+	if maxGas < gaslimit {
+		return 0, ErrGasLimit
+	}
+	if minPrice != nil && minPrice.Cmp(tx.GasPrice()) > 0 {
+		return 0, ErrUnderpriced
 	}
-
 	intrGas, err := IntrinsicGas(tx.Data(), tx.To() == nil)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	if gaslimit < intrGas {
-		return ErrIntrinsicGas
+		return 0, ErrIntrinsicGas
 	}
-	return nil
+	return gaslimit, nil
 }
 
-func (pool *TxPool) checkDescCmd(tx *stx.T, state *state.StateDB) (err error) {
+// CheckDescCmd checks the stake command of a transaction against the stake
+// pools of the given state, number being the one of the block including it.
+func CheckDescCmd(tx *stx.T, state *state.StateDB, number uint64) (err error) {
 	cmd := tx.Desc_Cmd
 	stakeState := stake.NewStakeState(state)
 	if cmd.BuyShare != nil {
@@ -783,7 +984,7 @@ func (pool *TxPool) checkDescCmd(tx *stx.T, state *state.StateDB) (err error) {
 			err = errors.New("pool is closed")
 			return
 		}
-		if stakePool.BlockNumber+stake.GetLockingBlockNum() > pool.chain.CurrentBlock().NumberU64() {
+		if stakePool.BlockNumber+stake.GetLockingBlockNum() > number {
 			err = errors.New("pool locking in")
 			return
 		}
@@ -812,14 +1013,42 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 		return false, fmt.Errorf("known failed transaction: %x", hash)
 	}
 
+	// If the transaction spends nils of pooled ones, it must pay for replacing them
+	conflicts := pool.conflicts(tx)
+	for _, old := range conflicts {
+		if !pool.outbids(tx, old) {
+			log.Trace("Discarding underpriced replacement transaction", "hash", hash, "old", old.Hash(), "priced", tx.GasPrice())
+			return false, ErrReplaceUnderpriced
+		}
+	}
+
 	// If the transaction fails basic validation, discard it
 	if err := pool.validateTx(tx, local); err != nil {
 		log.Trace("Discarding invalid transaction", "hash", hash, "err", err)
 		invalidTxCounter.Inc(1)
 		return false, err
 	}
-	// If the transaction pool is full, discard underpriced transactions
-	if uint64(pool.all.Count()) >= pool.config.GlobalSlots+pool.config.GlobalQueue {
+	return pool.admit(tx, local, conflicts)
+}
+
+// admit inserts a validated transaction into the queue. The pooled transactions
+// it conflicts with, which it is known to outbid, are only dropped once the new
+// one is accepted, so a rejected replacement leaves the pool untouched.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) admit(tx *types.Transaction, local bool, conflicts types.Transactions) (bool, error) {
+	hash := tx.Hash()
+
+	// Local transactions skip the price check of validateTx, but the queue
+	// refuses anything below the pool gas price all the same
+	if tx.GasPrice().Cmp(pool.gasPrice) < 0 {
+		log.Trace("Discarding underpriced transaction", "hash", hash, "priced", tx.GasPrice())
+		underpricedTxCounter.Inc(1)
+		return false, ErrUnderpriced
+	}
+	// If the transaction pool is full, discard underpriced transactions. The
+	// replaced transactions are about to leave, their slots are free already.
+	if count := pool.all.Count() - len(conflicts); uint64(count) >= pool.config.GlobalSlots+pool.config.GlobalQueue {
 		// If the new transaction is underpriced, don't accept it
 		if !local && pool.newQueue.Underpriced(tx, pool.locals) {
 			log.Trace("Discarding underpriced transaction", "hash", hash, "priced", tx.GasPrice())
@@ -827,7 +1056,7 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 			return false, ErrUnderpriced
 		}
 		// New transaction is better than our worse ones, make room for it
-		drop := pool.priced.Discard(pool.gasPrice, pool.all.Count()-int(pool.config.GlobalSlots+pool.config.GlobalQueue-1))
+		drop := pool.priced.Discard(pool.gasPrice, count-int(pool.config.GlobalSlots+pool.config.GlobalQueue-1))
 		for _, tx := range drop {
 			pool.removeTx(tx.Hash())
 			if pool.canAddPkrTx() {
@@ -841,9 +1070,27 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 	if err != nil {
 		return false, err
 	}
+	// The transaction is accepted, drop the ones it replaces
+	for _, old := range conflicts {
+		pool.removeTx(old.Hash())
+		if pool.canAddPkrTx() {
+			pool.pkrTxOuts.delPendintTxOut(*old)
+		}
+		pool.replaced[old.Hash()] = replacement{hash: hash, time: time.Now()}
+		replacedTxCounter.Inc(1)
+		log.Debug("Replaced pooled transaction", "old", old.Hash(), "new", hash, "priced", tx.GasPrice())
+
+		go pool.replaceFeed.Send(ReplacedTxEvent{Old: old, New: tx})
+	}
+	for _, in := range TxSpends(tx) {
+		pool.spends[in] = hash
+	}
 	if pool.canAddPkrTx() {
 		pool.pkrTxOuts.AddPendingTxOut(*tx)
 	}
+	pool.journalTx(tx, local)
+	admittedTxCounter.Inc(1)
+
 	log.Trace("Pooled new future transaction", "hash", hash, "from", tx.From(), "to", tx.To())
 	return flag, nil
 }
@@ -861,6 +1108,124 @@ func (pool *TxPool) enqueueTx(hash common.Hash, tx *types.Transaction) (bool, er
 	return true, nil
 }
 
+// replacement records the transaction that replaced a pooled one.
+type replacement struct {
+	hash common.Hash
+	time time.Time
+}
+
+// TxSpends returns the nils and roots consumed by the transaction. Two
+// transactions sharing any of them can never both be mined.
+func TxSpends(tx *types.Transaction) []c_type.Uint256 {
+	return stxSpends(tx.GetZZSTX())
+}
+
+// stxSpends lists the nils and roots consumed by every kind of input of the
+// transaction.
+func stxSpends(tx *stx.T) (ins []c_type.Uint256) {
+	if tx == nil {
+		return
+	}
+	for _, in := range tx.Desc_O.Ins {
+		ins = append(ins, in.Nil, in.Root)
+	}
+	for _, in := range tx.Desc_Z.Ins {
+		ins = append(ins, in.Nil)
+	}
+	for _, in := range tx.Tx1.Ins_P {
+		ins = append(ins, in.Nil, in.Root)
+	}
+	for _, in := range tx.Tx1.Ins_C {
+		ins = append(ins, in.Nil)
+	}
+	return
+}
+
+// conflicts retrieves the pooled transactions spending any of the nils or
+// roots of the given one.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) conflicts(tx *types.Transaction) (txs types.Transactions) {
+	seen := make(map[common.Hash]struct{})
+	for _, in := range TxSpends(tx) {
+		hash, ok := pool.spends[in]
+		if !ok || hash == tx.Hash() {
+			continue
+		}
+		if _, ok := seen[hash]; ok {
+			continue
+		}
+		seen[hash] = struct{}{}
+		if old := pool.all.Get(hash); old != nil {
+			txs = append(txs, old)
+		}
+	}
+	return
+}
+
+// outbids checks whether the gas price of tx is at least the configured price
+// bump above the one of the pooled transaction old.
+func (pool *TxPool) outbids(tx, old *types.Transaction) bool {
+	threshold := new(big.Int).Mul(old.GasPrice(), big.NewInt(100+int64(pool.config.PriceBump)))
+	threshold = threshold.Div(threshold, big.NewInt(100))
+
+	return tx.GasPrice().Cmp(old.GasPrice()) > 0 && tx.GasPrice().Cmp(threshold) >= 0
+}
+
+// journalTx adds the specified transaction to the local disk journal if it is
+// deemed to have been sent from a local account.
+func (pool *TxPool) journalTx(tx *types.Transaction, local bool) {
+	// Only journal if it's enabled and the transaction is local
+	if pool.journal == nil || !local {
+		return
+	}
+	if pool.localTxs.Get(tx.Hash()) != nil {
+		return
+	}
+	pool.localTxs.Add(tx)
+	if err := pool.journal.insert(tx); err != nil {
+		log.Warn("Failed to journal local transaction", "err", err)
+	}
+}
+
+// local retrieves the local transactions still waiting in the pool. The ones
+// whose nils or roots were already spent on chain, by themselves or by a
+// conflicting transaction, can never be mined anymore and are dropped.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) local() types.Transactions {
+	zst := pool.currentState.CopyWithNoZState().NextZState()
+
+	var txs, spent types.Transactions
+	pool.localTxs.Range(func(hash common.Hash, tx *types.Transaction) bool {
+		if isInsSpent(tx.GetZZSTX(), zst) {
+			spent = append(spent, tx)
+		} else {
+			txs = append(txs, tx)
+		}
+		return true
+	})
+	for _, tx := range spent {
+		log.Debug("Dropping spent local transaction", "hash", tx.Hash())
+		pool.removeTx(tx.Hash())
+		if pool.canAddPkrTx() {
+			pool.pkrTxOuts.delPendintTxOut(*tx)
+		}
+	}
+	return txs
+}
+
+// isInsSpent reports whether any of the inputs of the transaction is already
+// recorded as spent in the given zero state.
+func isInsSpent(tx *stx.T, zst *zstate.ZState) bool {
+	for _, in := range stxSpends(tx) {
+		if zst.State.HasIn(&in) {
+			return true
+		}
+	}
+	return false
+}
+
 // AddLocal enqueues a single transaction into the pool if it is valid, marking
 // the sender as a local one in the mean time, ensuring it goes around the local
 // pricing constraints.
@@ -894,9 +1259,16 @@ func (pool *TxPool) AddRemotes(txs []*types.Transaction) []error {
 
 // addTx enqueues a single transaction into the pool if it is valid.
 func (pool *TxPool) addTx(tx *types.Transaction, local bool) error {
+	// Verify the proofs before taking the lock, they dominate the admission cost
+	verr := pool.verifyStateless([]*types.Transaction{tx})[0]
+
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
 
+	if verr != nil {
+		pool.rejectStateless(tx, verr)
+		return verr
+	}
 	// Try to inject the transaction and update any state
 	_, err := pool.add(tx, local)
 	if err != nil {
@@ -908,26 +1280,45 @@ func (pool *TxPool) addTx(tx *types.Transaction, local bool) error {
 
 // addTxs attempts to queue a batch of transactions if they are valid.
 func (pool *TxPool) addTxs(txs []*types.Transaction, local bool) []error {
+	// Verify the whole batch in parallel before taking the lock, only the
+	// checks against the current state need to be serialized
+	verrs := pool.verifyStateless(txs)
+
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
 
-	return pool.addTxsLocked(txs, local)
+	return pool.addTxsLocked(txs, verrs, local)
 }
 
-// addTxsLocked attempts to queue a batch of transactions if they are valid,
-// whilst assuming the transaction pool lock is already held.
-func (pool *TxPool) addTxsLocked(txs []*types.Transaction, local bool) []error {
+// addTxsLocked attempts to queue a batch of statelessly verified transactions
+// if they are valid, whilst assuming the transaction pool lock is already held.
+func (pool *TxPool) addTxsLocked(txs []*types.Transaction, verrs []error, local bool) []error {
 	// Add the batch of transaction, tracking the accepted ones
 	errs := make([]error, len(txs))
 
-	for _, tx := range txs {
-		_, err := pool.add(tx, local)
-		errs = append(errs, err)
+	for i, tx := range txs {
+		if verrs[i] != nil {
+			pool.rejectStateless(tx, verrs[i])
+			errs[i] = verrs[i]
+			continue
+		}
+		_, errs[i] = pool.add(tx, local)
 	}
 	pool.promoteExecutables()
 	return errs
 }
 
+// rejectStateless records a transaction that failed validateStateless.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) rejectStateless(tx *types.Transaction, err error) {
+	log.Trace("Discarding invalid transaction", "hash", tx.Hash(), "err", err)
+	if err == ErrVerifyError {
+		pool.faileds[tx.Hash()] = time.Now()
+	}
+	invalidTxCounter.Inc(1)
+}
+
 // Status returns the status (unknown/pending/queued) of a batch of transactions
 // identified by their hashes.
 func (pool *TxPool) Status(hashes []common.Hash) []TxStatus {
@@ -958,11 +1349,18 @@ func (pool *TxPool) Get(hash common.Hash) *types.Transaction {
 // removeTx removes a single transaction from the queue, moving all subsequent
 // transactions back to the future queue.
 func (pool *TxPool) removeTx(hash common.Hash) {
+	pool.localTxs.Remove(hash)
+
 	// Fetch the transaction we wish to delete
 	tx := pool.all.Get(hash)
 	if tx == nil {
 		return
 	}
+	for _, in := range TxSpends(tx) {
+		if pool.spends[in] == hash {
+			delete(pool.spends, in)
+		}
+	}
 
 	pool.priced.Remove(tx)
 	delete(pool.beats, hash)
@@ -0,0 +1,498 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dece-cash/go-dece/common"
+	"github.com/dece-cash/go-dece/core/state"
+	"github.com/dece-cash/go-dece/core/types"
+	"github.com/dece-cash/go-dece/czero/c_type"
+	"github.com/dece-cash/go-dece/czero/superzk"
+	"github.com/dece-cash/go-dece/decedb"
+	"github.com/dece-cash/go-dece/event"
+	"github.com/dece-cash/go-dece/params"
+	"github.com/dece-cash/go-dece/rlp"
+	"github.com/dece-cash/go-dece/zero/localdb"
+	"github.com/dece-cash/go-dece/zero/stake"
+	"github.com/dece-cash/go-dece/zero/txs/assets"
+	"github.com/dece-cash/go-dece/zero/txs/stx"
+	"github.com/dece-cash/go-dece/zero/txs/stx/stx_v0"
+	"github.com/dece-cash/go-dece/zero/txs/stx/tx"
+	"github.com/dece-cash/go-dece/zero/txtool"
+	"github.com/dece-cash/go-dece/zero/txtool/flight"
+	"github.com/dece-cash/go-dece/zero/utils"
+)
+
+// testBlockChain is a blockChain made of an empty genesis block only.
+type testBlockChain struct {
+	db      state.Database
+	genesis *types.Block
+	feed    event.Feed
+}
+
+func newTestBlockChain() *testBlockChain {
+	return &testBlockChain{
+		db: state.NewDatabase(decedb.NewMemDatabase()),
+		genesis: types.NewBlockWithHeader(&types.Header{
+			Number:     new(big.Int),
+			Difficulty: new(big.Int),
+			Time:       big.NewInt(time.Now().Unix()),
+			GasLimit:   params.GenesisGasLimit,
+		}),
+	}
+}
+
+func (bc *testBlockChain) CurrentBlock() *types.Block { return bc.genesis }
+
+func (bc *testBlockChain) GetBlock(hash common.Hash, number uint64) *types.Block {
+	if hash == bc.genesis.Hash() {
+		return bc.genesis
+	}
+	return nil
+}
+
+func (bc *testBlockChain) StateAt(header *types.Header) (*state.StateDB, error) {
+	return state.New(bc.db, nil)
+}
+
+func (bc *testBlockChain) SubscribeChainHeadEvent(ch chan<- ChainHeadEvent) event.Subscription {
+	return bc.feed.Subscribe(ch)
+}
+
+// setupTxPool creates a pool holding at most size transactions.
+func setupTxPool(size uint64) *TxPool {
+	config := DefaultTxPoolConfig
+	config.Journal = ""
+	config.PriceLimit = 1
+	config.GlobalSlots = size / 2
+	config.GlobalQueue = size - size/2
+
+	return NewTxPool(config, params.TestChainConfig, newTestBlockChain())
+}
+
+// headBlockChain is a testBlockChain whose head can be moved along a line of
+// blocks.
+type headBlockChain struct {
+	*testBlockChain
+	mu     sync.Mutex
+	blocks []*types.Block
+}
+
+func newHeadBlockChain() *headBlockChain {
+	bc := newTestBlockChain()
+	return &headBlockChain{testBlockChain: bc, blocks: []*types.Block{bc.genesis}}
+}
+
+func (bc *headBlockChain) CurrentBlock() *types.Block {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	return bc.blocks[len(bc.blocks)-1]
+}
+
+func (bc *headBlockChain) GetBlock(hash common.Hash, number uint64) *types.Block {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	if number < uint64(len(bc.blocks)) && bc.blocks[number].Hash() == hash {
+		return bc.blocks[number]
+	}
+	return nil
+}
+
+// extend adds a block on top of the head, with its own gas limit.
+func (bc *headBlockChain) extend() *types.Block {
+	parent := bc.CurrentBlock()
+	block := types.NewBlockWithHeader(&types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number(), common.Big1),
+		Difficulty: new(big.Int),
+		Time:       new(big.Int).Add(parent.Time(), common.Big1),
+		GasLimit:   parent.GasLimit() + 1,
+	})
+	bc.mu.Lock()
+	bc.blocks = append(bc.blocks, block)
+	bc.mu.Unlock()
+	return block
+}
+
+// spendingTx creates a transaction spending the given nil as a Tx1 input, the
+// id telling apart transactions spending the same one.
+func spendingTx(id byte, price int64, in c_type.Uint256) *types.Transaction {
+	t := &stx.T{Tx1: tx.Tx{Ins_C: []tx.In_C{{Nil: in}}}}
+	t.Ehash[0] = id
+	return types.NewTxWithGTx(25000, big.NewInt(price), t)
+}
+
+func testNil(b byte) (ret c_type.Uint256) {
+	ret[0], ret[31] = b, 0xff
+	return
+}
+
+// Tests that the inputs of every kind are both listed as spent by the
+// transaction and detected once they are spent on chain.
+func TestTxSpends(t *testing.T) {
+	root, spent := testNil(1), testNil(2)
+
+	tests := []struct {
+		kind  string
+		tx    stx.T
+		spent []c_type.Uint256
+	}{
+		{"Desc_O", stx.T{Desc_O: stx_v0.Desc_O{Ins: []stx_v0.In_S{{Root: root, Nil: spent}}}}, []c_type.Uint256{spent, root}},
+		{"Desc_Z", stx.T{Desc_Z: stx_v0.Desc_Z{Ins: []stx_v0.In_Z{{Nil: spent}}}}, []c_type.Uint256{spent}},
+		{"Tx1.Ins_P", stx.T{Tx1: tx.Tx{Ins_P: []tx.In_P{{Root: root, Nil: spent}}}}, []c_type.Uint256{spent, root}},
+		{"Tx1.Ins_C", stx.T{Tx1: tx.Tx{Ins_C: []tx.In_C{{Nil: spent}}}}, []c_type.Uint256{spent}},
+	}
+	for _, test := range tests {
+		spends := TxSpends(types.NewTxWithGTx(25000, big.NewInt(1), &test.tx))
+		if len(spends) != len(test.spent) {
+			t.Errorf("%s: spends %d inputs, want %d", test.kind, len(spends), len(test.spent))
+			continue
+		}
+		for i := range spends {
+			if spends[i] != test.spent[i] {
+				t.Errorf("%s: input %d is %x, want %x", test.kind, i, spends[i], test.spent[i])
+			}
+		}
+
+		// Spend the nil on chain through another transaction
+		statedb, _ := state.New(state.NewDatabase(decedb.NewMemDatabase()), nil)
+		zst := statedb.NextZState()
+		if isInsSpent(&test.tx, zst) {
+			t.Errorf("%s: inputs spent before the nil is", test.kind)
+		}
+		if err := zst.State.AddStx(&stx.T{Tx1: tx.Tx{Ins_C: []tx.In_C{{Nil: spent}}}}); err != nil {
+			t.Fatalf("%s: failed to spend the nil: %v", test.kind, err)
+		}
+		if !isInsSpent(&test.tx, zst) {
+			t.Errorf("%s: inputs not spent after the nil is", test.kind)
+		}
+	}
+}
+
+// Tests that a transaction paying the price bump replaces the pooled one
+// spending the same nil.
+func TestTxPoolReplacement(t *testing.T) {
+	pool := setupTxPool(16)
+	defer pool.Stop()
+
+	old, tx := spendingTx(1, 100, testNil(1)), spendingTx(2, 110, testNil(1))
+	if _, err := pool.admit(old, false, nil); err != nil {
+		t.Fatalf("failed to pool the transaction: %v", err)
+	}
+	conflicts := pool.conflicts(tx)
+	if len(conflicts) != 1 || conflicts[0].Hash() != old.Hash() {
+		t.Fatalf("conflicts mismatch: have %v, want %x", conflicts, old.Hash())
+	}
+	if !pool.outbids(tx, old) {
+		t.Fatalf("transaction paying the price bump doesn't outbid")
+	}
+	if _, err := pool.admit(tx, false, conflicts); err != nil {
+		t.Fatalf("failed to pool the replacement: %v", err)
+	}
+	if pool.all.Get(old.Hash()) != nil {
+		t.Errorf("replaced transaction still pooled")
+	}
+	if pool.all.Get(tx.Hash()) == nil {
+		t.Errorf("replacement not pooled")
+	}
+	if hash := pool.spends[testNil(1)]; hash != tx.Hash() {
+		t.Errorf("nil spent by %x, want %x", hash, tx.Hash())
+	}
+	if r, ok := pool.replaced[old.Hash()]; !ok || r.hash != tx.Hash() {
+		t.Errorf("replacement not recorded")
+	}
+}
+
+// Tests that a replacement not paying the price bump is rejected and leaves the
+// pooled transaction in place.
+func TestTxPoolReplacementUnderpriced(t *testing.T) {
+	pool := setupTxPool(16)
+	defer pool.Stop()
+
+	old := spendingTx(1, 100, testNil(1))
+	if _, err := pool.admit(old, false, nil); err != nil {
+		t.Fatalf("failed to pool the transaction: %v", err)
+	}
+	for _, price := range []int64{90, 100, 109} {
+		if _, err := pool.add(spendingTx(2, price, testNil(1)), false); err != ErrReplaceUnderpriced {
+			t.Errorf("price %d: error mismatch: have %v, want %v", price, err, ErrReplaceUnderpriced)
+		}
+	}
+	// A local replacement outbidding the pooled one but below the pool gas
+	// price, raised in the mean time, is refused by the queue
+	pool.gasPrice = big.NewInt(200)
+	tx := spendingTx(3, 110, testNil(1))
+	if _, err := pool.admit(tx, true, pool.conflicts(tx)); err != ErrUnderpriced {
+		t.Errorf("error mismatch: have %v, want %v", err, ErrUnderpriced)
+	}
+	if pool.all.Get(old.Hash()) == nil {
+		t.Errorf("pooled transaction dropped by rejected replacements")
+	}
+	if hash := pool.spends[testNil(1)]; hash != old.Hash() {
+		t.Errorf("nil spent by %x, want %x", hash, old.Hash())
+	}
+	if len(pool.replaced) != 0 {
+		t.Errorf("rejected replacements recorded")
+	}
+}
+
+// Tests that a full pool still takes replacements, the slots of the replaced
+// transactions being reused, while rejecting underpriced newcomers untouched.
+func TestTxPoolFullReplacement(t *testing.T) {
+	pool := setupTxPool(4)
+	defer pool.Stop()
+
+	var pooled []*types.Transaction
+	for i := byte(1); i <= 4; i++ {
+		tx := spendingTx(i, 100, testNil(i))
+		if _, err := pool.admit(tx, false, nil); err != nil {
+			t.Fatalf("failed to pool transaction %d: %v", i, err)
+		}
+		pooled = append(pooled, tx)
+	}
+
+	// A newcomer no better than the pooled ones is rejected
+	if _, err := pool.admit(spendingTx(5, 100, testNil(5)), false, nil); err != ErrUnderpriced {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrUnderpriced)
+	}
+	// A replacement takes the slot of the transaction it replaces
+	tx := spendingTx(6, 110, testNil(1))
+	if _, err := pool.admit(tx, false, pool.conflicts(tx)); err != nil {
+		t.Fatalf("failed to pool the replacement: %v", err)
+	}
+	if count := pool.all.Count(); count != 4 {
+		t.Errorf("pooled transactions mismatch: have %d, want 4", count)
+	}
+	if pool.all.Get(pooled[0].Hash()) != nil {
+		t.Errorf("replaced transaction still pooled")
+	}
+	for i, tx := range pooled[1:] {
+		if pool.all.Get(tx.Hash()) == nil {
+			t.Errorf("transaction %d dropped by the replacement", i+2)
+		}
+	}
+}
+
+// Tests that the transactions are validated as part of the block following the
+// head, as the block import does: a pool unlocked in that block can be closed.
+func TestTxPoolValidationNumber(t *testing.T) {
+	var (
+		registered = uint64(100)
+		unlocked   = registered + stake.GetLockingBlockNum()
+		owner      = c_type.PKr{1}
+	)
+	for _, tt := range []struct {
+		head   uint64
+		locked bool
+	}{
+		{head: unlocked - 2, locked: true},
+		{head: unlocked - 1, locked: false},
+	} {
+		chain := newTestBlockChain()
+		chain.genesis = types.NewBlockWithHeader(&types.Header{
+			Number:     new(big.Int).SetUint64(tt.head),
+			Difficulty: new(big.Int),
+			Time:       big.NewInt(time.Now().Unix()),
+			GasLimit:   params.GenesisGasLimit,
+		})
+		config := DefaultTxPoolConfig
+		config.Journal = ""
+		pool := NewTxPool(config, params.TestChainConfig, chain)
+
+		pool.mu.Lock()
+		if num := pool.pendingNumber(); num != tt.head+1 {
+			t.Errorf("head %d: pending number mismatch: have %d, want %d", tt.head, num, tt.head+1)
+		}
+		stake.NewStakeState(pool.currentState).AddStakePool(&stake.StakePool{PKr: owner, Amount: new(big.Int), BlockNumber: registered})
+		close := &stx.T{From: owner}
+		close.Desc_Cmd.ClosePool = &stx.ClosePoolCmd{}
+		err := CheckDescCmd(close, pool.currentState.CopyWithNoZState(), pool.pendingNumber())
+		pool.mu.Unlock()
+		pool.Stop()
+
+		if locked := err != nil && err.Error() == "pool locking in"; locked != tt.locked {
+			t.Errorf("head %d: locked mismatch: have %v (%v), want %v", tt.head, locked, err, tt.locked)
+		}
+	}
+}
+
+// signedSpends funds a key with count outputs in the state of the pool and
+// returns the transactions spending each of them, signed by the key.
+func signedSpends(t *testing.T, pool *TxPool, count int) []*types.Transaction {
+	superzk.ZeroInit_NoCircuit()
+
+	seed := c_type.Uint256{1}
+	sk := superzk.Seed2Sk(&seed)
+	tk, _ := superzk.Sk2Tk(&sk)
+	pk, _ := superzk.Tk2Pk(&tk)
+	pkr := superzk.Pk2PKr(&pk, &c_type.Uint256{1})
+
+	var (
+		value = big.NewInt(1000000000000000)
+		fee   = new(big.Int).Mul(big.NewInt(25000), big.NewInt(params.Gta))
+		dece  = utils.CurrencyToUint256("DECE")
+	)
+	zst := pool.currentState.NextZState()
+	roots := make([]c_type.Uint256, count)
+	for i := range roots {
+		out := &tx.Out_P{PKr: pkr, Asset: assets.Asset{Tkn: &assets.Token{Currency: dece, Value: utils.U256(*value)}}}
+		roots[i] = zst.State.AddOut_P(out, &c_type.Uint256{byte(i + 1)})
+	}
+	zst.Update()
+	zst.RecordBlock(pool.currentState.Database().TrieDB().WDiskDB(), &c_type.Uint256{})
+
+	txs := make([]*types.Transaction, 0, count)
+	for _, root := range roots {
+		param := &txtool.GTxParam{
+			Gas:      25000,
+			GasPrice: big.NewInt(params.Gta),
+			Fee:      assets.Token{Currency: dece, Value: utils.U256(*fee)},
+			From:     txtool.Kr{PKr: pkr},
+			Ins:      []txtool.GIn{{Out: txtool.Out{Root: root, State: localdb.RootState{OS: *zst.State.GetOut(&root)}}}},
+			Outs: []txtool.GOut{{PKr: pkr, Asset: assets.Asset{Tkn: &assets.Token{
+				Currency: dece,
+				Value:    utils.U256(*new(big.Int).Sub(value, fee)),
+			}}}},
+			Z: new(bool),
+		}
+		gtx, err := flight.SignTx(&sk, param)
+		if err != nil {
+			t.Fatalf("failed to sign the spend of %x: %v", root, err)
+		}
+		txs = append(txs, types.NewTxWithGTx(param.Gas, param.GasPrice, &gtx.Tx))
+	}
+	return txs
+}
+
+// Tests that batches of remote transactions added concurrently admit the ones
+// carrying valid signatures and reject the forged ones, which are remembered as
+// failed without touching the pool.
+func TestTxPoolConcurrentAddRemotes(t *testing.T) {
+	pool := setupTxPool(64)
+	defer pool.Stop()
+
+	const batches = 8
+	valid := signedSpends(t, pool, batches)
+	forged := make([]*types.Transaction, batches)
+	for i, tx := range valid {
+		enc, _ := rlp.EncodeToBytes(tx)
+		forged[i] = new(types.Transaction)
+		if err := rlp.DecodeBytes(enc, forged[i]); err != nil {
+			t.Fatalf("failed to copy transaction %d: %v", i, err)
+		}
+		forged[i].GetZZSTX().Sign[0] ^= 0xff
+	}
+
+	var (
+		wg   sync.WaitGroup
+		errs = make([][]error, batches)
+	)
+	for i := 0; i < batches; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = pool.AddRemotes([]*types.Transaction{valid[i], forged[i]})
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < batches; i++ {
+		if errs[i][0] != nil {
+			t.Errorf("batch %d: valid transaction rejected: %v", i, errs[i][0])
+		}
+		if pool.all.Get(valid[i].Hash()) == nil {
+			t.Errorf("batch %d: valid transaction not pooled", i)
+		}
+		if errs[i][1] != ErrVerifyError {
+			t.Errorf("batch %d: forged transaction error mismatch: have %v, want %v", i, errs[i][1], ErrVerifyError)
+		}
+		if pool.all.Get(forged[i].Hash()) != nil {
+			t.Errorf("batch %d: forged transaction pooled", i)
+		}
+		if _, ok := pool.faileds[forged[i].Hash()]; !ok {
+			t.Errorf("batch %d: forged transaction not recorded as failed", i)
+		}
+	}
+	if count := pool.all.Count(); count != batches {
+		t.Errorf("pooled transactions mismatch: have %d, want %d", count, batches)
+	}
+	// Resubmitting the forged transactions is rejected without verifying again
+	for i, err := range pool.AddRemotes(forged) {
+		if err == nil || err == ErrVerifyError {
+			t.Errorf("forged transaction %d: resubmission error mismatch: have %v", i, err)
+		}
+	}
+}
+
+// Tests that Sync resets the pool on the current head of the chain at once, and
+// that the event of a head the pool already caught up with is skipped.
+func TestTxPoolSync(t *testing.T) {
+	chain := newHeadBlockChain()
+	config := DefaultTxPoolConfig
+	config.Journal = ""
+	pool := NewTxPool(config, params.TestChainConfig, chain)
+	defer pool.Stop()
+
+	poolHead := func() (*types.Header, *state.StateDB) {
+		pool.mu.RLock()
+		defer pool.mu.RUnlock()
+		return pool.head, pool.currentState
+	}
+	if head, _ := poolHead(); head.Hash() != chain.genesis.Hash() {
+		t.Fatalf("initial head mismatch: have #%d, want the genesis", head.Number)
+	}
+
+	// The pool follows a new head on Sync, without waiting for its event
+	block := chain.extend()
+	pool.Sync()
+	head, statedb := poolHead()
+	if head.Hash() != block.Hash() {
+		t.Fatalf("synced head mismatch: have #%d, want #%d", head.Number, block.Number())
+	}
+	if number := pool.pendingNumber(); number != block.NumberU64()+1 {
+		t.Errorf("pending number mismatch: have %d, want %d", number, block.NumberU64()+1)
+	}
+	if pool.currentMaxGas != block.GasLimit() {
+		t.Errorf("max gas mismatch: have %d, want %d", pool.currentMaxGas, block.GasLimit())
+	}
+
+	// Syncing again, or being notified of the same head, keeps the pool state
+	pool.Sync()
+	chain.feed.Send(ChainHeadEvent{Block: block})
+	pool.Sync()
+	if _, current := poolHead(); current != statedb {
+		t.Errorf("pool reset again on the head it was synced on")
+	}
+
+	// Without Sync, the event of a new head resets the pool in the background
+	block = chain.extend()
+	chain.feed.Send(ChainHeadEvent{Block: block})
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		if head, _ := poolHead(); head.Hash() == block.Hash() {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("pool not reset on the new head #%d", block.Number())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
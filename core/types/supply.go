@@ -0,0 +1,94 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// CurrencySupply is the amount of a currency minted and burned by the chain.
+type CurrencySupply struct {
+	Currency string
+	Minted   *big.Int
+	Burned   *big.Int
+}
+
+// Total returns the amount of the currency in existence.
+func (s *CurrencySupply) Total() *big.Int {
+	return new(big.Int).Sub(s.Minted, s.Burned)
+}
+
+func (s *CurrencySupply) String() string {
+	return fmt.Sprintf("%s: minted %v, burned %v", s.Currency, s.Minted, s.Burned)
+}
+
+// Supply is the ledger of the currencies issued by the chain from its genesis
+// up to a block, sorted by currency.
+type Supply []*CurrencySupply
+
+// Get returns the supply of the given currency, nil if it was never minted.
+func (s Supply) Get(currency string) *CurrencySupply {
+	i := sort.Search(len(s), func(i int) bool { return s[i].Currency >= currency })
+	if i < len(s) && s[i].Currency == currency {
+		return s[i]
+	}
+	return nil
+}
+
+// Apply returns the supply after a block minting and burning the given amounts
+// of currencies, leaving s untouched.
+func (s Supply) Apply(minted, burned map[string]*big.Int) Supply {
+	ledger := make(map[string]*CurrencySupply, len(s)+len(minted))
+	for _, cs := range s {
+		ledger[cs.Currency] = &CurrencySupply{cs.Currency, new(big.Int).Set(cs.Minted), new(big.Int).Set(cs.Burned)}
+	}
+	get := func(currency string) *CurrencySupply {
+		if ledger[currency] == nil {
+			ledger[currency] = &CurrencySupply{currency, new(big.Int), new(big.Int)}
+		}
+		return ledger[currency]
+	}
+	for currency, amount := range minted {
+		cs := get(currency)
+		cs.Minted.Add(cs.Minted, amount)
+	}
+	for currency, amount := range burned {
+		cs := get(currency)
+		cs.Burned.Add(cs.Burned, amount)
+	}
+	supply := make(Supply, 0, len(ledger))
+	for _, cs := range ledger {
+		supply = append(supply, cs)
+	}
+	sort.Slice(supply, func(i, j int) bool { return supply[i].Currency < supply[j].Currency })
+	return supply
+}
+
+// Equal reports whether both ledgers hold the same amounts.
+func (s Supply) Equal(other Supply) bool {
+	if len(s) != len(other) {
+		return false
+	}
+	for i := range s {
+		if s[i].Currency != other[i].Currency || s[i].Minted.Cmp(other[i].Minted) != 0 || s[i].Burned.Cmp(other[i].Burned) != 0 {
+			return false
+		}
+	}
+	return true
+}
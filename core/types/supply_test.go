@@ -0,0 +1,60 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/dece-cash/go-dece/rlp"
+)
+
+func TestSupplyApply(t *testing.T) {
+	var supply Supply
+	supply = supply.Apply(map[string]*big.Int{"DECE": big.NewInt(100)}, nil)
+	next := supply.Apply(
+		map[string]*big.Int{"DECE": big.NewInt(24), "TOKEN": big.NewInt(7)},
+		map[string]*big.Int{"DECE": big.NewInt(4)},
+	)
+	if total := supply.Get("DECE").Total(); total.Cmp(big.NewInt(100)) != 0 {
+		t.Errorf("parent supply modified: have %v, want 100", total)
+	}
+	if len(next) != 2 || next[0].Currency != "DECE" || next[1].Currency != "TOKEN" {
+		t.Fatalf("currencies mismatch: %v", next)
+	}
+	if total := next.Get("DECE").Total(); total.Cmp(big.NewInt(120)) != 0 {
+		t.Errorf("DECE supply mismatch: have %v, want 120", total)
+	}
+	if minted := next.Get("TOKEN").Minted; minted.Cmp(big.NewInt(7)) != 0 {
+		t.Errorf("TOKEN minted mismatch: have %v, want 7", minted)
+	}
+	if next.Get("OTHER") != nil {
+		t.Errorf("unknown currency found")
+	}
+
+	enc, err := rlp.EncodeToBytes(next)
+	if err != nil {
+		t.Fatalf("failed to encode supply: %v", err)
+	}
+	var dec Supply
+	if err := rlp.DecodeBytes(enc, &dec); err != nil {
+		t.Fatalf("failed to decode supply: %v", err)
+	}
+	if !dec.Equal(next) {
+		t.Errorf("supply mismatch after RLP round trip: have %v, want %v", dec, next)
+	}
+}
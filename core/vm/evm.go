@@ -149,6 +149,16 @@ func (evm *EVM) Interpreter() Interpreter {
 	return evm.interpreter
 }
 
+// assetTracer returns the tracer of the nested calls and asset operations, if
+// the configured tracer wants them.
+func (evm *EVM) assetTracer() AssetTracer {
+	if !evm.vmConfig.Debug {
+		return nil
+	}
+	tracer, _ := evm.vmConfig.Tracer.(AssetTracer)
+	return tracer
+}
+
 // Call executes the contract associated with the addr with the given input as
 // parameters. It also handles any necessary value transfer required and takes
 // the necessary steps to create accounts and reverses the state in case of an
@@ -193,6 +203,12 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 		defer func() { // Lazy evaluation of the parameters
 			evm.vmConfig.Tracer.CaptureEnd(ret, gas-contract.Gas, time.Since(start), err)
 		}()
+	} else if tracer := evm.assetTracer(); tracer != nil {
+		tracer.CaptureEnter(CALL, caller.Address(), addr, input, gas, asset)
+
+		defer func() {
+			tracer.CaptureExit(ret, gas-contract.Gas, err)
+		}()
 	}
 	ret, err = run(evm, contract, input)
 
@@ -244,6 +260,13 @@ func (evm *EVM) CallCode(caller ContractRef, addr common.Address, input []byte,
 		return ret, leftOverGas, err
 	}
 
+	if tracer := evm.assetTracer(); tracer != nil {
+		tracer.CaptureEnter(CALLCODE, caller.Address(), addr, input, gas, asset)
+
+		defer func() {
+			tracer.CaptureExit(ret, gas-contract.Gas, err)
+		}()
+	}
 	ret, err = run(evm, contract, input)
 	if err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
@@ -282,6 +305,13 @@ func (evm *EVM) DelegateCall(caller ContractRef, addr common.Address, input []by
 		return ret, leftOverGas, err
 	}
 
+	if tracer := evm.assetTracer(); tracer != nil {
+		tracer.CaptureEnter(DELEGATECALL, caller.Address(), addr, input, gas, nil)
+
+		defer func() {
+			tracer.CaptureExit(ret, gas-contract.Gas, err)
+		}()
+	}
 	ret, err = run(evm, contract, input)
 	if err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
@@ -327,6 +357,14 @@ func (evm *EVM) StaticCall(caller ContractRef, addr common.Address, input []byte
 		return ret, leftOverGas, err
 	}
 
+	if tracer := evm.assetTracer(); tracer != nil {
+		tracer.CaptureEnter(STATICCALL, caller.Address(), addr, input, gas, nil)
+
+		defer func() {
+			tracer.CaptureExit(ret, gas-contract.Gas, err)
+		}()
+	}
+
 	// When an error was returned by the EVM or when setting the creation code
 	// above we revert to the snapshot and consume any gas remaining. Additionally
 	// when we're in Homestead this also counts for code storage gas errors.
@@ -399,6 +437,8 @@ func (evm *EVM) create(caller ContractRef, code []byte, gas uint64, asset *asset
 
 	if evm.vmConfig.Debug && evm.depth == 0 {
 		evm.vmConfig.Tracer.CaptureStart(caller.Address(), address, true, code, gas, asset)
+	} else if tracer := evm.assetTracer(); tracer != nil {
+		tracer.CaptureEnter(CREATE, caller.Address(), address, code, gas, asset)
 	}
 	start := time.Now()
 
@@ -434,6 +474,8 @@ func (evm *EVM) create(caller ContractRef, code []byte, gas uint64, asset *asset
 	}
 	if evm.vmConfig.Debug && evm.depth == 0 {
 		evm.vmConfig.Tracer.CaptureEnd(ret, gas-contract.Gas, time.Since(start), err)
+	} else if tracer := evm.assetTracer(); tracer != nil {
+		tracer.CaptureExit(ret, gas-contract.Gas, err)
 	}
 	return ret, address, contract.Gas, err
 
@@ -915,6 +915,9 @@ func handleAllotTicket(d []byte, evm *EVM, contract *Contract, mem []byte) (comm
 		}
 
 		evm.StateDB.AddTicket(contract.Address(), categoryName, value)
+		if tracer := evm.assetTracer(); tracer != nil {
+			tracer.CaptureAllotTicket(contract.Address(), categoryName, value)
+		}
 	}
 
 	toAddr := evm.StateDB.GetNonceAddress(d[44:64])
@@ -989,9 +992,10 @@ func handleIssueToken(d []byte, evm *EVM, contract *Contract, mem []byte) (bool,
 	if strings.Contains(coinName, "DECE") {
 		return false, fmt.Errorf("issueToken error , contract : %s, error : %s", contract.Address(), "coinName can not contains DECE")
 	}
+	var fee *big.Int
 	address := evm.StateDB.GetContrctAddressByToken(coinName)
 	if address == (common.Address{}) {
-		fee := new(big.Int).Set(level6)
+		fee = new(big.Int).Set(level6)
 		if evm.chainConfig.ChainID.Uint64() == 2019 {
 			fee = tokenFee(coinName)
 		}
@@ -1020,6 +1024,10 @@ func handleIssueToken(d []byte, evm *EVM, contract *Contract, mem []byte) (bool,
 
 	total := new(big.Int).SetBytes(d[32:64])
 	evm.StateDB.AddBalance(contract.Address(), coinName, total)
+	evm.StateDB.AddIssued(coinName, total)
+	if tracer := evm.assetTracer(); tracer != nil {
+		tracer.CaptureIssueToken(contract.Address(), coinName, total, fee)
+	}
 	return true, nil
 }
 
@@ -1208,6 +1216,9 @@ func makeLog(size int) executionFunc {
 			if err != nil {
 				memory.Set(mStart.Uint64(), 256, make([]byte, 256))
 			} else {
+				if tracer := interpreter.evm.assetTracer(); tracer != nil {
+					tracer.CaptureClosePkg(contract.Address(), id, pkg.O.Asset)
+				}
 				if pkg.O.Asset.Tkn != nil {
 					currency := common.BytesToString(pkg.O.Asset.Tkn.Currency[:])
 					amount := pkg.O.Asset.Tkn.Value.ToIntRef()
@@ -1248,6 +1259,9 @@ func makeLog(size int) executionFunc {
 			if err := interpreter.evm.StateDB.NextZState().Pkgs.Transfer(&id, contract.Address().ToPKr(), toAddr.ToPKr()); err != nil {
 				memory.Set(mStart.Uint64()+length-32, 32, hashFalse)
 			} else {
+				if tracer := interpreter.evm.assetTracer(); tracer != nil {
+					tracer.CaptureTransferPkg(contract.Address(), toAddr, id)
+				}
 				memory.Set(mStart.Uint64()+length-32, 32, hashTrue)
 			}
 			contract.Gas += interpreter.evm.callGasTemp
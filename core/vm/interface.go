@@ -36,6 +36,9 @@ type StateDB interface {
 	GetBalance(common.Address, string) *big.Int
 	Balances(addr common.Address) map[string]*big.Int
 
+	AddIssued(string, *big.Int)
+	AddBurned(string, *big.Int)
+
 	RegisterTicket(common.Address, string) bool
 	GetContrctAddressByTicket(key string) common.Address
 
@@ -23,6 +23,7 @@ import (
 	"math/big"
 	"time"
 
+	"github.com/dece-cash/go-dece/czero/c_type"
 	"github.com/dece-cash/go-dece/zero/txs/assets"
 
 	"github.com/dece-cash/go-dece/common"
@@ -105,6 +106,19 @@ type Tracer interface {
 	CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) error
 }
 
+// AssetTracer is an optional extension of Tracer. A tracer implementing it is
+// also notified of the nested calls, with the asset they carry, and of the
+// asset operations done by the contracts through the special log topics.
+type AssetTracer interface {
+	Tracer
+	CaptureEnter(typ OpCode, from common.Address, to common.Address, input []byte, gas uint64, asset *assets.Asset)
+	CaptureExit(output []byte, gasUsed uint64, err error)
+	CaptureIssueToken(contract common.Address, currency string, amount *big.Int, fee *big.Int)
+	CaptureAllotTicket(contract common.Address, category string, value common.Hash)
+	CaptureClosePkg(contract common.Address, id c_type.Uint256, asset assets.Asset)
+	CaptureTransferPkg(contract common.Address, to common.Address, id c_type.Uint256)
+}
+
 // StructLogger is an EVM state logger and implements Tracer.
 //
 // StructLogger can capture state based on the given Log configuration and also keeps
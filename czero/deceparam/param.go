@@ -1,51 +1,71 @@
 package deceparam
 
-func SIP1() uint64 {
-	if is_dev {
-		return 0
-	} else {
-		return uint64(140000) // for miner rewards
+import (
+	"math"
+	"math/big"
+	"sync"
+)
+
+var (
+	sipsLock sync.RWMutex
+	sips     = make(map[int]uint64)
+)
+
+// InitSIPs schedules the SIP forks from SIP1 at the blocks configured in the
+// genesis of a chain, the nil ones keeping their block on the main network.
+// The forks of a previously opened chain are all dropped.
+func InitSIPs(blocks []*big.Int) {
+	configured := make(map[int]uint64)
+	for i, num := range blocks {
+		if num != nil {
+			configured[i+1] = num.Uint64()
+		}
 	}
+	sipsLock.Lock()
+	sips = configured
+	sipsLock.Unlock()
 }
 
-func SIP2() uint64 {
+func sip(n int, num uint64) uint64 {
+	sipsLock.RLock()
+	configured, ok := sips[n]
+	sipsLock.RUnlock()
+	if ok {
+		return configured
+	}
 	if is_dev {
 		return 0
 	} else {
-		return uint64(200800) // for miner rewards
+		return num
 	}
 }
 
+func SIP1() uint64 {
+	return sip(1, 140000) // for miner rewards
+}
+
+func SIP2() uint64 {
+	return sip(2, 200800) // for miner rewards
+}
+
 func SIP3() uint64 {
-	if is_dev {
-		return 0
-	} else {
-		return uint64(200970) // for miner rewards
-	}
+	return sip(3, 200970) // for miner rewards
 }
 
 func SIP4() uint64 { //WORLDSHARE booster fix
-	if is_dev {
-		return 0
-	} else {
-		return uint64(2845500) // for miner rewards
-	}
+	return sip(4, 2845500) // for miner rewards
 }
 
 func SIP5() uint64 { //WORLDSHARE booster fix
-	if is_dev {
-		return 0
-	} else {
-		return uint64(2863000) // for miner rewards
-	}
+	return sip(5, 2863000) // for miner rewards
 }
 
 func SIP6() uint64 { //WORLDSHARE booster fix
-	if is_dev {
-		return 0
-	} else {
-		return uint64(3495000) // for miner rewards
-	}
+	return sip(6, 3495000) // for miner rewards
+}
+
+func SIP7() uint64 { //package lock conditions
+	return sip(7, math.MaxUint64) // not scheduled yet
 }
 
 const MAX_O_INS_LENGTH = int(2500)
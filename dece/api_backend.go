@@ -217,6 +217,18 @@ func (b *DeceAPIBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.
 	return b.dece.TxPool().SubscribeNewTxsEvent(ch)
 }
 
+func (b *DeceAPIBackend) TxPoolReplacement(hash common.Hash) (common.Hash, bool) {
+	return b.dece.TxPool().Replacement(hash)
+}
+
+func (b *DeceAPIBackend) TxPoolGasPrice() *big.Int {
+	return b.dece.TxPool().GasPrice()
+}
+
+func (b *DeceAPIBackend) SubscribeReplacedTxEvent(ch chan<- core.ReplacedTxEvent) event.Subscription {
+	return b.dece.TxPool().SubscribeReplacedTxEvent(ch)
+}
+
 func (b *DeceAPIBackend) Downloader() *downloader.Downloader {
 	return b.dece.Downloader()
 }
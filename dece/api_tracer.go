@@ -566,6 +566,10 @@ func (api *PrivateDebugAPI) traceTx(ctx context.Context, message core.Message, v
 		err    error
 	)
 	switch {
+	case config != nil && config.Tracer != nil && isNativeTracer(*config.Tracer):
+		// Built in Go tracers run without a JavaScript engine to interrupt
+		tracer, _ = tracers.NewNative(*config.Tracer)
+
 	case config != nil && config.Tracer != nil:
 		// Define a meaningful timeout of a single transaction trace
 		timeout := defaultTraceTimeout
@@ -612,11 +616,20 @@ func (api *PrivateDebugAPI) traceTx(ctx context.Context, message core.Message, v
 	case *tracers.Tracer:
 		return tracer.GetResult()
 
+	case *tracers.AssetTracer:
+		return tracer.GetResult()
+
 	default:
 		panic(fmt.Sprintf("bad tracer type %T", tracer))
 	}
 }
 
+// isNativeTracer reports whether the named tracer is a built in Go tracer.
+func isNativeTracer(name string) bool {
+	_, ok := tracers.NewNative(name)
+	return ok
+}
+
 // computeTxEnv returns the execution environment of a certain transaction.
 func (api *PrivateDebugAPI) computeTxEnv(blockHash common.Hash, txIndex int, reexec uint64) (core.Message, vm.Context, *state.StateDB, error) {
 	// Create the parent state database
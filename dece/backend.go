@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"path/filepath"
 	"runtime"
 	"sync"
 	"sync/atomic"
@@ -34,6 +35,8 @@ import (
 	"github.com/dece-cash/go-dece/zero/zconfig"
 
 	"github.com/dece-cash/go-dece/internal/ethapi"
+	"github.com/dece-cash/go-dece/zero/txtool/prepare"
+	"github.com/dece-cash/go-dece/zero/wallet/atomicswap"
 	"github.com/dece-cash/go-dece/zero/wallet/exchange"
 
 	"github.com/dece-cash/go-dece/accounts"
@@ -122,7 +125,7 @@ func New(ctx *node.ServiceContext, config *Config) (*Dece, error) {
 	if !config.SyncMode.IsValid() {
 		return nil, fmt.Errorf("invalid sync mode %d", config.SyncMode)
 	}
-	chainDb, err := CreateDB(ctx, config, "chaindata")
+	chainDb, err := CreateDB(ctx, config, "chaindata", config.DatabaseFreezer)
 	if err != nil {
 		return nil, err
 	}
@@ -171,9 +174,9 @@ func New(ctx *node.ServiceContext, config *Config) (*Dece, error) {
 	}
 	dece.bloomIndexer.Start(dece.blockchain)
 
-	// if config.TxPool.Journal != "" {
-	//	config.TxPool.Journal = ctx.ResolvePath(config.TxPool.Journal)
-	// }
+	if config.TxPool.Journal != "" {
+		config.TxPool.Journal = ctx.ResolvePath(config.TxPool.Journal)
+	}
 
 	config.TxPool.StartLight = config.StartLight
 
@@ -199,6 +202,7 @@ func New(ctx *node.ServiceContext, config *Config) (*Dece, error) {
 	// init exchange
 	if config.StartExchange {
 		dece.exchange = exchange.NewExchange(zconfig.Exchange_dir(), dece.txPool, dece.accountManager, config.AutoMerge)
+		atomicswap.NewAtomicSwap(zconfig.AtomicSwap_dir(), dece.exchange, dece.blockchain, &prepare.DefaultTxParamState{})
 	}
 
 	if config.StartStake {
@@ -244,14 +248,26 @@ func makeExtraData(extra []byte) []byte {
 	return extra
 }
 
-// CreateDB creates the chain database.
-func CreateDB(ctx *node.ServiceContext, config *Config, name string) (decedb.Database, error) {
+// CreateDB creates the chain database, with its ancient store in freezer or
+// in the ancient directory of the database if freezer is empty.
+func CreateDB(ctx *node.ServiceContext, config *Config, name string, freezer string) (decedb.Database, error) {
 	db, err := ctx.OpenDatabase(name, config.DatabaseCache, config.DatabaseHandles)
 	if err != nil {
 		return nil, err
 	}
-	if db, ok := db.(*decedb.LDBDatabase); ok {
-		db.Meter("dece/db/chaindata/")
+	ldb, ok := db.(*decedb.LDBDatabase)
+	if !ok {
+		return db, nil
+	}
+	ldb.Meter("dece/db/chaindata/")
+	if freezer == "" {
+		freezer = filepath.Join(ldb.Path(), "ancient")
+	} else {
+		freezer = ctx.ResolvePath(freezer)
+	}
+	if db, err = rawdb.NewDatabaseWithFreezer(ldb, freezer, config.FreezerDepth); err != nil {
+		ldb.Close()
+		return nil, err
 	}
 	return db, nil
 }
@@ -269,6 +285,9 @@ func CreateConsensusEngine(ctx *node.ServiceContext, config *ethash.Config, chai
 	case ethash.ModeShared:
 		log.Warn("Ethash used in shared mode")
 		return ethash.NewShared()
+	case ethash.ModeDev:
+		log.Warn("Ethash used in developer mode", "period", config.DevPeriod)
+		return ethash.NewDeveloper(config.DevPeriod)
 	default:
 		engine := ethash.New(ethash.Config{
 			CacheDir:       ctx.ResolvePath(config.CacheDir),
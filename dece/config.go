@@ -99,6 +99,8 @@ type Config struct {
 	SkipBcVersionCheck bool `toml:"-"`
 	DatabaseHandles    int  `toml:"-"`
 	DatabaseCache      int
+	DatabaseFreezer    string
+	FreezerDepth       uint64 // Blocks deeper than this are moved to the freezer, 0 disables freezing
 	TrieCache          int
 	TrieTimeout        time.Duration
 
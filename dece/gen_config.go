@@ -32,6 +32,8 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		SkipBcVersionCheck      bool `toml:"-"`
 		DatabaseHandles         int  `toml:"-"`
 		DatabaseCache           int
+		DatabaseFreezer         string
+		FreezerDepth            uint64
 		TrieCache               int
 		TrieTimeout             time.Duration
 		MinerThreads            int           `toml:",omitempty"`
@@ -58,6 +60,8 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.SkipBcVersionCheck = c.SkipBcVersionCheck
 	enc.DatabaseHandles = c.DatabaseHandles
 	enc.DatabaseCache = c.DatabaseCache
+	enc.DatabaseFreezer = c.DatabaseFreezer
+	enc.FreezerDepth = c.FreezerDepth
 	enc.TrieCache = c.TrieCache
 	enc.TrieTimeout = c.TrieTimeout
 	enc.MinerThreads = c.MinerThreads
@@ -88,6 +92,8 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		SkipBcVersionCheck      *bool `toml:"-"`
 		DatabaseHandles         *int  `toml:"-"`
 		DatabaseCache           *int
+		DatabaseFreezer         *string
+		FreezerDepth            *uint64
 		TrieCache               *int
 		TrieTimeout             *time.Duration
 		MinerThreads            *int           `toml:",omitempty"`
@@ -143,6 +149,12 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.DatabaseCache != nil {
 		c.DatabaseCache = *dec.DatabaseCache
 	}
+	if dec.DatabaseFreezer != nil {
+		c.DatabaseFreezer = *dec.DatabaseFreezer
+	}
+	if dec.FreezerDepth != nil {
+		c.FreezerDepth = *dec.FreezerDepth
+	}
 	if dec.TrieCache != nil {
 		c.TrieCache = *dec.TrieCache
 	}
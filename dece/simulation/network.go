@@ -0,0 +1,530 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package simulation runs networks of full DECE nodes in-process on top of
+// p2p/simulations: every node runs the chain, the voter and the miner, the
+// blocks being sealed instantly by the developer engine. The nodes and their
+// accounts are derived from their names and share a genesis funding the
+// accounts, so that a scenario (pools, shares, partitions, reorgs and nodes
+// going offline) plays the same way on every run.
+package simulation
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/dece-cash/go-dece/accounts"
+	"github.com/dece-cash/go-dece/accounts/keystore"
+	"github.com/dece-cash/go-dece/common"
+	"github.com/dece-cash/go-dece/consensus/ethash"
+	"github.com/dece-cash/go-dece/core"
+	"github.com/dece-cash/go-dece/core/types"
+	"github.com/dece-cash/go-dece/crypto"
+	"github.com/dece-cash/go-dece/czero/c_type"
+	"github.com/dece-cash/go-dece/czero/deceparam"
+	"github.com/dece-cash/go-dece/czero/superzk"
+	"github.com/dece-cash/go-dece/dece"
+	"github.com/dece-cash/go-dece/node"
+	"github.com/dece-cash/go-dece/p2p/discover"
+	"github.com/dece-cash/go-dece/p2p/simulations"
+	"github.com/dece-cash/go-dece/p2p/simulations/adapters"
+	"github.com/dece-cash/go-dece/params"
+)
+
+// serviceName is the name of the DECE service of the simulation nodes.
+const serviceName = "dece"
+
+// password encrypts the accounts in the keystores of the nodes.
+const password = "simulation"
+
+// Config is the chain shared by the nodes of a simulation.
+type Config struct {
+	NetworkId uint64
+	Nodes     []string           // Names of the nodes, deriving their keys and accounts
+	Funds     *big.Int           // Balance in ta of DECE of every account at the genesis
+	Stake     params.StakeConfig // Stake economics of the chain
+	Timeout   time.Duration      // Time a wait gives up after
+}
+
+// DefaultConfig has small stake windows and cheap pools and shares. Its
+// minimal share pool size is never reached so that the miners never wait
+// for the votes of the nodes which went offline.
+var DefaultConfig = Config{
+	NetworkId: 2019,
+	Funds:     new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether)),
+	Stake: params.StakeConfig{
+		PoolValueThreshold:   big.NewInt(params.Ether),
+		LockingBlockNum:      5,
+		BasePrice:            big.NewInt(params.Ether),
+		OutOfDateWindow:      50,
+		MissVotedWindow:      55,
+		PayWindow:            5,
+		StatisticsMissWindow: 10,
+		MinSharePoolSize:     math.MaxUint32,
+	},
+	Timeout: time.Minute,
+}
+
+// Network is a simulated network of DECE nodes.
+type Network struct {
+	config  Config
+	genesis *core.Genesis
+	net     *simulations.Network
+	nodes   map[string]*Node
+
+	txLock sync.Mutex // Transactions are built on the chain of txtool.Ref_inst
+}
+
+// Node is a node of the simulated network and its account.
+type Node struct {
+	Name string
+	ID   discover.NodeID
+
+	network *Network
+	key     *ecdsa.PrivateKey // Key of the account
+	pk      c_type.Uint512
+	tk      c_type.Tk
+}
+
+// deriveKey derives the key of the given kind of a node from its name.
+func deriveKey(kind, name string) *ecdsa.PrivateKey {
+	key, err := crypto.ToECDSA(crypto.Keccak256([]byte("dece simulation " + kind + " " + name)))
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// NewNetwork creates the nodes of the network without starting them. The
+// chain runs in developer mode, the blocks confirming immediately.
+func NewNetwork(config Config) (*Network, error) {
+	if len(config.Nodes) == 0 {
+		return nil, errors.New("the simulation needs at least one node")
+	}
+	if config.Timeout == 0 {
+		config.Timeout = DefaultConfig.Timeout
+	}
+	superzk.ZeroInit_NoCircuit()
+	deceparam.Init_Dev(true)
+	deceparam.InitComfirmedBlock(0)
+
+	self := &Network{config: config, nodes: make(map[string]*Node)}
+	self.net = simulations.NewNetwork(adapters.NewSimAdapter(adapters.Services{serviceName: self.newService}), &simulations.NetworkConfig{
+		ID:             "dece",
+		DefaultService: serviceName,
+	})
+
+	stake := config.Stake
+	self.genesis = &core.Genesis{
+		Config: &params.ChainConfig{
+			ChainID:             new(big.Int).SetUint64(config.NetworkId),
+			AutumnTwilightBlock: big.NewInt(0),
+			SIP1Block:           big.NewInt(0),
+			SIP2Block:           big.NewInt(0),
+			SIP3Block:           big.NewInt(0),
+			SIP4Block:           big.NewInt(0),
+			SIP5Block:           big.NewInt(0),
+			SIP6Block:           big.NewInt(0),
+			SIP7Block:           big.NewInt(0),
+			Ethash:              new(params.EthashConfig),
+			Stake:               &stake,
+		},
+		Timestamp:  uint64(time.Now().Unix()),
+		ExtraData:  []byte("simulation"),
+		GasLimit:   params.GenesisGasLimit,
+		Difficulty: big.NewInt(1),
+		Alloc:      make(core.GenesisAlloc),
+	}
+
+	for _, name := range config.Nodes {
+		if _, ok := self.nodes[name]; ok {
+			return nil, fmt.Errorf("duplicate node %q", name)
+		}
+		nodeKey := deriveKey("node", name)
+		n := &Node{Name: name, ID: discover.PubkeyID(&nodeKey.PublicKey), network: self, key: deriveKey("account", name)}
+		tk := crypto.PrivkeyToTk(n.key)
+		n.tk = tk.ToTk()
+		n.pk = tk.ToPk().ToUint512()
+		if _, err := self.net.NewNodeWithConfig(&adapters.NodeConfig{
+			ID:         n.ID,
+			PrivateKey: nodeKey,
+			Name:       name,
+			Services:   []string{serviceName},
+		}); err != nil {
+			return nil, err
+		}
+		self.nodes[name] = n
+
+		if config.Funds != nil && config.Funds.Sign() > 0 {
+			pkr := n.MainPKr()
+			self.genesis.Alloc[common.BytesToAddress(pkr[:])] = core.GenesisAccount{Balance: new(big.Int).Set(config.Funds)}
+		}
+	}
+	return self, nil
+}
+
+// newService creates the DECE service of a node, its account being imported
+// and unlocked for the votes and the transactions.
+func (self *Network) newService(ctx *adapters.ServiceContext) (node.Service, error) {
+	n := self.byID(ctx.Config.ID)
+	if n == nil {
+		return nil, fmt.Errorf("unknown node %s", ctx.Config.ID)
+	}
+	ks := ctx.NodeContext.AccountManager.Backends(keystore.KeyStoreType)[0].(*keystore.KeyStore)
+	account, err := ks.ImportECDSA(n.key, password, 0)
+	if err != nil {
+		// The node was restarted
+		tk := crypto.PrivkeyToTk(n.key)
+		if account, err = ks.Find(accounts.Account{Address: tk.ToPk()}); err != nil {
+			return nil, err
+		}
+	}
+	if err := ks.Unlock(account, password); err != nil {
+		return nil, err
+	}
+
+	config := dece.DefaultConfig
+	config.Genesis = self.genesis
+	config.NetworkId = self.config.NetworkId
+	config.Ethash.PowMode = ethash.ModeDev
+	return dece.New(ctx.NodeContext, &config)
+}
+
+func (self *Network) byID(id discover.NodeID) *Node {
+	for _, n := range self.nodes {
+		if n.ID == id {
+			return n
+		}
+	}
+	return nil
+}
+
+// Node returns the node of the given name, nil if there is none.
+func (self *Network) Node(name string) *Node {
+	return self.nodes[name]
+}
+
+// Nodes returns the nodes in the order of the configuration.
+func (self *Network) Nodes() (nodes []*Node) {
+	for _, name := range self.config.Nodes {
+		nodes = append(nodes, self.nodes[name])
+	}
+	return
+}
+
+// Genesis returns the genesis of the chain of the network.
+func (self *Network) Genesis() *core.Genesis {
+	return self.genesis
+}
+
+// Shutdown stops all the nodes.
+func (self *Network) Shutdown() {
+	self.net.Shutdown()
+}
+
+// StartAll starts all the nodes and connects each of them to the others. The
+// first node mines a block, the nodes importing it being synchronised and
+// accepting the transactions of their peers from then on.
+func (self *Network) StartAll() error {
+	for _, n := range self.Nodes() {
+		if err := n.Start(); err != nil {
+			return err
+		}
+	}
+	if err := self.Heal(); err != nil {
+		return err
+	}
+	_, err := self.Mine(self.config.Nodes[0], 1)
+	return err
+}
+
+// Connect connects the two nodes and waits for their devp2p connection.
+func (self *Network) Connect(one, other string) error {
+	a, b, err := self.pair(one, other)
+	if err != nil {
+		return err
+	}
+	if conn := self.net.GetConn(a.ID, b.ID); conn != nil && conn.Up {
+		return nil
+	}
+	return self.waitConns(fmt.Sprintf("connection of %s and %s", one, other), func() error {
+		return self.net.Connect(a.ID, b.ID)
+	}, func() bool {
+		conn := self.net.GetConn(a.ID, b.ID)
+		return conn != nil && conn.Up
+	})
+}
+
+// Disconnect drops the connection of the two nodes, which do not dial each
+// other again.
+func (self *Network) Disconnect(one, other string) error {
+	a, b, err := self.pair(one, other)
+	if err != nil {
+		return err
+	}
+	if conn := self.net.GetConn(a.ID, b.ID); conn == nil || !conn.Up {
+		return nil
+	}
+	return self.waitConns(fmt.Sprintf("disconnection of %s and %s", one, other), func() error {
+		return self.net.Disconnect(a.ID, b.ID)
+	}, func() bool {
+		conn := self.net.GetConn(a.ID, b.ID)
+		return conn == nil || !conn.Up
+	})
+}
+
+func (self *Network) pair(one, other string) (a, b *Node, e error) {
+	if a, b = self.nodes[one], self.nodes[other]; a == nil || b == nil {
+		e = fmt.Errorf("unknown node %q or %q", one, other)
+	} else if a == b {
+		e = fmt.Errorf("node %q can not connect to itself", one)
+	}
+	return
+}
+
+// Partition splits the running nodes into the given groups: the nodes of a
+// group are connected to each other and disconnected from the other groups.
+// The running nodes in no group are isolated.
+func (self *Network) Partition(groups ...[]string) error {
+	group := make(map[string]int)
+	for i, names := range groups {
+		for _, name := range names {
+			if self.nodes[name] == nil {
+				return fmt.Errorf("unknown node %q", name)
+			}
+			group[name] = i + 1
+		}
+	}
+	nodes := self.running()
+	for i, a := range nodes {
+		for _, b := range nodes[i+1:] {
+			var err error
+			if ga, gb := group[a.Name], group[b.Name]; ga != 0 && ga == gb {
+				err = self.Connect(a.Name, b.Name)
+			} else {
+				err = self.Disconnect(a.Name, b.Name)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Isolate disconnects the node from all the others.
+func (self *Network) Isolate(name string) error {
+	for _, n := range self.running() {
+		if n.Name != name {
+			if err := self.Disconnect(name, n.Name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Heal connects all the running nodes to each other.
+func (self *Network) Heal() error {
+	var names []string
+	for _, n := range self.running() {
+		names = append(names, n.Name)
+	}
+	return self.Partition(names)
+}
+
+func (self *Network) running() (nodes []*Node) {
+	for _, n := range self.Nodes() {
+		if n.Up() {
+			nodes = append(nodes, n)
+		}
+	}
+	return
+}
+
+// waitConns runs the change of the connections and waits for the condition,
+// checked again on every connection event of the network, until the timeout
+// of the network.
+func (self *Network) waitConns(what string, change func() error, cond func() bool) error {
+	events := make(chan *simulations.Event, 16)
+	sub := self.net.Events().Subscribe(events)
+	defer sub.Unsubscribe()
+
+	if err := change(); err != nil {
+		return err
+	}
+	timeout := time.NewTimer(self.config.Timeout)
+	defer timeout.Stop()
+	for !cond() {
+		select {
+		case <-events:
+		case <-timeout.C:
+			return fmt.Errorf("timeout waiting for the %s", what)
+		}
+	}
+	return nil
+}
+
+// waitHeads waits for the condition, checked again on every new head of the
+// nodes, until the timeout of the network.
+func (self *Network) waitHeads(what string, nodes []*Node, cond func() bool) error {
+	heads := make(chan core.ChainHeadEvent, 16)
+	for _, n := range nodes {
+		sub := n.dece().BlockChain().SubscribeChainHeadEvent(heads)
+		defer sub.Unsubscribe()
+	}
+	timeout := time.NewTimer(self.config.Timeout)
+	defer timeout.Stop()
+	for !cond() {
+		select {
+		case <-heads:
+		case <-timeout.C:
+			return fmt.Errorf("timeout waiting for the %s", what)
+		}
+	}
+	return nil
+}
+
+// WaitSync waits for the running nodes to have the same head and returns it.
+func (self *Network) WaitSync() (head *types.Block, e error) {
+	nodes := self.running()
+	e = self.waitHeads("nodes to agree on the head", nodes, func() bool {
+		head = nil
+		for _, n := range nodes {
+			current := n.Head()
+			if head == nil {
+				head = current
+			} else if current.Hash() != head.Hash() {
+				return false
+			}
+		}
+		return head != nil
+	})
+	return
+}
+
+// Mine mines n blocks on the named node and waits for the connected nodes to
+// import them.
+func (self *Network) Mine(name string, n uint64) (head *types.Block, e error) {
+	miner := self.nodes[name]
+	if miner == nil {
+		return nil, fmt.Errorf("unknown node %q", name)
+	}
+	if e = miner.Mine(n); e != nil {
+		return
+	}
+	head = miner.Head()
+	for _, peer := range self.running() {
+		if conn := self.net.GetConn(miner.ID, peer.ID); conn != nil && conn.Up {
+			if e = peer.WaitBlock(head.NumberU64()); e != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// Start starts the node, an empty one joining back the network syncs the
+// chain from its peers.
+func (self *Node) Start() error {
+	return self.network.net.Start(self.ID)
+}
+
+// Stop stops the node, its pools and shares missing their votes. Its chain
+// is lost, the simulation nodes keeping it in memory.
+func (self *Node) Stop() error {
+	return self.network.net.Stop(self.ID)
+}
+
+// Up tells whether the node is running.
+func (self *Node) Up() bool {
+	n := self.network.net.GetNode(self.ID)
+	return n != nil && n.Up
+}
+
+// Dece returns the DECE service of the running node.
+func (self *Node) Dece() *dece.Dece {
+	n := self.network.net.GetNode(self.ID)
+	if n == nil || !n.Up {
+		return nil
+	}
+	service, _ := n.Node.(*adapters.SimNode).Service(serviceName).(*dece.Dece)
+	return service
+}
+
+// Head returns the head block of the node.
+func (self *Node) Head() *types.Block {
+	return self.dece().BlockChain().CurrentBlock()
+}
+
+func (self *Node) dece() *dece.Dece {
+	service := self.Dece()
+	if service == nil {
+		panic(fmt.Sprintf("node %s is not running", self.Name))
+	}
+	return service
+}
+
+// MainPKr returns the PKr the node is funded at in the genesis, which also
+// receives its rewards and votes for its shares and pool.
+func (self *Node) MainPKr() c_type.PKr {
+	return superzk.Pk2PKr(&self.pk, &c_type.Uint256{1})
+}
+
+// Mine mines n blocks on top of the head of the node, the blocks being
+// broadcast to its peers. The miner stops once the last one is its head.
+func (self *Node) Mine(n uint64) error {
+	d := self.dece()
+	target := d.BlockChain().CurrentBlock().NumberU64() + n
+	if err := d.StartMining(true); err != nil {
+		return err
+	}
+	defer d.StopMining()
+	return self.WaitBlock(target)
+}
+
+// WaitBlock waits for the head of the node to reach the block number.
+func (self *Node) WaitBlock(number uint64) error {
+	d := self.dece()
+	return self.network.waitHeads(fmt.Sprintf("block %d of %s", number, self.Name), []*Node{self}, func() bool {
+		return d.BlockChain().CurrentBlock().NumberU64() >= number
+	})
+}
+
+// WaitPending waits for the transaction to be in the pool of the node, so
+// that the blocks it mines next include it.
+func (self *Node) WaitPending(hash common.Hash) error {
+	pool := self.dece().TxPool()
+	txs := make(chan core.NewTxsEvent, 16)
+	sub := pool.SubscribeNewTxsEvent(txs)
+	defer sub.Unsubscribe()
+
+	timeout := time.NewTimer(self.network.config.Timeout)
+	defer timeout.Stop()
+	for pool.Get(hash) == nil {
+		select {
+		case <-txs:
+		case <-timeout.C:
+			return fmt.Errorf("timeout waiting for the transaction %s in the pool of %s", hash.Hex(), self.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulation
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/dece-cash/go-dece/consensus/ethash"
+	"github.com/dece-cash/go-dece/core/rawdb"
+	"github.com/dece-cash/go-dece/params"
+	"github.com/dece-cash/go-dece/zero/stake"
+)
+
+func newTestNetwork(t *testing.T, nodes ...string) *Network {
+	config := DefaultConfig
+	config.Nodes = nodes
+	net, err := NewNetwork(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := net.StartAll(); err != nil {
+		net.Shutdown()
+		t.Fatal(err)
+	}
+	return net
+}
+
+func TestNetwork(t *testing.T) {
+	if testing.Short() {
+		t.Skip("mining a block takes a second")
+	}
+	net := newTestNetwork(t, "miner", "pool", "staker")
+	defer net.Shutdown()
+	pool, staker := net.Node("pool"), net.Node("staker")
+
+	if balance := staker.Balance(); balance.Cmp(DefaultConfig.Funds) != 0 {
+		t.Fatalf("genesis balance: have %v, want %v", balance, DefaultConfig.Funds)
+	}
+
+	// The pool is registered and the staker buys shares in it
+	miner := net.Node("miner")
+	tx, err := pool.RegisterPool(2500)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := miner.WaitPending(tx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := net.Mine("miner", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := staker.WaitTx(tx); err != nil {
+		t.Fatal(err)
+	}
+	value := new(big.Int).Mul(big.NewInt(30), big.NewInt(params.Ether))
+	if tx, err = staker.BuyShares(value, pool); err != nil {
+		t.Fatal(err)
+	}
+	if err := miner.WaitPending(tx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := net.Mine("miner", 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := staker.WaitTx(tx); err != nil {
+		t.Fatal(err)
+	}
+	err = net.CheckShares("staker", func(shares []*stake.Share) error {
+		if len(shares) != 1 || shares[0].PoolId == nil || *shares[0].PoolId != pool.PoolId() {
+			return fmt.Errorf("shares: %v", shares)
+		}
+		if shares[0].InitNum < 20 {
+			return fmt.Errorf("bought %d shares, want 20 at least", shares[0].InitNum)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The pool votes for the shares chosen while it is online
+	if _, err := net.Mine("miner", 3); err != nil {
+		t.Fatal(err)
+	}
+	err = net.CheckPool("pool", func(p *stake.StakePool) error {
+		if p == nil || p.ChoicedShareNum == 0 || p.MissedVoteNum == p.ChoicedShareNum {
+			return fmt.Errorf("online pool: %+v", p)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Offline, it misses the votes of all the shares chosen once its last
+	// votes are in, a block sealed before it stopped being imported late
+	// with their parent votes
+	if err := pool.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := net.Mine("miner", 2); err != nil {
+		t.Fatal(err)
+	}
+	var choiced, missed uint32
+	net.CheckPool("pool", func(p *stake.StakePool) error {
+		choiced, missed = p.ChoicedShareNum, p.MissedVoteNum
+		return nil
+	})
+	if _, err := net.Mine("miner", 3); err != nil {
+		t.Fatal(err)
+	}
+	err = net.CheckPool("pool", func(p *stake.StakePool) error {
+		if p.ChoicedShareNum == choiced || p.MissedVoteNum-missed != p.ChoicedShareNum-choiced {
+			return fmt.Errorf("offline pool: %d choiced %d missed, before %d choiced %d missed", p.ChoicedShareNum, p.MissedVoteNum, choiced, missed)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The supply ledger holds the genesis allocations and the block rewards,
+	// the fees being minted again to the coinbases
+	head := staker.Head()
+	supply := rawdb.ReadSupply(staker.Dece().ChainDb(), head.Hash(), head.NumberU64())
+	if supply == nil {
+		t.Fatalf("no supply recorded for block %d", head.NumberU64())
+	}
+	want := new(big.Int)
+	for _, account := range net.Genesis().Alloc {
+		want.Add(want, account.Balance)
+	}
+	for number := uint64(1); number <= head.NumberU64(); number++ {
+		reward, _ := ethash.BlockRewards(new(big.Int).SetUint64(number))
+		want.Add(want, reward)
+	}
+	if dece := supply.Get("DECE"); dece == nil || dece.Total().Cmp(want) != 0 {
+		t.Fatalf("DECE supply: have %v, want %v", dece, want)
+	}
+}
+
+func TestReorg(t *testing.T) {
+	if testing.Short() {
+		t.Skip("mining a block takes a second")
+	}
+	net := newTestNetwork(t, "a", "b", "c")
+	defer net.Shutdown()
+	a, b := net.Node("a"), net.Node("b")
+
+	if err := net.Partition([]string{"a"}, []string{"b", "c"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Mine(1); err != nil {
+		t.Fatal(err)
+	}
+	// The chain of b grows two blocks longer than the one of a
+	orphan := a.Head()
+	if err := b.Mine(orphan.NumberU64() + 2 - b.Head().NumberU64()); err != nil {
+		t.Fatal(err)
+	}
+	if err := net.Heal(); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Mine(1); err != nil {
+		t.Fatal(err)
+	}
+	head, err := net.WaitSync()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.NumberU64() < orphan.NumberU64()+3 {
+		t.Fatalf("head %d, want the longer chain", head.NumberU64())
+	}
+	if block := a.dece().BlockChain().GetBlockByNumber(orphan.NumberU64()); block.Hash() == orphan.Hash() {
+		t.Fatalf("block %d of a still canonical", orphan.NumberU64())
+	}
+}
@@ -0,0 +1,334 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulation
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/dece-cash/go-dece/common"
+	"github.com/dece-cash/go-dece/core"
+	"github.com/dece-cash/go-dece/core/rawdb"
+	"github.com/dece-cash/go-dece/crypto"
+	"github.com/dece-cash/go-dece/czero/c_type"
+	"github.com/dece-cash/go-dece/czero/deceparam"
+	"github.com/dece-cash/go-dece/czero/superzk"
+	"github.com/dece-cash/go-dece/params"
+	"github.com/dece-cash/go-dece/rlp"
+	"github.com/dece-cash/go-dece/zero/localdb"
+	"github.com/dece-cash/go-dece/zero/stake"
+	"github.com/dece-cash/go-dece/zero/txs/assets"
+	"github.com/dece-cash/go-dece/zero/txs/stx"
+	"github.com/dece-cash/go-dece/zero/txtool"
+	"github.com/dece-cash/go-dece/zero/txtool/flight"
+	"github.com/dece-cash/go-dece/zero/txtool/prepare"
+	"github.com/dece-cash/go-dece/zero/utils"
+)
+
+// stakeGas is the gas of the stake transactions, as the stake API uses.
+const stakeGas = 25000
+
+// PoolPKr returns the PKr registering the pool of the node, the id of the
+// pool being its hash.
+func (self *Node) PoolPKr() c_type.PKr {
+	var rand c_type.Uint256
+	copy(rand[:], crypto.Keccak256(self.tk[:]))
+	return superzk.Pk2PKr(&self.pk, &rand)
+}
+
+// PoolId returns the id of the pool registered by the node.
+func (self *Node) PoolId() common.Hash {
+	pkr := self.PoolPKr()
+	return crypto.Keccak256Hash(pkr[:])
+}
+
+// RegisterPool sends the transaction registering the pool of the node,
+// locking the value the chain requires. The node votes for its pool.
+func (self *Node) RegisterPool(feeRate uint32) (common.Hash, error) {
+	if feeRate < deceparam.LOWEST_STAKING_NODE_FEE_RATE || feeRate > deceparam.HIGHEST_STAKING_NODE_FEE_RATE {
+		return common.Hash{}, fmt.Errorf("fee rate %v out of [%v, %v]", feeRate, deceparam.LOWEST_STAKING_NODE_FEE_RATE, deceparam.HIGHEST_STAKING_NODE_FEE_RATE)
+	}
+	pkr := self.PoolPKr()
+	return self.sendStakeTx(&pkr, prepare.Cmds{RegistPool: &stx.RegistPoolCmd{
+		Value:   utils.U256(*stake.GetPoolValueThreshold()),
+		Vote:    self.MainPKr(),
+		FeeRate: feeRate,
+	}})
+}
+
+// ClosePool sends the transaction closing the pool of the node.
+func (self *Node) ClosePool() (common.Hash, error) {
+	pkr := self.PoolPKr()
+	return self.sendStakeTx(&pkr, prepare.Cmds{ClosePool: &stx.ClosePoolCmd{}})
+}
+
+// BuyShares sends the transaction buying shares for the value in ta, in the
+// pool of the owner if not nil. The pool votes for the shares bought in it,
+// the node voting for its solo shares.
+func (self *Node) BuyShares(value *big.Int, owner *Node) (common.Hash, error) {
+	cmd := &stx.BuyShareCmd{Value: utils.U256(*value), Vote: self.MainPKr()}
+	if owner != nil {
+		id := owner.PoolId()
+		cmd.Pool = id.HashToUint256()
+		cmd.Vote = owner.MainPKr()
+	}
+	pkr := self.MainPKr()
+	return self.sendStakeTx(&pkr, prepare.Cmds{BuyShare: cmd})
+}
+
+// sendStakeTx builds the transaction on the chain of the node, signs it with
+// its account and adds it to its pool.
+func (self *Node) sendStakeTx(refundTo *c_type.PKr, cmds prepare.Cmds) (common.Hash, error) {
+	d := self.dece()
+	gasPrice := big.NewInt(params.Gta)
+	pre := prepare.PreTxParam{
+		From:     self.pk,
+		RefundTo: refundTo,
+		Fee: assets.Token{
+			Currency: utils.CurrencyToUint256(params.DefaultCurrency),
+			Value:    utils.U256(*new(big.Int).Mul(gasPrice, big.NewInt(stakeGas))),
+		},
+		GasPrice: gasPrice,
+		Cmds:     cmds,
+	}
+
+	self.network.txLock.Lock()
+	defer self.network.txLock.Unlock()
+
+	// The pool validates the transaction on the head it is built on
+	d.TxPool().Sync()
+	txtool.Ref_inst.SetBC(&core.State1BlockChain{Bc: d.BlockChain()})
+	param, err := prepare.GenTxParam(&pre, &wallet{self}, &prepare.DefaultTxParamState{})
+	if err != nil {
+		return common.Hash{}, err
+	}
+	account, err := d.AccountManager().FindAccountByPk(self.pk)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	w, err := d.AccountManager().Find(account)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	seed, err := w.GetSeed()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	sk := superzk.Seed2Sk(seed.SeedToUint256())
+	gtx, err := flight.SignTx(&sk, param)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err := d.APIBackend.CommitTx(&gtx); err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(gtx.Hash[:]), nil
+}
+
+// Balance returns the DECE in ta the node can spend at its head.
+func (self *Node) Balance() *big.Int {
+	balance := new(big.Int)
+	for _, utxo := range self.utxos() {
+		if utxo.Asset.Tkn != nil && utils.Uint256ToCurrency(&utxo.Asset.Tkn.Currency) == params.DefaultCurrency {
+			balance.Add(balance, utxo.Asset.Tkn.Value.ToIntRef())
+		}
+	}
+	return balance
+}
+
+// utxos returns the unspent outs of the account at the head of the node.
+func (self *Node) utxos() (utxos prepare.Utxos) {
+	bc := self.dece().BlockChain()
+	db := bc.GetDB()
+	var (
+		roots []c_type.Uint256
+		dels  = make(map[c_type.Uint256]bool)
+	)
+	for num := uint64(0); num <= bc.CurrentBlock().NumberU64(); num++ {
+		hash := rawdb.ReadCanonicalHash(db, num)
+		if block := localdb.GetBlock(db, num, hash.HashToUint256()); block != nil {
+			roots = append(roots, block.Roots...)
+			for _, del := range block.Dels {
+				dels[del] = true
+			}
+		}
+	}
+	for _, root := range roots {
+		rs := localdb.GetRoot(db, &root)
+		if rs == nil || !superzk.IsMyPKr(&self.tk, rs.OS.ToPKr()) {
+			continue
+		}
+		douts := flight.DecOut(&self.tk, []txtool.Out{{Root: root, State: *rs}})
+		if len(douts) == 0 || len(douts[0].Nils) == 0 || dels[douts[0].Nils[0]] {
+			continue
+		}
+		utxos = append(utxos, prepare.Utxo{Root: root, Asset: douts[0].Asset})
+	}
+	return
+}
+
+// wallet selects the outs of a node for its transactions.
+type wallet struct {
+	node *Node
+}
+
+// FindRoots implements prepare.TxParamGenerator.
+func (self *wallet) FindRoots(pk *c_type.Uint512, currency string, amount *big.Int) (roots prepare.Utxos, remain big.Int) {
+	remain.Set(amount)
+	if *pk != self.node.pk {
+		return
+	}
+	for _, utxo := range self.node.utxos() {
+		if remain.Sign() <= 0 {
+			break
+		}
+		if utxo.Asset.Tkn != nil && utils.Uint256ToCurrency(&utxo.Asset.Tkn.Currency) == currency {
+			roots = append(roots, utxo)
+			remain.Sub(&remain, utxo.Asset.Tkn.Value.ToIntRef())
+		}
+	}
+	return
+}
+
+// FindRootsByTicket implements prepare.TxParamGenerator, the stake
+// transactions spending no ticket.
+func (self *wallet) FindRootsByTicket(pk *c_type.Uint512, tickets []assets.Ticket) (roots prepare.Utxos, remain map[c_type.Uint256]c_type.Uint256) {
+	remain = make(map[c_type.Uint256]c_type.Uint256)
+	for _, ticket := range tickets {
+		remain[ticket.Value] = ticket.Category
+	}
+	return
+}
+
+// GetRoot implements prepare.TxParamGenerator.
+func (self *wallet) GetRoot(root *c_type.Uint256) *prepare.Utxo {
+	for _, utxo := range self.node.utxos() {
+		if utxo.Root == *root {
+			return &utxo
+		}
+	}
+	return nil
+}
+
+// DefaultRefundTo implements prepare.TxParamGenerator.
+func (self *wallet) DefaultRefundTo(pk *c_type.Uint512) *c_type.PKr {
+	pkr := self.node.MainPKr()
+	return &pkr
+}
+
+// StakePool returns the pool of the owner at the head of the node, nil if it
+// is not registered.
+func (self *Node) StakePool(owner *Node) *stake.StakePool {
+	state, err := self.dece().BlockChain().State()
+	if err != nil {
+		return nil
+	}
+	return stake.NewStakeState(state).GetStakePool(owner.PoolId())
+}
+
+// Shares returns the shares bought by the owner at the head of the node, in
+// the order they were bought.
+func (self *Node) Shares(owner *Node) (shares []*stake.Share) {
+	bc := self.dece().BlockChain()
+	state, err := bc.State()
+	if err != nil {
+		return nil
+	}
+	stakeState := stake.NewStakeState(state)
+	db := bc.GetDB()
+	for num := uint64(1); num <= bc.CurrentBlock().NumberU64(); num++ {
+		hash := rawdb.ReadCanonicalHash(db, num)
+		for _, share := range stake.GetSharesByBlock(db, hash, num) {
+			if share.BlockNumber != num || !superzk.IsMyPKr(&owner.tk, &share.PKr) {
+				continue
+			}
+			if current := stakeState.GetShare(common.BytesToHash(share.Id())); current != nil {
+				shares = append(shares, current)
+			}
+		}
+	}
+	return
+}
+
+// CheckPool checks the running nodes agree on the head and on the pool of
+// the owner, which passes the check. The check is given a nil pool if it is
+// not registered.
+func (self *Network) CheckPool(owner string, check func(pool *stake.StakePool) error) error {
+	o := self.nodes[owner]
+	if o == nil {
+		return fmt.Errorf("unknown node %q", owner)
+	}
+	if _, err := self.WaitSync(); err != nil {
+		return err
+	}
+	var (
+		pool *stake.StakePool
+		enc  []byte
+	)
+	for i, n := range self.running() {
+		p := n.StakePool(o)
+		blob, err := rlp.EncodeToBytes(p)
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			pool, enc = p, blob
+		} else if !bytes.Equal(blob, enc) {
+			return fmt.Errorf("nodes disagree on the pool of %s", owner)
+		}
+	}
+	return check(pool)
+}
+
+// CheckShares checks the running nodes agree on the head and on the shares
+// of the owner, which pass the check.
+func (self *Network) CheckShares(owner string, check func(shares []*stake.Share) error) error {
+	o := self.nodes[owner]
+	if o == nil {
+		return fmt.Errorf("unknown node %q", owner)
+	}
+	if _, err := self.WaitSync(); err != nil {
+		return err
+	}
+	var (
+		shares []*stake.Share
+		enc    []byte
+	)
+	for i, n := range self.running() {
+		s := n.Shares(o)
+		blob, err := rlp.EncodeToBytes(s)
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			shares, enc = s, blob
+		} else if !bytes.Equal(blob, enc) {
+			return fmt.Errorf("nodes disagree on the shares of %s", owner)
+		}
+	}
+	return check(shares)
+}
+
+// WaitTx waits for the transaction to be in the chain of the node.
+func (self *Node) WaitTx(hash common.Hash) error {
+	d := self.dece()
+	return self.network.waitHeads(fmt.Sprintf("transaction %s on %s", hash.Hex(), self.Name), []*Node{self}, func() bool {
+		tx, _, _, _ := rawdb.ReadTransaction(d.ChainDb(), hash)
+		return tx != nil
+	})
+}
@@ -0,0 +1,235 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/dece-cash/go-dece/common"
+	"github.com/dece-cash/go-dece/common/hexutil"
+	"github.com/dece-cash/go-dece/core/vm"
+	"github.com/dece-cash/go-dece/czero/c_type"
+	"github.com/dece-cash/go-dece/zero/txs/assets"
+	"github.com/dece-cash/go-dece/zero/utils"
+)
+
+// assetJSON is the readable form of an asset, the currency and the category
+// being turned back into their names.
+type assetJSON struct {
+	Currency string          `json:"currency,omitempty"`
+	Value    *utils.U256     `json:"value,omitempty"`
+	Category string          `json:"category,omitempty"`
+	Ticket   *c_type.Uint256 `json:"ticket,omitempty"`
+}
+
+func newAssetJSON(asset *assets.Asset) *assetJSON {
+	if asset == nil || (asset.Tkn == nil && asset.Tkt == nil) {
+		return nil
+	}
+	ret := &assetJSON{}
+	if asset.Tkn != nil {
+		value := asset.Tkn.Value
+		ret.Currency = common.BytesToString(asset.Tkn.Currency[:])
+		ret.Value = &value
+	}
+	if asset.Tkt != nil {
+		ticket := asset.Tkt.Value
+		ret.Category = common.BytesToString(asset.Tkt.Category[:])
+		ret.Ticket = &ticket
+	}
+	return ret
+}
+
+// assetSend is an asset sent by a frame to another address.
+type assetSend struct {
+	To common.Address `json:"to"`
+	*assetJSON
+}
+
+// tokenIssue is a currency issued by a contract, the fee being paid in DECE
+// when the currency is registered.
+type tokenIssue struct {
+	Currency string      `json:"currency"`
+	Amount   utils.U256  `json:"amount"`
+	Fee      *utils.U256 `json:"fee,omitempty"`
+}
+
+// ticketAllot is a ticket created by a contract.
+type ticketAllot struct {
+	Category string      `json:"category"`
+	Ticket   common.Hash `json:"ticket"`
+}
+
+// pkgOp is a package closed or transferred by a contract.
+type pkgOp struct {
+	Op    string          `json:"op"`
+	Id    c_type.Uint256  `json:"id"`
+	To    *common.Address `json:"to,omitempty"`
+	Asset *assetJSON      `json:"asset,omitempty"`
+}
+
+// assetFrame is a call of the traced transaction with the asset movements
+// done while it was running.
+type assetFrame struct {
+	Type     string         `json:"type"`
+	From     common.Address `json:"from"`
+	To       common.Address `json:"to"`
+	Input    hexutil.Bytes  `json:"input"`
+	Output   hexutil.Bytes  `json:"output,omitempty"`
+	Gas      hexutil.Uint64 `json:"gas"`
+	GasUsed  hexutil.Uint64 `json:"gasUsed"`
+	Error    string         `json:"error,omitempty"`
+	Received *assetJSON     `json:"received,omitempty"`
+	Sent     []assetSend    `json:"sent,omitempty"`
+	Issued   []tokenIssue   `json:"issued,omitempty"`
+	Allotted []ticketAllot  `json:"allotted,omitempty"`
+	Pkgs     []pkgOp        `json:"pkgs,omitempty"`
+	Calls    []*assetFrame  `json:"calls,omitempty"`
+}
+
+// AssetTracer is a native tracer reporting the call tree of a transaction.
+// Every frame lists the assets it received and sent, the currencies it issued,
+// the tickets it allotted and the packages it closed or transferred, sends
+// through topic_send showing up as calls without input. The operations of the
+// frames having an error were reverted.
+type AssetTracer struct {
+	callstack []*assetFrame
+}
+
+// NewAssetTracer creates a tracer of the asset movements.
+func NewAssetTracer() *AssetTracer {
+	return &AssetTracer{}
+}
+
+// CaptureStart implements the Tracer interface to initialize the root frame.
+func (t *AssetTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, asset *assets.Asset) error {
+	typ := vm.CALL
+	if create {
+		typ = vm.CREATE
+	}
+	t.CaptureEnter(typ, from, to, input, gas, asset)
+	return nil
+}
+
+// CaptureState implements the Tracer interface, single steps are not traced.
+func (t *AssetTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureFault implements the Tracer interface, the error is reported by the
+// frame ending with it.
+func (t *AssetTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureEnd implements the Tracer interface to finalize the root frame.
+func (t *AssetTracer) CaptureEnd(output []byte, gasUsed uint64, _ time.Duration, err error) error {
+	if len(t.callstack) == 1 {
+		t.exit(output, gasUsed, err)
+	}
+	return nil
+}
+
+// CaptureEnter implements the AssetTracer interface to open a nested frame.
+func (t *AssetTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, asset *assets.Asset) {
+	t.callstack = append(t.callstack, &assetFrame{
+		Type:     typ.String(),
+		From:     from,
+		To:       to,
+		Input:    common.CopyBytes(input),
+		Gas:      hexutil.Uint64(gas),
+		Received: newAssetJSON(asset),
+	})
+}
+
+// CaptureExit implements the AssetTracer interface to close a nested frame,
+// accounting its asset as sent by the caller if it succeeded.
+func (t *AssetTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	if len(t.callstack) > 1 {
+		t.exit(output, gasUsed, err)
+	}
+}
+
+func (t *AssetTracer) exit(output []byte, gasUsed uint64, err error) {
+	size := len(t.callstack)
+	frame := t.callstack[size-1]
+	frame.Output = common.CopyBytes(output)
+	frame.GasUsed = hexutil.Uint64(gasUsed)
+	if err != nil {
+		frame.Error = err.Error()
+	}
+	if size == 1 {
+		return
+	}
+	t.callstack = t.callstack[:size-1]
+	parent := t.callstack[size-2]
+	parent.Calls = append(parent.Calls, frame)
+	if err == nil && frame.Received != nil {
+		parent.Sent = append(parent.Sent, assetSend{To: frame.To, assetJSON: frame.Received})
+	}
+}
+
+// CaptureIssueToken implements the AssetTracer interface.
+func (t *AssetTracer) CaptureIssueToken(contract common.Address, currency string, amount *big.Int, fee *big.Int) {
+	if frame := t.current(); frame != nil {
+		issue := tokenIssue{Currency: currency, Amount: utils.U256(*amount)}
+		if fee != nil {
+			paid := utils.U256(*fee)
+			issue.Fee = &paid
+		}
+		frame.Issued = append(frame.Issued, issue)
+	}
+}
+
+// CaptureAllotTicket implements the AssetTracer interface.
+func (t *AssetTracer) CaptureAllotTicket(contract common.Address, category string, value common.Hash) {
+	if frame := t.current(); frame != nil {
+		frame.Allotted = append(frame.Allotted, ticketAllot{Category: category, Ticket: value})
+	}
+}
+
+// CaptureClosePkg implements the AssetTracer interface.
+func (t *AssetTracer) CaptureClosePkg(contract common.Address, id c_type.Uint256, asset assets.Asset) {
+	if frame := t.current(); frame != nil {
+		frame.Pkgs = append(frame.Pkgs, pkgOp{Op: "close", Id: id, Asset: newAssetJSON(&asset)})
+	}
+}
+
+// CaptureTransferPkg implements the AssetTracer interface.
+func (t *AssetTracer) CaptureTransferPkg(contract common.Address, to common.Address, id c_type.Uint256) {
+	if frame := t.current(); frame != nil {
+		frame.Pkgs = append(frame.Pkgs, pkgOp{Op: "transfer", Id: id, To: &to})
+	}
+}
+
+func (t *AssetTracer) current() *assetFrame {
+	if len(t.callstack) == 0 {
+		return nil
+	}
+	return t.callstack[len(t.callstack)-1]
+}
+
+// GetResult returns the root frame of the call tree encoded as JSON.
+func (t *AssetTracer) GetResult() (json.RawMessage, error) {
+	if len(t.callstack) != 1 {
+		return nil, errors.New("incorrect number of top-level calls")
+	}
+	return json.Marshal(t.callstack[0])
+}
@@ -0,0 +1,123 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/dece-cash/go-dece/common"
+	"github.com/dece-cash/go-dece/core/vm"
+	"github.com/dece-cash/go-dece/czero/c_type"
+	"github.com/dece-cash/go-dece/zero/txs/assets"
+	"github.com/dece-cash/go-dece/zero/utils"
+)
+
+// Tests that the asset tracer builds the call tree with the asset movements
+// of every frame, the asset of a failed call not being counted as sent.
+func TestAssetTracer(t *testing.T) {
+	tracer, ok := NewNative("assetTracer")
+	if !ok {
+		t.Fatalf("asset tracer not registered")
+	}
+	at := tracer.(*AssetTracer)
+
+	var (
+		from     = common.BytesToAddress([]byte{0x01})
+		contract = common.BytesToAddress([]byte{0x02})
+		callee   = common.BytesToAddress([]byte{0x03})
+		failing  = common.BytesToAddress([]byte{0x04})
+	)
+	dece := func(value uint64) *assets.Asset {
+		return &assets.Asset{Tkn: &assets.Token{Currency: utils.CurrencyToUint256("DECE"), Value: utils.NewU256(value)}}
+	}
+	at.CaptureStart(from, contract, false, []byte{0xaa}, 100000, dece(10))
+
+	at.CaptureIssueToken(contract, "COIN", big.NewInt(1000), big.NewInt(5))
+	at.CaptureAllotTicket(contract, "CARD", common.Hash{0x07})
+
+	at.CaptureEnter(vm.CALL, contract, callee, nil, 5000, dece(3))
+	at.CaptureTransferPkg(callee, failing, c_type.Uint256{0x08})
+	at.CaptureExit(nil, 2000, nil)
+
+	at.CaptureEnter(vm.CALL, contract, failing, []byte{0xbb}, 5000, dece(4))
+	at.CaptureExit(nil, 5000, errors.New("reverted"))
+
+	at.CaptureEnd([]byte{0xcc}, 30000, 0, nil)
+
+	result, err := at.GetResult()
+	if err != nil {
+		t.Fatalf("failed to get result: %v", err)
+	}
+	var root struct {
+		Type     string
+		To       common.Address
+		GasUsed  string
+		Output   string
+		Received struct {
+			Currency string
+			Value    json.Number
+		}
+		Sent []struct {
+			To       common.Address
+			Currency string
+			Value    json.Number
+		}
+		Issued []struct {
+			Currency    string
+			Amount, Fee json.Number
+		}
+		Allotted []struct{ Category string }
+		Calls    []struct {
+			To    common.Address
+			Error string
+			Pkgs  []struct {
+				Op string
+				To common.Address
+			}
+		}
+	}
+	if err := json.Unmarshal(result, &root); err != nil {
+		t.Fatalf("failed to decode result %s: %v", result, err)
+	}
+	if root.Type != "CALL" || root.To != contract || root.GasUsed != "0x7530" || root.Output != "0xcc" {
+		t.Errorf("root frame mismatch: %s", result)
+	}
+	if root.Received.Currency != "DECE" || root.Received.Value != "10" {
+		t.Errorf("received asset mismatch: %+v", root.Received)
+	}
+	if len(root.Sent) != 1 || root.Sent[0].To != callee || root.Sent[0].Value != "3" {
+		t.Errorf("sent assets mismatch: %+v", root.Sent)
+	}
+	if len(root.Issued) != 1 || root.Issued[0].Currency != "COIN" || root.Issued[0].Amount != "1000" || root.Issued[0].Fee != "5" {
+		t.Errorf("issued tokens mismatch: %+v", root.Issued)
+	}
+	if len(root.Allotted) != 1 || root.Allotted[0].Category != "CARD" {
+		t.Errorf("allotted tickets mismatch: %+v", root.Allotted)
+	}
+	if len(root.Calls) != 2 {
+		t.Fatalf("nested calls mismatch: have %d, want 2", len(root.Calls))
+	}
+	if call := root.Calls[0]; call.To != callee || len(call.Pkgs) != 1 || call.Pkgs[0].Op != "transfer" || call.Pkgs[0].To != failing {
+		t.Errorf("first call mismatch: %+v", call)
+	}
+	if call := root.Calls[1]; call.To != failing || call.Error != "reverted" {
+		t.Errorf("failed call mismatch: %+v", call)
+	}
+}
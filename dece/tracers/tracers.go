@@ -14,13 +14,14 @@
 // You should have received a copy of the GNU Lesser General Public License
 // along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
 
-// Package tracers is a collection of JavaScript transaction tracers.
+// Package tracers is a collection of JavaScript and native transaction tracers.
 package tracers
 
 import (
 	"strings"
 	"unicode"
 
+	"github.com/dece-cash/go-dece/core/vm"
 	"github.com/dece-cash/go-dece/dece/tracers/internal/tracers"
 )
 
@@ -44,6 +45,19 @@ func init() {
 	}
 }
 
+// natives contains the built in Go tracers by name.
+var natives = map[string]func() vm.Tracer{
+	"assetTracer": func() vm.Tracer { return NewAssetTracer() },
+}
+
+// NewNative creates the built in Go tracer of the given name, if any.
+func NewNative(name string) (vm.Tracer, bool) {
+	if ctor, ok := natives[name]; ok {
+		return ctor(), true
+	}
+	return nil, false
+}
+
 // tracer retrieves a specific JavaScript tracer by name.
 func tracer(name string) (string, bool) {
 	if tracer, ok := all[name]; ok {
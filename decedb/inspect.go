@@ -0,0 +1,169 @@
+package decedb
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+)
+
+// KeyGroup describes a family of keys sharing a common prefix in one of the
+// schemas stored in a database.
+type KeyGroup struct {
+	Schema string              // owner of the keys, e.g. "rawdb" or "localdb"
+	Name   string              // human readable name of the data stored
+	Prefix []byte              // common prefix of all the keys in the group
+	Match  func(k []byte) bool // optional extra check on the whole key
+}
+
+func (g *KeyGroup) matches(key []byte) bool {
+	if !bytes.HasPrefix(key, g.Prefix) {
+		return false
+	}
+	if g.Match != nil && !g.Match(key) {
+		return false
+	}
+	return true
+}
+
+// KeyStat accumulates the number and the size of the keys of a group.
+type KeyStat struct {
+	Schema    string
+	Name      string
+	Count     uint64
+	KeySize   uint64
+	ValueSize uint64
+}
+
+func (s *KeyStat) add(key, value []byte) {
+	s.Count++
+	s.KeySize += uint64(len(key))
+	s.ValueSize += uint64(len(value))
+}
+
+// Size returns the total number of bytes taken by the keys and the values.
+func (s *KeyStat) Size() uint64 {
+	return s.KeySize + s.ValueSize
+}
+
+// Inspector groups the keys of a database by their known prefixes. Keys
+// matching several groups are accounted to the one with the longest prefix.
+type Inspector struct {
+	groups  []KeyGroup
+	index   []int // group -> position in stats
+	stats   []KeyStat
+	unknown KeyStat
+	total   KeyStat
+}
+
+// NewInspector creates an inspector for the given key groups.
+func NewInspector(groups []KeyGroup) *Inspector {
+	ins := &Inspector{
+		groups:  groups,
+		index:   make([]int, len(groups)),
+		unknown: KeyStat{Schema: "-", Name: "Unaccounted"},
+		total:   KeyStat{Schema: "-", Name: "Total"},
+	}
+	// Groups sharing a schema and a name are reported as a single line.
+	seen := make(map[string]int)
+	for i, g := range groups {
+		name := g.Schema + "/" + g.Name
+		if pos, ok := seen[name]; ok {
+			ins.index[i] = pos
+			continue
+		}
+		seen[name] = len(ins.stats)
+		ins.index[i] = len(ins.stats)
+		ins.stats = append(ins.stats, KeyStat{Schema: g.Schema, Name: g.Name})
+	}
+	return ins
+}
+
+// Add accounts a single database entry.
+func (self *Inspector) Add(key, value []byte) {
+	best := -1
+	for i := range self.groups {
+		if self.groups[i].matches(key) {
+			if best < 0 || len(self.groups[i].Prefix) > len(self.groups[best].Prefix) {
+				best = i
+			}
+		}
+	}
+	if best < 0 {
+		self.unknown.add(key, value)
+	} else {
+		self.stats[self.index[best]].add(key, value)
+	}
+	self.total.add(key, value)
+}
+
+// AddIterator accounts every entry returned by the iterator and releases it.
+func (self *Inspector) AddIterator(it iterator.Iterator) error {
+	defer it.Release()
+	for it.Next() {
+		self.Add(it.Key(), it.Value())
+	}
+	return it.Error()
+}
+
+// Stats returns the statistics of the non-empty groups sorted by size,
+// followed by the unaccounted keys.
+func (self *Inspector) Stats() (ret []KeyStat) {
+	for _, s := range self.stats {
+		if s.Count > 0 {
+			ret = append(ret, s)
+		}
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].Size() > ret[j].Size()
+	})
+	return append(ret, self.unknown)
+}
+
+// Total returns the statistics of all the keys seen by the inspector.
+func (self *Inspector) Total() KeyStat {
+	return self.total
+}
+
+// Write prints the report as a table.
+func (self *Inspector) Write(w io.Writer) {
+	WriteKeyStats(w, append(self.Stats(), self.total))
+}
+
+// WriteKeyStats prints the statistics as a table.
+func WriteKeyStats(w io.Writer, stats []KeyStat) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "SCHEMA\tCATEGORY\tCOUNT\tKEYS\tVALUES\tSIZE\t")
+	for _, s := range stats {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\t\n",
+			s.Schema,
+			s.Name,
+			s.Count,
+			sizeString(s.KeySize),
+			sizeString(s.ValueSize),
+			sizeString(s.Size()),
+		)
+	}
+	tw.Flush()
+}
+
+func sizeString(size uint64) string {
+	switch {
+	case size > 1000000000:
+		return fmt.Sprintf("%.2f GB", float64(size)/1000000000)
+	case size > 1000000:
+		return fmt.Sprintf("%.2f MB", float64(size)/1000000)
+	case size > 1000:
+		return fmt.Sprintf("%.2f kB", float64(size)/1000)
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
+
+// Iteratee wraps the prefix iteration supported by the LevelDB backed databases.
+type Iteratee interface {
+	NewIteratorWithPrefix(prefix []byte) iterator.Iterator
+}
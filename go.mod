@@ -24,6 +24,7 @@ require (
 	github.com/google/go-cmp v0.5.2 // indirect
 	github.com/gosuri/uilive v0.0.1 // indirect
 	github.com/gosuri/uiprogress v0.0.1
+	github.com/graph-gophers/graphql-go v0.0.0-20191115155744-f33e81362277
 	github.com/hashicorp/golang-lru v0.0.0-20160813221303-0a025b7e63ad
 	github.com/huin/goupnp v0.0.0-20161224104101-679507af18f3
 	github.com/influxdata/influxdb v1.2.3-0.20180221223340-01288bdb0883
@@ -0,0 +1,870 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package graphql provides a GraphQL interface to DECE node data.
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/btcsuite/btcutil/base58"
+
+	"github.com/dece-cash/go-dece/common"
+	"github.com/dece-cash/go-dece/common/hexutil"
+	"github.com/dece-cash/go-dece/core/rawdb"
+	"github.com/dece-cash/go-dece/core/types"
+	"github.com/dece-cash/go-dece/czero/c_type"
+	"github.com/dece-cash/go-dece/internal/ethapi"
+	"github.com/dece-cash/go-dece/rpc"
+	"github.com/dece-cash/go-dece/zero/localdb"
+	"github.com/dece-cash/go-dece/zero/stake"
+	"github.com/dece-cash/go-dece/zero/txs/assets"
+	"github.com/dece-cash/go-dece/zero/utils"
+)
+
+// maxBlocksRange is the maximum number of blocks returned by a blocks query.
+const maxBlocksRange = 1024
+
+var (
+	errBlocksRange   = errors.New("from block is after to block")
+	errBlocksTooMany = fmt.Errorf("too many blocks requested, the maximum is %d", maxBlocksRange)
+)
+
+// PKr is a one-time address, represented as a base58 string.
+type PKr c_type.PKr
+
+// MarshalText returns the base58 representation of p.
+func (p PKr) MarshalText() ([]byte, error) {
+	return []byte(base58.Encode(p[:])), nil
+}
+
+// ImplementsGraphQLType returns true if PKr implements the specified GraphQL type.
+func (PKr) ImplementsGraphQLType(name string) bool { return name == "PKr" }
+
+// UnmarshalGraphQL unmarshals the provided GraphQL query data.
+func (p *PKr) UnmarshalGraphQL(input interface{}) error {
+	switch input := input.(type) {
+	case string:
+		data := base58.Decode(input)
+		if len(data) != len(p) {
+			return fmt.Errorf("invalid PKr: %v", input)
+		}
+		copy(p[:], data)
+		return nil
+	default:
+		return fmt.Errorf("Unexpected type for PKr: %v", input)
+	}
+}
+
+func toPKr(pkr *c_type.PKr) *PKr {
+	if pkr == nil {
+		return nil
+	}
+	ret := PKr(*pkr)
+	return &ret
+}
+
+func toHash(u *c_type.Uint256) common.Hash {
+	return common.BytesToHash(u[:])
+}
+
+func toBig(u *utils.U256) hexutil.Big {
+	return hexutil.Big(*u.ToInt())
+}
+
+// Token represents an amount of a currency.
+type Token struct {
+	token *assets.Token
+}
+
+func (t *Token) Currency(ctx context.Context) string {
+	return utils.Uint256ToCurrency(&t.token.Currency)
+}
+
+func (t *Token) Value(ctx context.Context) hexutil.Big {
+	return toBig(&t.token.Value)
+}
+
+// Ticket represents a non fungible asset.
+type Ticket struct {
+	ticket *assets.Ticket
+}
+
+func (t *Ticket) Category(ctx context.Context) string {
+	return utils.Uint256ToCurrency(&t.ticket.Category)
+}
+
+func (t *Ticket) Value(ctx context.Context) common.Hash {
+	return toHash(&t.ticket.Value)
+}
+
+// Output represents an output recorded in the local database of the chain.
+type Output struct {
+	backend ethapi.Backend
+	root    c_type.Uint256
+	state   *localdb.RootState
+}
+
+func (o *Output) Root(ctx context.Context) common.Hash {
+	return toHash(&o.root)
+}
+
+func (o *Output) Index(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(o.state.OS.Index)
+}
+
+func (o *Output) Block(ctx context.Context) (*Block, error) {
+	return blockByNumber(ctx, o.backend, rpc.BlockNumber(o.state.Num))
+}
+
+func (o *Output) Transaction(ctx context.Context) (*Transaction, error) {
+	if o.state.TxHash == (c_type.Uint256{}) {
+		return nil, nil
+	}
+	return transactionByHash(ctx, o.backend, toHash(&o.state.TxHash))
+}
+
+func (o *Output) Confidential(ctx context.Context) bool {
+	return o.state.OS.Out_C != nil
+}
+
+func (o *Output) Pkr(ctx context.Context) *PKr {
+	return toPKr(o.state.OS.ToPKr())
+}
+
+func (o *Output) Token(ctx context.Context) *Token {
+	if out := o.state.OS.Out_P; out != nil && out.Asset.Tkn != nil {
+		return &Token{out.Asset.Tkn}
+	}
+	return nil
+}
+
+func (o *Output) Ticket(ctx context.Context) *Ticket {
+	if out := o.state.OS.Out_P; out != nil && out.Asset.Tkt != nil {
+		return &Ticket{out.Asset.Tkt}
+	}
+	return nil
+}
+
+func (o *Output) Memo(ctx context.Context) *hexutil.Bytes {
+	if out := o.state.OS.Out_P; out != nil {
+		memo := hexutil.Bytes(out.Memo[:])
+		return &memo
+	}
+	return nil
+}
+
+func (o *Output) AssetCM(ctx context.Context) *common.Hash {
+	if out := o.state.OS.Out_C; out != nil {
+		cm := toHash(&out.AssetCM)
+		return &cm
+	}
+	return nil
+}
+
+func (o *Output) RootCM(ctx context.Context) *common.Hash {
+	if o.state.OS.RootCM != nil {
+		cm := toHash(o.state.OS.RootCM)
+		return &cm
+	}
+	return nil
+}
+
+// Log represents an individual log message. All arguments are mandatory.
+type Log struct {
+	backend     ethapi.Backend
+	transaction *Transaction
+	log         *types.Log
+}
+
+func (l *Log) Transaction(ctx context.Context) *Transaction {
+	return l.transaction
+}
+
+func (l *Log) Account(ctx context.Context) PKr {
+	return PKr(l.log.Address)
+}
+
+func (l *Log) Index(ctx context.Context) int32 {
+	return int32(l.log.Index)
+}
+
+func (l *Log) Topics(ctx context.Context) []common.Hash {
+	return l.log.Topics
+}
+
+func (l *Log) Data(ctx context.Context) hexutil.Bytes {
+	return hexutil.Bytes(l.log.Data)
+}
+
+// Transaction represents a DECE transaction, along with the block it was
+// mined in if any.
+type Transaction struct {
+	backend ethapi.Backend
+	tx      *types.Transaction
+	block   *Block
+	index   uint64
+}
+
+// receipt returns the receipt of the transaction, nil if it is still in the pool.
+func (t *Transaction) receipt(ctx context.Context) (*types.Receipt, error) {
+	if t.block == nil {
+		return nil, nil
+	}
+	receipts, err := t.block.resolveReceipts(ctx)
+	if err != nil || t.index >= uint64(len(receipts)) {
+		return nil, err
+	}
+	return receipts[t.index], nil
+}
+
+func (t *Transaction) Hash(ctx context.Context) common.Hash {
+	return t.tx.Hash()
+}
+
+func (t *Transaction) Index(ctx context.Context) *int32 {
+	if t.block == nil {
+		return nil
+	}
+	index := int32(t.index)
+	return &index
+}
+
+func (t *Transaction) Block(ctx context.Context) *Block {
+	return t.block
+}
+
+func (t *Transaction) From(ctx context.Context) PKr {
+	return PKr(t.tx.From())
+}
+
+func (t *Transaction) Gas(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(t.tx.Gas())
+}
+
+func (t *Transaction) GasPrice(ctx context.Context) hexutil.Big {
+	return hexutil.Big(*t.tx.GasPrice())
+}
+
+func (t *Transaction) Fee(ctx context.Context) *Token {
+	return &Token{&t.tx.GetZZSTX().Fee}
+}
+
+func (t *Transaction) Contract(ctx context.Context) *PKr {
+	return toPKr(t.tx.GetZZSTX().ContractAddress())
+}
+
+func (t *Transaction) Nils(ctx context.Context) []common.Hash {
+	stx := t.tx.GetZZSTX()
+	nils := []common.Hash{}
+	for i := range stx.Desc_O.Ins {
+		nils = append(nils, toHash(&stx.Desc_O.Ins[i].Nil))
+	}
+	for i := range stx.Desc_Z.Ins {
+		nils = append(nils, toHash(&stx.Desc_Z.Ins[i].Nil))
+	}
+	for i := range stx.Tx1.Ins_P {
+		nils = append(nils, toHash(&stx.Tx1.Ins_P[i].Nil))
+	}
+	for i := range stx.Tx1.Ins_C {
+		nils = append(nils, toHash(&stx.Tx1.Ins_C[i].Nil))
+	}
+	return nils
+}
+
+func (t *Transaction) Outputs(ctx context.Context) ([]*Output, error) {
+	if t.block == nil {
+		return []*Output{}, nil
+	}
+	outputs, err := t.block.Outputs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hash := t.tx.Hash()
+	ret := []*Output{}
+	for _, output := range outputs {
+		if output.state.TxHash == *hash.HashToUint256() {
+			ret = append(ret, output)
+		}
+	}
+	return ret, nil
+}
+
+func (t *Transaction) Status(ctx context.Context) (*hexutil.Uint64, error) {
+	receipt, err := t.receipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	status := hexutil.Uint64(receipt.Status)
+	return &status, nil
+}
+
+func (t *Transaction) GasUsed(ctx context.Context) (*hexutil.Uint64, error) {
+	receipt, err := t.receipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	used := hexutil.Uint64(receipt.GasUsed)
+	return &used, nil
+}
+
+func (t *Transaction) CumulativeGasUsed(ctx context.Context) (*hexutil.Uint64, error) {
+	receipt, err := t.receipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	used := hexutil.Uint64(receipt.CumulativeGasUsed)
+	return &used, nil
+}
+
+func (t *Transaction) Logs(ctx context.Context) (*[]*Log, error) {
+	receipt, err := t.receipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	ret := make([]*Log, 0, len(receipt.Logs))
+	for _, log := range receipt.Logs {
+		ret = append(ret, &Log{t.backend, t, log})
+	}
+	return &ret, nil
+}
+
+func (t *Transaction) Share(ctx context.Context) (*Share, error) {
+	receipt, err := t.receipt(ctx)
+	if err != nil || receipt == nil || receipt.ShareId == nil {
+		return nil, err
+	}
+	share := stake.GetShareByBlockNumber(t.backend.ChainDb(), *receipt.ShareId, t.block.block.Hash(), t.block.block.NumberU64())
+	if share == nil {
+		return nil, nil
+	}
+	return &Share{t.backend, share}, nil
+}
+
+func (t *Transaction) StakePool(ctx context.Context) (*StakePool, error) {
+	receipt, err := t.receipt(ctx)
+	if err != nil || receipt == nil || receipt.PoolId == nil {
+		return nil, err
+	}
+	pool := stake.GetStakePoolByBlockNumber(t.backend.ChainDb(), *receipt.PoolId, t.block.block.Hash(), t.block.block.NumberU64())
+	if pool == nil {
+		return nil, nil
+	}
+	return &StakePool{t.backend, pool}, nil
+}
+
+// Share represents a record of a share.
+type Share struct {
+	backend ethapi.Backend
+	share   *stake.Share
+}
+
+func (s *Share) Id(ctx context.Context) common.Hash {
+	return common.BytesToHash(s.share.Id())
+}
+
+func (s *Share) Pkr(ctx context.Context) PKr {
+	return PKr(s.share.PKr)
+}
+
+func (s *Share) VotePKr(ctx context.Context) PKr {
+	return PKr(s.share.VotePKr)
+}
+
+func (s *Share) Transaction(ctx context.Context) (*Transaction, error) {
+	return transactionByHash(ctx, s.backend, s.share.TransactionHash)
+}
+
+func (s *Share) PoolId(ctx context.Context) *common.Hash {
+	return s.share.PoolId
+}
+
+func (s *Share) Pool(ctx context.Context) (*StakePool, error) {
+	if s.share.PoolId == nil {
+		return nil, nil
+	}
+	return stakePoolById(ctx, s.backend, *s.share.PoolId)
+}
+
+func (s *Share) Value(ctx context.Context) *hexutil.Big {
+	return (*hexutil.Big)(s.share.Value)
+}
+
+func (s *Share) BlockNumber(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(s.share.BlockNumber)
+}
+
+func (s *Share) InitNum(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(s.share.InitNum)
+}
+
+func (s *Share) Num(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(s.share.Num)
+}
+
+func (s *Share) WillVoteNum(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(s.share.WillVoteNum)
+}
+
+func (s *Share) Fee(ctx context.Context) int32 {
+	return int32(s.share.Fee)
+}
+
+func (s *Share) Status(ctx context.Context) int32 {
+	return int32(s.share.Status)
+}
+
+func (s *Share) Income(ctx context.Context) *hexutil.Big {
+	return (*hexutil.Big)(s.share.Income)
+}
+
+func (s *Share) Profit(ctx context.Context) *hexutil.Big {
+	return (*hexutil.Big)(s.share.Profit)
+}
+
+func (s *Share) LastPayTime(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(s.share.LastPayTime)
+}
+
+// StakePool represents a record of a stake pool.
+type StakePool struct {
+	backend ethapi.Backend
+	pool    *stake.StakePool
+}
+
+func (p *StakePool) Id(ctx context.Context) common.Hash {
+	return common.BytesToHash(p.pool.Id())
+}
+
+func (p *StakePool) Pkr(ctx context.Context) PKr {
+	return PKr(p.pool.PKr)
+}
+
+func (p *StakePool) VotePKr(ctx context.Context) PKr {
+	return PKr(p.pool.VotePKr)
+}
+
+func (p *StakePool) Transaction(ctx context.Context) (*Transaction, error) {
+	return transactionByHash(ctx, p.backend, p.pool.TransactionHash)
+}
+
+func (p *StakePool) BlockNumber(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(p.pool.BlockNumber)
+}
+
+func (p *StakePool) Amount(ctx context.Context) *hexutil.Big {
+	return (*hexutil.Big)(p.pool.Amount)
+}
+
+func (p *StakePool) Fee(ctx context.Context) int32 {
+	return int32(p.pool.Fee)
+}
+
+func (p *StakePool) CurrentShareNum(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(p.pool.CurrentShareNum)
+}
+
+func (p *StakePool) WishVoteNum(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(p.pool.WishVoteNum)
+}
+
+func (p *StakePool) ChoicedShareNum(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(p.pool.ChoicedShareNum)
+}
+
+func (p *StakePool) MissedVoteNum(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(p.pool.MissedVoteNum)
+}
+
+func (p *StakePool) ExpireNum(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(p.pool.ExpireNum)
+}
+
+func (p *StakePool) Income(ctx context.Context) *hexutil.Big {
+	return (*hexutil.Big)(p.pool.Income)
+}
+
+func (p *StakePool) Profit(ctx context.Context) *hexutil.Big {
+	return (*hexutil.Big)(p.pool.Profit)
+}
+
+func (p *StakePool) LastPayTime(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(p.pool.LastPayTime)
+}
+
+func (p *StakePool) Closed(ctx context.Context) bool {
+	return p.pool.Closed
+}
+
+// Vote represents the vote of a share for a block.
+type Vote struct {
+	backend ethapi.Backend
+	vote    types.HeaderVote
+	block   *Block
+}
+
+func (v *Vote) Id(ctx context.Context) common.Hash {
+	return v.vote.Id
+}
+
+func (v *Vote) IsPool(ctx context.Context) bool {
+	return v.vote.IsPool
+}
+
+func (v *Vote) Sign(ctx context.Context) hexutil.Bytes {
+	return hexutil.Bytes(v.vote.Sign[:])
+}
+
+func (v *Vote) Share(ctx context.Context) (*Share, error) {
+	block := v.block.block
+	if share := stake.GetShareByBlockNumber(v.backend.ChainDb(), v.vote.Id, block.Hash(), block.NumberU64()); share != nil {
+		return &Share{v.backend, share}, nil
+	}
+	// The share is recorded in a block only when the block changes it
+	state, _, err := v.backend.StateAndHeaderByNumber(ctx, rpc.BlockNumber(block.NumberU64()))
+	if state == nil || err != nil {
+		return nil, err
+	}
+	share := stake.NewStakeState(state).GetShare(v.vote.Id)
+	if share == nil {
+		return nil, nil
+	}
+	return &Share{v.backend, share}, nil
+}
+
+// Block represents a DECE block. The receipts and the records of the local
+// database are loaded on the first query needing them.
+type Block struct {
+	backend ethapi.Backend
+	block   *types.Block
+
+	lock     sync.Mutex
+	receipts types.Receipts
+	outputs  []*Output
+}
+
+func (b *Block) resolveReceipts(ctx context.Context) (types.Receipts, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.receipts == nil {
+		receipts, err := b.backend.GetReceipts(ctx, b.block.Hash())
+		if err != nil {
+			return nil, err
+		}
+		b.receipts = receipts
+	}
+	return b.receipts, nil
+}
+
+// local returns the roots and the nils recorded for the block in the local
+// database.
+func (b *Block) local() *localdb.Block {
+	hash := b.block.Hash()
+	if block := localdb.GetBlock(b.backend.ChainDb(), b.block.NumberU64(), hash.HashToUint256()); block != nil {
+		return block
+	}
+	return &localdb.Block{}
+}
+
+func (b *Block) Number(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(b.block.NumberU64())
+}
+
+func (b *Block) Hash(ctx context.Context) common.Hash {
+	return b.block.Hash()
+}
+
+func (b *Block) Parent(ctx context.Context) (*Block, error) {
+	if b.block.NumberU64() == 0 {
+		return nil, nil
+	}
+	return blockByHash(ctx, b.backend, b.block.ParentHash())
+}
+
+func (b *Block) Nonce(ctx context.Context) hexutil.Bytes {
+	nonce := b.block.Header().Nonce
+	return hexutil.Bytes(nonce[:])
+}
+
+func (b *Block) Miner(ctx context.Context) PKr {
+	return PKr(b.block.Coinbase())
+}
+
+func (b *Block) Difficulty(ctx context.Context) hexutil.Big {
+	return hexutil.Big(*b.block.Difficulty())
+}
+
+func (b *Block) TotalDifficulty(ctx context.Context) (hexutil.Big, error) {
+	td := b.backend.GetTd(b.block.Hash())
+	if td == nil {
+		return hexutil.Big{}, fmt.Errorf("total difficulty not found %x", b.block.Hash())
+	}
+	return hexutil.Big(*td), nil
+}
+
+func (b *Block) GasLimit(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(b.block.GasLimit())
+}
+
+func (b *Block) GasUsed(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(b.block.GasUsed())
+}
+
+func (b *Block) Timestamp(ctx context.Context) hexutil.Big {
+	return hexutil.Big(*b.block.Time())
+}
+
+func (b *Block) ExtraData(ctx context.Context) hexutil.Bytes {
+	return hexutil.Bytes(b.block.Extra())
+}
+
+func (b *Block) StateRoot(ctx context.Context) common.Hash {
+	return b.block.Root()
+}
+
+func (b *Block) TransactionsRoot(ctx context.Context) common.Hash {
+	return b.block.TxHash()
+}
+
+func (b *Block) ReceiptsRoot(ctx context.Context) common.Hash {
+	return b.block.ReceiptHash()
+}
+
+func (b *Block) Reward(ctx context.Context) hexutil.Big {
+	return hexutil.Big(*ethapi.GetBlockReward(b.block)[0])
+}
+
+func (b *Block) CommunityReward(ctx context.Context) hexutil.Big {
+	return hexutil.Big(*ethapi.GetBlockReward(b.block)[1])
+}
+
+func (b *Block) TransactionCount(ctx context.Context) int32 {
+	return int32(len(b.block.Transactions()))
+}
+
+func (b *Block) Transactions(ctx context.Context) []*Transaction {
+	ret := make([]*Transaction, 0, len(b.block.Transactions()))
+	for i, tx := range b.block.Transactions() {
+		ret = append(ret, &Transaction{b.backend, tx, b, uint64(i)})
+	}
+	return ret
+}
+
+func (b *Block) TransactionAt(ctx context.Context, args struct{ Index int32 }) *Transaction {
+	txs := b.block.Transactions()
+	if args.Index < 0 || int(args.Index) >= len(txs) {
+		return nil
+	}
+	return &Transaction{b.backend, txs[args.Index], b, uint64(args.Index)}
+}
+
+func (b *Block) Outputs(ctx context.Context) ([]*Output, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.outputs == nil {
+		roots := b.local().Roots
+		outputs := make([]*Output, 0, len(roots))
+		for _, root := range roots {
+			state := localdb.GetRoot(b.backend.ChainDb(), &root)
+			if state == nil {
+				return nil, fmt.Errorf("output %x of block %d not found", root[:], b.block.NumberU64())
+			}
+			outputs = append(outputs, &Output{b.backend, root, state})
+		}
+		b.outputs = outputs
+	}
+	return b.outputs, nil
+}
+
+func (b *Block) Nils(ctx context.Context) []common.Hash {
+	dels := b.local().Dels
+	nils := make([]common.Hash, 0, len(dels))
+	for i := range dels {
+		nils = append(nils, toHash(&dels[i]))
+	}
+	return nils
+}
+
+func (b *Block) votes(votes []types.HeaderVote) []*Vote {
+	ret := make([]*Vote, 0, len(votes))
+	for _, vote := range votes {
+		ret = append(ret, &Vote{b.backend, vote, b})
+	}
+	return ret
+}
+
+func (b *Block) CurrentVotes(ctx context.Context) []*Vote {
+	return b.votes(b.block.Header().CurrentVotes)
+}
+
+func (b *Block) ParentVotes(ctx context.Context) []*Vote {
+	return b.votes(b.block.Header().ParentVotes)
+}
+
+func (b *Block) Shares(ctx context.Context) []*Share {
+	shares, _ := stake.GetBlockRecords(b.backend.ChainDb(), b.block.Hash(), b.block.NumberU64())
+	ret := make([]*Share, 0, len(shares))
+	for _, share := range shares {
+		ret = append(ret, &Share{b.backend, share})
+	}
+	return ret
+}
+
+func (b *Block) StakePools(ctx context.Context) []*StakePool {
+	_, pools := stake.GetBlockRecords(b.backend.ChainDb(), b.block.Hash(), b.block.NumberU64())
+	ret := make([]*StakePool, 0, len(pools))
+	for _, pool := range pools {
+		ret = append(ret, &StakePool{b.backend, pool})
+	}
+	return ret
+}
+
+func blockByNumber(ctx context.Context, backend ethapi.Backend, number rpc.BlockNumber) (*Block, error) {
+	block, err := backend.BlockByNumber(ctx, number)
+	if block == nil || err != nil {
+		return nil, err
+	}
+	return &Block{backend: backend, block: block}, nil
+}
+
+func blockByHash(ctx context.Context, backend ethapi.Backend, hash common.Hash) (*Block, error) {
+	block, err := backend.GetBlock(ctx, hash)
+	if block == nil || err != nil {
+		return nil, err
+	}
+	return &Block{backend: backend, block: block}, nil
+}
+
+func transactionByHash(ctx context.Context, backend ethapi.Backend, hash common.Hash) (*Transaction, error) {
+	tx, blockHash, _, index := rawdb.ReadTransaction(backend.ChainDb(), hash)
+	if tx == nil {
+		if tx = backend.GetPoolTransaction(hash); tx == nil {
+			return nil, nil
+		}
+		return &Transaction{backend: backend, tx: tx}, nil
+	}
+	block, err := blockByHash(ctx, backend, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	return &Transaction{backend, tx, block, index}, nil
+}
+
+func stakePoolById(ctx context.Context, backend ethapi.Backend, id common.Hash) (*StakePool, error) {
+	state, _, err := backend.StateAndHeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	pool := stake.NewStakeState(state).GetStakePool(id)
+	if pool == nil {
+		return nil, nil
+	}
+	return &StakePool{backend, pool}, nil
+}
+
+// Resolver is the top-level object in the GraphQL hierarchy.
+type Resolver struct {
+	backend ethapi.Backend
+}
+
+func (r *Resolver) Block(ctx context.Context, args struct {
+	Number *hexutil.Uint64
+	Hash   *common.Hash
+}) (*Block, error) {
+	switch {
+	case args.Number != nil && args.Hash != nil:
+		return nil, errors.New("only one of number or hash must be specified")
+	case args.Hash != nil:
+		return blockByHash(ctx, r.backend, *args.Hash)
+	case args.Number != nil:
+		return blockByNumber(ctx, r.backend, rpc.BlockNumber(*args.Number))
+	default:
+		return blockByNumber(ctx, r.backend, rpc.LatestBlockNumber)
+	}
+}
+
+func (r *Resolver) Blocks(ctx context.Context, args struct {
+	From hexutil.Uint64
+	To   *hexutil.Uint64
+}) ([]*Block, error) {
+	from := uint64(args.From)
+	head := r.backend.CurrentBlock().NumberU64()
+	to := head
+	if args.To != nil {
+		to = uint64(*args.To)
+	}
+	if to < from {
+		if args.To != nil {
+			return nil, errBlocksRange
+		}
+		return []*Block{}, nil
+	}
+	if to-from >= maxBlocksRange {
+		return nil, errBlocksTooMany
+	}
+	if to > head {
+		to = head
+	}
+	if to < from {
+		return []*Block{}, nil
+	}
+	ret := make([]*Block, 0, to-from+1)
+	for i := from; i <= to; i++ {
+		block, err := blockByNumber(ctx, r.backend, rpc.BlockNumber(i))
+		if err != nil {
+			return nil, err
+		}
+		if block == nil {
+			break
+		}
+		ret = append(ret, block)
+	}
+	return ret, nil
+}
+
+func (r *Resolver) Transaction(ctx context.Context, args struct{ Hash common.Hash }) (*Transaction, error) {
+	return transactionByHash(ctx, r.backend, args.Hash)
+}
+
+func (r *Resolver) Output(ctx context.Context, args struct{ Root common.Hash }) *Output {
+	root := args.Root.HashToUint256()
+	state := localdb.GetRoot(r.backend.ChainDb(), root)
+	if state == nil {
+		return nil
+	}
+	return &Output{r.backend, *root, state}
+}
+
+func (r *Resolver) Share(ctx context.Context, args struct{ Id common.Hash }) (*Share, error) {
+	state, _, err := r.backend.StateAndHeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	share := stake.NewStakeState(state).GetShare(args.Id)
+	if share == nil {
+		return nil, nil
+	}
+	return &Share{r.backend, share}, nil
+}
+
+func (r *Resolver) StakePool(ctx context.Context, args struct{ Id common.Hash }) (*StakePool, error) {
+	return stakePoolById(ctx, r.backend, args.Id)
+}
@@ -0,0 +1,211 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/dece-cash/go-dece/common"
+	"github.com/dece-cash/go-dece/common/hexutil"
+	"github.com/dece-cash/go-dece/dece/simulation"
+	"github.com/dece-cash/go-dece/params"
+)
+
+func TestBuildSchema(t *testing.T) {
+	// Make sure the schema can be parsed and matched up to the object model.
+	if _, err := newHandler(nil); err != nil {
+		t.Errorf("Could not construct GraphQL handler: %v", err)
+	}
+}
+
+// newTestChain mines a chain on which a pool is registered and shares bought
+// in it are voted for, returning the network and the pool node.
+func newTestChain(t *testing.T) (*simulation.Network, *simulation.Node) {
+	config := simulation.DefaultConfig
+	config.Nodes = []string{"miner", "pool", "staker"}
+	net, err := simulation.NewNetwork(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := net.StartAll(); err != nil {
+		net.Shutdown()
+		t.Fatal(err)
+	}
+	miner, pool, staker := net.Node("miner"), net.Node("pool"), net.Node("staker")
+
+	tx, err := pool.RegisterPool(2500)
+	if err == nil {
+		err = miner.WaitPending(tx)
+	}
+	if err == nil {
+		_, err = net.Mine("miner", 1)
+	}
+	if err == nil {
+		err = staker.WaitTx(tx)
+	}
+	if err == nil {
+		tx, err = staker.BuyShares(new(big.Int).Mul(big.NewInt(30), big.NewInt(params.Ether)), pool)
+	}
+	if err == nil {
+		err = miner.WaitPending(tx)
+	}
+	if err == nil {
+		_, err = net.Mine("miner", 5)
+	}
+	if err == nil {
+		err = staker.WaitTx(tx)
+	}
+	if err != nil {
+		net.Shutdown()
+		t.Fatal(err)
+	}
+	return net, pool
+}
+
+func TestResolvers(t *testing.T) {
+	if testing.Short() {
+		t.Skip("mining a block takes a second")
+	}
+	net, pool := newTestChain(t)
+	defer net.Shutdown()
+
+	var (
+		ctx      = context.Background()
+		miner    = net.Node("miner")
+		resolver = &Resolver{miner.Dece().APIBackend}
+		head     = uint64(miner.Head().NumberU64())
+		number   = func(n uint64) *hexutil.Uint64 { return (*hexutil.Uint64)(&n) }
+	)
+
+	// The ranges are bounded by the head and the maximum number of blocks
+	type blocksArgs struct {
+		From hexutil.Uint64
+		To   *hexutil.Uint64
+	}
+	tests := []struct {
+		args blocksArgs
+		len  uint64
+		err  error
+	}{
+		{args: blocksArgs{From: 0}, len: head + 1},
+		{args: blocksArgs{From: 1, To: number(head + 10)}, len: head},
+		{args: blocksArgs{From: 0, To: number(maxBlocksRange - 1)}, len: head + 1},
+		{args: blocksArgs{From: hexutil.Uint64(head + 1)}, len: 0},
+		{args: blocksArgs{From: hexutil.Uint64(head + 1), To: number(head + 5)}, len: 0},
+		{args: blocksArgs{From: 2, To: number(1)}, err: errBlocksRange},
+		{args: blocksArgs{From: 0, To: number(maxBlocksRange)}, err: errBlocksTooMany},
+		{args: blocksArgs{From: 1 << 40, To: number(1<<40 + maxBlocksRange)}, err: errBlocksTooMany},
+	}
+	for i, tt := range tests {
+		blocks, err := resolver.Blocks(ctx, tt.args)
+		if err != tt.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+			continue
+		}
+		if uint64(len(blocks)) != tt.len {
+			t.Errorf("test %d: blocks mismatch: have %d, want %d", i, len(blocks), tt.len)
+			continue
+		}
+		for j, block := range blocks {
+			if want := uint64(tt.args.From) + uint64(j); uint64(block.Number(ctx)) != want {
+				t.Errorf("test %d: block %d number mismatch: have %d, want %d", i, j, block.Number(ctx), want)
+			}
+		}
+	}
+
+	blocks, err := resolver.Blocks(ctx, blocksArgs{From: 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var outputs, votes int
+	var poolBlock, shareBlock *Block
+	for _, block := range blocks {
+		// The outputs are found by their roots, in the block recording them
+		blockOutputs, err := block.Outputs(ctx)
+		if err != nil {
+			t.Fatalf("block %d: %v", block.Number(ctx), err)
+		}
+		for _, output := range blockOutputs {
+			found := resolver.Output(ctx, struct{ Root common.Hash }{output.Root(ctx)})
+			if found == nil {
+				t.Fatalf("block %d: output %x not found", block.Number(ctx), output.Root(ctx))
+			}
+			outBlock, err := found.Block(ctx)
+			if err != nil || outBlock == nil || outBlock.Hash(ctx) != block.Hash(ctx) {
+				t.Errorf("block %d: output %x block mismatch: have %v, %v", block.Number(ctx), output.Root(ctx), outBlock, err)
+			}
+		}
+		outputs += len(blockOutputs)
+
+		// The votes are cast by the shares bought in the pool
+		for _, vote := range append(block.CurrentVotes(ctx), block.ParentVotes(ctx)...) {
+			share, err := vote.Share(ctx)
+			if err != nil || share == nil {
+				t.Fatalf("block %d: share of vote %x not found: %v", block.Number(ctx), vote.Id(ctx), err)
+			}
+			if id := share.PoolId(ctx); id == nil || *id != pool.PoolId() {
+				t.Errorf("block %d: vote %x pool mismatch: have %v, want %x", block.Number(ctx), vote.Id(ctx), id, pool.PoolId())
+			}
+			votes++
+		}
+		if poolBlock == nil && len(block.StakePools(ctx)) > 0 {
+			poolBlock = block
+		}
+		if shareBlock == nil && len(block.Shares(ctx)) > 0 {
+			shareBlock = block
+		}
+	}
+	if outputs == 0 {
+		t.Error("no outputs recorded")
+	}
+	if votes == 0 {
+		t.Error("no votes recorded")
+	}
+
+	// The pool and the share are recorded in the blocks registering and
+	// buying them, and in the blocks changing them later on
+	if poolBlock == nil {
+		t.Fatal("no pool recorded")
+	}
+	record := poolBlock.StakePools(ctx)[0]
+	if record.Id(ctx) != pool.PoolId() || uint64(record.BlockNumber(ctx)) != uint64(poolBlock.Number(ctx)) {
+		t.Errorf("pool record mismatch: have %x in block %d, want %x in block %d", record.Id(ctx), record.BlockNumber(ctx), pool.PoolId(), poolBlock.Number(ctx))
+	}
+	current, err := resolver.StakePool(ctx, struct{ Id common.Hash }{pool.PoolId()})
+	if err != nil || current == nil {
+		t.Fatalf("current pool not found: %v", err)
+	}
+	if current.ChoicedShareNum(ctx) == 0 {
+		t.Errorf("current pool chose no share")
+	}
+	if shareBlock == nil {
+		t.Fatal("no share recorded")
+	}
+	share := shareBlock.Shares(ctx)[0]
+	if id := share.PoolId(ctx); id == nil || *id != pool.PoolId() || share.InitNum(ctx) == 0 {
+		t.Errorf("share record mismatch: pool %v, %d shares", id, share.InitNum(ctx))
+	}
+	currentShare, err := resolver.Share(ctx, struct{ Id common.Hash }{share.Id(ctx)})
+	if err != nil || currentShare == nil {
+		t.Fatalf("current share not found: %v", err)
+	}
+	if currentShare.InitNum(ctx) != share.InitNum(ctx) {
+		t.Errorf("current share mismatch: have %d shares, want %d", currentShare.InitNum(ctx), share.InitNum(ctx))
+	}
+}
@@ -0,0 +1,295 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+const schema string = `
+    # Bytes32 is a 32 byte binary string, represented as 0x-prefixed hexadecimal.
+    scalar Bytes32
+    # PKr is a 96 byte one-time address, represented as a base58 string.
+    scalar PKr
+    # Bytes is an arbitrary length binary string, represented as 0x-prefixed hexadecimal.
+    # An empty byte string is represented as '0x'. Byte strings must have an even number of hexadecimal nybbles.
+    scalar Bytes
+    # BigInt is a large integer. Input is accepted as either a JSON number or as a string.
+    # Strings may be either decimal or 0x-prefixed hexadecimal. Output values are all
+    # 0x-prefixed hexadecimal.
+    scalar BigInt
+    # Long is a 64 bit unsigned integer.
+    scalar Long
+
+    schema {
+        query: Query
+    }
+
+    # Token is an amount of a currency.
+    type Token {
+        # Currency is the name of the currency, DECE for the base currency.
+        currency: String!
+        # Value is the amount, in the smallest unit of the currency.
+        value: BigInt!
+    }
+
+    # Ticket is a non fungible asset.
+    type Ticket {
+        # Category is the name of the category of the ticket.
+        category: String!
+        # Value is the identifier of the ticket within its category.
+        value: Bytes32!
+    }
+
+    # Output is an output recorded by the chain, identified by its root.
+    type Output {
+        # Root is the identifier of the output, referred to when it is spent.
+        root: Bytes32!
+        # Index is the position of the output in the commitment tree.
+        index: Long!
+        # Block is the block that created the output.
+        block: Block
+        # Transaction is the transaction that created the output, null for the
+        # outputs the chain created itself such as the rewards.
+        transaction: Transaction
+        # Confidential is true if the asset of the output is hidden behind a commitment.
+        confidential: Boolean!
+        # PKr is the one-time address receiving the output.
+        pkr: PKr
+        # Token is the currency and amount of a public output.
+        token: Token
+        # Ticket is the ticket of a public output.
+        ticket: Ticket
+        # Memo is the memo attached to a public output.
+        memo: Bytes
+        # AssetCM is the commitment to the asset of a confidential output.
+        assetCM: Bytes32
+        # RootCM is the commitment to the output, from which its root is derived.
+        rootCM: Bytes32
+    }
+
+    # Log is an event emitted by a contract.
+    type Log {
+        # Index is the index of this log in the block.
+        index: Int!
+        # Account is the contract that emitted the log.
+        account: PKr!
+        # Topics is the list of 0-4 indexed topics of the log.
+        topics: [Bytes32!]!
+        # Data is the unindexed data of the log.
+        data: Bytes!
+        # Transaction is the transaction that emitted the log.
+        transaction: Transaction!
+    }
+
+    # Transaction is a DECE transaction.
+    type Transaction {
+        # Hash is the hash of the transaction.
+        hash: Bytes32!
+        # Index is the index of the transaction in its block, null for a
+        # transaction still in the pool.
+        index: Int
+        # Block is the block the transaction was mined in, null for a
+        # transaction still in the pool.
+        block: Block
+        # From is the one-time address of the sender.
+        from: PKr!
+        # Gas is the maximum amount of gas the transaction may use.
+        gas: Long!
+        # GasPrice is the price of a unit of gas, in the fee currency.
+        gasPrice: BigInt!
+        # Fee is the maximum fee the sender pays for the transaction.
+        fee: Token!
+        # Contract is the contract called or created by the transaction.
+        contract: PKr
+        # Nils are the nullifiers of the outputs spent by the transaction.
+        nils: [Bytes32!]!
+        # Outputs are the outputs created by the transaction, empty for a
+        # transaction still in the pool.
+        outputs: [Output!]!
+        # Status is 1 if the transaction succeeded and 0 if it failed, null
+        # for a transaction still in the pool.
+        status: Long
+        # GasUsed is the amount of gas used by the transaction, null for a
+        # transaction still in the pool.
+        gasUsed: Long
+        # CumulativeGasUsed is the gas used by the transactions of the block up
+        # to and including this one.
+        cumulativeGasUsed: Long
+        # Logs are the logs emitted by the transaction.
+        logs: [Log!]
+        # Share is the share bought by the transaction.
+        share: Share
+        # StakePool is the stake pool registered or changed by the transaction.
+        stakePool: StakePool
+    }
+
+    # Share is a share bought to take part in the votes of the blocks.
+    type Share {
+        # Id is the identifier of the share.
+        id: Bytes32!
+        # PKr is the one-time address owning the share.
+        pkr: PKr!
+        # VotePKr is the one-time address signing the votes of the share.
+        votePKr: PKr!
+        # Transaction is the transaction that bought the share.
+        transaction: Transaction
+        # PoolId is the identifier of the stake pool the share votes through.
+        poolId: Bytes32
+        # Pool is the current state of the stake pool the share votes through.
+        pool: StakePool
+        # Value is the price paid for each share.
+        value: BigInt
+        # BlockNumber is the number of the block that bought the share.
+        blockNumber: Long!
+        # InitNum is the number of shares bought.
+        initNum: Long!
+        # Num is the number of shares remaining.
+        num: Long!
+        # WillVoteNum is the number of shares chosen but not voted yet.
+        willVoteNum: Long!
+        # Fee is the fee of the stake pool, in units of 1/10000.
+        fee: Int!
+        # Status is 0 for a normal share, 1 for an outdated one and 2 for one
+        # that has been paid back in full.
+        status: Int!
+        # Income is the reward received by the share.
+        income: BigInt
+        # Profit is the reward paid to the owner of the share.
+        profit: BigInt
+        # LastPayTime is the number of the block that last paid the share.
+        lastPayTime: Long!
+    }
+
+    # StakePool is a pool voting for the shares that join it.
+    type StakePool {
+        # Id is the identifier of the stake pool.
+        id: Bytes32!
+        # PKr is the one-time address owning the pool.
+        pkr: PKr!
+        # VotePKr is the one-time address signing the votes of the pool.
+        votePKr: PKr!
+        # Transaction is the transaction that registered the pool.
+        transaction: Transaction
+        # BlockNumber is the number of the block that registered the pool.
+        blockNumber: Long!
+        # Amount is the amount locked by the pool.
+        amount: BigInt
+        # Fee is the fee of the pool, in units of 1/10000.
+        fee: Int!
+        # CurrentShareNum is the number of shares in the pool.
+        currentShareNum: Long!
+        # WishVoteNum is the number of the shares of the pool waiting to vote.
+        wishVoteNum: Long!
+        # ChoicedShareNum is the number of the shares of the pool chosen to vote.
+        choicedShareNum: Long!
+        # MissedVoteNum is the number of votes the pool missed.
+        missedVoteNum: Long!
+        # ExpireNum is the number of the shares of the pool that expired.
+        expireNum: Long!
+        # Income is the reward received by the pool.
+        income: BigInt
+        # Profit is the reward paid to the owner of the pool.
+        profit: BigInt
+        # LastPayTime is the number of the block that last paid the pool.
+        lastPayTime: Long!
+        # Closed is true if the pool has been closed.
+        closed: Boolean!
+    }
+
+    # Vote is the vote of a share for a block.
+    type Vote {
+        # Id is the identifier of the share that voted.
+        id: Bytes32!
+        # IsPool is true if the vote was signed by the stake pool of the share.
+        isPool: Boolean!
+        # Sign is the signature of the vote.
+        sign: Bytes!
+        # Share is the share that voted, in the state following the block
+        # holding the vote.
+        share: Share
+    }
+
+    # Block is a DECE block.
+    type Block {
+        # Number is the number of this block, starting at 0 for the genesis block.
+        number: Long!
+        # Hash is the block hash of this block.
+        hash: Bytes32!
+        # Parent is the parent block of this block.
+        parent: Block
+        # Nonce is the block nonce, an 8 byte sequence determined by the miner.
+        nonce: Bytes!
+        # Miner is the one-time address receiving the mining reward.
+        miner: PKr!
+        # Difficulty is a measure of the difficulty of mining this block.
+        difficulty: BigInt!
+        # TotalDifficulty is the sum of all difficulty values up to and including
+        # this block.
+        totalDifficulty: BigInt!
+        # GasLimit is the maximum amount of gas that was available to transactions in this block.
+        gasLimit: Long!
+        # GasUsed is the amount of gas that was used executing transactions in this block.
+        gasUsed: Long!
+        # Timestamp is the unix timestamp at which this block was mined.
+        timestamp: BigInt!
+        # ExtraData is an arbitrary data field supplied by the miner.
+        extraData: Bytes!
+        # StateRoot is the hash of the state trie after this block was processed.
+        stateRoot: Bytes32!
+        # TransactionsRoot is the hash of the root of the trie of transactions in this block.
+        transactionsRoot: Bytes32!
+        # ReceiptsRoot is the hash of the trie of transaction receipts in this block.
+        receiptsRoot: Bytes32!
+        # Reward is the reward of the miner.
+        reward: BigInt!
+        # CommunityReward is the reward paid to the community pool.
+        communityReward: BigInt!
+        # TransactionCount is the number of transactions in this block.
+        transactionCount: Int!
+        # Transactions is a list of transactions associated with this block.
+        transactions: [Transaction!]!
+        # TransactionAt returns the transaction at the specified index.
+        transactionAt(index: Int!): Transaction
+        # Outputs are the outputs created in this block.
+        outputs: [Output!]!
+        # Nils are the nullifiers of the outputs spent in this block.
+        nils: [Bytes32!]!
+        # CurrentVotes are the votes of the shares for this block.
+        currentVotes: [Vote!]!
+        # ParentVotes are the votes for the parent block included late in this block.
+        parentVotes: [Vote!]!
+        # Shares are the shares bought or changed in this block.
+        shares: [Share!]!
+        # StakePools are the stake pools registered or changed in this block.
+        stakePools: [StakePool!]!
+    }
+
+    type Query {
+        # Block fetches a block by number or by hash. If neither is
+        # supplied, the most recent known block is returned.
+        block(number: Long, hash: Bytes32): Block
+        # Blocks returns all the blocks between two numbers, inclusive, at most
+        # 1024 of them. The blocks past the most recent known block are left
+        # out, to defaulting to that block when it is not supplied.
+        blocks(from: Long!, to: Long): [Block!]!
+        # Transaction returns a transaction specified by its hash.
+        transaction(hash: Bytes32!): Transaction
+        # Output returns an output specified by its root.
+        output(root: Bytes32!): Output
+        # Share returns the current state of a share.
+        share(id: Bytes32!): Share
+        # StakePool returns the current state of a stake pool.
+        stakePool(id: Bytes32!): StakePool
+    }
+`
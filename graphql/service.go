@@ -0,0 +1,121 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/dece-cash/go-dece/dece"
+	"github.com/dece-cash/go-dece/internal/ethapi"
+	"github.com/dece-cash/go-dece/log"
+	"github.com/dece-cash/go-dece/node"
+	"github.com/dece-cash/go-dece/p2p"
+	"github.com/dece-cash/go-dece/rpc"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+)
+
+// Service encapsulates a GraphQL service.
+type Service struct {
+	endpoint string             // The host:port endpoint for this service.
+	cors     []string           // Allowed CORS domains
+	vhosts   []string           // Recognised vhosts
+	timeouts rpc.HTTPTimeouts   // Timeout settings for HTTP requests.
+	backend  ethapi.Backend     // The backend that queries will operate on.
+	access   *rpc.AccessControl // Access keys of the queries, nil if authentication is disabled.
+	handler  http.Handler       // The `http.Handler` used to answer queries.
+	listener net.Listener       // The listening socket.
+}
+
+// New constructs a new GraphQL service instance. Unless access is nil, the
+// queries must carry the bearer token of an access key allowing "graphql".
+func New(backend ethapi.Backend, endpoint string, cors, vhosts []string, timeouts rpc.HTTPTimeouts, access *rpc.AccessControl) (*Service, error) {
+	return &Service{
+		endpoint: endpoint,
+		cors:     cors,
+		vhosts:   vhosts,
+		timeouts: timeouts,
+		backend:  backend,
+		access:   access,
+	}, nil
+}
+
+// Protocols returns the list of protocols exported by this service.
+func (s *Service) Protocols() []p2p.Protocol { return nil }
+
+// APIs returns the list of APIs exported by this service.
+func (s *Service) APIs() []rpc.API { return nil }
+
+// Start is called after all services have been constructed and the networking
+// layer was also initialized to spawn any goroutines required by the service.
+func (s *Service) Start(server *p2p.Server) error {
+	var err error
+	s.handler, err = newHandler(s.backend)
+	if err != nil {
+		return err
+	}
+	if s.access != nil {
+		s.handler = s.access.Handler("graphql", s.handler)
+	}
+	if s.listener, err = net.Listen("tcp", s.endpoint); err != nil {
+		return err
+	}
+	go rpc.NewHTTPServer(s.cors, s.vhosts, s.timeouts, s.handler).Serve(s.listener)
+	log.Info("GraphQL endpoint opened", "url", fmt.Sprintf("http://%s/graphql", s.endpoint))
+	return nil
+}
+
+// newHandler returns a new `http.Handler` that will answer GraphQL queries.
+func newHandler(backend ethapi.Backend) (http.Handler, error) {
+	s, err := graphql.ParseSchema(schema, &Resolver{backend})
+	if err != nil {
+		return nil, err
+	}
+	h := &relay.Handler{Schema: s}
+
+	mux := http.NewServeMux()
+	mux.Handle("/graphql", h)
+	mux.Handle("/graphql/", h)
+	return mux, nil
+}
+
+// Stop terminates all goroutines belonging to the service, blocking until they
+// are all terminated.
+func (s *Service) Stop() error {
+	if s.listener != nil {
+		s.listener.Close()
+		s.listener = nil
+		log.Info("GraphQL endpoint closed", "url", fmt.Sprintf("http://%s/graphql", s.endpoint))
+	}
+	return nil
+}
+
+// RegisterGraphQLService is a utility function to construct a new service and
+// register it against a node, answering the queries from the chain of its
+// Dece service. The queries are authenticated with the access keys of the RPC
+// endpoints of the node when it has some.
+func RegisterGraphQLService(stack *node.Node, endpoint string, cors, vhosts []string, timeouts rpc.HTTPTimeouts) error {
+	return stack.Register(func(ctx *node.ServiceContext) (node.Service, error) {
+		var deceServ *dece.Dece
+		if err := ctx.Service(&deceServ); err != nil {
+			return nil, err
+		}
+		return New(deceServ.APIBackend, endpoint, cors, vhosts, timeouts, ctx.RPCAccess)
+	})
+}
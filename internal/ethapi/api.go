@@ -176,6 +176,48 @@ func (s *PublicTxPoolAPI) Status() map[string]hexutil.Uint {
 	}
 }
 
+// Replacement returns the hash of the transaction that replaced the given one
+// because it spends the same nils at a higher price, or nil if the transaction
+// was not replaced.
+func (s *PublicTxPoolAPI) Replacement(hash common.Hash) *common.Hash {
+	if replacement, ok := s.b.TxPoolReplacement(hash); ok {
+		return &replacement
+	}
+	return nil
+}
+
+// ReplacedTransactions creates a subscription that is triggered each time a
+// pooled transaction is replaced by a higher priced one spending the same nils.
+func (s *PublicTxPoolAPI) ReplacedTransactions(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		replaced := make(chan core.ReplacedTxEvent, 128)
+		replacedSub := s.b.SubscribeReplacedTxEvent(replaced)
+		defer replacedSub.Unsubscribe()
+
+		for {
+			select {
+			case ev := <-replaced:
+				notifier.Notify(rpcSub.ID, map[string]common.Hash{
+					"old": ev.Old.Hash(),
+					"new": ev.New.Hash(),
+				})
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
 // Inspect retrieves the content of the transaction pool and flattens it into an
 // easily inspectable list.
 
@@ -435,6 +477,84 @@ func (s *PrivateAccountAPI) ImportMnemonic(mnemonic string, password string, a *
 	return acc.Address, err
 }
 
+func hdPath(path *string, def accounts.DerivationPath) (accounts.DerivationPath, error) {
+	if path == nil || *path == "" {
+		return def, nil
+	}
+	return accounts.ParseDerivationPath(*path)
+}
+
+// ImportHDMnemonic stores the account at path of a BIP-39 mnemonic, protected
+// by the optional mnemonic password. The first account of the default base path
+// is imported when path is not given.
+func (s *PrivateAccountAPI) ImportHDMnemonic(mnemonic string, password string, path *string, mnemonicPassword *string, a *uint64) (address.PKAddress, error) {
+	derivationPath, err := hdPath(path, accounts.DefaultBaseDerivationPath)
+	if err != nil {
+		return address.PKAddress{}, err
+	}
+	var mpass string
+	if mnemonicPassword != nil {
+		mpass = *mnemonicPassword
+	}
+	at := uint64(0)
+	if a != nil {
+		at = *a
+	}
+
+	acc, err := fetchKeystore(s.am).ImportHDMnemonic(mnemonic, mpass, derivationPath, password, at)
+	return acc.Address, err
+}
+
+// DeriveHDAccounts stores the accounts of a BIP-39 mnemonic incremented from
+// the base path, up to the last one having outputs in the blocks from at.
+func (s *PrivateAccountAPI) DeriveHDAccounts(mnemonic string, password string, base *string, mnemonicPassword *string, a *uint64) ([]address.PKAddress, error) {
+	basePath, err := hdPath(base, accounts.DefaultBaseDerivationPath)
+	if err != nil {
+		return nil, err
+	}
+	var mpass string
+	if mnemonicPassword != nil {
+		mpass = *mnemonicPassword
+	}
+	at := uint64(0)
+	if a != nil {
+		at = *a
+	}
+
+	accs, err := fetchKeystore(s.am).DeriveHDAccounts(mnemonic, mpass, basePath, hdOutputs(at), password, at)
+	if err != nil {
+		return nil, err
+	}
+	addrs := make([]address.PKAddress, 0, len(accs))
+	for _, acc := range accs {
+		addrs = append(addrs, acc.Address)
+	}
+	return addrs, nil
+}
+
+// hdOutputs scans the PKrs of the outputs of the confirmed blocks from at.
+func hdOutputs(at uint64) keystore.HDScanFunc {
+	return func(match func(pkr *c_type.PKr)) error {
+		for start := at; ; {
+			blocks, err := flight.SRI_Inst.GetBlocksInfo(start, 1000)
+			if err != nil {
+				return err
+			}
+			if len(blocks) == 0 {
+				return nil
+			}
+			for _, block := range blocks {
+				for _, out := range block.Outs {
+					if pkr := out.State.OS.ToPKr(); pkr != nil {
+						match(pkr)
+					}
+				}
+			}
+			start = uint64(blocks[len(blocks)-1].Num) + 1
+		}
+	}
+}
+
 // UnlockAccount will unlock the account associated with the given address with
 // the given password for duration seconds. If duration is nil it will use a
 // default of 300 seconds. It returns an indication if the account was unlocked.
@@ -529,14 +649,19 @@ func (s *PrivateAccountAPI) signTransaction(ctx context.Context, args SendTxArgs
 			return
 		}
 		log.Info("ToTxParam", "utxos", len(pretx.Ins))
-		seed, err := wallet.GetSeedWithPassphrase(passwd)
-		if err != nil {
-			exchange.CurrentExchange().ClearTxParam(pretx)
-			e = err
-			return
+		var gtx txtool.GTx
+		if signer, ok := wallet.(accounts.TxSigner); ok {
+			gtx, err = signer.SignTx(pretx)
+		} else {
+			var seed *address.Seed
+			if seed, err = wallet.GetSeedWithPassphrase(passwd); err != nil {
+				exchange.CurrentExchange().ClearTxParam(pretx)
+				e = err
+				return
+			}
+			sk := superzk.Seed2Sk(seed.SeedToUint256())
+			gtx, err = flight.SignTx(&sk, pretx)
 		}
-		sk := superzk.Seed2Sk(seed.SeedToUint256())
-		gtx, err := flight.SignTx(&sk, pretx)
 		if err != nil {
 			exchange.CurrentExchange().ClearTxParam(pretx)
 			e = err
@@ -1442,6 +1567,14 @@ func (s *PublicBlockChainAPI) rpcOutputBlock(b *types.Block, inclTx bool, fullTx
 	if err != nil {
 		return nil, err
 	}
+	if inclTx && fullTx {
+		roots := blockRoots(s.b.ChainDb(), b.Hash(), b.NumberU64())
+		for _, tx := range fields["transactions"].([]interface{}) {
+			if tx := tx.(*RPCTransaction); tx != nil && tx.Zero != nil {
+				tx.Zero.Roots = roots[tx.Hash]
+			}
+		}
+	}
 	fields["totalDifficulty"] = (*hexutil.Big)(s.b.GetTd(b.Hash()))
 	return fields, err
 }
@@ -1460,6 +1593,7 @@ type RPCTransaction struct {
 	TransactionIndex hexutil.Uint    `json:"transactionIndex"`
 	Value            *hexutil.Big    `json:"value"`
 	Stx              *stx.T          `json:"stx"`
+	Zero             *RPCZero        `json:"zero"`
 }
 
 func addressToPKrAddress(addr common.Address) (ret PKrAddress) {
@@ -1487,6 +1621,7 @@ func newRPCTransaction(tx *types.Transaction, blockHash common.Hash, blockNumber
 		Input:    hexutil.Bytes(tx.Data()),
 		To:       to,
 		Stx:      tx.Stxt(),
+		Zero:     newRPCZero(tx.Stxt(), nil),
 	}
 	if blockHash != (common.Hash{}) {
 		result.BlockHash = blockHash
@@ -1574,10 +1709,18 @@ func (s *PublicTransactionPoolAPI) GetBlockTransactionCountByHash(ctx context.Co
 	return nil
 }
 
+// withRoots fills the roots created by the mined transaction tx.
+func (s *PublicTransactionPoolAPI) withRoots(tx *RPCTransaction) *RPCTransaction {
+	if tx != nil && tx.Zero != nil && tx.BlockNumber != nil {
+		tx.Zero.Roots = txRoots(s.b.ChainDb(), tx.Hash, tx.BlockHash, tx.BlockNumber.ToInt().Uint64())
+	}
+	return tx
+}
+
 // GetTransactionByBlockNumberAndIndex returns the transaction for the given block number and index.
 func (s *PublicTransactionPoolAPI) GetTransactionByBlockNumberAndIndex(ctx context.Context, blockNr rpc.BlockNumber, index hexutil.Uint) *RPCTransaction {
 	if block, _ := s.b.BlockByNumber(ctx, blockNr); block != nil {
-		return newRPCTransactionFromBlockIndex(block, uint64(index))
+		return s.withRoots(newRPCTransactionFromBlockIndex(block, uint64(index)))
 	}
 	return nil
 }
@@ -1585,7 +1728,7 @@ func (s *PublicTransactionPoolAPI) GetTransactionByBlockNumberAndIndex(ctx conte
 // GetTransactionByBlockHashAndIndex returns the transaction for the given block hash and index.
 func (s *PublicTransactionPoolAPI) GetTransactionByBlockHashAndIndex(ctx context.Context, blockHash common.Hash, index hexutil.Uint) *RPCTransaction {
 	if block, _ := s.b.GetBlock(ctx, blockHash); block != nil {
-		return newRPCTransactionFromBlockIndex(block, uint64(index))
+		return s.withRoots(newRPCTransactionFromBlockIndex(block, uint64(index)))
 	}
 	return nil
 }
@@ -1610,7 +1753,7 @@ func (s *PublicTransactionPoolAPI) GetRawTransactionByBlockHashAndIndex(ctx cont
 func (s *PublicTransactionPoolAPI) GetTransactionByHash(ctx context.Context, hash common.Hash) *RPCTransaction {
 	// Try to return an already finalized transaction
 	if tx, blockHash, blockNumber, index := rawdb.ReadTransaction(s.b.ChainDb(), hash); tx != nil {
-		return newRPCTransaction(tx, blockHash, blockNumber, index)
+		return s.withRoots(newRPCTransaction(tx, blockHash, blockNumber, index))
 	}
 	// No finalized transaction, try to retrieve it from the pool
 	if tx := s.b.GetPoolTransaction(hash); tx != nil {
@@ -1674,6 +1817,7 @@ func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, ha
 		"logsBloom":         receipt.Bloom,
 		"shareId":           receipt.ShareId,
 		"poolId":            receipt.PoolId,
+		"zero":              newRPCZero(tx.Stxt(), txRoots(s.b.ChainDb(), hash, blockHash, blockNumber)),
 	}
 
 	// Assign receipt status or post state.
@@ -1908,7 +2052,7 @@ func (args *SendTxArgs) toCreatePkg(state *state.StateDB, fromAccount accounts.A
 	}
 	txParam.RefundTo = fromAccount.GetPkr(nil).NewRef()
 	txParam.Fee = feeToken
-	pkgCreateCmd := prepare.PkgCreateCmd{c_type.RandUint256(), toPkr, asset, stringToUint512(args.Memo)}
+	pkgCreateCmd := prepare.PkgCreateCmd{c_type.RandUint256(), toPkr, asset, stringToUint512(args.Memo), nil}
 	txParam.Cmds.PkgCreate = &pkgCreateCmd
 	return
 
@@ -2074,7 +2218,7 @@ func (args *ClosePkgArgs) toTxParam(fromAccount accounts.Account) (txParam prepa
 	txParam.From = fromAccount.Address.ToUint512()
 	txParam.RefundTo = fromAccount.GetPkr(nil).NewRef()
 	txParam.Fee = feeToken
-	pkgCloseCmd := prepare.PkgCloseCmd{*args.PkgId, *args.Key}
+	pkgCloseCmd := prepare.PkgCloseCmd{*args.PkgId, *args.Key, nil, false}
 	txParam.Cmds.PkgClose = &pkgCloseCmd
 	return
 
@@ -0,0 +1,175 @@
+package ethapi
+
+import (
+	"context"
+	"math/big"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/dece-cash/go-dece/common"
+	"github.com/dece-cash/go-dece/common/address"
+	"github.com/dece-cash/go-dece/common/hexutil"
+	"github.com/dece-cash/go-dece/czero/c_type"
+	"github.com/dece-cash/go-dece/zero/txs/assets"
+	"github.com/dece-cash/go-dece/zero/utils"
+	"github.com/dece-cash/go-dece/zero/wallet/atomicswap"
+)
+
+// PrivateAtomicSwapAPI locks the DECE side of hash time locked swaps in
+// packages of the exchange accounts. It spends from the accounts, and is only
+// served where the private APIs are.
+type PrivateAtomicSwapAPI struct {
+	b Backend
+}
+
+func currentAtomicSwap() (*atomicswap.AtomicSwap, error) {
+	swap := atomicswap.CurrentAtomicSwap()
+	if swap == nil {
+		return nil, errors.New("exchange mode no start")
+	}
+	return swap, nil
+}
+
+func rpcSwap(swap *atomicswap.Swap) map[string]interface{} {
+	result := map[string]interface{}{
+		"id":       swap.Id,
+		"role":     swap.Role,
+		"state":    swap.State,
+		"pk":       address.PKAddress(swap.Pk),
+		"owner":    pkrToPKrAddress(swap.Owner),
+		"currency": strings.Trim(string(swap.Token.Currency[:]), zerobyte),
+		"value":    (*hexutil.Big)(swap.Token.Value.ToIntRef()),
+		"key":      swap.Key,
+		"hash":     swap.Lock.HashLock,
+		"expiry":   hexutil.Uint64(swap.Lock.Expiry),
+		"createTx": common.BytesToHash(swap.CreateTx[:]),
+	}
+	if swap.CloseTx != (c_type.Uint256{}) {
+		result["closeTx"] = common.BytesToHash(swap.CloseTx[:])
+	}
+	if swap.CloseNum != 0 {
+		result["closeNumber"] = hexutil.Uint64(swap.CloseNum)
+	}
+	if secret := swap.GetSecret(); secret != nil {
+		result["secret"] = *secret
+	}
+	return result
+}
+
+func swapToken(currency Smbol, value *Big) (token assets.Token, e error) {
+	if currency.IsEmpty() || value == nil || value.ToInt().Sign() <= 0 {
+		e = errors.New("swap currency and value can not be empty")
+		return
+	}
+	token = assets.Token{
+		utils.CurrencyToUint256(string(currency)),
+		utils.U256(*value.ToInt()),
+	}
+	return
+}
+
+// Initiate locks value of currency from pk for the counterparty to until the
+// expiry block, with the hash of a new secret kept by the swap.
+func (s *PrivateAtomicSwapAPI) Initiate(ctx context.Context, pk address.PKAddress, to AllMixedAddress, currency Smbol, value *Big, expiry hexutil.Uint64, gasPrice *hexutil.Big) (map[string]interface{}, error) {
+	swaps, err := currentAtomicSwap()
+	if err != nil {
+		return nil, err
+	}
+	token, err := swapToken(currency, value)
+	if err != nil {
+		return nil, err
+	}
+	swap, err := swaps.Initiate(pk.ToUint512().NewRef(), to.ToPKr(), token, uint64(expiry), (*big.Int)(gasPrice))
+	if err != nil {
+		return nil, err
+	}
+	return rpcSwap(swap), nil
+}
+
+// Participate locks value of currency from pk for the counterparty to until
+// the expiry block, with the hash of the secret of the counterparty.
+func (s *PrivateAtomicSwapAPI) Participate(ctx context.Context, pk address.PKAddress, to AllMixedAddress, currency Smbol, value *Big, hash c_type.Uint256, expiry hexutil.Uint64, gasPrice *hexutil.Big) (map[string]interface{}, error) {
+	swaps, err := currentAtomicSwap()
+	if err != nil {
+		return nil, err
+	}
+	token, err := swapToken(currency, value)
+	if err != nil {
+		return nil, err
+	}
+	swap, err := swaps.Participate(pk.ToUint512().NewRef(), to.ToPKr(), token, hash, uint64(expiry), (*big.Int)(gasPrice))
+	if err != nil {
+		return nil, err
+	}
+	return rpcSwap(swap), nil
+}
+
+// Audit checks the package created by the counterparty for pk against the
+// agreed hash and tracks it.
+func (s *PrivateAtomicSwapAPI) Audit(ctx context.Context, pk address.PKAddress, id c_type.Uint256, key c_type.Uint256, hash c_type.Uint256) (map[string]interface{}, error) {
+	swaps, err := currentAtomicSwap()
+	if err != nil {
+		return nil, err
+	}
+	swap, err := swaps.Audit(pk.ToUint512().NewRef(), &id, &key, hash)
+	if err != nil {
+		return nil, err
+	}
+	return rpcSwap(swap), nil
+}
+
+// Redeem closes the package of a received swap with the secret.
+func (s *PrivateAtomicSwapAPI) Redeem(ctx context.Context, id c_type.Uint256, secret c_type.Uint256, gasPrice *hexutil.Big) (common.Hash, error) {
+	swaps, err := currentAtomicSwap()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	hash, err := swaps.Redeem(&id, secret, (*big.Int)(gasPrice))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(hash[:]), nil
+}
+
+// Refund closes the package of an expired swap back to its creator.
+func (s *PrivateAtomicSwapAPI) Refund(ctx context.Context, id c_type.Uint256, gasPrice *hexutil.Big) (common.Hash, error) {
+	swaps, err := currentAtomicSwap()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	hash, err := swaps.Refund(&id, (*big.Int)(gasPrice))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(hash[:]), nil
+}
+
+func (s *PrivateAtomicSwapAPI) GetSwap(ctx context.Context, id c_type.Uint256) (map[string]interface{}, error) {
+	swaps, err := currentAtomicSwap()
+	if err != nil {
+		return nil, err
+	}
+	swap := swaps.GetSwap(&id)
+	if swap == nil {
+		return nil, nil
+	}
+	return rpcSwap(swap), nil
+}
+
+// GetSwaps lists the swaps of pk, or all of them when pk is not given.
+func (s *PrivateAtomicSwapAPI) GetSwaps(ctx context.Context, pk *address.PKAddress) ([]map[string]interface{}, error) {
+	swaps, err := currentAtomicSwap()
+	if err != nil {
+		return nil, err
+	}
+	var account *c_type.Uint512
+	if pk != nil {
+		account = pk.ToUint512().NewRef()
+	}
+	result := []map[string]interface{}{}
+	for _, swap := range swaps.GetSwaps(account) {
+		result = append(result, rpcSwap(&swap))
+	}
+	return result, nil
+}
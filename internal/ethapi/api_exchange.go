@@ -2,6 +2,7 @@ package ethapi
 
 import (
 	"context"
+	"strings"
 
 	"github.com/dece-cash/go-dece/zero/txtool/prepare"
 
@@ -580,3 +581,101 @@ func (s *PublicExchangeAPI) SetBalancePkr(ctx context.Context, pkr PKrAddress) e
 func (s *PublicExchangeAPI) IgnorePkrUtxos(ctx context.Context, pkr PKrAddress, ignore bool) (utxos []exchange.Utxo, e error) {
 	return exchange.CurrentExchange().IgnorePkrUtxos(*pkr.ToPKr(), ignore)
 }
+
+func rpcExchangePkg(p *exchange.Pkg) map[string]interface{} {
+	pkg := map[string]interface{}{}
+	pkg["id"] = p.Z.Pack.Id
+	pkg["to"] = pkrToPKrAddress(p.Z.Pack.PKr)
+	pkg["from"] = pkrToPKrAddress(p.Z.From)
+	pkg["created"] = hexutil.Uint64(p.Z.High)
+	pkg["closed"] = p.Z.Closed
+	if p.To != nil {
+		pkg["toPk"] = address.PKAddress(*p.To)
+	}
+	if p.From != nil {
+		pkg["fromPk"] = address.PKAddress(*p.From)
+	}
+	return pkg
+}
+
+// GetPkgs lists the packages received by pk, or created by pk when from is
+// set. Closed packages are only listed with closed set.
+func (s *PublicExchangeAPI) GetPkgs(ctx context.Context, pk address.PKAddress, from bool, closed bool) ([]map[string]interface{}, error) {
+	exchangeInstance := exchange.CurrentExchange()
+	if exchangeInstance == nil {
+		return nil, errors.New("exchange mode no start")
+	}
+	result := []map[string]interface{}{}
+	for _, p := range exchangeInstance.FindPkgs(pk.ToUint512().NewRef(), from) {
+		if p.Z.Closed && !closed {
+			continue
+		}
+		result = append(result, rpcExchangePkg(&p))
+	}
+	return result, nil
+}
+
+// GetPkgHistory returns the creation, transfers and closing of a package
+// indexed by the exchange.
+func (s *PublicExchangeAPI) GetPkgHistory(ctx context.Context, id c_type.Uint256) ([]map[string]interface{}, error) {
+	exchangeInstance := exchange.CurrentExchange()
+	if exchangeInstance == nil {
+		return nil, errors.New("exchange mode no start")
+	}
+	result := []map[string]interface{}{}
+	for _, event := range exchangeInstance.GetPkgHistory(&id) {
+		result = append(result, map[string]interface{}{
+			"num":    hexutil.Uint64(event.Num),
+			"action": event.Action,
+			"to":     pkrToPKrAddress(event.PKr),
+		})
+	}
+	return result, nil
+}
+
+// OpenPkg reads the content of an indexed package with its key, without
+// closing it.
+func (s *PublicExchangeAPI) OpenPkg(ctx context.Context, id c_type.Uint256, key c_type.Uint256) (map[string]interface{}, error) {
+	exchangeInstance := exchange.CurrentExchange()
+	if exchangeInstance == nil {
+		return nil, errors.New("exchange mode no start")
+	}
+	opkg, err := exchangeInstance.OpenPkg(&id, &key)
+	if err != nil {
+		return nil, err
+	}
+	asset := map[string]interface{}{}
+	if opkg.Asset.Tkn != nil {
+		asset["tkn"] = map[string]interface{}{
+			"currency": strings.Trim(string(opkg.Asset.Tkn.Currency[:]), zerobyte),
+			"value":    opkg.Asset.Tkn.Value,
+		}
+	}
+	if opkg.Asset.Tkt != nil {
+		asset["tkt"] = map[string]interface{}{
+			"category": strings.Trim(string(opkg.Asset.Tkt.Category[:]), zerobyte),
+			"value":    opkg.Asset.Tkt.Value,
+		}
+	}
+	return map[string]interface{}{
+		"id":    id,
+		"asset": asset,
+		"memo":  opkg.Memo,
+	}, nil
+}
+
+// ClosePkg builds, signs and sends the PkgClose transaction releasing the
+// content of a package received by pk.
+func (s *PublicExchangeAPI) ClosePkg(ctx context.Context, pk address.PKAddress, id c_type.Uint256, key c_type.Uint256, gasPrice *hexutil.Big) (common.Hash, error) {
+	exchangeInstance := exchange.CurrentExchange()
+	if exchangeInstance == nil {
+		return common.Hash{}, errors.New("exchange mode no start")
+	}
+	hash, err := exchangeInstance.ClosePkg(pk.ToUint512().NewRef(), &id, &key, nil, (*big.Int)(gasPrice))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	txhash := common.Hash{}
+	copy(txhash[:], hash[:])
+	return txhash, nil
+}
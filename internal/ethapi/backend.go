@@ -79,6 +79,9 @@ type Backend interface {
 	Stats() (pending int, queued int)
 	TxPoolContent() (types.Transactions, types.Transactions)
 	SubscribeNewTxsEvent(chan<- core.NewTxsEvent) event.Subscription
+	TxPoolReplacement(hash common.Hash) (common.Hash, bool)
+	TxPoolGasPrice() *big.Int
+	SubscribeReplacedTxEvent(chan<- core.ReplacedTxEvent) event.Subscription
 
 	ChainConfig() *params.ChainConfig
 	CurrentBlock() *types.Block
@@ -155,6 +158,12 @@ func GetAPIs(apiBackend Backend) []rpc.API {
 			Service:   &PublicExchangeAPI{apiBackend},
 			Public:    true,
 		},
+		{
+			Namespace: "swap",
+			Version:   "1.0",
+			Service:   &PrivateAtomicSwapAPI{apiBackend},
+			Public:    false,
+		},
 		{
 			Namespace: "dece",
 			Version:   "1.0",
@@ -0,0 +1,187 @@
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/dece-cash/go-dece/common/hexutil"
+	"github.com/dece-cash/go-dece/consensus/ethash"
+	"github.com/dece-cash/go-dece/core/rawdb"
+	"github.com/dece-cash/go-dece/core/types"
+	"github.com/dece-cash/go-dece/decedb"
+	"github.com/dece-cash/go-dece/rpc"
+	"github.com/dece-cash/go-dece/zero/stake"
+)
+
+const (
+	// maxEmissionProjection bounds the blocks past the head of the chain
+	// projected by dece_emissionSchedule (about a year of blocks).
+	maxEmissionProjection = 1 << 21
+
+	// maxEmissionPeriods bounds the periods returned by dece_emissionSchedule.
+	maxEmissionPeriods = 10000
+)
+
+// RPCEmission is the DECE emitted by a period of blocks, the fees of their
+// transactions excluded. Pow is paid to the coinbases of the blocks, Community
+// to the community pool, Solo and Pool to the solo and pool votes. No team
+// reward is minted by the chain any more, Team is always zero.
+//
+// The blocks past the head are projected, a period holding some of them being
+// marked Projected: their votes are assumed to be all cast by pools.
+//
+// Supply is the DECE in existence at the end of the period, minted less burned,
+// as recorded by the supply ledger of dece_getSupply. Past the head, it is the
+// supply of the head plus the projected emission. It is omitted when the ledger
+// of the block was not recorded, gece checksupply filling it in.
+type RPCEmission struct {
+	From      hexutil.Uint64 `json:"from"`
+	To        hexutil.Uint64 `json:"to"`
+	Projected bool           `json:"projected"`
+	Pow       *hexutil.Big   `json:"pow"`
+	Community *hexutil.Big   `json:"community"`
+	Solo      *hexutil.Big   `json:"solo"`
+	Pool      *hexutil.Big   `json:"pool"`
+	Team      *hexutil.Big   `json:"team"`
+	Total     *hexutil.Big   `json:"total"`
+	Supply    *hexutil.Big   `json:"supply,omitempty"`
+}
+
+func newRPCEmission(from, to uint64) *RPCEmission {
+	return &RPCEmission{
+		From:      hexutil.Uint64(from),
+		To:        hexutil.Uint64(to),
+		Pow:       new(hexutil.Big),
+		Community: new(hexutil.Big),
+		Solo:      new(hexutil.Big),
+		Pool:      new(hexutil.Big),
+		Team:      new(hexutil.Big),
+		Total:     new(hexutil.Big),
+	}
+}
+
+func addBig(sum *hexutil.Big, x *big.Int) {
+	(*big.Int)(sum).Add((*big.Int)(sum), x)
+}
+
+// EmissionSchedule returns the DECE emitted from block from to block to, both
+// included, in periods of granularity blocks, the whole range when it is zero.
+// The rewards are computed by the rules of the consensus, from the headers of
+// the chain up to its head and projected beyond.
+func (s *PublicBlockChainAPI) EmissionSchedule(ctx context.Context, from, to hexutil.Uint64, granularity *hexutil.Uint64) ([]*RPCEmission, error) {
+	if to < from {
+		return nil, errors.New("to is before from")
+	}
+	period := uint64(to-from) + 1
+	if granularity != nil && *granularity > 0 && uint64(*granularity) < period {
+		period = uint64(*granularity)
+	}
+	if (uint64(to-from)+period)/period > maxEmissionPeriods {
+		return nil, errors.New("too many periods, increase the granularity")
+	}
+
+	state, head, err := s.b.StateAndHeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	if uint64(to) > head.Number.Uint64()+maxEmissionProjection {
+		return nil, errors.New("to is too far in the future")
+	}
+	var (
+		st             = stake.NewStakeState(state)
+		headerByNumber = func(number uint64) (*types.Header, error) {
+			header, err := s.b.HeaderByNumber(ctx, rpc.BlockNumber(number))
+			if header == nil && err == nil {
+				err = fmt.Errorf("header #%d not found", number)
+			}
+			return header, err
+		}
+		db         = s.b.ChainDb()
+		number     = head.Number.Uint64()
+		headSupply = ledgerSupply(db, head)
+		results    []*RPCEmission
+	)
+	for start := uint64(from); ; start += period {
+		end := start + period - 1
+		if end > uint64(to) || end < start {
+			end = uint64(to)
+		}
+		current, err := emission(st, number, headerByNumber, start, end)
+		if err != nil {
+			return nil, err
+		}
+		if end <= number {
+			header, err := headerByNumber(end)
+			if err != nil {
+				return nil, err
+			}
+			current.Supply = (*hexutil.Big)(ledgerSupply(db, header))
+		} else if headSupply != nil {
+			projected, err := emission(st, number, headerByNumber, number+1, end)
+			if err != nil {
+				return nil, err
+			}
+			current.Supply = (*hexutil.Big)(new(big.Int).Add(headSupply, (*big.Int)(projected.Total)))
+		}
+		results = append(results, current)
+		if end == uint64(to) {
+			return results, nil
+		}
+	}
+}
+
+// emission returns the DECE emitted by the blocks from from to to, both
+// included, its supply left unset. The block rewards are summed in closed
+// form, the headers up to the head being only read while their votes are
+// rewarded.
+func emission(st *stake.StakeState, head uint64, headerByNumber func(uint64) (*types.Header, error), from, to uint64) (*RPCEmission, error) {
+	result := newRPCEmission(from, to)
+	result.Projected = to > head
+	if from == 0 {
+		from = 1
+	}
+	if to < from {
+		return result, nil
+	}
+	coinbase, community := ethash.BlockRewardsRange(from, to)
+	addBig(result.Pow, coinbase)
+	addBig(result.Community, community)
+	if soloReward, reward := st.StakeRewardsRange(from, to); soloReward.Sign() > 0 || reward.Sign() > 0 {
+		for number := from; number <= to && number <= head; number++ {
+			header, err := headerByNumber(number)
+			if err != nil {
+				return nil, err
+			}
+			solo, pool := st.VoteRewards(header)
+			addBig(result.Solo, solo)
+			addBig(result.Pool, pool)
+		}
+		if to > head {
+			projected := from
+			if projected <= head {
+				projected = head + 1
+			}
+			_, reward := st.StakeRewardsRange(projected, to)
+			addBig(result.Pool, reward.Mul(reward, big.NewInt(stake.MaxVoteCount)))
+		}
+	}
+	for _, x := range []*hexutil.Big{result.Pow, result.Community, result.Solo, result.Pool} {
+		addBig(result.Total, (*big.Int)(x))
+	}
+	return result, nil
+}
+
+// ledgerSupply returns the DECE in existence up to the block of header, as
+// recorded by the supply ledger, nil if the ledger of the block is missing.
+func ledgerSupply(db decedb.Database, header *types.Header) *big.Int {
+	supply := rawdb.ReadSupply(db, header.Hash(), header.Number.Uint64())
+	if supply == nil {
+		return nil
+	}
+	if cs := supply.Get("DECE"); cs != nil {
+		return cs.Total()
+	}
+	return new(big.Int)
+}
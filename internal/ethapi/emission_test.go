@@ -0,0 +1,201 @@
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/dece-cash/go-dece/common/hexutil"
+	"github.com/dece-cash/go-dece/consensus/ethash"
+	"github.com/dece-cash/go-dece/core/rawdb"
+	"github.com/dece-cash/go-dece/core/state"
+	"github.com/dece-cash/go-dece/core/types"
+	"github.com/dece-cash/go-dece/czero/deceparam"
+	"github.com/dece-cash/go-dece/decedb"
+	"github.com/dece-cash/go-dece/rpc"
+	"github.com/dece-cash/go-dece/zero/stake"
+)
+
+func newEmissionState(t *testing.T) *stake.StakeState {
+	statedb, err := state.New(state.NewDatabase(decedb.NewMemDatabase()), nil)
+	if err != nil {
+		t.Fatalf("failed to create state: %v", err)
+	}
+	return stake.NewStakeState(statedb)
+}
+
+// blockEmission sums the rewards of the blocks from from to to one by one, as
+// the consensus pays them.
+func blockEmission(st *stake.StakeState, from, to uint64) (pow, community, votes *big.Int) {
+	pow, community, votes = new(big.Int), new(big.Int), new(big.Int)
+	for number := from; number <= to; number++ {
+		if number == 0 {
+			continue
+		}
+		num := new(big.Int).SetUint64(number)
+		coinbase, reward := ethash.BlockRewards(num)
+		pow.Add(pow, coinbase)
+		community.Add(community, reward)
+		_, vote := st.StakeCurrentReward(num)
+		votes.Add(votes, vote.Mul(vote, big.NewInt(stake.MaxVoteCount)))
+	}
+	return
+}
+
+func TestEmissionForkBoundaries(t *testing.T) {
+	defer deceparam.Init_Dev(deceparam.Is_Dev())
+
+	var (
+		st       = newEmissionState(t)
+		halve    = uint64(3057600)
+		interval = uint64(8294400)
+		noHeader = func(number uint64) (*types.Header, error) {
+			return nil, errors.New("no header")
+		}
+	)
+	boundaries := []uint64{
+		1,
+		deceparam.SIP1(), deceparam.SIP2(), deceparam.SIP3(),
+		deceparam.SIP4(), deceparam.SIP5(), deceparam.SIP6(),
+		halve, halve + interval, halve + 2*interval,
+	}
+	for _, dev := range []bool{false, true} {
+		deceparam.Init_Dev(dev)
+		for _, boundary := range boundaries {
+			from, to := boundary-1, boundary+1
+			pow, community, votes := blockEmission(st, from, to)
+
+			result, err := emission(st, 0, noHeader, from, to)
+			if err != nil {
+				t.Fatalf("dev %v, blocks %d-%d: %v", dev, from, to, err)
+			}
+			if (*big.Int)(result.Pow).Cmp(pow) != 0 {
+				t.Errorf("dev %v, blocks %d-%d: pow mismatch: have %v, want %v", dev, from, to, result.Pow, pow)
+			}
+			if (*big.Int)(result.Community).Cmp(community) != 0 {
+				t.Errorf("dev %v, blocks %d-%d: community mismatch: have %v, want %v", dev, from, to, result.Community, community)
+			}
+			if (*big.Int)(result.Pool).Cmp(votes) != 0 {
+				t.Errorf("dev %v, blocks %d-%d: pool mismatch: have %v, want %v", dev, from, to, result.Pool, votes)
+			}
+			total := new(big.Int).Add(pow, community)
+			if (*big.Int)(result.Total).Cmp(total.Add(total, votes)) != 0 {
+				t.Errorf("dev %v, blocks %d-%d: total mismatch: have %v, want %v", dev, from, to, result.Total, total)
+			}
+			if !result.Projected {
+				t.Errorf("dev %v, blocks %d-%d: not projected past the head", dev, from, to)
+			}
+		}
+	}
+}
+
+func TestEmissionSplit(t *testing.T) {
+	var (
+		st       = newEmissionState(t)
+		noHeader = func(number uint64) (*types.Header, error) {
+			return nil, errors.New("no header")
+		}
+		to = uint64(3057600 + 8294400 + 1)
+	)
+	whole, err := emission(st, 0, noHeader, 0, to)
+	if err != nil {
+		t.Fatalf("failed to compute the emission: %v", err)
+	}
+	for _, at := range []uint64{0, 1, 3057600, 3057600 + 8294400} {
+		first, err := emission(st, 0, noHeader, 0, at)
+		if err != nil {
+			t.Fatalf("failed to compute the emission to %d: %v", at, err)
+		}
+		second, err := emission(st, 0, noHeader, at+1, to)
+		if err != nil {
+			t.Fatalf("failed to compute the emission from %d: %v", at+1, err)
+		}
+		sum := new(big.Int).Add((*big.Int)(first.Total), (*big.Int)(second.Total))
+		if sum.Cmp((*big.Int)(whole.Total)) != 0 {
+			t.Errorf("split at %d: total mismatch: have %v, want %v", at, sum, whole.Total)
+		}
+	}
+}
+
+// emissionBackend serves a chain of headers whose supply ledgers are recorded
+// in db.
+type emissionBackend struct {
+	Backend
+	db      decedb.Database
+	statedb *state.StateDB
+	headers []*types.Header
+}
+
+func (b *emissionBackend) ChainDb() decedb.Database { return b.db }
+
+func (b *emissionBackend) StateAndHeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
+	return b.statedb, b.headers[len(b.headers)-1], nil
+}
+
+func (b *emissionBackend) HeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Header, error) {
+	if int(blockNr) < len(b.headers) {
+		return b.headers[blockNr], nil
+	}
+	return nil, nil
+}
+
+// Tests that the supply of the emission schedule is the one of the ledger up to
+// the head, and the supply of the head plus the projected emission beyond.
+func TestEmissionSupply(t *testing.T) {
+	statedb, _ := state.New(state.NewDatabase(decedb.NewMemDatabase()), nil)
+	b := &emissionBackend{db: decedb.NewMemDatabase(), statedb: statedb}
+
+	var (
+		supply types.Supply
+		want   []*big.Int
+	)
+	for number := int64(0); number < 3; number++ {
+		header := &types.Header{Number: big.NewInt(number)}
+		if number > 0 {
+			header.ParentHash = b.headers[number-1].Hash()
+		}
+		b.headers = append(b.headers, header)
+
+		// Block n mints 100(n+1) DECE and burns 10
+		supply = supply.Apply(map[string]*big.Int{"DECE": big.NewInt(100 * (number + 1))}, map[string]*big.Int{"DECE": big.NewInt(10)})
+		rawdb.WriteSupply(b.db, header.Hash(), header.Number.Uint64(), supply)
+		want = append(want, supply.Get("DECE").Total())
+	}
+	st := stake.NewStakeState(statedb)
+	for _, end := range []uint64{3, 4} {
+		projected, err := emission(st, 2, nil, 3, end)
+		if err != nil {
+			t.Fatalf("failed to project the emission to %d: %v", end, err)
+		}
+		want = append(want, new(big.Int).Add(want[2], (*big.Int)(projected.Total)))
+	}
+
+	api := NewPublicBlockChainAPI(b)
+	granularity := hexutil.Uint64(1)
+	results, err := api.EmissionSchedule(context.Background(), 0, 4, &granularity)
+	if err != nil {
+		t.Fatalf("failed to get the emission schedule: %v", err)
+	}
+	if len(results) != len(want) {
+		t.Fatalf("periods mismatch: have %d, want %d", len(results), len(want))
+	}
+	for i, result := range results {
+		if result.Supply == nil || (*big.Int)(result.Supply).Cmp(want[i]) != 0 {
+			t.Errorf("block %d: supply mismatch: have %v, want %v", i, result.Supply, want[i])
+		}
+		if result.Projected != (i > 2) {
+			t.Errorf("block %d: projected mismatch: have %v", i, result.Projected)
+		}
+	}
+
+	// Without a ledger, the supply is omitted
+	rawdb.DeleteSupply(b.db, b.headers[1].Hash(), 1)
+	results, err = api.EmissionSchedule(context.Background(), 1, 1, nil)
+	if err != nil {
+		t.Fatalf("failed to get the emission schedule: %v", err)
+	}
+	if results[0].Supply != nil {
+		t.Errorf("supply without a ledger: have %v", results[0].Supply)
+	}
+}
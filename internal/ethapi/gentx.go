@@ -19,8 +19,10 @@ import (
 )
 
 type PkgCloseArgs struct {
-	Id  c_type.Uint256
-	Key c_type.Uint256
+	Id       c_type.Uint256
+	Key      c_type.Uint256
+	Preimage *c_type.Uint256
+	Refund   bool
 }
 
 func (self *PkgCloseArgs) toCmd() *prepare.PkgCloseCmd {
@@ -30,6 +32,8 @@ func (self *PkgCloseArgs) toCmd() *prepare.PkgCloseCmd {
 	return &prepare.PkgCloseCmd{
 		self.Id,
 		self.Key,
+		self.Preimage,
+		self.Refund,
 	}
 }
 
@@ -54,6 +58,24 @@ type PkgCreateArgs struct {
 	Currency Smbol
 	Value    *Big
 	Memo     c_type.Uint512
+	Lock     *PkgLockArgs
+}
+
+type PkgLockArgs struct {
+	Height   hexutil.Uint64
+	HashLock c_type.Uint256
+	Expiry   hexutil.Uint64
+}
+
+func (self *PkgLockArgs) toLock() *stx.PkgLock {
+	if self == nil {
+		return nil
+	}
+	return &stx.PkgLock{
+		uint64(self.Height),
+		self.HashLock,
+		uint64(self.Expiry),
+	}
 }
 
 func (self *PkgCreateArgs) toCmd() *prepare.PkgCreateCmd {
@@ -72,6 +94,7 @@ func (self *PkgCreateArgs) toCmd() *prepare.PkgCreateCmd {
 		self.PKr.ToPKr(),
 		asset,
 		self.Memo,
+		self.Lock.toLock(),
 	}
 }
 
@@ -0,0 +1,191 @@
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/dece-cash/go-dece/common"
+	"github.com/dece-cash/go-dece/common/hexutil"
+	"github.com/dece-cash/go-dece/consensus"
+	"github.com/dece-cash/go-dece/core"
+	"github.com/dece-cash/go-dece/core/types"
+	"github.com/dece-cash/go-dece/core/vm"
+	"github.com/dece-cash/go-dece/czero/c_type"
+	"github.com/dece-cash/go-dece/rpc"
+	"github.com/dece-cash/go-dece/zero/txtool"
+	"github.com/dece-cash/go-dece/zero/txtool/verify"
+)
+
+// The checks a simulated transaction goes through, in order. The name of the
+// first failing one is reported in SimulateResult.Check.
+const (
+	simCheckSize      = "size"
+	simCheckGas       = "gas"
+	simCheckFee       = "fee"
+	simCheckProof     = "proof"
+	simCheckCmd       = "cmd"
+	simCheckState     = "state"
+	simCheckExecution = "execution"
+)
+
+// RPCPkgChange is a package created, transferred or closed by a transaction.
+type RPCPkgChange struct {
+	Id     c_type.Uint256 `json:"id"`
+	Action string         `json:"action"`
+}
+
+// SimulateResult is the verdict of dece_simulateTx. When the transaction is
+// rejected, Check names the failing check and Error carries its reason.
+type SimulateResult struct {
+	Hash     common.Hash     `json:"hash"`
+	Accepted bool            `json:"accepted"`
+	Check    string          `json:"check,omitempty"`
+	Error    string          `json:"error,omitempty"`
+	Replaces []common.Hash   `json:"replaces,omitempty"`
+	Zero     *RPCZero        `json:"zero"`
+	Pkgs     []RPCPkgChange  `json:"pkgs,omitempty"`
+	GasLimit hexutil.Uint64  `json:"gasLimit"`
+	GasUsed  hexutil.Uint64  `json:"gasUsed"`
+	Failed   bool            `json:"failed"`
+	Contract *common.Address `json:"contractAddress,omitempty"`
+	Logs     []*types.Log    `json:"logs"`
+}
+
+func (r *SimulateResult) reject(check string, err error) *SimulateResult {
+	r.Check = check
+	r.Error = err.Error()
+	return r
+}
+
+// simChain resolves the headers needed by the EVM of a simulated transaction.
+type simChain struct {
+	ctx context.Context
+	b   Backend
+}
+
+func (c *simChain) Engine() consensus.Engine {
+	return c.b.GetEngin()
+}
+
+func (c *simChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	header, err := c.b.HeaderByNumber(c.ctx, rpc.BlockNumber(number))
+	if err != nil || header == nil || header.Hash() != hash {
+		return nil
+	}
+	return header
+}
+
+// SimulateTx checks whether the signed transaction gtx would be accepted on top
+// of the block blockNr, without sending it to the pool or the network. It goes
+// through the same checks as the transaction pool and executes the transaction
+// on a copy of the state, returning the outputs and logs it would produce.
+func (s *PublicTransactionPoolAPI) SimulateTx(ctx context.Context, gtx txtool.GTx, blockNr rpc.BlockNumber) (*SimulateResult, error) {
+	statedb, parent, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if statedb == nil || err != nil {
+		return nil, err
+	}
+	gasPrice := big.Int(gtx.GasPrice)
+	tx := types.NewTxWithGTx(uint64(gtx.Gas), &gasPrice, &gtx.Tx)
+	ztx := tx.GetZZSTX()
+
+	result := &SimulateResult{
+		Hash: tx.Hash(),
+		Zero: newRPCZero(ztx, nil),
+		Logs: []*types.Log{},
+	}
+	if pkg := ztx.Desc_Pkg.Create; pkg != nil {
+		result.Pkgs = append(result.Pkgs, RPCPkgChange{pkg.Id, "create"})
+	}
+	if pkg := ztx.Desc_Pkg.Transfer; pkg != nil {
+		result.Pkgs = append(result.Pkgs, RPCPkgChange{pkg.Id, "transfer"})
+	}
+	if pkg := ztx.Desc_Pkg.Close; pkg != nil {
+		result.Pkgs = append(result.Pkgs, RPCPkgChange{pkg.Id, "close"})
+	}
+
+	if tx.Size() > 3200*1024 {
+		return result.reject(simCheckSize, core.ErrOversizedData), nil
+	}
+	// The fee is checked as the pool does, against its minimum gas price
+	gasLimit, err := core.ValidateFee(tx, statedb, parent.GasLimit, s.b.TxPoolGasPrice())
+	if err == core.ErrGasLimit || err == core.ErrIntrinsicGas {
+		return result.reject(simCheckGas, err), nil
+	}
+	if err != nil {
+		return result.reject(simCheckFee, err), nil
+	}
+	result.GasLimit = hexutil.Uint64(gasLimit)
+	if !tx.IsOpContract() && len(tx.Data()) > 0 {
+		return result.reject(simCheckFee, errors.New("not create or call contract tx payload must be nil")), nil
+	}
+
+	// The transaction is checked as a part of the block following the parent
+	num := parent.Number.Uint64() + 1
+	if err := verify.VerifyWithoutState(tx.Ehash().NewRef(), ztx, num); err != nil {
+		return result.reject(simCheckProof, err), nil
+	}
+	if err := core.CheckDescCmd(ztx, statedb, num); err != nil {
+		return result.reject(simCheckCmd, err), nil
+	}
+	zstate := statedb.NextZState()
+	if err := verify.VerifyWithState(ztx, zstate, num); err != nil {
+		return result.reject(simCheckState, err), nil
+	}
+	result.Replaces = s.poolConflicts(tx)
+
+	// Execute the transaction in a block on top of the parent
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		GasLimit:   parent.GasLimit,
+		Time:       big.NewInt(time.Now().Unix()),
+		Coinbase:   parent.Coinbase,
+		Difficulty: parent.Difficulty,
+	}
+	if header.Time.Cmp(parent.Time) <= 0 {
+		header.Time = new(big.Int).Add(parent.Time, common.Big1)
+	}
+	rootsBefore := len(zstate.State.GetBlockRoots())
+
+	var usedGas uint64
+	gp := new(core.GasPool).AddGas(header.GasLimit)
+	receipt, _, err := core.ApplyTransaction(s.b.ChainConfig(), &simChain{ctx, s.b}, nil, gp, statedb, header, tx, &usedGas, vm.Config{})
+	if err != nil {
+		return result.reject(simCheckExecution, err), nil
+	}
+	result.Accepted = true
+	result.Zero.Roots = zstate.State.GetBlockRoots()[rootsBefore:]
+	result.GasUsed = hexutil.Uint64(receipt.GasUsed)
+	result.Failed = receipt.Status == types.ReceiptStatusFailed
+	if receipt.ContractAddress != (common.Address{}) {
+		result.Contract = &receipt.ContractAddress
+	}
+	if receipt.Logs != nil {
+		result.Logs = receipt.Logs
+	}
+	return result, nil
+}
+
+// poolConflicts returns the pooled transactions spending the same nils as tx,
+// which tx would have to outbid to enter the pool.
+func (s *PublicTransactionPoolAPI) poolConflicts(tx *types.Transaction) (hashes []common.Hash) {
+	spends := make(map[c_type.Uint256]struct{})
+	for _, in := range core.TxSpends(tx) {
+		spends[in] = struct{}{}
+	}
+	pending, queued := s.b.TxPoolContent()
+	for _, pooled := range append(pending, queued...) {
+		if pooled.Hash() == tx.Hash() {
+			continue
+		}
+		for _, in := range core.TxSpends(pooled) {
+			if _, ok := spends[in]; ok {
+				hashes = append(hashes, pooled.Hash())
+				break
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,250 @@
+package ethapi
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dece-cash/go-dece/common"
+	"github.com/dece-cash/go-dece/common/hexutil"
+	"github.com/dece-cash/go-dece/consensus"
+	"github.com/dece-cash/go-dece/consensus/ethash"
+	"github.com/dece-cash/go-dece/core/state"
+	"github.com/dece-cash/go-dece/core/types"
+	"github.com/dece-cash/go-dece/czero/c_type"
+	"github.com/dece-cash/go-dece/czero/superzk"
+	"github.com/dece-cash/go-dece/decedb"
+	"github.com/dece-cash/go-dece/params"
+	"github.com/dece-cash/go-dece/rpc"
+	"github.com/dece-cash/go-dece/zero/localdb"
+	"github.com/dece-cash/go-dece/zero/txs/assets"
+	"github.com/dece-cash/go-dece/zero/txs/stx"
+	"github.com/dece-cash/go-dece/zero/txs/stx/tx"
+	"github.com/dece-cash/go-dece/zero/txtool"
+	"github.com/dece-cash/go-dece/zero/txtool/flight"
+	"github.com/dece-cash/go-dece/zero/utils"
+)
+
+// simBackend serves a single head and its state to dece_simulateTx.
+type simBackend struct {
+	Backend
+	statedb  *state.StateDB
+	header   *types.Header
+	gasPrice *big.Int
+}
+
+func (b *simBackend) StateAndHeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
+	return b.statedb.CopyWithNoZState(), b.header, nil
+}
+
+func (b *simBackend) HeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Header, error) {
+	return b.header, nil
+}
+
+func (b *simBackend) TxPoolGasPrice() *big.Int { return b.gasPrice }
+
+func (b *simBackend) TxPoolContent() (types.Transactions, types.Transactions) { return nil, nil }
+
+func (b *simBackend) ChainConfig() *params.ChainConfig { return params.TestChainConfig }
+
+func (b *simBackend) GetEngin() consensus.Engine { return ethash.NewFaker() }
+
+// simAccount is a key owning outputs recorded in the state of a simBackend.
+type simAccount struct {
+	sk  c_type.Uint512
+	pkr c_type.PKr
+}
+
+func newSimAccount(seed byte) *simAccount {
+	a := &simAccount{sk: superzk.Seed2Sk(&c_type.Uint256{seed})}
+	tk, _ := superzk.Sk2Tk(&a.sk)
+	pk, _ := superzk.Tk2Pk(&tk)
+	a.pkr = superzk.Pk2PKr(&pk, &c_type.Uint256{1})
+	return a
+}
+
+func newSimBackend() *simBackend {
+	statedb, _ := state.New(state.NewDatabase(decedb.NewMemDatabase()), nil)
+	return &simBackend{
+		statedb: statedb,
+		header: &types.Header{
+			Number:     big.NewInt(1),
+			Difficulty: big.NewInt(1),
+			Time:       big.NewInt(time.Now().Unix()),
+			GasLimit:   params.GenesisGasLimit,
+		},
+		gasPrice: big.NewInt(params.Gta),
+	}
+}
+
+// record persists the outputs and nils added to the zero state.
+func (b *simBackend) record() {
+	zst := b.statedb.NextZState()
+	zst.Update()
+	zst.RecordBlock(b.statedb.Database().TrieDB().WDiskDB(), &c_type.Uint256{})
+}
+
+// fund gives the account an output of value in currency.
+func (b *simBackend) fund(a *simAccount, currency string, value *big.Int) txtool.Out {
+	out := &tx.Out_P{PKr: a.pkr, Asset: simAsset(currency, value)}
+	zst := b.statedb.NextZState()
+	root := zst.State.AddOut_P(out, &c_type.Uint256{byte(len(zst.State.GetBlockRoots()) + 1)})
+	b.record()
+	return txtool.Out{Root: root, State: localdb.RootState{OS: *zst.State.GetOut(&root)}}
+}
+
+func simAsset(currency string, value *big.Int) assets.Asset {
+	return assets.Asset{Tkn: &assets.Token{Currency: utils.CurrencyToUint256(currency), Value: utils.U256(*value)}}
+}
+
+// spend signs a transaction of the account spending in, paying the fee in the
+// currency of in and sending the change back to the account.
+func (a *simAccount) spend(t *testing.T, in txtool.Out, cmds txtool.Cmds) txtool.GTx {
+	var (
+		token = in.State.OS.Out_P.Asset.Tkn
+		fee   = new(big.Int).Mul(big.NewInt(25000), big.NewInt(params.Gta))
+		left  = new(big.Int).Sub(token.Value.ToInt(), fee)
+	)
+	if cmds.Contract != nil {
+		left.Sub(left, cmds.Contract.Asset.Tkn.Value.ToInt())
+	}
+	param := &txtool.GTxParam{
+		Gas:      25000,
+		GasPrice: big.NewInt(params.Gta),
+		Fee:      assets.Token{Currency: token.Currency, Value: utils.U256(*fee)},
+		From:     txtool.Kr{PKr: a.pkr},
+		Ins:      []txtool.GIn{{Out: in}},
+		Outs:     []txtool.GOut{{PKr: a.pkr, Asset: assets.Asset{Tkn: &assets.Token{Currency: token.Currency, Value: utils.U256(*left)}}}},
+		Cmds:     cmds,
+		Z:        new(bool),
+	}
+	gtx, err := flight.SignTx(&a.sk, param)
+	if err != nil {
+		t.Fatalf("failed to sign the spend of %x: %v", in.Root, err)
+	}
+	return gtx
+}
+
+// unsignedTx builds a transaction paying fee DECE at the given gas price,
+// stopped by any check up to the proofs.
+func unsignedTx(fee *big.Int, price int64, data []byte) txtool.GTx {
+	gtx := txtool.GTx{Gas: 25000, GasPrice: hexutil.Big(*big.NewInt(price))}
+	gtx.Tx.Fee = assets.Token{Currency: utils.CurrencyToUint256("DECE"), Value: utils.U256(*fee)}
+	if data != nil {
+		gtx.Tx.Desc_Cmd.Contract = &stx.ContractCmd{To: &c_type.PKr{1}, Data: data}
+	}
+	return gtx
+}
+
+// Tests that dece_simulateTx reports the first check a transaction fails, the
+// fee being checked against the minimum gas price of the pool, and executes the
+// transactions passing all of them.
+func TestSimulateTx(t *testing.T) {
+	superzk.ZeroInit_NoCircuit()
+
+	var (
+		b        = newSimBackend()
+		api      = NewPublicTransactionPoolAPI(b, nil)
+		owner    = newSimAccount(1)
+		contract = newSimAccount(2)
+		value    = new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Gta*25000))
+		fee      = new(big.Int).Mul(big.NewInt(25000), big.NewInt(params.Gta))
+	)
+	// A token paying the fees of its contract, which has no DECE to pay them
+	contractAddr := common.BytesToAddress(contract.pkr[:])
+	b.statedb.RegisterToken(contractAddr, "ABC")
+	b.statedb.SetTokenRate(contractAddr, "ABC", big.NewInt(1), big.NewInt(1))
+
+	spent := owner.spend(t, b.fund(owner, "DECE", value), txtool.Cmds{})
+	if err := b.statedb.NextZState().AddStx(&spent.Tx); err != nil {
+		t.Fatalf("failed to spend the output: %v", err)
+	}
+	b.record()
+
+	tests := []struct {
+		name  string
+		gtx   txtool.GTx
+		check string
+		err   string
+	}{
+		{
+			name:  "oversized",
+			gtx:   unsignedTx(fee, params.Gta, make([]byte, 3300*1024)),
+			check: simCheckSize,
+			err:   "oversized data",
+		},
+		{
+			name:  "over block gas limit",
+			gtx:   unsignedTx(new(big.Int).Mul(big.NewInt(int64(params.GenesisGasLimit)+1), big.NewInt(params.Gta)), params.Gta, nil),
+			check: simCheckGas,
+			err:   "exceeds block gas limit",
+		},
+		{
+			name:  "under pool gas price",
+			gtx:   unsignedTx(big.NewInt(25000*(params.Gta-1)), params.Gta-1, nil),
+			check: simCheckFee,
+			err:   "transaction underpriced",
+		},
+		{
+			name:  "unsigned",
+			gtx:   unsignedTx(fee, params.Gta, nil),
+			check: simCheckProof,
+			err:   "ehash error",
+		},
+		{
+			name:  "closing a missing pool",
+			gtx:   owner.spend(t, b.fund(owner, "DECE", value), txtool.Cmds{ClosePool: &stx.ClosePoolCmd{}}),
+			check: simCheckCmd,
+			err:   "pool is not exist",
+		},
+		{
+			name:  "double spend",
+			gtx:   spent,
+			check: simCheckState,
+			err:   "already in nils",
+		},
+		{
+			name: "contract fee unpaid",
+			gtx: owner.spend(t, b.fund(owner, "ABC", value), txtool.Cmds{Contract: &stx.ContractCmd{
+				Asset: simAsset("ABC", big.NewInt(1)),
+				To:    &contract.pkr,
+			}}),
+			check: simCheckExecution,
+			err:   "insufficient balance to pay for gas",
+		},
+	}
+	for _, test := range tests {
+		result, err := api.SimulateTx(context.Background(), test.gtx, rpc.LatestBlockNumber)
+		if err != nil {
+			t.Errorf("%s: simulation failed: %v", test.name, err)
+			continue
+		}
+		if result.Accepted {
+			t.Errorf("%s: transaction accepted", test.name)
+		}
+		if result.Check != test.check || !strings.Contains(result.Error, test.err) {
+			t.Errorf("%s: rejection mismatch: have %s (%s), want %s (%s)", test.name, result.Check, result.Error, test.check, test.err)
+		}
+	}
+
+	// A plain spend goes through every check and is executed
+	gtx := owner.spend(t, b.fund(owner, "DECE", value), txtool.Cmds{})
+	result, err := api.SimulateTx(context.Background(), gtx, rpc.LatestBlockNumber)
+	if err != nil {
+		t.Fatalf("simulation failed: %v", err)
+	}
+	if !result.Accepted || result.Check != "" {
+		t.Fatalf("transaction rejected by the %s check: %s", result.Check, result.Error)
+	}
+	if result.GasLimit != 25000 {
+		t.Errorf("gas limit mismatch: have %d, want 25000", result.GasLimit)
+	}
+	if result.GasUsed == 0 || result.Failed {
+		t.Errorf("execution mismatch: used %d gas, failed %v", result.GasUsed, result.Failed)
+	}
+	if len(result.Zero.Roots) != 1 {
+		t.Errorf("created outputs mismatch: have %d, want 1", len(result.Zero.Roots))
+	}
+}
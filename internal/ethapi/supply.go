@@ -0,0 +1,59 @@
+package ethapi
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/dece-cash/go-dece/common/hexutil"
+	"github.com/dece-cash/go-dece/consensus/ethash"
+	"github.com/dece-cash/go-dece/core/rawdb"
+	"github.com/dece-cash/go-dece/rpc"
+)
+
+// RPCSupply is the amount of a currency issued by the chain up to a block.
+// Circulating is the Total less the rewards held back by the chain, only
+// reported for DECE.
+type RPCSupply struct {
+	Currency    string       `json:"currency"`
+	Minted      *hexutil.Big `json:"minted"`
+	Burned      *hexutil.Big `json:"burned"`
+	Total       *hexutil.Big `json:"total"`
+	Circulating *hexutil.Big `json:"circulating,omitempty"`
+}
+
+// GetSupply returns the supply of the currencies issued by the chain up to
+// the given block, from the ledger the node keeps while importing the blocks.
+func (s *PublicBlockChainAPI) GetSupply(ctx context.Context, blockNr rpc.BlockNumber) ([]*RPCSupply, error) {
+	state, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	db := s.b.ChainDb()
+	supply := rawdb.ReadSupply(db, header.Hash(), header.Number.Uint64())
+	if supply == nil && blockNr == rpc.PendingBlockNumber && header.Number.Sign() > 0 {
+		// The pending block is not written yet, its issuance is in its state
+		if parent := rawdb.ReadSupply(db, header.ParentHash, header.Number.Uint64()-1); parent != nil {
+			supply = parent.Apply(state.Issuance())
+		}
+	}
+	if supply == nil {
+		return nil, fmt.Errorf("no supply recorded for block %d, run gece checksupply", header.Number.Uint64())
+	}
+
+	results := make([]*RPCSupply, 0, len(supply))
+	for _, cs := range supply {
+		result := &RPCSupply{
+			Currency: cs.Currency,
+			Minted:   (*hexutil.Big)(cs.Minted),
+			Burned:   (*hexutil.Big)(cs.Burned),
+			Total:    (*hexutil.Big)(cs.Total()),
+		}
+		if cs.Currency == "DECE" {
+			pool := state.GetBalance(ethash.CommunityRewardPool, "DECE")
+			result.Circulating = (*hexutil.Big)(new(big.Int).Sub(cs.Total(), pool))
+		}
+		results = append(results, result)
+	}
+	return results, state.Error()
+}
@@ -0,0 +1,119 @@
+package ethapi
+
+import (
+	"github.com/dece-cash/go-dece/common"
+	"github.com/dece-cash/go-dece/common/hexutil"
+	"github.com/dece-cash/go-dece/czero/c_type"
+	"github.com/dece-cash/go-dece/decedb"
+	"github.com/dece-cash/go-dece/zero/localdb"
+	"github.com/dece-cash/go-dece/zero/txs/stx"
+	"github.com/dece-cash/go-dece/zero/utils"
+)
+
+// RPCZero is the public summary of the zero part of a transaction. The
+// confidential inputs and outputs are only counted, their assets need the
+// TK of their owner to be read. The roots of the outputs are only known once
+// the block of the transaction is executed, they are omitted for the pending
+// transactions and by RPCMarshalBlock, which has no database to read them.
+type RPCZero struct {
+	OIns        hexutil.Uint     `json:"oIns"`
+	ZIns        hexutil.Uint     `json:"zIns"`
+	OOuts       hexutil.Uint     `json:"oOuts"`
+	ZOuts       hexutil.Uint     `json:"zOuts"`
+	Nils        []c_type.Uint256 `json:"nils"`
+	Roots       []c_type.Uint256 `json:"roots,omitempty"`
+	FeeCurrency string           `json:"feeCurrency"`
+	Fee         utils.U256       `json:"fee"`
+	Cmd         string           `json:"cmd,omitempty"`
+	PkgIds      []c_type.Uint256 `json:"pkgIds,omitempty"`
+}
+
+// newRPCZero summarizes the zero transaction t, the roots being the outputs
+// recorded for the transaction when its block was executed.
+func newRPCZero(t *stx.T, roots []c_type.Uint256) *RPCZero {
+	if t == nil {
+		return nil
+	}
+	ret := &RPCZero{
+		OIns:        hexutil.Uint(len(t.Desc_O.Ins) + len(t.Tx1.Ins_P)),
+		ZIns:        hexutil.Uint(len(t.Desc_Z.Ins) + len(t.Tx1.Ins_C)),
+		OOuts:       hexutil.Uint(len(t.Desc_O.Outs) + len(t.Tx1.Outs_P)),
+		ZOuts:       hexutil.Uint(len(t.Desc_Z.Outs) + len(t.Tx1.Outs_C)),
+		Nils:        []c_type.Uint256{},
+		Roots:       roots,
+		FeeCurrency: common.BytesToString(t.Fee.Currency[:]),
+		Fee:         t.Fee.Value,
+	}
+	for _, in := range t.Desc_O.Ins {
+		ret.Nils = append(ret.Nils, in.Nil)
+	}
+	for _, in := range t.Desc_Z.Ins {
+		ret.Nils = append(ret.Nils, in.Nil)
+	}
+	for _, in := range t.Tx1.Ins_P {
+		ret.Nils = append(ret.Nils, in.Nil)
+	}
+	for _, in := range t.Tx1.Ins_C {
+		ret.Nils = append(ret.Nils, in.Nil)
+	}
+
+	switch {
+	case t.Desc_Cmd.BuyShare != nil:
+		ret.Cmd = "BuyShare"
+	case t.Desc_Cmd.RegistPool != nil:
+		ret.Cmd = "RegistPool"
+	case t.Desc_Cmd.ClosePool != nil:
+		ret.Cmd = "ClosePool"
+	case t.Desc_Cmd.Contract != nil:
+		ret.Cmd = "Contract"
+	}
+	if pkg := t.Desc_Pkg.Create; pkg != nil {
+		ret.Cmd = "PkgCreate"
+		ret.PkgIds = append(ret.PkgIds, pkg.Id)
+	}
+	if pkg := t.Desc_Pkg.Transfer; pkg != nil {
+		ret.Cmd = "PkgTransfer"
+		ret.PkgIds = append(ret.PkgIds, pkg.Id)
+	}
+	if pkg := t.Desc_Pkg.Close; pkg != nil {
+		ret.Cmd = "PkgClose"
+		ret.PkgIds = append(ret.PkgIds, pkg.Id)
+	}
+	return ret
+}
+
+// txRoots returns the roots created by the transaction in the given block,
+// in the order they were added to the state. The roots of a transaction are
+// contiguous, so the scan of the block stops at the end of its run.
+func txRoots(db decedb.Database, hash common.Hash, blockHash common.Hash, blockNumber uint64) (ret []c_type.Uint256) {
+	block := localdb.GetBlock(db, blockNumber, blockHash.HashToUint256())
+	if block == nil {
+		return
+	}
+	txHash := hash.HashToUint256()
+	for i := range block.Roots {
+		if rs := localdb.GetRoot(db, &block.Roots[i]); rs != nil && rs.TxHash == *txHash {
+			ret = append(ret, block.Roots[i])
+		} else if len(ret) > 0 {
+			break
+		}
+	}
+	return
+}
+
+// blockRoots returns the roots created in the given block grouped by the
+// transactions creating them, reading each of them once.
+func blockRoots(db decedb.Database, blockHash common.Hash, blockNumber uint64) map[common.Hash][]c_type.Uint256 {
+	block := localdb.GetBlock(db, blockNumber, blockHash.HashToUint256())
+	if block == nil {
+		return nil
+	}
+	ret := make(map[common.Hash][]c_type.Uint256)
+	for i := range block.Roots {
+		if rs := localdb.GetRoot(db, &block.Roots[i]); rs != nil {
+			hash := common.BytesToHash(rs.TxHash[:])
+			ret[hash] = append(ret[hash], block.Roots[i])
+		}
+	}
+	return ret
+}
@@ -0,0 +1,106 @@
+package ethapi
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/dece-cash/go-dece/common"
+	"github.com/dece-cash/go-dece/common/hexutil"
+	"github.com/dece-cash/go-dece/core/types"
+	"github.com/dece-cash/go-dece/czero/c_type"
+	"github.com/dece-cash/go-dece/decedb"
+	"github.com/dece-cash/go-dece/zero/localdb"
+	"github.com/dece-cash/go-dece/zero/txs/stx"
+)
+
+// rootsBackend serves a single block whose roots are recorded in db.
+type rootsBackend struct {
+	Backend
+	db    decedb.Database
+	block *types.Block
+}
+
+func (b *rootsBackend) ChainDb() decedb.Database { return b.db }
+
+func (b *rootsBackend) GetTd(hash common.Hash) *big.Int { return big.NewInt(1) }
+
+func (b *rootsBackend) GetBlock(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	return b.block, nil
+}
+
+// newRootsBackend records a block of two transactions, the first creating two
+// outputs and the second one, followed by the output of the block reward.
+func newRootsBackend() (*rootsBackend, map[common.Hash][]c_type.Uint256) {
+	var txs []*types.Transaction
+	for i := byte(1); i <= 2; i++ {
+		t := &stx.T{}
+		t.Ehash[0] = i
+		txs = append(txs, types.NewTxWithGTx(25000, big.NewInt(1), t))
+	}
+	block := types.NewBlock(&types.Header{Number: big.NewInt(7)}, txs, nil)
+
+	db := decedb.NewMemDatabase()
+	want := make(map[common.Hash][]c_type.Uint256)
+	var roots []c_type.Uint256
+	for i, hash := range []common.Hash{txs[0].Hash(), txs[0].Hash(), txs[1].Hash(), common.BytesToHash([]byte{1})} {
+		root, cm := c_type.Uint256{byte(i + 1)}, c_type.Uint256{byte(i + 1), 1}
+		localdb.PutRoot(db, &root, &localdb.RootState{OS: localdb.OutState{RootCM: &cm}, TxHash: *hash.HashToUint256()})
+		roots = append(roots, root)
+		want[hash] = append(want[hash], root)
+	}
+	localdb.PutBlock(db, block.NumberU64(), block.Hash().HashToUint256(), &localdb.Block{Roots: roots})
+
+	return &rootsBackend{db: db, block: block}, want
+}
+
+// Tests that the roots of a mined transaction are the outputs it created in its
+// block, whether looked up alone or for the whole block.
+func TestTxRoots(t *testing.T) {
+	b, want := newRootsBackend()
+	block := b.block
+
+	all := blockRoots(b.db, block.Hash(), block.NumberU64())
+	if !reflect.DeepEqual(all, want) {
+		t.Errorf("block roots mismatch: have %x, want %x", all, want)
+	}
+	for _, tx := range block.Transactions() {
+		if roots := txRoots(b.db, tx.Hash(), block.Hash(), block.NumberU64()); !reflect.DeepEqual(roots, want[tx.Hash()]) {
+			t.Errorf("transaction %x: roots mismatch: have %x, want %x", tx.Hash(), roots, want[tx.Hash()])
+		}
+	}
+	if roots := txRoots(b.db, common.Hash{9}, block.Hash(), block.NumberU64()); roots != nil {
+		t.Errorf("unknown transaction has roots %x", roots)
+	}
+	if roots := blockRoots(b.db, common.Hash{9}, block.NumberU64()); roots != nil {
+		t.Errorf("unknown block has roots %x", roots)
+	}
+}
+
+// Tests that the block views of the transactions carry their roots.
+func TestBlockTxRoots(t *testing.T) {
+	b, want := newRootsBackend()
+
+	fields, err := NewPublicBlockChainAPI(b).rpcOutputBlock(b.block, true, true)
+	if err != nil {
+		t.Fatalf("failed to output the block: %v", err)
+	}
+	for i, tx := range fields["transactions"].([]interface{}) {
+		tx := tx.(*RPCTransaction)
+		if !reflect.DeepEqual(tx.Zero.Roots, want[tx.Hash]) {
+			t.Errorf("block transaction %d: roots mismatch: have %x, want %x", i, tx.Zero.Roots, want[tx.Hash])
+		}
+	}
+
+	api := NewPublicTransactionPoolAPI(b, nil)
+	for i, tx := range b.block.Transactions() {
+		rpcTx := api.GetTransactionByBlockHashAndIndex(context.Background(), b.block.Hash(), hexutil.Uint(i))
+		if rpcTx == nil {
+			t.Fatalf("transaction %d not found", i)
+		}
+		if !reflect.DeepEqual(rpcTx.Zero.Roots, want[tx.Hash()]) {
+			t.Errorf("transaction %d: roots mismatch: have %x, want %x", i, rpcTx.Zero.Roots, want[tx.Hash()])
+		}
+	}
+}
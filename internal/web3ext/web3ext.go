@@ -32,6 +32,7 @@ var Modules = map[string]string{
 	"txpool":     TxPool_JS,
 	"ssi":        SSI_JS,
 	"exchange":   Exchange_JS,
+	"swap":       Swap_JS,
 	"light":      LightNode_JS,
 	"stake":      Stake_JS,
 	"flight":     Flight_JS,
@@ -453,6 +454,24 @@ web3._extend({
             inputFormatter: [web3._extend.utils.toHex],
 			outputFormatter: web3._extend.utils.toDecimal
 		}),
+		new web3._extend.Method({
+			name: 'emissionSchedule',
+			call: 'dece_emissionSchedule',
+			params: 3,
+			inputFormatter: [web3._extend.utils.toHex, web3._extend.utils.toHex, web3._extend.utils.toHex]
+		}),
+		new web3._extend.Method({
+			name: 'getSupply',
+			call: 'dece_getSupply',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'simulateTx',
+			call: 'dece_simulateTx',
+			params: 2,
+			inputFormatter: [null, web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
 		new web3._extend.Method({
 			name: 'getRawTransaction',
 			call: 'dece_getRawTransactionByHash',
@@ -598,6 +617,16 @@ web3._extend({
 			call: 'personal_deriveAccount',
 			params: 3
 		}),
+		new web3._extend.Method({
+			name: 'importHDMnemonic',
+			call: 'personal_importHDMnemonic',
+			params: 5
+		}),
+		new web3._extend.Method({
+			name: 'deriveHDAccounts',
+			call: 'personal_deriveHDAccounts',
+			params: 5
+		}),
 		new web3._extend.Method({
 			name: 'signTransaction',
 			call: 'personal_signTransaction',
@@ -834,6 +863,27 @@ web3._extend({
 			name: 'ignorePkrUtxos',
 			call: 'exchange_ignorePkrUtxos',
 			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'getPkgs',
+			call: 'exchange_getPkgs',
+			params: 3
+		}),
+		new web3._extend.Method({
+			name: 'getPkgHistory',
+			call: 'exchange_getPkgHistory',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'openPkg',
+			call: 'exchange_openPkg',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'closePkg',
+			call: 'exchange_closePkg',
+			params: 4,
+			inputFormatter: [null, null, null, web3._extend.utils.fromDecimal]
 		})
 	]
 });
@@ -1094,3 +1144,49 @@ web3._extend({
 	]
 });
 `
+
+const Swap_JS = `
+web3._extend({
+	property: 'swap',
+	methods: [
+		new web3._extend.Method({
+			name: 'initiate',
+			call: 'swap_initiate',
+			params: 6,
+			inputFormatter: [null, null, null, web3._extend.utils.fromDecimal, web3._extend.utils.fromDecimal, null]
+		}),
+		new web3._extend.Method({
+			name: 'participate',
+			call: 'swap_participate',
+			params: 7,
+			inputFormatter: [null, null, null, web3._extend.utils.fromDecimal, null, web3._extend.utils.fromDecimal, null]
+		}),
+		new web3._extend.Method({
+			name: 'audit',
+			call: 'swap_audit',
+			params: 4
+		}),
+		new web3._extend.Method({
+			name: 'redeem',
+			call: 'swap_redeem',
+			params: 3
+		}),
+		new web3._extend.Method({
+			name: 'refund',
+			call: 'swap_refund',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'getSwap',
+			call: 'swap_getSwap',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getSwaps',
+			call: 'swap_getSwaps',
+			params: 1,
+			inputFormatter: [null]
+		}),
+	]
+});
+`
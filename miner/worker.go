@@ -84,6 +84,7 @@ type Work struct {
 	receipts []*types.Receipt
 
 	createdAt time.Time
+	epoch     uint32 // Mining session the work was created in
 
 	handledTxs    []*types.Transaction
 	errHandledTxs []*types.Transaction
@@ -149,6 +150,7 @@ type worker struct {
 	// atomic status counters
 	mining int32
 	atWork int32
+	epoch  uint32 // Mining session, bumped every time the miner stops
 
 	pendingVote pendingVote
 
@@ -260,6 +262,13 @@ func (self *worker) stop() {
 	}
 	atomic.StoreInt32(&self.mining, 0)
 	atomic.StoreInt32(&self.atWork, 0)
+	atomic.AddUint32(&self.epoch, 1)
+}
+
+// isStale reports whether work was created in a mining session that has been
+// stopped since.
+func (self *worker) isStale(work *Work) bool {
+	return work.epoch != atomic.LoadUint32(&self.epoch)
 }
 
 func (self *worker) register(agent Agent) {
@@ -342,7 +351,8 @@ func (self *worker) powResultLoop() {
 			log.Info("Broadcast Lottery", "poshash", result.Block.HashPos(), "block", result.Block.Number().Uint64())
 
 			go func() {
-				if lotter.wait() {
+				// A block sealed before the miner stopped is dropped once its votes are in
+				if lotter.wait() && !self.isStale(result.Work) {
 					result.Block.SetVotes(lotter.currentHeaderVotes, lotter.parentHeaderVotes)
 					self.recv <- result
 				}
@@ -455,6 +465,7 @@ func (self *worker) makeCurrent(parent *types.Block, header *types.Header) error
 		state:     state,
 		header:    header,
 		createdAt: time.Now(),
+		epoch:     atomic.LoadUint32(&self.epoch),
 	}
 	// Keep track of transactions which return errors so they can be removed
 	work.tcount = 0
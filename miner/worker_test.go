@@ -0,0 +1,61 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+// mockAgent counts the times it is stopped.
+type mockAgent struct {
+	Agent
+	stops int
+}
+
+func (a *mockAgent) Stop() { a.stops++ }
+
+// Tests that stopping the miner makes the work of its session stale, so that a
+// block sealed before is not written, while the work of the next session is
+// kept.
+func TestStaleWork(t *testing.T) {
+	agent := new(mockAgent)
+	w := &worker{agents: map[Agent]struct{}{agent: {}}}
+	atomic.StoreInt32(&w.mining, 1)
+
+	before := &Work{epoch: atomic.LoadUint32(&w.epoch)}
+	if w.isStale(before) {
+		t.Fatalf("work of the running session is stale")
+	}
+	w.stop()
+	if agent.stops != 1 {
+		t.Errorf("agent stops mismatch: have %d, want 1", agent.stops)
+	}
+	if !w.isStale(before) {
+		t.Errorf("work of a stopped session is not stale")
+	}
+	after := &Work{epoch: atomic.LoadUint32(&w.epoch)}
+	if w.isStale(after) {
+		t.Errorf("work of the new session is stale")
+	}
+
+	// Stopping an idle miner still ends its session
+	w.stop()
+	if !w.isStale(after) {
+		t.Errorf("work of a session stopped while idle is not stale")
+	}
+}
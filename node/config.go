@@ -27,6 +27,7 @@ import (
 
 	"github.com/dece-cash/go-dece/accounts"
 	"github.com/dece-cash/go-dece/accounts/keystore"
+	"github.com/dece-cash/go-dece/accounts/usbwallet"
 	"github.com/dece-cash/go-dece/common"
 	"github.com/dece-cash/go-dece/crypto"
 	"github.com/dece-cash/go-dece/log"
@@ -83,7 +84,13 @@ type Config struct {
 	// scrypt KDF at the expense of security.
 	UseLightweightKDF bool `toml:",omitempty"`
 
-	// NoUSB disables hardware wallet monitoring and connectivity.
+	// USB enables hardware wallet monitoring and connectivity. The devices are
+	// queried for their accounts when the node starts, a device not answering
+	// would hold the startup, so the monitoring is off unless asked for.
+	USB bool `toml:",omitempty"`
+
+	// NoUSB disables hardware wallet monitoring and connectivity, even if USB
+	// is set.
 	NoUSB bool `toml:",omitempty"`
 
 	// IPCPath is the requested location to place the IPC endpoint. If the path is
@@ -150,6 +157,30 @@ type Config struct {
 	// private APIs to untrusted users is a major security risk.
 	WSExposeAll bool `toml:",omitempty"`
 
+	// GraphQLHost is the host interface on which to start the GraphQL server. If this
+	// field is empty, no GraphQL API endpoint will be started. When RPCAuth is set,
+	// the queries must carry the bearer token of an access key allowing "graphql".
+	GraphQLHost string `toml:",omitempty"`
+
+	// GraphQLPort is the TCP port number on which to start the GraphQL server. The
+	// default zero value is/ valid and will pick a port number randomly (useful
+	// for ephemeral nodes).
+	GraphQLPort int `toml:",omitempty"`
+
+	// GraphQLCors is the Cross-Origin Resource Sharing header to send to requesting
+	// clients. Please be aware that CORS is a browser enforced security, it's fully
+	// useless for custom HTTP clients.
+	GraphQLCors []string `toml:",omitempty"`
+
+	// GraphQLVirtualHosts is the list of virtual hostnames which are allowed on incoming requests.
+	// This is by default {'localhost'}.
+	GraphQLVirtualHosts []string `toml:",omitempty"`
+
+	// RPCAuth requires the clients of the IPC, HTTP, websocket and GraphQL
+	// interfaces to authenticate with an access key, which limits the namespaces
+	// and the methods they may call and the rate of their calls.
+	RPCAuth *rpc.AuthConfig `toml:",omitempty"`
+
 	// Logger is a custom logger to use with the p2p.Server.
 	Logger log.Logger `toml:",omitempty"`
 }
@@ -223,6 +254,15 @@ func (c *Config) WSEndpoint() string {
 	return fmt.Sprintf("%s:%d", c.WSHost, c.WSPort)
 }
 
+// GraphQLEndpoint resolves a GraphQL endpoint based on the configured host interface
+// and port parameters.
+func (c *Config) GraphQLEndpoint() string {
+	if c.GraphQLHost == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", c.GraphQLHost, c.GraphQLPort)
+}
+
 // DefaultWSEndpoint returns the websocket endpoint used by default.
 func DefaultWSEndpoint() string {
 	config := &Config{WSHost: DefaultWSHost, WSPort: DefaultWSPort}
@@ -425,5 +465,9 @@ func makeAccountManager(conf *Config) (*accounts.Manager, string, error) {
 	backends := []accounts.Backend{
 		keystore.NewKeyStore(keydir, scryptN, scryptP),
 	}
+	if conf.USB && !conf.NoUSB {
+		// Start a USB hub for the hardware wallets running the DECE application
+		backends = append(backends, usbwallet.NewHub())
+	}
 	return accounts.NewManager(backends...), ephemeral, nil
 }
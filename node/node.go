@@ -54,6 +54,8 @@ type Node struct {
 	rpcAPIs       []rpc.API   // List of APIs currently provided by the node
 	inprocHandler *rpc.Server // In-process RPC request handler to process the API requests
 
+	rpcAccess *rpc.AccessControl // Access keys of the RPC endpoints (nil = authentication disabled)
+
 	ipcEndpoint string       // IPC endpoint to listen at (empty = IPC disabled)
 	ipcListener net.Listener // IPC RPC listener socket to serve API requests
 	ipcHandler  *rpc.Server  // IPC RPC request handler to process the API requests
@@ -146,6 +148,17 @@ func (n *Node) Start() error {
 	if err := n.openDataDir(); err != nil {
 		return err
 	}
+	// The access keys are shared by the endpoints, the in-process one excepted,
+	// and by the services serving their own, such as GraphQL
+	n.rpcAccess = nil
+	if n.config.RPCAuth != nil {
+		access, err := rpc.NewAccessControl(n.config.RPCAuth)
+		if err != nil {
+			return err
+		}
+		n.rpcAccess = access
+		n.log.Info("RPC authentication enabled", "keys", len(n.config.RPCAuth.Keys), "audit", n.config.RPCAuth.Audit)
+	}
 
 	// Initialize the p2p server. This creates the node key and
 	// discovery databases.
@@ -174,6 +187,7 @@ func (n *Node) Start() error {
 			services:       make(map[reflect.Type]Service),
 			EventMux:       n.eventmux,
 			AccountManager: n.accman,
+			RPCAccess:      n.rpcAccess,
 		}
 		for kind, s := range services { // copy needed for threaded access
 			ctx.services[kind] = s
@@ -306,7 +320,7 @@ func (n *Node) startIPC(apis []rpc.API) error {
 	if n.ipcEndpoint == "" {
 		return nil // IPC disabled.
 	}
-	listener, handler, err := rpc.StartIPCEndpoint(n.ipcEndpoint, apis)
+	listener, handler, err := rpc.StartIPCEndpoint(n.ipcEndpoint, apis, n.rpcAccess)
 	if err != nil {
 		return err
 	}
@@ -336,7 +350,7 @@ func (n *Node) startHTTP(endpoint string, apis []rpc.API, modules []string, cors
 	if endpoint == "" {
 		return nil
 	}
-	listener, handler, err := rpc.StartHTTPEndpoint(endpoint, apis, modules, cors, vhosts, timeouts)
+	listener, handler, err := rpc.StartHTTPEndpoint(endpoint, apis, modules, cors, vhosts, timeouts, n.rpcAccess)
 	if err != nil {
 		return err
 	}
@@ -369,7 +383,7 @@ func (n *Node) startWS(endpoint string, apis []rpc.API, modules []string, wsOrig
 	if endpoint == "" {
 		return nil
 	}
-	listener, handler, err := rpc.StartWSEndpoint(endpoint, apis, modules, wsOrigins, exposeAll)
+	listener, handler, err := rpc.StartWSEndpoint(endpoint, apis, modules, wsOrigins, exposeAll, n.rpcAccess)
 	if err != nil {
 		return err
 	}
@@ -56,14 +56,14 @@ var (
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), new(EthashConfig)}
+	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, nil, nil, nil, nil, nil, nil, nil, new(EthashConfig)}
 
 	// AllCliqueProtocolChanges contains every protocol change (EIPs) introduced
 	// and accepted by the Ethereum core developers into the Clique consensus.
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil}
+	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, nil, nil, nil, nil, nil, nil, nil, nil}
 
 	TestChainConfig = &ChainConfig{
 		ChainID:             big.NewInt(1),
@@ -83,10 +83,64 @@ type ChainConfig struct {
 
 	AutumnTwilightBlock *big.Int `json:"AutumnTwilightBlock,omitempty"` // AutumnTwilightBlock switch block (nil = no fork, 0 = already on AutumnTwilightBlock)
 
+	// The SIP forks switch blocks, nil keeps the block of the main network (0 in
+	// the developer mode)
+	SIP1Block *big.Int `json:"sip1Block,omitempty"` // SIP1 switch block, miner rewards
+	SIP2Block *big.Int `json:"sip2Block,omitempty"` // SIP2 switch block, miner rewards
+	SIP3Block *big.Int `json:"sip3Block,omitempty"` // SIP3 switch block, miner rewards
+	SIP4Block *big.Int `json:"sip4Block,omitempty"` // SIP4 switch block, share pool booster fix
+	SIP5Block *big.Int `json:"sip5Block,omitempty"` // SIP5 switch block, share pool booster fix
+	SIP6Block *big.Int `json:"sip6Block,omitempty"` // SIP6 switch block, share pool booster fix
+	SIP7Block *big.Int `json:"sip7Block,omitempty"` // SIP7 switch block, package lock conditions
+
+	Stake *StakeConfig `json:"stake,omitempty"` // Stake economics, nil keeps the ones of the main network
+
 	// Various consensus engines
 	Ethash *EthashConfig `json:"ethash,omitempty"`
 }
 
+// StakeConfig is the economics of the proof of stake of a chain. The zero
+// fields keep the value of the main network.
+type StakeConfig struct {
+	PoolValueThreshold   *big.Int `json:"poolValueThreshold,omitempty"`   // Value locked to register a stake pool
+	LockingBlockNum      uint64   `json:"lockingBlockNum,omitempty"`      // Blocks a closed stake pool stays locked
+	BasePrice            *big.Int `json:"basePrice,omitempty"`            // Price of the first share
+	OutOfDateWindow      uint64   `json:"outOfDateWindow,omitempty"`      // Blocks after which a share expires
+	MissVotedWindow      uint64   `json:"missVotedWindow,omitempty"`      // Blocks after which a share missing its votes expires
+	PayWindow            uint64   `json:"payWindow,omitempty"`            // Blocks between the payments of the share rewards
+	StatisticsMissWindow uint64   `json:"statisticsMissWindow,omitempty"` // Blocks of the statistics of the missed votes
+	MinSharePoolSize     uint32   `json:"minSharePoolSize,omitempty"`     // Shares of a pool below which its missed votes are not punished
+}
+
+// String implements the stringer interface, returning the configured economics.
+func (c *StakeConfig) String() string {
+	return fmt.Sprintf("{PoolValueThreshold: %v LockingBlockNum: %v BasePrice: %v OutOfDateWindow: %v MissVotedWindow: %v PayWindow: %v StatisticsMissWindow: %v MinSharePoolSize: %v}",
+		c.PoolValueThreshold,
+		c.LockingBlockNum,
+		c.BasePrice,
+		c.OutOfDateWindow,
+		c.MissVotedWindow,
+		c.PayWindow,
+		c.StatisticsMissWindow,
+		c.MinSharePoolSize,
+	)
+}
+
+// equal returns whether the economics of c and o are the same.
+func (c *StakeConfig) equal(o *StakeConfig) bool {
+	if c == nil || o == nil {
+		return c == o
+	}
+	return configNumEqual(c.PoolValueThreshold, o.PoolValueThreshold) &&
+		configNumEqual(c.BasePrice, o.BasePrice) &&
+		c.LockingBlockNum == o.LockingBlockNum &&
+		c.OutOfDateWindow == o.OutOfDateWindow &&
+		c.MissVotedWindow == o.MissVotedWindow &&
+		c.PayWindow == o.PayWindow &&
+		c.StatisticsMissWindow == o.StatisticsMissWindow &&
+		c.MinSharePoolSize == o.MinSharePoolSize
+}
+
 // EthashConfig is the consensus engine configs for proof-of-work based sealing.
 type EthashConfig struct{}
 
@@ -115,13 +169,21 @@ func (c *ChainConfig) String() string {
 	default:
 		engine = "unknown"
 	}
-	return fmt.Sprintf("{ChainID: %v AutumnTwilight: %v Engine: %v}",
+	return fmt.Sprintf("{ChainID: %v AutumnTwilight: %v SIPs: %v Stake: %v Engine: %v}",
 		c.ChainID,
 		c.AutumnTwilightBlock,
+		c.SIPBlocks(),
+		c.Stake,
 		engine,
 	)
 }
 
+// SIPBlocks returns the switch blocks of the SIP forks from SIP1, nil for the
+// ones keeping the block of the main network.
+func (c *ChainConfig) SIPBlocks() []*big.Int {
+	return []*big.Int{c.SIP1Block, c.SIP2Block, c.SIP3Block, c.SIP4Block, c.SIP5Block, c.SIP6Block, c.SIP7Block}
+}
+
 // IsAutumnTwilight returns whether num is either equal to the AutumnTwilight fork block or greater.
 func (c *ChainConfig) IsAutumnTwilight(num *big.Int) bool {
 	return isForked(c.AutumnTwilightBlock, num)
@@ -162,6 +224,16 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	if isForkIncompatible(c.AutumnTwilightBlock, newcfg.AutumnTwilightBlock, head) {
 		return newCompatError("AutumnTwilight fork block", c.AutumnTwilightBlock, newcfg.AutumnTwilightBlock)
 	}
+	sips, newsips := c.SIPBlocks(), newcfg.SIPBlocks()
+	for i := range sips {
+		if isForkIncompatible(sips[i], newsips[i], head) {
+			return newCompatError(fmt.Sprintf("SIP%d fork block", i+1), sips[i], newsips[i])
+		}
+	}
+	// The stake economics apply from the genesis block
+	if head.Sign() > 0 && !c.Stake.equal(newcfg.Stake) {
+		return newCompatError("stake config", common.Big1, common.Big1)
+	}
 	return nil
 }
 
@@ -0,0 +1,125 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestCheckCompatible(t *testing.T) {
+	type test struct {
+		stored, new *ChainConfig
+		head        uint64
+		wantErr     *ConfigCompatError
+	}
+	tests := []test{
+		{stored: AllEthashProtocolChanges, new: AllEthashProtocolChanges, head: 0, wantErr: nil},
+		{stored: AllEthashProtocolChanges, new: AllEthashProtocolChanges, head: 100, wantErr: nil},
+		// A SIP fork not reached yet can be rescheduled
+		{
+			stored:  &ChainConfig{SIP1Block: big.NewInt(10)},
+			new:     &ChainConfig{SIP1Block: big.NewInt(20)},
+			head:    9,
+			wantErr: nil,
+		},
+		{
+			stored:  &ChainConfig{},
+			new:     &ChainConfig{SIP7Block: big.NewInt(20)},
+			head:    9,
+			wantErr: nil,
+		},
+		// A passed SIP fork can't be rescheduled, nor unset
+		{
+			stored: &ChainConfig{SIP1Block: big.NewInt(10)},
+			new:    &ChainConfig{SIP1Block: big.NewInt(20)},
+			head:   25,
+			wantErr: &ConfigCompatError{
+				What:         "SIP1 fork block",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    big.NewInt(20),
+				RewindTo:     9,
+			},
+		},
+		{
+			stored: &ChainConfig{SIP3Block: big.NewInt(30), SIP4Block: big.NewInt(40)},
+			new:    &ChainConfig{SIP3Block: big.NewInt(30)},
+			head:   50,
+			wantErr: &ConfigCompatError{
+				What:         "SIP4 fork block",
+				StoredConfig: big.NewInt(40),
+				NewConfig:    nil,
+				RewindTo:     39,
+			},
+		},
+		// Nor scheduled before the head
+		{
+			stored: &ChainConfig{},
+			new:    &ChainConfig{SIP2Block: big.NewInt(20)},
+			head:   25,
+			wantErr: &ConfigCompatError{
+				What:         "SIP2 fork block",
+				StoredConfig: nil,
+				NewConfig:    big.NewInt(20),
+				RewindTo:     19,
+			},
+		},
+		// The stake economics can be changed in the genesis block only
+		{
+			stored:  &ChainConfig{Stake: &StakeConfig{PayWindow: 5}},
+			new:     &ChainConfig{Stake: &StakeConfig{PayWindow: 10}},
+			head:    0,
+			wantErr: nil,
+		},
+		{
+			stored:  &ChainConfig{Stake: &StakeConfig{PoolValueThreshold: big.NewInt(100), PayWindow: 5}},
+			new:     &ChainConfig{Stake: &StakeConfig{PoolValueThreshold: big.NewInt(100), PayWindow: 5}},
+			head:    100,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{Stake: &StakeConfig{PoolValueThreshold: big.NewInt(100)}},
+			new:    &ChainConfig{Stake: &StakeConfig{PoolValueThreshold: big.NewInt(200)}},
+			head:   1,
+			wantErr: &ConfigCompatError{
+				What:         "stake config",
+				StoredConfig: big.NewInt(1),
+				NewConfig:    big.NewInt(1),
+				RewindTo:     0,
+			},
+		},
+		{
+			stored: &ChainConfig{},
+			new:    &ChainConfig{Stake: &StakeConfig{LockingBlockNum: 10}},
+			head:   100,
+			wantErr: &ConfigCompatError{
+				What:         "stake config",
+				StoredConfig: big.NewInt(1),
+				NewConfig:    big.NewInt(1),
+				RewindTo:     0,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		err := test.stored.CheckCompatible(test.new, test.head)
+		if !reflect.DeepEqual(err, test.wantErr) {
+			t.Errorf("error mismatch:\nstored: %v\nnew: %v\nhead: %v\nerr: %v\nwant: %v", test.stored, test.new, test.head, err, test.wantErr)
+		}
+	}
+}
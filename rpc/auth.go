@@ -0,0 +1,441 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dece-cash/go-dece/log"
+)
+
+const (
+	// failedAuthRate is the number of failed authentications allowed per second
+	// from a remote address, failedAuthBurst the number allowed at once.
+	failedAuthRate  = 0.2
+	failedAuthBurst = 5
+
+	// maxFailedAuthAddrs bounds the remote addresses whose failures are tracked.
+	maxFailedAuthAddrs = 4096
+)
+
+var (
+	errInvalidCredential = errors.New("invalid credential")
+	errExpiredCredential = errors.New("expired credential")
+	errTooManyFailures   = errors.New("too many failed authentications")
+)
+
+// AccessKey grants a client the calls of some namespaces and methods of the
+// RPC APIs. The client presents either the API key or a JWT signed with the
+// secret, as a bearer token of its HTTP and WebSocket requests or through
+// rpc_authenticate on the connections which cannot carry one.
+type AccessKey struct {
+	// Name identifies the client in the audit log, it is the subject of its JWTs.
+	Name string
+
+	// Key is the API key of the client.
+	Key string `toml:",omitempty"`
+
+	// Secret is the HS256 secret of the JWTs of the client, these must expire.
+	Secret string `toml:",omitempty"`
+
+	// Allow lists the namespaces ("dece") and the methods ("dece_getBalance")
+	// the client may call, "*" allowing all of them.
+	Allow []string
+
+	// Rate is the number of calls allowed per second, zero for no limit. Burst
+	// is the number of calls which can be made at once, one by default.
+	Rate  float64 `toml:",omitempty"`
+	Burst int     `toml:",omitempty"`
+}
+
+// AuthConfig enables the authentication of the clients of the RPC endpoints.
+type AuthConfig struct {
+	Keys []AccessKey
+
+	// Audit logs every call with the name of the key it is made with.
+	Audit bool `toml:",omitempty"`
+}
+
+// AccessControl authenticates the clients of a server and authorizes their
+// calls by the allowlist and the rate limit of their access key.
+type AccessControl struct {
+	byKey  map[[sha256.Size]byte]*accessKey
+	byName map[string]*accessKey
+	audit  bool
+
+	failuresLock sync.Mutex
+	failures     map[string]*tokenBucket // Failed authentications by remote address
+}
+
+type accessKey struct {
+	AccessKey
+	allowAll bool
+	allow    map[string]bool
+	bucket   tokenBucket
+}
+
+// NewAccessControl creates the access control of the given keys.
+func NewAccessControl(config *AuthConfig) (*AccessControl, error) {
+	ac := &AccessControl{
+		byKey:    make(map[[sha256.Size]byte]*accessKey),
+		byName:   make(map[string]*accessKey),
+		audit:    config.Audit,
+		failures: make(map[string]*tokenBucket),
+	}
+	for _, k := range config.Keys {
+		switch {
+		case k.Name == "":
+			return nil, errors.New("access key without a name")
+		case ac.byName[k.Name] != nil:
+			return nil, fmt.Errorf("duplicate access key %q", k.Name)
+		case k.Key == "" && k.Secret == "":
+			return nil, fmt.Errorf("access key %q has neither a key nor a secret", k.Name)
+		case len(k.Allow) == 0:
+			return nil, fmt.Errorf("access key %q allows no method", k.Name)
+		case k.Rate < 0 || k.Burst < 0:
+			return nil, fmt.Errorf("access key %q has a negative rate limit", k.Name)
+		}
+		key := &accessKey{
+			AccessKey: k,
+			allow:     make(map[string]bool),
+			bucket:    tokenBucket{rate: k.Rate, burst: k.Burst},
+		}
+		for _, allowed := range k.Allow {
+			if allowed == "*" {
+				key.allowAll = true
+			}
+			key.allow[allowed] = true
+		}
+		if k.Key != "" {
+			hash := sha256.Sum256([]byte(k.Key))
+			if ac.byKey[hash] != nil {
+				return nil, fmt.Errorf("access keys %q and %q share their key", ac.byKey[hash].Name, k.Name)
+			}
+			ac.byKey[hash] = key
+		}
+		ac.byName[k.Name] = key
+	}
+	return ac, nil
+}
+
+// authenticate returns the access key of an API key or a JWT, along with the
+// time the credential expires at, zero if it never does.
+func (ac *AccessControl) authenticate(credential string) (*accessKey, time.Time, error) {
+	// The keys are looked up by hash not to leak their prefix through timing
+	if key := ac.byKey[sha256.Sum256([]byte(credential))]; key != nil {
+		return key, time.Time{}, nil
+	}
+	if strings.Count(credential, ".") == 2 {
+		return ac.verifyJWT(credential)
+	}
+	return nil, time.Time{}, errInvalidCredential
+}
+
+// authenticateFrom is authenticate for a client at the given remote address,
+// which is refused any attempt once it failed too many of them.
+func (ac *AccessControl) authenticateFrom(remote string, credential string) (*accessKey, time.Time, error) {
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+	now := time.Now()
+	ac.failuresLock.Lock()
+	bucket := ac.failures[remote]
+	limited := bucket != nil && !bucket.available(now)
+	ac.failuresLock.Unlock()
+	if limited {
+		return nil, time.Time{}, errTooManyFailures
+	}
+
+	key, expiry, err := ac.authenticate(credential)
+	if err != nil {
+		ac.fail(remote, now)
+	}
+	return key, expiry, err
+}
+
+// fail records a failed authentication from a remote address.
+func (ac *AccessControl) fail(remote string, now time.Time) {
+	ac.failuresLock.Lock()
+	defer ac.failuresLock.Unlock()
+
+	bucket := ac.failures[remote]
+	if bucket == nil {
+		// Forget the addresses which have not failed for long before tracking
+		// a new one
+		if len(ac.failures) >= maxFailedAuthAddrs {
+			for addr, b := range ac.failures {
+				if b.full(now) {
+					delete(ac.failures, addr)
+				}
+			}
+		}
+		if len(ac.failures) >= maxFailedAuthAddrs {
+			return
+		}
+		bucket = &tokenBucket{rate: failedAuthRate, burst: failedAuthBurst}
+		ac.failures[remote] = bucket
+	}
+	bucket.take(now)
+}
+
+// verifyJWT checks the signature and the expiry of a JWT, its subject naming
+// the access key whose secret signed it.
+func (ac *AccessControl) verifyJWT(token string) (*accessKey, time.Time, error) {
+	parts := strings.Split(token, ".")
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	var claims struct {
+		Sub string `json:"sub"`
+		Exp int64  `json:"exp"`
+		Nbf int64  `json:"nbf"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil || header.Alg != "HS256" {
+		return nil, time.Time{}, errInvalidCredential
+	}
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return nil, time.Time{}, errInvalidCredential
+	}
+	key := ac.byName[claims.Sub]
+	if key == nil || key.Secret == "" {
+		return nil, time.Time{}, errInvalidCredential
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, time.Time{}, errInvalidCredential
+	}
+	mac := hmac.New(sha256.New, []byte(key.Secret))
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, time.Time{}, errInvalidCredential
+	}
+	now := time.Now()
+	if claims.Exp == 0 || now.Unix() >= claims.Exp || now.Unix() < claims.Nbf {
+		return nil, time.Time{}, errExpiredCredential
+	}
+	return key, time.Unix(claims.Exp, 0), nil
+}
+
+func decodeJWTPart(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// authenticateRequest returns the authentication of an HTTP request, the one
+// of no key if it carries no bearer token.
+func (ac *AccessControl) authenticateRequest(r *http.Request) (*connAuth, error) {
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return new(connAuth), nil
+	}
+	const prefix = "Bearer "
+	if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return nil, errInvalidCredential
+	}
+	key, expiry, err := ac.authenticateFrom(r.RemoteAddr, header[len(prefix):])
+	if err != nil {
+		return nil, err
+	}
+	return &connAuth{key: key, expiry: expiry}, nil
+}
+
+// Handler requires the HTTP requests served by next to carry the bearer token
+// of an access key allowing the given method, within its rate limit. It guards
+// the HTTP endpoints serving other than JSON-RPC, such as GraphQL.
+func (ac *AccessControl) Handler(method string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, err := ac.authenticateRequest(r)
+		if err != nil {
+			writeAuthError(w, err)
+			return
+		}
+		ctx := context.WithValue(r.Context(), "remote", r.RemoteAddr)
+		ctx = context.WithValue(ctx, connAuthKey{}, auth)
+		key, authErr := ac.authorize(ctx, method)
+		ac.log(ctx, key, method, authErr)
+		if authErr != nil {
+			writeAuthError(w, authErr)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeAuthError answers an HTTP request denied by the access control.
+func writeAuthError(w http.ResponseWriter, err error) {
+	switch err.(type) {
+	case *forbiddenError:
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case *rateLimitError:
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+	default:
+		if err == errTooManyFailures {
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	}
+}
+
+// authorize checks that the connection of a request is authenticated with a
+// key allowing the method, within its rate limit.
+func (ac *AccessControl) authorize(ctx context.Context, method string) (*accessKey, Error) {
+	auth, _ := ctx.Value(connAuthKey{}).(*connAuth)
+	key, expiry := auth.get()
+	switch {
+	case key == nil:
+		return nil, &unauthorizedError{"authentication required"}
+	case !expiry.IsZero() && !time.Now().Before(expiry):
+		return key, &unauthorizedError{errExpiredCredential.Error()}
+	case !key.allows(method):
+		return key, &forbiddenError{method}
+	case !key.bucket.take(time.Now()):
+		return key, &rateLimitError{}
+	}
+	return key, nil
+}
+
+// log writes a call to the audit log, the denied ones being only logged for
+// debugging otherwise.
+func (ac *AccessControl) log(ctx context.Context, key *accessKey, method string, err Error) {
+	if !ac.audit && err == nil {
+		return
+	}
+	name := ""
+	if key != nil {
+		name = key.Name
+	}
+	remote, _ := ctx.Value("remote").(string)
+	logCtx := []interface{}{"key", name, "method", method, "remote", remote}
+	if err != nil {
+		logCtx = append(logCtx, "err", err.Error())
+	}
+	if ac.audit {
+		log.Info("RPC call", logCtx...)
+	} else {
+		log.Debug("RPC call denied", logCtx...)
+	}
+}
+
+func (k *accessKey) allows(method string) bool {
+	if k.allowAll || k.allow[method] {
+		return true
+	}
+	elems := strings.SplitN(method, serviceMethodSeparator, 2)
+	return k.allow[elems[0]]
+}
+
+// tokenBucket limits the rate of some events, no limit being set by a zero rate.
+type tokenBucket struct {
+	rate  float64 // Tokens added per second
+	burst int     // Tokens held at most, one by default
+
+	lock   sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// refill adds the tokens accrued since the last event, the lock being held.
+func (b *tokenBucket) refill(now time.Time) {
+	burst := math.Max(float64(b.burst), 1)
+	if b.last.IsZero() {
+		b.tokens = burst
+	} else {
+		b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+}
+
+// take consumes a token, if one is left.
+func (b *tokenBucket) take(now time.Time) bool {
+	if b.rate <= 0 {
+		return true
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.refill(now)
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// available returns whether a token is left, without consuming it.
+func (b *tokenBucket) available(now time.Time) bool {
+	if b.rate <= 0 {
+		return true
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.refill(now)
+	return b.tokens >= 1
+}
+
+// full returns whether the bucket holds all its tokens again.
+func (b *tokenBucket) full(now time.Time) bool {
+	if b.rate <= 0 {
+		return true
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.refill(now)
+	return b.tokens >= math.Max(float64(b.burst), 1)
+}
+
+type connAuthKey struct{}
+
+// connAuth is the access key a connection is authenticated with.
+type connAuth struct {
+	lock   sync.RWMutex
+	key    *accessKey
+	expiry time.Time
+}
+
+func (a *connAuth) get() (*accessKey, time.Time) {
+	if a == nil {
+		return nil, time.Time{}
+	}
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	return a.key, a.expiry
+}
+
+func (a *connAuth) set(key *accessKey, expiry time.Time) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.key, a.expiry = key, expiry
+}
@@ -0,0 +1,408 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type AuthTestService struct{}
+
+func (s *AuthTestService) Echo(str string) string {
+	return str
+}
+
+func (s *AuthTestService) Other() int {
+	return 1
+}
+
+func (s *AuthTestService) Ticks(ctx context.Context) (*Subscription, error) {
+	notifier, supported := NotifierFromContext(ctx)
+	if !supported {
+		return nil, ErrNotificationsUnsupported
+	}
+	return notifier.CreateSubscription(), nil
+}
+
+var authTestConfig = &AuthConfig{
+	Keys: []AccessKey{
+		{Name: "echo", Key: "echo-key", Allow: []string{"test_echo", "test_subscribe"}},
+		{Name: "namespace", Key: "namespace-key", Secret: "namespace-secret", Allow: []string{"test"}},
+		{Name: "limited", Key: "limited-key", Allow: []string{"*"}, Rate: 0.001, Burst: 2},
+		{Name: "jwt", Secret: "jwt-secret", Allow: []string{"*"}},
+	},
+}
+
+func newAuthTestServer(t *testing.T) *Server {
+	access, err := NewAccessControl(authTestConfig)
+	if err != nil {
+		t.Fatalf("failed to create the access control: %v", err)
+	}
+	server := NewServer()
+	server.SetAccessControl(access)
+	if err := server.RegisterName("test", new(AuthTestService)); err != nil {
+		t.Fatalf("failed to register the test service: %v", err)
+	}
+	return server
+}
+
+func signJWT(secret string, header, claims interface{}) string {
+	encode := func(v interface{}) string {
+		data, _ := json.Marshal(v)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	payload := encode(header) + "." + encode(claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return payload + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func errorCode(err error) int {
+	if err, ok := err.(Error); ok {
+		return err.ErrorCode()
+	}
+	return 0
+}
+
+func TestAccessControlConfig(t *testing.T) {
+	tests := []AccessKey{
+		{Key: "key", Allow: []string{"*"}},
+		{Name: "none", Allow: []string{"*"}},
+		{Name: "nothing", Key: "key"},
+		{Name: "negative", Key: "key", Allow: []string{"*"}, Rate: -1},
+	}
+	for i, k := range tests {
+		if _, err := NewAccessControl(&AuthConfig{Keys: []AccessKey{k}}); err == nil {
+			t.Errorf("test %d: invalid key %+v accepted", i, k)
+		}
+	}
+	duplicates := [][]AccessKey{
+		{{Name: "a", Key: "key", Allow: []string{"*"}}, {Name: "a", Key: "other", Allow: []string{"*"}}},
+		{{Name: "a", Key: "key", Allow: []string{"*"}}, {Name: "b", Key: "key", Allow: []string{"*"}}},
+	}
+	for i, keys := range duplicates {
+		if _, err := NewAccessControl(&AuthConfig{Keys: keys}); err == nil {
+			t.Errorf("test %d: duplicate keys accepted", i)
+		}
+	}
+}
+
+func TestAuthenticateCredentials(t *testing.T) {
+	access, err := NewAccessControl(authTestConfig)
+	if err != nil {
+		t.Fatalf("failed to create the access control: %v", err)
+	}
+	var (
+		hs256  = map[string]string{"alg": "HS256", "typ": "JWT"}
+		now    = time.Now().Unix()
+		expiry = now + 60
+	)
+	tests := []struct {
+		credential string
+		name       string
+		expiry     int64
+		err        error
+	}{
+		{credential: "echo-key", name: "echo"},
+		{credential: "namespace-key", name: "namespace"},
+		{credential: "unknown-key", err: errInvalidCredential},
+		{credential: "", err: errInvalidCredential},
+		{
+			credential: signJWT("jwt-secret", hs256, map[string]interface{}{"sub": "jwt", "exp": expiry}),
+			name:       "jwt",
+			expiry:     expiry,
+		},
+		{
+			credential: signJWT("namespace-secret", hs256, map[string]interface{}{"sub": "namespace", "exp": expiry}),
+			name:       "namespace",
+			expiry:     expiry,
+		},
+		{
+			// Signed with the secret of another key
+			credential: signJWT("namespace-secret", hs256, map[string]interface{}{"sub": "jwt", "exp": expiry}),
+			err:        errInvalidCredential,
+		},
+		{
+			// The keys without a secret accept no JWT
+			credential: signJWT("", hs256, map[string]interface{}{"sub": "echo", "exp": expiry}),
+			err:        errInvalidCredential,
+		},
+		{
+			credential: signJWT("jwt-secret", hs256, map[string]interface{}{"sub": "unknown", "exp": expiry}),
+			err:        errInvalidCredential,
+		},
+		{
+			credential: signJWT("jwt-secret", map[string]string{"alg": "none"}, map[string]interface{}{"sub": "jwt", "exp": expiry}),
+			err:        errInvalidCredential,
+		},
+		{
+			credential: signJWT("jwt-secret", hs256, map[string]interface{}{"sub": "jwt"}),
+			err:        errExpiredCredential,
+		},
+		{
+			credential: signJWT("jwt-secret", hs256, map[string]interface{}{"sub": "jwt", "exp": now - 1}),
+			err:        errExpiredCredential,
+		},
+		{
+			credential: signJWT("jwt-secret", hs256, map[string]interface{}{"sub": "jwt", "exp": expiry, "nbf": now + 30}),
+			err:        errExpiredCredential,
+		},
+		{credential: "a.b.c", err: errInvalidCredential},
+	}
+	for i, tt := range tests {
+		key, exp, err := access.authenticate(tt.credential)
+		if err != tt.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if key.Name != tt.name {
+			t.Errorf("test %d: key mismatch: have %s, want %s", i, key.Name, tt.name)
+		}
+		if (tt.expiry == 0 && !exp.IsZero()) || (tt.expiry != 0 && exp.Unix() != tt.expiry) {
+			t.Errorf("test %d: expiry mismatch: have %v, want %d", i, exp, tt.expiry)
+		}
+	}
+}
+
+func TestAuthAllowlist(t *testing.T) {
+	server := newAuthTestServer(t)
+	defer server.Stop()
+
+	tests := []struct {
+		credential string
+		method     string
+		code       int
+	}{
+		{credential: "", method: "test_echo", code: -32001},
+		{credential: "", method: "rpc_modules", code: -32001},
+		{credential: "echo-key", method: "test_echo"},
+		{credential: "echo-key", method: "test_other", code: -32002},
+		{credential: "echo-key", method: "rpc_modules", code: -32002},
+		{credential: "namespace-key", method: "test_echo"},
+		{credential: "namespace-key", method: "test_other"},
+		{credential: "namespace-key", method: "rpc_modules", code: -32002},
+		{credential: "limited-key", method: "rpc_modules"},
+	}
+	for i, tt := range tests {
+		client := DialInProc(server)
+		if tt.credential != "" {
+			if err := client.Authenticate(context.Background(), tt.credential); err != nil {
+				t.Fatalf("test %d: failed to authenticate: %v", i, err)
+			}
+		}
+		var args []interface{}
+		if tt.method == "test_echo" {
+			args = append(args, "hello")
+		}
+		var result interface{}
+		err := client.Call(&result, tt.method, args...)
+		if code := errorCode(err); code != tt.code {
+			t.Errorf("test %d: %s error mismatch: have %v (code %d), want code %d", i, tt.method, err, code, tt.code)
+		}
+		client.Close()
+	}
+}
+
+func TestAuthRateLimit(t *testing.T) {
+	server := newAuthTestServer(t)
+	defer server.Stop()
+
+	client := DialInProc(server)
+	defer client.Close()
+	if err := client.Authenticate(context.Background(), "limited-key"); err != nil {
+		t.Fatalf("failed to authenticate: %v", err)
+	}
+	var result string
+	for i := 0; i < 2; i++ {
+		if err := client.Call(&result, "test_echo", "hello"); err != nil {
+			t.Fatalf("call %d within the burst failed: %v", i, err)
+		}
+	}
+	if err := client.Call(&result, "test_echo", "hello"); errorCode(err) != -32005 {
+		t.Fatalf("call past the burst: have %v, want the rate limit", err)
+	}
+
+	// The bucket refills at its rate
+	var (
+		bucket = tokenBucket{rate: 2, burst: 2}
+		now    = time.Now()
+	)
+	if !bucket.take(now) || !bucket.take(now) || bucket.take(now) {
+		t.Fatal("bucket burst mismatch")
+	}
+	if bucket.take(now.Add(100 * time.Millisecond)) {
+		t.Fatal("token taken before it refilled")
+	}
+	if !bucket.take(now.Add(600 * time.Millisecond)) {
+		t.Fatal("token not refilled")
+	}
+	if !bucket.full(now.Add(2 * time.Second)) {
+		t.Fatal("bucket not full after its refill")
+	}
+}
+
+func TestFailedAuthRateLimit(t *testing.T) {
+	access, err := NewAccessControl(authTestConfig)
+	if err != nil {
+		t.Fatalf("failed to create the access control: %v", err)
+	}
+	for i := 0; i < failedAuthBurst; i++ {
+		if _, _, err := access.authenticateFrom("10.0.0.1:1000", "wrong-key"); err != errInvalidCredential {
+			t.Fatalf("attempt %d: error mismatch: have %v, want %v", i, err, errInvalidCredential)
+		}
+	}
+	// The address is refused even a valid key, from any port
+	for _, remote := range []string{"10.0.0.1:1000", "10.0.0.1:2000"} {
+		if _, _, err := access.authenticateFrom(remote, "echo-key"); err != errTooManyFailures {
+			t.Errorf("%s: error mismatch: have %v, want %v", remote, err, errTooManyFailures)
+		}
+	}
+	if key, _, err := access.authenticateFrom("10.0.0.2:1000", "echo-key"); err != nil || key.Name != "echo" {
+		t.Errorf("other address refused: %v", err)
+	}
+
+	// rpc_authenticate is limited as well
+	server := newAuthTestServer(t)
+	defer server.Stop()
+
+	client := DialInProc(server)
+	defer client.Close()
+	for i := 0; i < failedAuthBurst; i++ {
+		if err := client.Authenticate(context.Background(), "wrong-key"); err == nil {
+			t.Fatalf("attempt %d: wrong key accepted", i)
+		}
+	}
+	err = client.Authenticate(context.Background(), "echo-key")
+	if err == nil || err.Error() != errTooManyFailures.Error() {
+		t.Fatalf("error mismatch: have %v, want %v", err, errTooManyFailures)
+	}
+}
+
+func TestAuthUnsubscribe(t *testing.T) {
+	server := newAuthTestServer(t)
+	defer server.Stop()
+
+	// The key may unsubscribe without test_unsubscribe being allowed
+	client := DialInProc(server)
+	defer client.Close()
+	if err := client.Authenticate(context.Background(), "echo-key"); err != nil {
+		t.Fatalf("failed to authenticate: %v", err)
+	}
+	var id string
+	if err := client.Call(&id, "test_subscribe", "ticks"); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	var ok bool
+	if err := client.Call(&ok, "test_unsubscribe", id); err != nil || !ok {
+		t.Fatalf("failed to unsubscribe: %v", err)
+	}
+
+	// Another connection cannot cancel the subscriptions of the first one
+	other := DialInProc(server)
+	defer other.Close()
+	if err := client.Call(&id, "test_subscribe", "ticks"); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	if err := other.Call(&ok, "test_unsubscribe", id); err == nil {
+		t.Fatal("subscription of another connection cancelled")
+	}
+	if err := client.Call(&ok, "test_unsubscribe", id); err != nil || !ok {
+		t.Fatalf("subscription lost: %v", err)
+	}
+}
+
+func TestAuthHTTP(t *testing.T) {
+	server := newAuthTestServer(t)
+	defer server.Stop()
+
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+
+	for _, tt := range []struct {
+		credential string
+		fail       bool
+	}{
+		{credential: "wrong-key", fail: true},
+		{credential: "echo-key"},
+	} {
+		client, err := DialHTTP(httpsrv.URL)
+		if err != nil {
+			t.Fatalf("failed to dial: %v", err)
+		}
+		if err := client.Authenticate(context.Background(), tt.credential); err != nil {
+			t.Fatalf("failed to authenticate: %v", err)
+		}
+		var result string
+		err = client.Call(&result, "test_echo", "hello")
+		if tt.fail && err == nil {
+			t.Errorf("%s: call accepted", tt.credential)
+		}
+		if !tt.fail && (err != nil || result != "hello") {
+			t.Errorf("%s: call failed: %v", tt.credential, err)
+		}
+		client.Close()
+	}
+}
+
+func TestAuthHandler(t *testing.T) {
+	access, err := NewAccessControl(authTestConfig)
+	if err != nil {
+		t.Fatalf("failed to create the access control: %v", err)
+	}
+	handler := access.Handler("graphql", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("served"))
+	}))
+
+	for i, tt := range []struct {
+		credential string
+		status     int
+	}{
+		{credential: "", status: http.StatusUnauthorized},
+		{credential: "wrong-key", status: http.StatusUnauthorized},
+		{credential: "echo-key", status: http.StatusForbidden},
+		{credential: "limited-key", status: http.StatusOK},
+		{credential: "limited-key", status: http.StatusOK},
+		{credential: "limited-key", status: http.StatusTooManyRequests},
+	} {
+		req := httptest.NewRequest("POST", "/", nil)
+		if tt.credential != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.credential)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.status {
+			t.Errorf("request %d (%q): status mismatch: have %d, want %d", i, tt.credential, rec.Code, tt.status)
+		}
+		if tt.status == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") != "Bearer" {
+			t.Errorf("request %d (%q): no bearer challenge", i, tt.credential)
+		}
+		if served := rec.Body.String() == "served"; served != (tt.status == http.StatusOK) {
+			t.Errorf("request %d (%q): served %v", i, tt.credential, served)
+		}
+	}
+}
@@ -261,6 +261,17 @@ func (c *Client) SupportedModules() (map[string]string, error) {
 	return result, err
 }
 
+// Authenticate presents the API key or the JWT of an access key to the server.
+// Over HTTP it is sent along every request, so it must be called before any
+// other call, over the other transports it authenticates the connection.
+func (c *Client) Authenticate(ctx context.Context, credential string) error {
+	if c.isHTTP {
+		c.writeConn.(*httpConn).req.Header.Set("Authorization", "Bearer "+credential)
+		return nil
+	}
+	return c.CallContext(ctx, nil, authenticateMethod, credential)
+}
+
 // Close closes the client, aborting any in-flight requests.
 func (c *Client) Close() {
 	if c.isHTTP {
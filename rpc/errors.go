@@ -64,3 +64,26 @@ type shutdownError struct{}
 func (e *shutdownError) ErrorCode() int { return -32000 }
 
 func (e *shutdownError) Error() string { return "server is shutting down" }
+
+// the request carries no valid credential of an access key
+type unauthorizedError struct{ message string }
+
+func (e *unauthorizedError) ErrorCode() int { return -32001 }
+
+func (e *unauthorizedError) Error() string { return e.message }
+
+// the access key of the request does not allow the method
+type forbiddenError struct{ method string }
+
+func (e *forbiddenError) ErrorCode() int { return -32002 }
+
+func (e *forbiddenError) Error() string {
+	return fmt.Sprintf("the method %s is not allowed", e.method)
+}
+
+// the access key of the request exceeded its rate limit
+type rateLimitError struct{}
+
+func (e *rateLimitError) ErrorCode() int { return -32005 }
+
+func (e *rateLimitError) Error() string { return "rate limit exceeded" }
@@ -35,7 +35,8 @@ func (srv *Server) ServeListener(l net.Listener) error {
 			return err
 		}
 		log.Trace("Accepted connection", "addr", conn.RemoteAddr())
-		go srv.ServeCodec(NewJSONCodec(conn), OptionMethodInvocation|OptionSubscriptions)
+		ctx := context.WithValue(context.Background(), "remote", "ipc")
+		go srv.serveCodec(ctx, NewJSONCodec(conn), OptionMethodInvocation|OptionSubscriptions)
 	}
 }
 
@@ -0,0 +1,433 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// OpenRPCVersion is the version of the OpenRPC specification the documents of
+// rpc_discover follow, see https://spec.open-rpc.org.
+const OpenRPCVersion = "1.2.6"
+
+// OpenRPCDocument describes the methods served by a server along with the
+// JSON schemas of their parameters and results.
+type OpenRPCDocument struct {
+	OpenRPC    string            `json:"openrpc"`
+	Info       OpenRPCInfo       `json:"info"`
+	Methods    []*OpenRPCMethod  `json:"methods"`
+	Components OpenRPCComponents `json:"components"`
+}
+
+// OpenRPCInfo is the metadata of an OpenRPC document.
+type OpenRPCInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// OpenRPCMethod describes a method, its parameters being positional.
+type OpenRPCMethod struct {
+	Name           string               `json:"name"`
+	ParamStructure string               `json:"paramStructure"`
+	Params         []*OpenRPCDescriptor `json:"params"`
+	Result         *OpenRPCDescriptor   `json:"result"`
+}
+
+// OpenRPCDescriptor describes a parameter or the result of a method.
+type OpenRPCDescriptor struct {
+	Name     string      `json:"name"`
+	Required bool        `json:"required,omitempty"`
+	Schema   *JSONSchema `json:"schema"`
+}
+
+// OpenRPCComponents holds the schemas of the named types, referred to by the
+// descriptors of the methods.
+type OpenRPCComponents struct {
+	Schemas map[string]*JSONSchema `json:"schemas"`
+}
+
+// JSONSchema is the subset of JSON Schema describing the JSON encoding of the
+// Go types.
+type JSONSchema struct {
+	Ref                  string                 `json:"$ref,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	MinItems             *int                   `json:"minItems,omitempty"`
+	MaxItems             *int                   `json:"maxItems,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	AdditionalProperties *JSONSchema            `json:"additionalProperties,omitempty"`
+}
+
+var (
+	jsonMarshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	bigIntType          = reflect.TypeOf(big.Int{})
+)
+
+// The patterns of the strings the types with a custom encoding marshal to.
+var (
+	hexRegexp     = regexp.MustCompile(`^0x[0-9a-fA-F]*$`)
+	decimalRegexp = regexp.MustCompile(`^-?[0-9]+$`)
+	base58Regexp  = regexp.MustCompile(`^[1-9A-HJ-NP-Za-km-z]+$`)
+)
+
+// Discover returns the OpenRPC document of the methods served by the server,
+// the ones the access key of the caller allows when it must authenticate.
+func (s *RPCService) Discover(ctx context.Context) *OpenRPCDocument {
+	allows := func(string) bool { return true }
+	if s.server.access != nil {
+		auth, _ := ctx.Value(connAuthKey{}).(*connAuth)
+		key, _ := auth.get()
+		if key == nil {
+			allows = func(string) bool { return false }
+		} else {
+			allows = key.allows
+		}
+	}
+	return s.server.openRPC(allows)
+}
+
+// openRPC reflects over the registered services to describe the methods
+// passing the filter.
+func (s *Server) openRPC(allows func(method string) bool) *OpenRPCDocument {
+	g := &schemaGenerator{
+		schemas: make(map[string]*JSONSchema),
+		names:   make(map[reflect.Type]string),
+		types:   make(map[string]reflect.Type),
+	}
+	doc := &OpenRPCDocument{
+		OpenRPC: OpenRPCVersion,
+		Info:    OpenRPCInfo{Title: "DECE JSON-RPC API", Version: "1.0"},
+		Methods: []*OpenRPCMethod{},
+	}
+
+	services := make([]string, 0, len(s.services))
+	for name := range s.services {
+		services = append(services, name)
+	}
+	sort.Strings(services)
+	for _, name := range services {
+		svc := s.services[name]
+
+		methods := make([]string, 0, len(svc.callbacks))
+		for method := range svc.callbacks {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+		for _, method := range methods {
+			if allows(name + serviceMethodSeparator + method) {
+				doc.Methods = append(doc.Methods, g.method(name+serviceMethodSeparator+method, svc.callbacks[method]))
+			}
+		}
+		// Unsubscribing is allowed along subscribing
+		if len(svc.subscriptions) > 0 && allows(name+subscribeMethodSuffix) {
+			doc.Methods = append(doc.Methods, g.subscribe(name, svc.subscriptions), g.unsubscribe(name))
+		}
+	}
+	doc.Components.Schemas = g.schemas
+	return doc
+}
+
+// schemaGenerator builds the JSON schemas of the types, the named types being
+// described once in the components of the document.
+type schemaGenerator struct {
+	schemas map[string]*JSONSchema
+	names   map[reflect.Type]string
+	types   map[string]reflect.Type
+}
+
+func (g *schemaGenerator) method(name string, callb *callback) *OpenRPCMethod {
+	m := &OpenRPCMethod{
+		Name:           name,
+		ParamStructure: "by-position",
+		Params:         g.params(callb.argTypes),
+		Result:         &OpenRPCDescriptor{Name: "result", Schema: &JSONSchema{Type: "null"}},
+	}
+	if mtype := callb.method.Type; mtype.NumOut() > 0 && callb.errPos != 0 {
+		m.Result.Schema = g.schema(mtype.Out(0))
+	}
+	return m
+}
+
+// params describes the arguments of a callback, the trailing pointers being
+// optional.
+func (g *schemaGenerator) params(types []reflect.Type) []*OpenRPCDescriptor {
+	params := make([]*OpenRPCDescriptor, len(types))
+	used := make(map[string]bool)
+	required := false
+	for i := len(types) - 1; i >= 0; i-- {
+		required = required || types[i].Kind() != reflect.Ptr
+		params[i] = &OpenRPCDescriptor{Required: required, Schema: g.schema(types[i])}
+	}
+	for i, t := range types {
+		for t.Name() == "" && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice) {
+			t = t.Elem()
+		}
+		name := formatName(t.Name())
+		if name == "" {
+			name = t.Kind().String()
+		}
+		if used[name] {
+			name = fmt.Sprintf("%s%d", name, i)
+		}
+		used[name] = true
+		params[i].Name = name
+	}
+	return params
+}
+
+// subscribe describes the subscriptions of a service, their arguments
+// following their name.
+func (g *schemaGenerator) subscribe(service string, subs subscriptions) *OpenRPCMethod {
+	names := make([]string, 0, len(subs))
+	for name := range subs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return &OpenRPCMethod{
+		Name:           service + subscribeMethodSuffix,
+		ParamStructure: "by-position",
+		Params: []*OpenRPCDescriptor{{
+			Name:     "subscription",
+			Required: true,
+			Schema:   &JSONSchema{Type: "string", Enum: names},
+		}},
+		Result: &OpenRPCDescriptor{Name: "id", Schema: &JSONSchema{Type: "string"}},
+	}
+}
+
+func (g *schemaGenerator) unsubscribe(service string) *OpenRPCMethod {
+	return &OpenRPCMethod{
+		Name:           service + unsubscribeMethodSuffix,
+		ParamStructure: "by-position",
+		Params:         []*OpenRPCDescriptor{{Name: "id", Required: true, Schema: &JSONSchema{Type: "string"}}},
+		Result:         &OpenRPCDescriptor{Name: "result", Schema: &JSONSchema{Type: "boolean"}},
+	}
+}
+
+// schema returns the schema of a type, a reference to the components for the
+// named types of the packages.
+func (g *schemaGenerator) schema(t reflect.Type) *JSONSchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Name() == "" || t.PkgPath() == "" {
+		return g.inline(t)
+	}
+	name, ok := g.names[t]
+	if !ok {
+		name = g.name(t)
+		// The schema is registered before it is built for the recursive types
+		schema := new(JSONSchema)
+		g.schemas[name] = schema
+		*schema = *g.inline(t)
+		schema.Title = t.String()
+	}
+	return &JSONSchema{Ref: "#/components/schemas/" + name}
+}
+
+// name returns the name of a type in the components, qualified by its package
+// when another type has the same name.
+func (g *schemaGenerator) name(t reflect.Type) string {
+	name := t.Name()
+	if other, ok := g.types[name]; ok && other != t {
+		name = path.Base(t.PkgPath()) + "." + name
+		if other, ok := g.types[name]; ok && other != t {
+			name = strings.Replace(t.PkgPath(), "/", "_", -1) + "." + t.Name()
+		}
+	}
+	g.names[t] = name
+	g.types[name] = t
+	return name
+}
+
+func implements(t, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PtrTo(t).Implements(iface)
+}
+
+// inline builds the schema of a type by the rules of encoding/json.
+func (g *schemaGenerator) inline(t reflect.Type) *JSONSchema {
+	switch {
+	case t == bigIntType:
+		return &JSONSchema{Type: "integer"}
+	case implements(t, textMarshalerType) || implements(t, jsonMarshalerType):
+		if schema := g.sample(t); schema != nil {
+			return schema
+		}
+		if implements(t, textMarshalerType) {
+			return &JSONSchema{Type: "string"}
+		}
+		return &JSONSchema{Description: "custom JSON encoding"}
+	case implements(t, textUnmarshalerType):
+		return &JSONSchema{Type: "string"}
+	case implements(t, jsonUnmarshalerType):
+		return &JSONSchema{Description: "custom JSON encoding"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &JSONSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &JSONSchema{Type: "string", Description: "base64 encoded"}
+		}
+		return &JSONSchema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Array:
+		n := t.Len()
+		return &JSONSchema{Type: "array", Items: g.schema(t.Elem()), MinItems: &n, MaxItems: &n}
+	case reflect.Map:
+		return &JSONSchema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		schema := &JSONSchema{Type: "object", Properties: make(map[string]*JSONSchema)}
+		g.fields(t, schema.Properties)
+		return schema
+	case reflect.Ptr:
+		return g.schema(t.Elem())
+	}
+	// interfaces and the types without JSON encoding accept anything
+	return &JSONSchema{}
+}
+
+// sample infers the schema of a type with a custom encoding from the JSON of
+// one of its values, the byte arrays and slices being filled not to encode as
+// zeros only. It returns nil if the value cannot be encoded.
+func (g *schemaGenerator) sample(t reflect.Type) (schema *JSONSchema) {
+	v := reflect.New(t)
+	switch {
+	case t.Kind() == reflect.Array && t.Elem().Kind() == reflect.Uint8:
+		for i := 0; i < t.Len(); i++ {
+			v.Elem().Index(i).SetUint(1)
+		}
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		v.Elem().SetBytes(bytes.Repeat([]byte{1}, 64))
+	}
+	// The encoders of some types fail on their zero value
+	defer func() {
+		if recover() != nil {
+			schema = nil
+		}
+	}()
+	data, err := json.Marshal(v.Interface())
+	if err != nil || len(data) == 0 {
+		return nil
+	}
+	switch data[0] {
+	case '"':
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return nil
+		}
+		return textSchema(t, text)
+	case '{':
+		schema := &JSONSchema{Type: "object"}
+		if t.Kind() == reflect.Struct {
+			schema.Properties = make(map[string]*JSONSchema)
+			g.fields(t, schema.Properties)
+		}
+		return schema
+	case '[':
+		schema := &JSONSchema{Type: "array"}
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			schema.Items = g.schema(t.Elem())
+		}
+		return schema
+	case 't', 'f':
+		return &JSONSchema{Type: "boolean"}
+	case 'n':
+		return nil
+	}
+	if decimalRegexp.Match(data) {
+		return &JSONSchema{Type: "integer"}
+	}
+	return &JSONSchema{Type: "number"}
+}
+
+// textSchema returns the schema of the strings a type marshals to, text being
+// the one of a value.
+func textSchema(t reflect.Type, text string) *JSONSchema {
+	isBytes := (t.Kind() == reflect.Array || t.Kind() == reflect.Slice) && t.Elem().Kind() == reflect.Uint8
+	switch {
+	case hexRegexp.MatchString(text):
+		if t.Kind() == reflect.Array && isBytes && len(text) == 2+2*t.Len() {
+			return &JSONSchema{Type: "string", Pattern: fmt.Sprintf("^0x[0-9a-fA-F]{%d}$", 2*t.Len()), Description: "hex encoded"}
+		}
+		return &JSONSchema{Type: "string", Pattern: hexRegexp.String(), Description: "hex encoded"}
+	case decimalRegexp.MatchString(text) && !isBytes:
+		return &JSONSchema{Type: "string", Pattern: decimalRegexp.String(), Description: "decimal"}
+	case base58Regexp.MatchString(text):
+		return &JSONSchema{Type: "string", Pattern: base58Regexp.String(), Description: "base58 encoded"}
+	}
+	return &JSONSchema{Type: "string"}
+}
+
+// fields adds the JSON fields of a struct to properties, those of its embedded
+// structs included.
+func (g *schemaGenerator) fields(t reflect.Type, properties map[string]*JSONSchema) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if idx := strings.Index(tag, ","); idx >= 0 {
+			name, opts = tag[:idx], tag[idx+1:]
+		}
+		ft := field.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if field.Anonymous && name == "" && ft.Kind() == reflect.Struct &&
+			!implements(ft, textMarshalerType) && !implements(ft, jsonMarshalerType) {
+			g.fields(ft, properties)
+			continue
+		}
+		if field.PkgPath != "" { // unexported
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if strings.Contains(opts, "string") {
+			properties[name] = &JSONSchema{Type: "string"}
+		} else {
+			properties[name] = g.schema(field.Type)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/dece-cash/go-dece/common/hexutil"
+)
+
+// DiscoverBase58 encodes as base58, as the addresses of the chain.
+type DiscoverBase58 [32]byte
+
+func (b DiscoverBase58) MarshalText() ([]byte, error) {
+	return hexutil.Bytes(b[:]).MarshalBase58Text()
+}
+
+// DiscoverHash encodes as hex, as the hashes of the chain.
+type DiscoverHash [32]byte
+
+func (b DiscoverHash) MarshalText() ([]byte, error) {
+	return hexutil.Bytes(b[:]).MarshalText()
+}
+
+// DiscoverAmount encodes as a decimal string.
+type DiscoverAmount big.Int
+
+func (b *DiscoverAmount) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + (*big.Int)(b).String() + `"`), nil
+}
+
+type DiscoverReceipt struct {
+	Hash   DiscoverHash    `json:"hash"`
+	To     DiscoverBase58  `json:"to"`
+	Amount *DiscoverAmount `json:"amount"`
+	Nonce  hexutil.Uint64  `json:"nonce"`
+	Memo   string          `json:"memo,omitempty"`
+}
+
+type DiscoverTestService struct{}
+
+func (s *DiscoverTestService) Send(to DiscoverBase58, amount *DiscoverAmount) (*DiscoverReceipt, error) {
+	return nil, nil
+}
+
+func (s *DiscoverTestService) Echo(str string) string {
+	return str
+}
+
+func (s *DiscoverTestService) Ticks(ctx context.Context) (*Subscription, error) {
+	notifier, _ := NotifierFromContext(ctx)
+	return notifier.CreateSubscription(), nil
+}
+
+func findMethod(doc *OpenRPCDocument, name string) *OpenRPCMethod {
+	for _, m := range doc.Methods {
+		if m.Name == name {
+			return m
+		}
+	}
+	return nil
+}
+
+// resolve follows the reference of a schema to the components of the document.
+func resolve(t *testing.T, doc *OpenRPCDocument, schema *JSONSchema) *JSONSchema {
+	if schema.Ref == "" {
+		return schema
+	}
+	resolved := doc.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+	if resolved == nil {
+		t.Fatalf("unresolved reference %s", schema.Ref)
+	}
+	return resolved
+}
+
+func checkString(t *testing.T, name string, schema *JSONSchema, value string) {
+	if schema.Type != "string" || schema.Pattern == "" {
+		t.Errorf("%s: schema mismatch: have %+v, want a string with a pattern", name, schema)
+		return
+	}
+	if !regexp.MustCompile(schema.Pattern).MatchString(value) {
+		t.Errorf("%s: %q does not match the pattern %s", name, value, schema.Pattern)
+	}
+}
+
+func TestDiscoverDocument(t *testing.T) {
+	server := NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("test", new(DiscoverTestService)); err != nil {
+		t.Fatalf("failed to register the test service: %v", err)
+	}
+	client := DialInProc(server)
+	defer client.Close()
+
+	var doc *OpenRPCDocument
+	if err := client.Call(&doc, "rpc_discover"); err != nil {
+		t.Fatalf("failed to discover: %v", err)
+	}
+	if doc.OpenRPC != OpenRPCVersion {
+		t.Errorf("version mismatch: have %s, want %s", doc.OpenRPC, OpenRPCVersion)
+	}
+	for _, name := range []string{"rpc_discover", "rpc_modules", "test_echo", "test_subscribe", "test_unsubscribe"} {
+		if findMethod(doc, name) == nil {
+			t.Errorf("method %s not described", name)
+		}
+	}
+
+	send := findMethod(doc, "test_send")
+	if send == nil {
+		t.Fatal("method test_send not described")
+	}
+	if len(send.Params) != 2 {
+		t.Fatalf("test_send params mismatch: have %d, want 2", len(send.Params))
+	}
+	if !send.Params[0].Required || send.Params[1].Required {
+		t.Errorf("test_send required params mismatch: have %v, %v", send.Params[0].Required, send.Params[1].Required)
+	}
+	to := resolve(t, doc, send.Params[0].Schema)
+	checkString(t, "to", to, "6vrvE5nHYBXtJt8czAeWHjkQMNCe6v9NZtvdWvYXYGH8")
+	if regexp.MustCompile(to.Pattern).MatchString("0x0101") {
+		t.Errorf("to: base58 pattern %s accepts hex", to.Pattern)
+	}
+	amount := resolve(t, doc, send.Params[1].Schema)
+	checkString(t, "amount", amount, "1000000000000000000")
+
+	receipt := resolve(t, doc, send.Result.Schema)
+	if receipt.Type != "object" {
+		t.Fatalf("receipt type mismatch: have %s, want object", receipt.Type)
+	}
+	var fields []string
+	for name := range receipt.Properties {
+		fields = append(fields, name)
+	}
+	if len(fields) != 5 {
+		t.Errorf("receipt fields mismatch: have %v", fields)
+	}
+	hash := resolve(t, doc, receipt.Properties["hash"])
+	checkString(t, "hash", hash, "0x"+strings.Repeat("ab", 32))
+	if regexp.MustCompile(hash.Pattern).MatchString("0xabab") {
+		t.Errorf("hash: pattern %s accepts a short hash", hash.Pattern)
+	}
+	checkString(t, "nonce", resolve(t, doc, receipt.Properties["nonce"]), "0x1f")
+	if !reflect.DeepEqual(resolve(t, doc, receipt.Properties["memo"]), &JSONSchema{Type: "string"}) {
+		t.Errorf("memo schema mismatch: have %+v", receipt.Properties["memo"])
+	}
+}
+
+func TestDiscoverAllowlist(t *testing.T) {
+	access, err := NewAccessControl(&AuthConfig{
+		Keys: []AccessKey{
+			{Name: "echo", Key: "echo-key", Allow: []string{"rpc_discover", "test_echo"}},
+			{Name: "all", Key: "all-key", Allow: []string{"*"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create the access control: %v", err)
+	}
+	server := NewServer()
+	defer server.Stop()
+	server.SetAccessControl(access)
+	if err := server.RegisterName("test", new(DiscoverTestService)); err != nil {
+		t.Fatalf("failed to register the test service: %v", err)
+	}
+
+	discover := func(credential string) *OpenRPCDocument {
+		client := DialInProc(server)
+		defer client.Close()
+		if err := client.Authenticate(context.Background(), credential); err != nil {
+			t.Fatalf("failed to authenticate: %v", err)
+		}
+		var doc *OpenRPCDocument
+		if err := client.Call(&doc, "rpc_discover"); err != nil {
+			t.Fatalf("failed to discover: %v", err)
+		}
+		return doc
+	}
+	doc := discover("echo-key")
+	var names []string
+	for _, m := range doc.Methods {
+		names = append(names, m.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"rpc_discover", "test_echo"}) {
+		t.Errorf("methods mismatch: have %v, want [rpc_discover test_echo]", names)
+	}
+	if doc.Components.Schemas["DiscoverReceipt"] != nil {
+		t.Errorf("schema of a disallowed method described")
+	}
+
+	if doc := discover("all-key"); findMethod(doc, "test_send") == nil || findMethod(doc, "test_unsubscribe") == nil {
+		t.Errorf("methods missing from the document of an unrestricted key")
+	}
+}
@@ -18,6 +18,7 @@ package rpc
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"runtime"
@@ -31,6 +32,9 @@ import (
 
 const MetadataApi = "rpc"
 
+// authenticateMethod is callable by the connections not authenticated yet.
+const authenticateMethod = MetadataApi + serviceMethodSeparator + "authenticate"
+
 // CodecOption specifies which type of messages this codec supports
 type CodecOption int
 
@@ -73,6 +77,28 @@ func (s *RPCService) Modules() map[string]string {
 	return modules
 }
 
+// Authenticate authenticates the connection with the API key or the JWT of an
+// access key, for the clients which cannot send it along their requests.
+func (s *RPCService) Authenticate(ctx context.Context, credential string) error {
+	auth, ok := ctx.Value(connAuthKey{}).(*connAuth)
+	if s.server.access == nil || !ok {
+		return errors.New("authentication is disabled")
+	}
+	remote, _ := ctx.Value("remote").(string)
+	key, expiry, err := s.server.access.authenticateFrom(remote, credential)
+	if err != nil {
+		return err
+	}
+	auth.set(key, expiry)
+	return nil
+}
+
+// SetAccessControl requires the clients of the server to authenticate with
+// the given access keys. It must be called before the server is serving.
+func (s *Server) SetAccessControl(access *AccessControl) {
+	s.access = access
+}
+
 // RegisterName will create a service for the given rcvr type under the given name. When no methods on the given rcvr
 // match the criteria to be either a RPC method or a subscription an error is returned. Otherwise a new service is
 // created and added to the service collection this server instance serves.
@@ -147,6 +173,11 @@ func (s *Server) serveRequest(ctx context.Context, codec ServerCodec, singleShot
 	if options&OptionSubscriptions == OptionSubscriptions {
 		ctx = context.WithValue(ctx, notifierKey{}, newNotifier(codec))
 	}
+	// the connection is authenticated through rpc_authenticate unless its
+	// transport did it already
+	if _, ok := ctx.Value(connAuthKey{}).(*connAuth); s.access != nil && !ok {
+		ctx = context.WithValue(ctx, connAuthKey{}, new(connAuth))
+	}
 	s.codecsMu.Lock()
 	if atomic.LoadInt32(&s.run) != 1 { // server stopped
 		s.codecsMu.Unlock()
@@ -212,8 +243,13 @@ func (s *Server) serveRequest(ctx context.Context, codec ServerCodec, singleShot
 // response back using the given codec. It will block until the codec is closed or the server is
 // stopped. In either case the codec is closed.
 func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
+	s.serveCodec(context.Background(), codec, options)
+}
+
+// serveCodec is ServeCodec with the context of the connection.
+func (s *Server) serveCodec(ctx context.Context, codec ServerCodec, options CodecOption) {
 	defer codec.Close()
-	s.serveRequest(context.Background(), codec, false, options)
+	s.serveRequest(ctx, codec, false, options)
 }
 
 // ServeSingleRequest reads and processes a single RPC request from the given codec. It will not
@@ -274,6 +310,14 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 		return codec.CreateErrorResponse(&req.id, &invalidParamsError{"Expected subscription id as first argument"}), nil
 	}
 
+	if s.access != nil && req.method != authenticateMethod {
+		key, err := s.access.authorize(ctx, req.method)
+		s.access.log(ctx, key, req.method, err)
+		if err != nil {
+			return codec.CreateErrorResponse(&req.id, err), nil
+		}
+	}
+
 	if req.callb.isSubscribe {
 		subid, err := s.createSubscription(ctx, codec, req)
 		if err != nil {
@@ -407,7 +451,7 @@ func (s *Server) readRequest(codec ServerCodec) ([]*serverRequest, bool, Error)
 
 		if r.isPubSub { // dece_subscribe, r.method contains the subscription method name
 			if callb, ok := svc.subscriptions[r.method]; ok {
-				requests[i] = &serverRequest{id: r.id, svcname: svc.name, callb: callb}
+				requests[i] = &serverRequest{id: r.id, svcname: svc.name, method: r.service + subscribeMethodSuffix, callb: callb}
 				if r.params != nil && len(callb.argTypes) > 0 {
 					argTypes := []reflect.Type{reflect.TypeOf("")}
 					argTypes = append(argTypes, callb.argTypes...)
@@ -424,7 +468,7 @@ func (s *Server) readRequest(codec ServerCodec) ([]*serverRequest, bool, Error)
 		}
 
 		if callb, ok := svc.callbacks[r.method]; ok { // lookup RPC method
-			requests[i] = &serverRequest{id: r.id, svcname: svc.name, callb: callb}
+			requests[i] = &serverRequest{id: r.id, svcname: svc.name, method: r.service + serviceMethodSeparator + r.method, callb: callb}
 			if r.params != nil && len(callb.argTypes) > 0 {
 				if args, err := codec.ParseRequestArguments(callb.argTypes, r.params); err == nil {
 					requests[i].args = args
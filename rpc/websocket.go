@@ -68,7 +68,17 @@ func (srv *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
 			decoder := func(v interface{}) error {
 				return websocketJSONCodec.Receive(conn, v)
 			}
-			srv.ServeCodec(NewCodec(conn, encoder, decoder), OptionMethodInvocation|OptionSubscriptions)
+			ctx := context.WithValue(context.Background(), "remote", conn.Request().RemoteAddr)
+			if srv.access != nil {
+				auth, err := srv.access.authenticateRequest(conn.Request())
+				if err != nil {
+					log.Debug("Rejected WebSocket connection", "remote", conn.Request().RemoteAddr, "err", err)
+					conn.Close()
+					return
+				}
+				ctx = context.WithValue(ctx, connAuthKey{}, auth)
+			}
+			srv.serveCodec(ctx, NewCodec(conn, encoder, decoder), OptionMethodInvocation|OptionSubscriptions)
 		},
 	}
 }
@@ -46,10 +46,17 @@ func (self *BlockGet) Unserial(v []byte) (e error) {
 		}
 	}
 }
-func BlockKey(num uint64, hash *c_type.Uint256) []byte {
-	block_key := []byte("$DECE_ZSTATE_BLOCK_SHOOTCUT$")
+// BlockKeyPrefix returns the common prefix of the block records of all the
+// blocks at the given height.
+func BlockKeyPrefix(num uint64) []byte {
+	block_key := append([]byte{}, blockPrefix...)
 	block_key = append(block_key, big.NewInt(int64(num)).Bytes()...)
 	block_key = append(block_key, []byte("$")...)
+	return block_key
+}
+
+func BlockKey(num uint64, hash *c_type.Uint256) []byte {
+	block_key := BlockKeyPrefix(num)
 	block_key = append(block_key, hash[:]...)
 	return block_key
 }
@@ -0,0 +1,116 @@
+package localdb
+
+import (
+	"bytes"
+
+	"github.com/dece-cash/go-dece/czero/c_type"
+	"github.com/dece-cash/go-dece/decedb"
+	"github.com/dece-cash/go-dece/rlp"
+)
+
+var (
+	blockPrefix     = []byte("$DECE_ZSTATE_BLOCK_SHOOTCUT$")
+	outStatPrefix   = []byte("$ZSTATE_OUT_STAT$")
+	rootStatePrefix = []byte("$DECE_LOCALDB_ROOTSTATE$")
+	rootCMPrefix    = []byte("$DECE_LOCALDB_ROOTCM2ROOT$")
+	pkgPrefix       = []byte("$DECE_LOCALDB_PKG_HASH$")
+)
+
+func group(name string, prefix []byte) decedb.KeyGroup {
+	return decedb.KeyGroup{Schema: "localdb", Name: name, Prefix: prefix}
+}
+
+// KeyGroups returns the key families written by localdb into the chain database.
+func KeyGroups() []decedb.KeyGroup {
+	return []decedb.KeyGroup{
+		group("Blocks", blockPrefix),
+		group("Out stats", outStatPrefix),
+		group("Roots", rootStatePrefix),
+		group("Root commitments", rootCMPrefix),
+		group("Pkgs", pkgPrefix),
+	}
+}
+
+// Anomalies counts the inconsistencies between the localdb records and the
+// canonical chain.
+type Anomalies struct {
+	Roots          uint64 // root states checked
+	OrphanedRoots  uint64 // roots not listed by the block record of their canonical block
+	Blocks         uint64 // block records checked
+	StaleBlocks    uint64 // block records of blocks which are not canonical
+	CorruptRecords uint64 // records which can not be decoded
+}
+
+// ParseBlockKey extracts the number and the hash from a key built by BlockKey.
+func ParseBlockKey(key []byte) (num uint64, hash c_type.Uint256, ok bool) {
+	if !bytes.HasPrefix(key, blockPrefix) {
+		return
+	}
+	rest := key[len(blockPrefix):]
+	if len(rest) < len(hash)+1 || rest[len(rest)-len(hash)-1] != '$' {
+		return
+	}
+	numBytes := rest[:len(rest)-len(hash)-1]
+	if len(numBytes) > 8 {
+		return
+	}
+	for _, b := range numBytes {
+		num = num<<8 | uint64(b)
+	}
+	copy(hash[:], rest[len(rest)-len(hash):])
+	ok = true
+	return
+}
+
+func containsRoot(roots []c_type.Uint256, root []byte) bool {
+	for i := range roots {
+		if bytes.Equal(roots[i][:], root) {
+			return true
+		}
+	}
+	return false
+}
+
+// InspectAnomalies walks the block records and the root states of db and
+// counts the ones which are not backed by the canonical chain. The canonical
+// function returns the hash of the canonical block at the given height.
+func InspectAnomalies(db decedb.Database, it decedb.Iteratee, canonical func(num uint64) (c_type.Uint256, bool)) (ret Anomalies) {
+	blocks := it.NewIteratorWithPrefix(blockPrefix)
+	for blocks.Next() {
+		ret.Blocks++
+		num, hash, ok := ParseBlockKey(blocks.Key())
+		if !ok {
+			ret.CorruptRecords++
+			continue
+		}
+		if h, ok := canonical(num); !ok || h != hash {
+			ret.StaleBlocks++
+		}
+	}
+	blocks.Release()
+
+	var (
+		lastNum   uint64
+		lastBlock *Block
+	)
+	roots := it.NewIteratorWithPrefix(rootStatePrefix)
+	for roots.Next() {
+		ret.Roots++
+		rs := RootState{}
+		if err := rlp.DecodeBytes(roots.Value(), &rs); err != nil {
+			ret.CorruptRecords++
+			continue
+		}
+		if lastBlock == nil || lastNum != rs.Num {
+			lastNum, lastBlock = rs.Num, nil
+			if hash, ok := canonical(rs.Num); ok {
+				lastBlock = GetBlock(db, rs.Num, &hash)
+			}
+		}
+		if lastBlock == nil || !containsRoot(lastBlock.Roots, roots.Key()[len(rootStatePrefix):]) {
+			ret.OrphanedRoots++
+		}
+	}
+	roots.Release()
+	return
+}
@@ -44,7 +44,7 @@ func (self *OutStatGet) Unserial(v []byte) (e error) {
 }
 
 func outStatName(root *c_type.Uint256) (ret []byte) {
-	ret = []byte("$ZSTATE_OUT_STAT$")
+	ret = append([]byte{}, outStatPrefix...)
 	ret = append(ret, root[:]...)
 	return
 }
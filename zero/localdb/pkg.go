@@ -57,7 +57,7 @@ func (self *PkgGet) Unserial(v []byte) (e error) {
 }
 
 func PkgKey(root *c_type.Uint256) []byte {
-	key := []byte("$DECE_LOCALDB_PKG_HASH$")
+	key := append([]byte{}, pkgPrefix...)
 	key = append(key, root[:]...)
 	return key
 }
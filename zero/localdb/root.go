@@ -41,13 +41,13 @@ func (self *RootStateGet) Unserial(v []byte) (e error) {
 }
 
 func Root2TxHashKey(root *c_type.Uint256) []byte {
-	key := []byte("$DECE_LOCALDB_ROOTSTATE$")
+	key := append([]byte{}, rootStatePrefix...)
 	key = append(key, root[:]...)
 	return key
 }
 
 func RootCM2RootKey(root_cm *c_type.Uint256) []byte {
-	key := []byte("$DECE_LOCALDB_ROOTCM2ROOT$")
+	key := append([]byte{}, rootCMPrefix...)
 	key = append(key, root_cm[:]...)
 	return key
 }
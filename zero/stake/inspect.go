@@ -0,0 +1,22 @@
+package stake
+
+import (
+	"github.com/dece-cash/go-dece/core/state"
+	"github.com/dece-cash/go-dece/decedb"
+)
+
+func group(name string, prefix string) decedb.KeyGroup {
+	return decedb.KeyGroup{Schema: "stake", Name: name, Prefix: []byte(prefix)}
+}
+
+// KeyGroups returns the key families written by the stake state into the
+// chain database.
+func KeyGroups() []decedb.KeyGroup {
+	return []decedb.KeyGroup{
+		group("Cons block records", state.StakeDB.Pre),
+		group("Shares", ShareDB.Pre),
+		group("Pools", StakePoolDB.Pre),
+		group("Block votes", string(blockVotesPrefix)),
+		group("Block share nums", string(blockShareNumPrefix)),
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"math"
 	"math/big"
 
 	"github.com/dece-cash/go-dece/zero/zconfig"
@@ -620,7 +621,7 @@ func (self *StakeState) CurrentPrice() *big.Int {
 	tree := NewTree(self, 0)
 	newNum := self.getNewShareNum()
 	size := tree.Size() + newNum
-	return new(big.Int).Add(basePrice, new(big.Int).Mul(addition, big.NewInt(int64(size))))
+	return new(big.Int).Add(getBasePrice(), new(big.Int).Mul(addition, big.NewInt(int64(size))))
 }
 
 func (self *StakeState) SumAmount(n int64) *big.Int {
@@ -677,26 +678,56 @@ func (self *StakeState) CaleAvgPrice(amount *big.Int) (uint32, *big.Int, *big.In
 	return uint32(left), new(big.Int).Div(sumAmount, big.NewInt(left)), basePrice
 }
 
+// StakeCurrentReward returns the rewards of a solo vote and of a pool vote in
+// the block blockNumber.
 func (self *StakeState) StakeCurrentReward(blockNumber *big.Int) (soloRewards *big.Int, totalRewards *big.Int) {
+	soloRewards, totalRewards, _ = self.stakeRewards(blockNumber.Uint64())
+	return
+}
 
-	return big.NewInt(0), big.NewInt(0)
+// StakeRewardsRange returns the rewards of a solo vote and of a pool vote, as
+// StakeCurrentReward does for a single block, summed over the blocks from from
+// to to, both included.
+func (self *StakeState) StakeRewardsRange(from, to uint64) (soloRewards *big.Int, totalRewards *big.Int) {
+	soloRewards, totalRewards = new(big.Int), new(big.Int)
+	for number := from; number <= to; {
+		soloReward, totalReward, last := self.stakeRewards(number)
+		if last > to {
+			last = to
+		}
+		count := new(big.Int).SetUint64(last - number + 1)
+		soloRewards.Add(soloRewards, soloReward.Mul(soloReward, count))
+		totalRewards.Add(totalRewards, totalReward.Mul(totalReward, count))
+		if last == to {
+			break
+		}
+		number = last + 1
+	}
+	return
+}
+
+// stakeRewards returns the rewards of a solo vote and of a pool vote in the
+// block number, and the last block, not before number, paying the same.
+func (self *StakeState) stakeRewards(number uint64) (soloReward *big.Int, totalReward *big.Int, last uint64) {
+
+	return big.NewInt(0), big.NewInt(0), math.MaxUint64
 
 	// if deceparam.Is_Dev() {
-	// 	return big.NewInt(600000000000000000), big.NewInt(900000000000000000)
+	// 	return big.NewInt(600000000000000000), big.NewInt(900000000000000000), math.MaxUint64
 	// }
 	//
-	// size := NewTree(self, blockNumber.Uint64()).Size()
+	// size := NewTree(self, number).Size()
 	// totalReward := new(big.Int).Add(baseReware, new(big.Int).Mul(rewareStep, big.NewInt(int64(size))))
 	//
 	// if totalReward.Cmp(maxReware) > 0 {
 	// 	totalReward = new(big.Int).Set(maxReware)
 	// }
 	//
-	// halve := ethash.Halve(blockNumber)
+	// halve := ethash.Halve(new(big.Int).SetUint64(number))
 	// totalReward = new(big.Int).Div(totalReward, halve)
 	// totalReward = new(big.Int).Div(totalReward, big.NewInt(3))
 	//
-	// return new(big.Int).Div(new(big.Int).Mul(totalReward, big.NewInt(SOLO_RATE)), big.NewInt(TOTAL_RATE)), totalReward
+	// return new(big.Int).Div(new(big.Int).Mul(totalReward, big.NewInt(SOLO_RATE)), big.NewInt(TOTAL_RATE)), totalReward, number
 }
 
 func GetPosRewardBySize(size uint64, blockNumber int64) (soloRewards *big.Int, totalRewards *big.Int) {
@@ -842,17 +873,64 @@ func (self *StakeState) verifyVote(num uint64, vote types.HeaderVote, stakeHash
 
 }
 
+// splitParentVoteReward splits the reward of a vote for the parent of a block
+// between the share, two thirds of it, and the remedy paid to the coinbase of
+// the block.
+func splitParentVoteReward(reward *big.Int) (share, remedy *big.Int) {
+	remedy = new(big.Int).Div(reward, big.NewInt(3))
+	return new(big.Int).Sub(reward, remedy), remedy
+}
+
+// paysRemedy returns whether the coinbase of the block is paid the remedy of
+// the votes for its parent.
+func paysRemedy(header *types.Header) bool {
+	return len(header.CurrentVotes) >= 2 && len(header.ParentVotes) > 0
+}
+
+// VoteRewards returns the rewards paid for the votes of the given block, split
+// between the solo votes and the pool votes. The remedy paid to the coinbase
+// for the votes for its parent is included.
+func (self *StakeState) VoteRewards(header *types.Header) (solo, pool *big.Int) {
+	solo, pool = new(big.Int), new(big.Int)
+	soloReward, reward := self.StakeCurrentReward(header.Number)
+	for _, vote := range header.CurrentVotes {
+		if vote.IsPool {
+			pool.Add(pool, reward)
+		} else {
+			solo.Add(solo, soloReward)
+		}
+	}
+	for _, vote := range header.ParentVotes {
+		r := soloReward
+		if vote.IsPool {
+			r = reward
+		}
+		share, remedy := splitParentVoteReward(r)
+		if paysRemedy(header) {
+			share.Add(share, remedy)
+		}
+		if vote.IsPool {
+			pool.Add(pool, share)
+		} else {
+			solo.Add(solo, share)
+		}
+	}
+	return
+}
+
 func (self *StakeState) processRemedyRewards(bc blockChain, header *types.Header) {
 	if header.Number.Uint64() > 0 {
 		parentHeader := bc.GetHeader(header.ParentHash, header.Number.Uint64()-1)
-		if len(parentHeader.CurrentVotes) >= 2 && len(parentHeader.ParentVotes) > 0 {
+		if paysRemedy(parentHeader) {
 			soloReware, totalReward := self.StakeCurrentReward(parentHeader.Number)
 			reward := new(big.Int)
 			for _, vote := range parentHeader.ParentVotes {
 				if vote.IsPool {
-					reward.Add(reward, new(big.Int).Div(totalReward, big.NewInt(3)))
+					_, remedy := splitParentVoteReward(totalReward)
+					reward.Add(reward, remedy)
 				} else {
-					reward.Add(reward, new(big.Int).Div(soloReware, big.NewInt(3)))
+					_, remedy := splitParentVoteReward(soloReware)
+					reward.Add(reward, remedy)
 				}
 			}
 			asset := assets.Asset{
@@ -863,6 +941,7 @@ func (self *StakeState) processRemedyRewards(bc blockChain, header *types.Header
 				nil,
 			}
 			self.statedb.NextZState().AddTxOut(parentHeader.Coinbase, asset, common.BytesToHash([]byte{3}))
+			self.statedb.AddIssued("DECE", reward)
 		}
 	}
 }
@@ -1009,8 +1088,8 @@ func (self *StakeState) processVotedShare(header *types.Header, bc blockChain) (
 	}
 
 	if len(preHeader.ParentVotes) > 0 {
-		reward = new(big.Int).Sub(reward, new(big.Int).Div(reward, big.NewInt(3)))
-		soloReware = new(big.Int).Sub(soloReware, new(big.Int).Div(soloReware, big.NewInt(3)))
+		reward, _ = splitParentVoteReward(reward)
+		soloReware, _ = splitParentVoteReward(soloReware)
 		for _, vote := range preHeader.ParentVotes {
 			err = self.rewardVote(vote, soloReware, reward, preHeader.Number.Uint64())
 			if err != nil {
@@ -1068,9 +1147,11 @@ func (self *StakeState) rewardVote(vote types.HeaderVote, soloReware, reward *bi
 		share.addProfit(new(big.Int).Sub(reward, poolReward))
 		share.addIncome(new(big.Int).Add(share.Value, new(big.Int).Sub(reward, poolReward)))
 		self.updateStakePool(pool)
+		self.statedb.AddIssued("DECE", reward)
 	} else {
 		share.addProfit(soloReware)
 		share.addIncome(new(big.Int).Add(share.Value, soloReware))
+		self.statedb.AddIssued("DECE", soloReware)
 	}
 	self.updateShare(share)
 	return nil
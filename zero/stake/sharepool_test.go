@@ -2,6 +2,7 @@ package stake
 
 import (
 	"fmt"
+	"math"
 	"math/big"
 	"testing"
 
@@ -91,6 +92,31 @@ func TestPosRewad(t *testing.T) {
 	fmt.Println(state.StakeCurrentReward(big.NewInt(3057600 + 8294400)))
 }
 
+// Tests that the rewards of a range of blocks are those of its blocks summed,
+// up to the last block number.
+func TestStakeRewardsRange(t *testing.T) {
+	state, _ := newState()
+
+	for _, r := range [][2]uint64{{0, 0}, {1, 10}, {3057595, 3057605}, {math.MaxUint64 - 2, math.MaxUint64}} {
+		wantSolo, wantTotal := new(big.Int), new(big.Int)
+		for number := r[0]; ; number++ {
+			solo, total := state.StakeCurrentReward(new(big.Int).SetUint64(number))
+			wantSolo.Add(wantSolo, solo)
+			wantTotal.Add(wantTotal, total)
+			if number == r[1] {
+				break
+			}
+		}
+		solo, total := state.StakeRewardsRange(r[0], r[1])
+		if solo.Cmp(wantSolo) != 0 || total.Cmp(wantTotal) != 0 {
+			t.Errorf("blocks %d-%d: rewards mismatch: have %v/%v, want %v/%v", r[0], r[1], solo, total, wantSolo, wantTotal)
+		}
+	}
+	if solo, total := state.StakeRewardsRange(2, 1); solo.Sign() != 0 || total.Sign() != 0 {
+		t.Errorf("empty range rewards: have %v/%v", solo, total)
+	}
+}
+
 func TestPosDif(t *testing.T) {
 	state, _ := newState()
 
@@ -2,7 +2,9 @@ package stake
 
 import (
 	"math/big"
+	"sync"
 
+	"github.com/dece-cash/go-dece/params"
 	"github.com/dece-cash/go-dece/zero/zconfig"
 
 	"github.com/dece-cash/go-dece/czero/deceparam"
@@ -25,11 +27,9 @@ var (
 	payWindow            = uint64(42336)  // 1 week 7*24*60*4.6
 	statisticsMissWindow = uint64(6048)   // 1 day 24*60*4.6
 
-	//test
-	//outOfDateWindow      = uint64(100)
-	//missVotedWindow      = uint64(120)
-	//payWindow            = uint64(5)
-	//statisticsMissWindow = uint64(10)
+	// the economics configured in the genesis of the chain
+	configLock sync.RWMutex
+	config     params.StakeConfig
 )
 
 const (
@@ -37,15 +37,36 @@ const (
 	TOTAL_RATE = 4
 
 	minSharePoolSize = 20000 // 20K
-	//test
-	//minSharePoolSize = 20
 
 	minMissRate    = 0.2
 	MaxVoteCount   = 3
 	ValidVoteCount = 2
 )
 
+// InitConfig sets the stake economics configured in the genesis of the chain,
+// the zero fields keep their default.
+func InitConfig(cfg *params.StakeConfig) {
+	configLock.Lock()
+	defer configLock.Unlock()
+
+	if cfg == nil {
+		config = params.StakeConfig{}
+	} else {
+		config = *cfg
+	}
+}
+
+func getConfig() params.StakeConfig {
+	configLock.RLock()
+	defer configLock.RUnlock()
+	return config
+}
+
 func getMinSharePoolSize() uint32 {
+	config := getConfig()
+	if config.MinSharePoolSize != 0 {
+		return config.MinSharePoolSize
+	}
 	if zconfig.IsTestFork() {
 		return 10000000
 	}
@@ -57,6 +78,10 @@ func getMinSharePoolSize() uint32 {
 }
 
 func GetPoolValueThreshold() *big.Int {
+	config := getConfig()
+	if config.PoolValueThreshold != nil {
+		return config.PoolValueThreshold
+	}
 	if deceparam.Is_Dev() {
 		return big.NewInt(1000000000000000000)
 	}
@@ -64,13 +89,29 @@ func GetPoolValueThreshold() *big.Int {
 }
 
 func GetLockingBlockNum() uint64 {
+	config := getConfig()
+	if config.LockingBlockNum != 0 {
+		return config.LockingBlockNum
+	}
 	if deceparam.Is_Dev() {
 		return 10
 	}
 	return lockingBlockNum
 }
 
+func getBasePrice() *big.Int {
+	config := getConfig()
+	if config.BasePrice != nil {
+		return config.BasePrice
+	}
+	return basePrice
+}
+
 func getStatisticsMissWindow() uint64 {
+	config := getConfig()
+	if config.StatisticsMissWindow != 0 {
+		return config.StatisticsMissWindow
+	}
 	if deceparam.Is_Dev() {
 		return 10
 	}
@@ -78,6 +119,10 @@ func getStatisticsMissWindow() uint64 {
 }
 
 func getOutOfDateWindow() uint64 {
+	config := getConfig()
+	if config.OutOfDateWindow != 0 {
+		return config.OutOfDateWindow
+	}
 	if deceparam.Is_Dev() {
 		return 100
 	}
@@ -85,6 +130,10 @@ func getOutOfDateWindow() uint64 {
 }
 
 func getMissVotedWindow() uint64 {
+	config := getConfig()
+	if config.MissVotedWindow != 0 {
+		return config.MissVotedWindow
+	}
 	if deceparam.Is_Dev() {
 		return 105
 	}
@@ -92,6 +141,10 @@ func getMissVotedWindow() uint64 {
 }
 
 func getPayPeriod() uint64 {
+	config := getConfig()
+	if config.PayWindow != 0 {
+		return config.PayWindow
+	}
 	if deceparam.Is_Dev() {
 		return 5
 	}
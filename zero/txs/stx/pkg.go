@@ -1,6 +1,10 @@
 package stx
 
 import (
+	"crypto/sha256"
+	"errors"
+	"hash"
+
 	"github.com/dece-cash/go-dece/czero/c_type"
 	"github.com/dece-cash/go-dece/crypto/sha3"
 	"github.com/dece-cash/go-dece/zero/txs/pkg"
@@ -8,8 +12,22 @@ import (
 )
 
 type PkgClose struct {
-	Id   c_type.Uint256
-	Sign c_type.Uint512
+	Id       c_type.Uint256
+	Sign     c_type.Uint512
+	Preimage []c_type.Uint256 `rlp:"tail"`
+}
+
+func (self *PkgClose) GetPreimage() *c_type.Uint256 {
+	if len(self.Preimage) == 0 {
+		return nil
+	}
+	return &self.Preimage[0]
+}
+
+func (self *PkgClose) writePreimage(d hash.Hash) {
+	if preimage := self.GetPreimage(); preimage != nil {
+		d.Write(preimage[:])
+	}
 }
 
 func (this PkgClose) ToRef() (ret *PkgClose) {
@@ -21,6 +39,7 @@ func (self *PkgClose) ToHash() (ret c_type.Uint256) {
 	d := sha3.NewKeccak256()
 	d.Write(self.Id[:])
 	d.Write(self.Sign[:])
+	self.writePreimage(d)
 	copy(ret[:], d.Sum(nil))
 	return ret
 }
@@ -28,6 +47,7 @@ func (self *PkgClose) ToHash() (ret c_type.Uint256) {
 func (self *PkgClose) Tx1_Hash() (ret c_type.Uint256) {
 	d := sha3.NewKeccak256()
 	d.Write(self.Id[:])
+	self.writePreimage(d)
 	copy(ret[:], d.Sum(nil))
 	return
 }
@@ -42,6 +62,7 @@ func (self *PkgClose) ToHash_for_gen() (ret c_type.Uint256) {
 func (self *PkgClose) ToHash_for_sign() (ret c_type.Uint256) {
 	d := sha3.NewKeccak256()
 	d.Write(self.Id[:])
+	self.writePreimage(d)
 	copy(ret[:], d.Sum(nil))
 	return ret
 }
@@ -100,11 +121,88 @@ func (self *PkgTransfer) Clone() (ret PkgTransfer) {
 	return
 }
 
+// PkgLock restricts when and by whom a package can be closed, a zero field
+// disables its condition.
+type PkgLock struct {
+	// The owner can close the package from this block on
+	Height uint64
+	// The owner has to reveal the preimage of this sha256 hash
+	HashLock c_type.Uint256
+	// From this block on only the creator can close the package
+	Expiry uint64
+}
+
+func (self *PkgLock) ToHash() (ret c_type.Uint256) {
+	d := sha3.NewKeccak256()
+	d.Write(utils.EncodeNumber(self.Height))
+	d.Write(self.HashLock[:])
+	d.Write(utils.EncodeNumber(self.Expiry))
+	copy(ret[:], d.Sum(nil))
+	return
+}
+
+func (self *PkgLock) Valid() bool {
+	if self.Height != 0 && self.Expiry != 0 && self.Expiry <= self.Height {
+		return false
+	}
+	return true
+}
+
+// PreimageToHash returns the hash locking a package with the preimage, sha256
+// as the hash locks of the other chains a swap can be paired with.
+func PreimageToHash(preimage *c_type.Uint256) (ret c_type.Uint256) {
+	return c_type.Uint256(sha256.Sum256(preimage[:]))
+}
+
+// CheckPkgClose checks whether a package with the lock can be closed at the
+// block num, by its creator when refund is set or else by its owner.
+func CheckPkgClose(lock *PkgLock, num uint64, refund bool, preimage *c_type.Uint256) error {
+	if lock == nil {
+		if refund {
+			return errors.New("pkg is not locked and can not be refunded")
+		}
+		return nil
+	}
+	expired := lock.Expiry != 0 && num >= lock.Expiry
+	if refund {
+		if !expired {
+			return errors.New("pkg lock is not expired")
+		}
+		return nil
+	}
+	if expired {
+		return errors.New("pkg lock is expired")
+	}
+	if num < lock.Height {
+		return errors.New("pkg lock height is not reached")
+	}
+	if lock.HashLock != (c_type.Uint256{}) {
+		if preimage == nil || PreimageToHash(preimage) != lock.HashLock {
+			return errors.New("pkg lock preimage is invalid")
+		}
+	}
+	return nil
+}
+
 type PkgCreate struct {
 	Id    c_type.Uint256
 	PKr   c_type.PKr
 	Pkg   pkg.Pkg_Z
 	Proof c_type.Proof
+	Lock  []PkgLock `rlp:"tail"`
+}
+
+func (self *PkgCreate) GetLock() *PkgLock {
+	if len(self.Lock) == 0 {
+		return nil
+	}
+	return &self.Lock[0]
+}
+
+func (self *PkgCreate) writeLock(d hash.Hash) {
+	if lock := self.GetLock(); lock != nil {
+		d.Write(lock.ToHash().NewRef()[:])
+	}
 }
 
 func (self *PkgCreate) Tx1_Hash() (ret c_type.Uint256) {
@@ -112,6 +210,7 @@ func (self *PkgCreate) Tx1_Hash() (ret c_type.Uint256) {
 	d.Write(self.Id[:])
 	d.Write(self.PKr[:])
 	d.Write(self.Pkg.ToHash().NewRef()[:])
+	self.writeLock(d)
 	copy(ret[:], d.Sum(nil))
 	return
 }
@@ -127,6 +226,7 @@ func (self *PkgCreate) ToHash() (ret c_type.Uint256) {
 	d.Write(self.PKr[:])
 	d.Write(self.Pkg.ToHash().NewRef()[:])
 	d.Write(ProofToHash(&self.Proof).NewRef()[:])
+	self.writeLock(d)
 	copy(ret[:], d.Sum(nil))
 	return ret
 }
@@ -145,6 +245,7 @@ func (self *PkgCreate) ToHash_for_sign() (ret c_type.Uint256) {
 	d.Write(self.PKr[:])
 	d.Write(self.Pkg.ToHash().NewRef()[:])
 	d.Write(ProofToHash(&self.Proof).NewRef()[:])
+	self.writeLock(d)
 	copy(ret[:], d.Sum(nil))
 	return ret
 }
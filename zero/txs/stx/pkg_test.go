@@ -0,0 +1,82 @@
+package stx
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/dece-cash/go-dece/czero/c_type"
+	"github.com/dece-cash/go-dece/rlp"
+)
+
+func TestPkgLock_RlpCompatible(t *testing.T) {
+	old := struct {
+		Id   c_type.Uint256
+		Sign c_type.Uint512
+	}{c_type.RandUint256(), c_type.Uint512{1}}
+	close := PkgClose{Id: old.Id, Sign: old.Sign}
+
+	b1, _ := rlp.EncodeToBytes(&old)
+	b2, _ := rlp.EncodeToBytes(&close)
+	if !bytes.Equal(b1, b2) {
+		t.Fatal("pkg close without preimage is not encoded as before")
+	}
+	if close.ToHash() != (&PkgClose{Id: old.Id, Sign: old.Sign, Preimage: []c_type.Uint256{}}).ToHash() {
+		t.Fatal("empty preimage changes the hash")
+	}
+
+	close.Preimage = []c_type.Uint256{c_type.RandUint256()}
+	b2, _ = rlp.EncodeToBytes(&close)
+	var decoded PkgClose
+	if err := rlp.DecodeBytes(b2, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.GetPreimage() == nil || *decoded.GetPreimage() != close.Preimage[0] {
+		t.Fatal("preimage is not decoded")
+	}
+}
+
+func TestCheckPkgClose(t *testing.T) {
+	preimage := c_type.RandUint256()
+	wrong := c_type.RandUint256()
+	lock := &PkgLock{Height: 10, HashLock: PreimageToHash(&preimage), Expiry: 20}
+
+	tests := []struct {
+		lock     *PkgLock
+		num      uint64
+		refund   bool
+		preimage *c_type.Uint256
+		ok       bool
+	}{
+		{nil, 0, false, nil, true},
+		{nil, 100, true, nil, false},
+		{lock, 9, false, &preimage, false},
+		{lock, 10, false, &preimage, true},
+		{lock, 15, false, nil, false},
+		{lock, 15, false, &wrong, false},
+		{lock, 20, false, &preimage, false},
+		{lock, 19, true, nil, false},
+		{lock, 20, true, nil, true},
+		{&PkgLock{Height: 10}, 10, false, nil, true},
+		{&PkgLock{Height: 10}, 1000, true, nil, false},
+	}
+	for i, test := range tests {
+		err := CheckPkgClose(test.lock, test.num, test.refund, test.preimage)
+		if (err == nil) != test.ok {
+			t.Errorf("test %d: have error %v, want ok %v", i, err, test.ok)
+		}
+	}
+
+	if (&PkgLock{Height: 20, Expiry: 20}).Valid() {
+		t.Error("lock expiring at its height is valid")
+	}
+}
+
+func TestPreimageToHash(t *testing.T) {
+	// The hash locks are sha256, as OP_SHA256 of the bitcoin family HTLCs
+	var preimage c_type.Uint256
+	hash := PreimageToHash(&preimage)
+	if want := "66687aadf862bd776c8fc18b8e9f8e20089714856ee233b3902a591d0d5f2925"; hex.EncodeToString(hash[:]) != want {
+		t.Errorf("hash mismatch: have %x, want %s", hash[:], want)
+	}
+}
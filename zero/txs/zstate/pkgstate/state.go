@@ -81,13 +81,21 @@ func (self *PkgState) Force_del(hash *c_type.Uint256, close *stx.PkgClose) (e er
 		e = fmt.Errorf("Close Pkg is nil: %v", hexutil.Encode(close.Id[:]))
 		return
 	} else {
+		var refund bool
 		if c_superzk.VerifyPKr_X(hash, &close.Sign, &pg.Pack.PKr) {
-			pg.Closed = true
-			self.data.Add(pg)
+			refund = false
+		} else if pg.Pack.GetLock() != nil && c_superzk.VerifyPKr_X(hash, &close.Sign, &pg.From) {
+			refund = true
 		} else {
 			e = fmt.Errorf("Close Pkg signed error: %v", hexutil.Encode(close.Id[:]))
 			return
 		}
+		if err := stx.CheckPkgClose(pg.Pack.GetLock(), self.num, refund, close.GetPreimage()); err != nil {
+			e = fmt.Errorf("Close Pkg %v: %v", err, hexutil.Encode(close.Id[:]))
+			return
+		}
+		pg.Closed = true
+		self.data.Add(pg)
 		return
 	}
 }
@@ -145,6 +153,9 @@ func (self *PkgState) Close(id *c_type.Uint256, pkr *c_type.PKr, key *c_type.Uin
 		if pg.Pack.PKr != *pkr {
 			e = fmt.Errorf("Close Pkg Owner Check Failed: %v", hexutil.Encode(id[:]))
 			return
+		} else if err := stx.CheckPkgClose(pg.Pack.GetLock(), self.num, false, nil); err != nil {
+			e = fmt.Errorf("Close Pkg %v: %v", err, hexutil.Encode(id[:]))
+			return
 		} else {
 			if ret.O, e = pkg.DePkg(key, &pg.Pack.Pkg); e != nil {
 				return
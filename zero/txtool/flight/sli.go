@@ -15,6 +15,16 @@ import (
 )
 
 func GenTx(param *txtool.GTxParam) (gtx txtool.GTx, e error) {
+	return genTx(param, nil)
+}
+
+// GenTxWithSigner generates the tx signed by a signer keeping the sk out of the
+// process, the SKr of param is not used.
+func GenTxWithSigner(param *txtool.GTxParam, signer generate_1.Signer) (gtx txtool.GTx, e error) {
+	return genTx(param, signer)
+}
+
+func genTx(param *txtool.GTxParam, signer generate_1.Signer) (gtx txtool.GTx, e error) {
 	var need_szk = true
 	var z = false
 	if (txtool.Ref_inst.Bc != nil) && (!deceparam.Is_Offline()) {
@@ -28,7 +38,7 @@ func GenTx(param *txtool.GTxParam) (gtx txtool.GTx, e error) {
 	param.Z = &z
 
 	if need_szk {
-		if tx, param, keys, bases, err := SignTx1(param); err != nil {
+		if tx, param, keys, bases, err := signTx1(param, signer); err != nil {
 			e = err
 			return
 		} else {
@@ -16,7 +16,11 @@ func SignTx0(param *txtool.GTxParam) (gtx txtool.GTx, e error) {
 }
 
 func SignTx1(txParam *txtool.GTxParam) (tx stx.T, param txtool.GTxParam, keys []c_type.Uint256, bases []c_type.Uint256, e error) {
-	if ctx, err := generate_1.SignTx(txParam); err != nil {
+	return signTx1(txParam, nil)
+}
+
+func signTx1(txParam *txtool.GTxParam, signer generate_1.Signer) (tx stx.T, param txtool.GTxParam, keys []c_type.Uint256, bases []c_type.Uint256, e error) {
+	if ctx, err := generate_1.SignTxWithSigner(txParam, signer); err != nil {
 		e = err
 		return
 	} else {
@@ -29,6 +29,15 @@ type sign_ctx struct {
 	bases        []c_type.Uint256
 	s            stx.T
 	ck           assets.CKState
+	signer       Signer
+}
+
+// Signer makes the signatures of a spending key kept out of the process, by a
+// hardware wallet. The tk of the key is enough for the rest of the tx.
+type Signer interface {
+	Tk() c_type.Tk
+	SignPKr(data *c_type.Uint256, pkr *c_type.PKr) (c_type.Uint512, error)
+	SignZPKa(data *c_type.Uint256, a *c_type.Uint256, pkr *c_type.PKr) (c_type.Uint512, error)
 }
 
 func (self *sign_ctx) Tx() (ret stx.T) {
@@ -52,7 +61,18 @@ func (self *sign_ctx) Bases() (ret []c_type.Uint256) {
 }
 
 func SignTx(param *txtool.GTxParam) (ctx sign_ctx, e error) {
+	return signTx(param, nil)
+}
+
+// SignTxWithSigner signs the tx with the signer instead of the SKr of the
+// param.
+func SignTxWithSigner(param *txtool.GTxParam, signer Signer) (ctx sign_ctx, e error) {
+	return signTx(param, signer)
+}
+
+func signTx(param *txtool.GTxParam, signer Signer) (ctx sign_ctx, e error) {
 	ctx.param = *param
+	ctx.signer = signer
 	if e = ctx.check(); e != nil {
 		return
 	}
@@ -86,16 +106,22 @@ func SignTx(param *txtool.GTxParam) (ctx sign_ctx, e error) {
 	return
 }
 
+func (self *sign_ctx) tk(skr *c_type.PKr) (c_type.Tk, error) {
+	if self.signer != nil {
+		return self.signer.Tk(), nil
+	}
+	return superzk.Sk2Tk(skr.ToUint512().NewRef())
+}
+
 func (self *sign_ctx) check() (e error) {
-	sk := self.param.From.SKr.ToUint512()
-	tk, e := superzk.Sk2Tk(&sk)
+	tk, e := self.tk(&self.param.From.SKr)
 	if !superzk.IsMyPKr(&tk, &self.param.From.PKr) {
 		e = errors.New("sk unmatch pkr for the From field")
 		return
 	}
 
 	for _, in := range self.param.Ins {
-		tk, _ := superzk.Sk2Tk(in.SKr.ToUint512().NewRef())
+		tk, _ := self.tk(&in.SKr)
 
 		if in.Out.State.OS.Out_P != nil {
 			if !superzk.IsMyPKr(&tk, &in.Out.State.OS.Out_P.PKr) {
@@ -189,6 +215,9 @@ func (self *sign_ctx) genCmd() (e error) {
 		self.s.Desc_Pkg.Create = &stx.PkgCreate{}
 		self.s.Desc_Pkg.Create.PKr = create.PKr
 		self.s.Desc_Pkg.Create.Id = create.Id
+		if create.Lock != nil {
+			self.s.Desc_Pkg.Create.Lock = []stx.PkgLock{*create.Lock}
+		}
 		create.Ar = c_superzk.RandomFr()
 		if cm, _, err := c_superzk.GenAssetCM_PC(create.Asset.ToTypeAsset().NewRef(), &create.Ar); err != nil {
 			e = err
@@ -196,8 +225,7 @@ func (self *sign_ctx) genCmd() (e error) {
 		} else {
 			self.s.Desc_Pkg.Create.Pkg.AssetCM = cm
 		}
-		sk := self.param.From.SKr.ToUint512()
-		tk, err := superzk.Sk2Tk(&sk)
+		tk, err := self.tk(&self.param.From.SKr)
 		if err != nil {
 			e = err
 			return
@@ -220,6 +248,9 @@ func (self *sign_ctx) genCmd() (e error) {
 		close := self.param.Cmds.PkgClose
 		self.s.Desc_Pkg.Close = &stx.PkgClose{}
 		self.s.Desc_Pkg.Close.Id = close.Id
+		if close.Preimage != nil {
+			self.s.Desc_Pkg.Close.Preimage = []c_type.Uint256{*close.Preimage}
+		}
 		self.balance_desc.Zin_acms = append(self.balance_desc.Zin_acms, close.AssetCM[:]...)
 		self.balance_desc.Zin_ars = append(self.balance_desc.Zin_ars, close.Ar[:]...)
 	}
@@ -237,8 +268,7 @@ func (self *sign_ctx) genCmd() (e error) {
 
 func (self *sign_ctx) genInsP() (e error) {
 	for _, in := range self.p_ins {
-		sk := in.SKr.ToUint512()
-		tk, _ := superzk.Sk2Tk(&sk)
+		tk, _ := self.tk(&in.SKr)
 
 		t_in := tx.In_P{}
 		t_in.Root = in.Out.Root
@@ -282,8 +312,7 @@ func (self *sign_ctx) genInsP() (e error) {
 
 func (self *sign_ctx) genInsC() (e error) {
 	for _, in := range self.c_ins {
-		sk := in.SKr.ToUint512()
-		tk, _ := superzk.Sk2Tk(&sk)
+		tk, _ := self.tk(&in.SKr)
 
 		t_in := tx.In_C{}
 
@@ -406,7 +435,7 @@ func (self *sign_ctx) genSign() (e error) {
 }
 
 func (self *sign_ctx) signFrom() (e error) {
-	if sign, err := c_superzk.SignPKr_X(self.param.From.SKr.ToUint512().NewRef(), &self.balance_desc.Hash, &self.s.From); err != nil {
+	if sign, err := self.signPKr_X(&self.param.From.SKr, &self.s.From); err != nil {
 		return err
 	} else {
 		self.s.Sign = sign
@@ -415,15 +444,36 @@ func (self *sign_ctx) signFrom() (e error) {
 	return
 }
 
+func (self *sign_ctx) signPKr_X(skr *c_type.PKr, pkr *c_type.PKr) (c_type.Uint512, error) {
+	if self.signer != nil {
+		return self.signer.SignPKr(&self.balance_desc.Hash, pkr)
+	}
+	return c_superzk.SignPKr_X(skr.ToUint512().NewRef(), &self.balance_desc.Hash, pkr)
+}
+
+func (self *sign_ctx) signPKr_P(skr *c_type.PKr, pkr *c_type.PKr) (c_type.Uint512, error) {
+	if self.signer != nil {
+		return self.signer.SignPKr(&self.balance_desc.Hash, pkr)
+	}
+	return c_superzk.SignPKr_P(skr.ToUint512().NewRef(), &self.balance_desc.Hash, pkr)
+}
+
+func (self *sign_ctx) signZPKa(skr *c_type.PKr, a *c_type.Uint256, pkr *c_type.PKr) (c_type.Uint512, error) {
+	if self.signer != nil {
+		return self.signer.SignZPKa(&self.balance_desc.Hash, a, pkr)
+	}
+	return c_superzk.SignZPKa(skr.ToUint512().NewRef(), &self.balance_desc.Hash, a, pkr)
+}
+
 func (self *sign_ctx) signInsP() (e error) {
 	for i := range self.s.Tx1.Ins_P {
 		t_in := self.p_ins[i]
-		if sign, err := c_superzk.SignPKr_P(t_in.SKr.ToUint512().NewRef(), &self.balance_desc.Hash, t_in.Out.State.OS.ToPKr()); err != nil {
+		if sign, err := self.signPKr_P(&t_in.SKr, t_in.Out.State.OS.ToPKr()); err != nil {
 			return err
 		} else {
 			self.s.Tx1.Ins_P[i].ASign = sign
 		}
-		tk, _ := superzk.Sk2Tk(t_in.SKr.ToUint512().NewRef())
+		tk, _ := self.tk(&t_in.SKr)
 		if sign, err := c_superzk.SignNil(
 			&tk,
 			&self.balance_desc.Hash,
@@ -441,7 +491,7 @@ func (self *sign_ctx) signInsP() (e error) {
 func (self *sign_ctx) signInsC() (e error) {
 	for i := range self.s.Tx1.Ins_C {
 		t_in := self.c_ins[i]
-		if sign, err := c_superzk.SignZPKa(t_in.SKr.ToUint512().NewRef(), &self.balance_desc.Hash, t_in.A, t_in.Out.State.OS.ToPKr()); err != nil {
+		if sign, err := self.signZPKa(&t_in.SKr, t_in.A, t_in.Out.State.OS.ToPKr()); err != nil {
 			e = err
 			return
 		} else {
@@ -453,17 +503,17 @@ func (self *sign_ctx) signInsC() (e error) {
 
 func (self *sign_ctx) signPkg() error {
 	if self.param.Cmds.PkgTransfer != nil {
-		if sign, err := c_superzk.SignPKr_X(self.param.From.SKr.ToUint512().NewRef(), &self.balance_desc.Hash, &self.param.Cmds.PkgTransfer.Owner); err != nil {
+		if sign, err := self.signPKr_X(&self.param.From.SKr, &self.param.Cmds.PkgTransfer.Owner); err != nil {
 			return err
 		} else {
 			self.s.Desc_Pkg.Transfer.Sign = sign
 		}
 	}
 	if self.param.Cmds.PkgClose != nil {
-		if sign, err := c_superzk.SignPKr_X(self.param.From.SKr.ToUint512().NewRef(), &self.balance_desc.Hash, &self.param.Cmds.PkgClose.Owner); err != nil {
+		if sign, err := self.signPKr_X(&self.param.From.SKr, &self.param.Cmds.PkgClose.Owner); err != nil {
 			return err
 		} else {
-			self.s.Desc_Pkg.Transfer.Sign = sign
+			self.s.Desc_Pkg.Close.Sign = sign
 		}
 	}
 	return nil
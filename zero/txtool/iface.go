@@ -41,10 +41,11 @@ type GTx struct {
 }
 
 type GPkgCloseCmd struct {
-	Id      c_type.Uint256
-	Owner   c_type.PKr
-	AssetCM c_type.Uint256
-	Ar      c_type.Uint256
+	Id       c_type.Uint256
+	Owner    c_type.PKr
+	AssetCM  c_type.Uint256
+	Ar       c_type.Uint256
+	Preimage *c_type.Uint256
 }
 
 type GPkgTransferCmd struct {
@@ -59,6 +60,7 @@ type GPkgCreateCmd struct {
 	Asset assets.Asset
 	Memo  c_type.Uint512
 	Ar    c_type.Uint256
+	Lock  *stx.PkgLock
 }
 
 type Cmds struct {
@@ -194,6 +194,7 @@ func BuildTxParam(
 		txParam.Cmds.PkgCreate.PKr = param.Cmds.PkgCreate.PKr
 		txParam.Cmds.PkgCreate.Asset = param.Cmds.PkgCreate.Asset
 		txParam.Cmds.PkgCreate.Memo = param.Cmds.PkgCreate.Memo
+		txParam.Cmds.PkgCreate.Lock = param.Cmds.PkgCreate.Lock
 	}
 
 	if param.Cmds.PkgTransfer != nil {
@@ -214,13 +215,23 @@ func BuildTxParam(
 	}
 
 	if param.Cmds.PkgClose != nil {
-		if p := state.GetPkgById(&param.Cmds.PkgTransfer.Id); p == nil {
+		if p := state.GetPkgById(&param.Cmds.PkgClose.Id); p == nil {
 			e = errors.New("close pkg but the pkg id is not exsits")
 			return
 		} else {
 			if !p.Closed {
+				txParam.Cmds.PkgClose = &txtool.GPkgCloseCmd{}
 				txParam.Cmds.PkgClose.Id = param.Cmds.PkgClose.Id
-				txParam.Cmds.PkgClose.Owner = p.Pack.PKr
+				if param.Cmds.PkgClose.Refund {
+					if p.Pack.GetLock() == nil {
+						e = errors.New("refund pkg but the pkg is not locked")
+						return
+					}
+					txParam.Cmds.PkgClose.Owner = p.From
+				} else {
+					txParam.Cmds.PkgClose.Owner = p.Pack.PKr
+					txParam.Cmds.PkgClose.Preimage = param.Cmds.PkgClose.Preimage
+				}
 				txParam.Cmds.PkgClose.AssetCM = p.Pack.Pkg.AssetCM
 				if opkg, err := pkg.DePkg(&param.Cmds.PkgClose.Key, &p.Pack.Pkg); err != nil {
 					e = errors.New("close pkg but password is error")
@@ -21,9 +21,13 @@ type Reception struct {
 	Asset assets.Asset
 }
 
+// PkgCloseCmd closes a package with its key. Preimage unlocks a hash-locked
+// package and Refund closes an expired package back to its creator.
 type PkgCloseCmd struct {
-	Id  c_type.Uint256
-	Key c_type.Uint256
+	Id       c_type.Uint256
+	Key      c_type.Uint256
+	Preimage *c_type.Uint256
+	Refund   bool
 }
 
 func (self *PkgCloseCmd) Asset() (ret assets.Asset, e error) {
@@ -51,6 +55,7 @@ type PkgCreateCmd struct {
 	PKr   c_type.PKr
 	Asset assets.Asset
 	Memo  c_type.Uint512
+	Lock  *stx.PkgLock
 }
 
 type Cmds struct {
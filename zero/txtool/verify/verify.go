@@ -12,5 +12,5 @@ func VerifyWithoutState(ehash *c_type.Uint256, tx *stx.T, num uint64) (e error)
 }
 
 func VerifyWithState(tx *stx.T, state *zstate.ZState, num uint64) (e error) {
-	return verify_1.VerifyWithState(tx, state)
+	return verify_1.VerifyWithState(tx, state, num)
 }
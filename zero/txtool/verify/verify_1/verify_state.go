@@ -19,14 +19,16 @@ import (
 type verifyWithStateCtx struct {
 	tx           *stx.T
 	state        *zstate.ZState
+	num          uint64
 	balance_desc c_type.BalanceDesc
 	ck           assets.CKState
 }
 
-func VerifyWithState(tx *stx.T, state *zstate.ZState) (e error) {
+func VerifyWithState(tx *stx.T, state *zstate.ZState, num uint64) (e error) {
 	ctx := verifyWithStateCtx{}
 	ctx.tx = tx
 	ctx.state = state
+	ctx.num = num
 	return ctx.verify()
 }
 
@@ -86,12 +88,21 @@ func (self *verifyWithStateCtx) verifyPkg() (e error) {
 			e = verify_utils.ReportError(fmt.Sprintf("Can not find pkg of the id %v", hexutil.Encode(self.tx.Desc_Pkg.Close.Id[:])), self.tx)
 			return
 		} else {
-			if c_superzk.VerifyPKr_X(&self.balance_desc.Hash, &self.tx.Desc_Pkg.Close.Sign, &pg.Pack.PKr) {
-				self.balance_desc.Zin_acms = append(self.balance_desc.Zin_acms, pg.Pack.Pkg.AssetCM[:]...)
+			close := self.tx.Desc_Pkg.Close
+			var refund bool
+			if c_superzk.VerifyPKr_X(&self.balance_desc.Hash, &close.Sign, &pg.Pack.PKr) {
+				refund = false
+			} else if pg.Pack.GetLock() != nil && c_superzk.VerifyPKr_X(&self.balance_desc.Hash, &close.Sign, &pg.From) {
+				refund = true
 			} else {
-				e = verify_utils.ReportError(fmt.Sprintf("Can not verify pkg sign of the id %v", hexutil.Encode(self.tx.Desc_Pkg.Close.Id[:])), self.tx)
+				e = verify_utils.ReportError(fmt.Sprintf("Can not verify pkg sign of the id %v", hexutil.Encode(close.Id[:])), self.tx)
 				return
 			}
+			if err := stx.CheckPkgClose(pg.Pack.GetLock(), self.num, refund, close.GetPreimage()); err != nil {
+				e = verify_utils.ReportError(fmt.Sprintf("%v of the id %v", err, hexutil.Encode(close.Id[:])), self.tx)
+				return
+			}
+			self.balance_desc.Zin_acms = append(self.balance_desc.Zin_acms, pg.Pack.Pkg.AssetCM[:]...)
 		}
 	}
 	return
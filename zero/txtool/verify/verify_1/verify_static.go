@@ -84,14 +84,46 @@ func (self *verifyWithoutStateCtx) verifyPkg() (e error) {
 		return
 	}
 	if self.tx.Desc_Pkg.Create != nil {
+		if e = self.verifyPkgLock(self.tx.Desc_Pkg.Create); e != nil {
+			return
+		}
 		self.zout_count++
 	}
 	if self.tx.Desc_Pkg.Close != nil {
+		if len(self.tx.Desc_Pkg.Close.Preimage) > 0 {
+			if self.num < deceparam.SIP7() {
+				e = verify_utils.ReportError("pkg close preimage is not allowed", self.tx)
+				return
+			}
+			if len(self.tx.Desc_Pkg.Close.Preimage) > 1 {
+				e = verify_utils.ReportError("pkg close has more than one preimage", self.tx)
+				return
+			}
+		}
 		self.zin_count++
 	}
 	return
 }
 
+func (self *verifyWithoutStateCtx) verifyPkgLock(create *stx.PkgCreate) (e error) {
+	if len(create.Lock) == 0 {
+		return
+	}
+	if self.num < deceparam.SIP7() {
+		e = verify_utils.ReportError("pkg create lock is not allowed", self.tx)
+		return
+	}
+	if len(create.Lock) > 1 {
+		e = verify_utils.ReportError("pkg create has more than one lock", self.tx)
+		return
+	}
+	if !create.Lock[0].Valid() {
+		e = verify_utils.ReportError("pkg create lock expires before its height", self.tx)
+		return
+	}
+	return
+}
+
 func (self *verifyWithoutStateCtx) verifyCmd() (e error) {
 	if !self.tx.Desc_Cmd.Valid() {
 		e = verify_utils.ReportError("cmd desc is invalid", self.tx)
@@ -0,0 +1,366 @@
+package atomicswap
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"sync/atomic"
+
+	"github.com/robfig/cron"
+
+	"github.com/dece-cash/go-dece/core/types"
+	"github.com/dece-cash/go-dece/czero/c_type"
+	"github.com/dece-cash/go-dece/czero/deceparam"
+	"github.com/dece-cash/go-dece/decedb"
+	"github.com/dece-cash/go-dece/log"
+	"github.com/dece-cash/go-dece/rlp"
+	"github.com/dece-cash/go-dece/zero/localdb"
+	"github.com/dece-cash/go-dece/zero/txs/assets"
+	"github.com/dece-cash/go-dece/zero/txs/pkg"
+	"github.com/dece-cash/go-dece/zero/txs/stx"
+	"github.com/dece-cash/go-dece/zero/utils"
+)
+
+// Backend sends the package transactions of the swaps, it is implemented by
+// the exchange.
+type Backend interface {
+	CreatePkg(pk *c_type.Uint512, to c_type.PKr, token assets.Token, lock *stx.PkgLock, gasPrice *big.Int) (id c_type.Uint256, key c_type.Uint256, txhash c_type.Uint256, e error)
+	ClosePkg(pk *c_type.Uint512, id *c_type.Uint256, key *c_type.Uint256, preimage *c_type.Uint256, gasPrice *big.Int) (txhash c_type.Uint256, e error)
+	RefundPkg(pk *c_type.Uint512, id *c_type.Uint256, key *c_type.Uint256, gasPrice *big.Int) (txhash c_type.Uint256, e error)
+}
+
+// Chain is the chain watched for the packages of the swaps.
+type Chain interface {
+	CurrentBlock() *types.Block
+	GetBlockByNumber(number uint64) *types.Block
+}
+
+// PkgState finds the packages in the current state.
+type PkgState interface {
+	GetPkgById(id *c_type.Uint256) (ret *localdb.ZPkg)
+}
+
+type AtomicSwap struct {
+	db            *decedb.LDBDatabase
+	backend       Backend
+	chain         Chain
+	state         PkgState
+	confirmations uint64
+	lock          sync.Mutex
+}
+
+var current_AtomicSwap *AtomicSwap
+
+func CurrentAtomicSwap() *AtomicSwap {
+	return current_AtomicSwap
+}
+
+func NewAtomicSwap(dbpath string, backend Backend, chain Chain, state PkgState) *AtomicSwap {
+	swap := newAtomicSwap(dbpath, backend, chain, state, deceparam.DefaultConfirmedBlock())
+	current_AtomicSwap = swap
+	AddJob("0/10 * * * * ?", swap.watch)
+	return swap
+}
+
+func newAtomicSwap(dbpath string, backend Backend, chain Chain, state PkgState, confirmations uint64) *AtomicSwap {
+	db, err := decedb.NewLDBDatabase(dbpath, 16, 16)
+	if err != nil {
+		panic(err)
+	}
+	swap := &AtomicSwap{
+		db:            db,
+		backend:       backend,
+		chain:         chain,
+		state:         state,
+		confirmations: confirmations,
+	}
+	if _, err := db.Get(numKey); err != nil {
+		swap.db.Put(numKey, utils.EncodeNumber(chain.CurrentBlock().NumberU64()))
+	}
+	return swap
+}
+
+// Initiate locks token in a package for the counterparty to, with the hash of
+// a new secret. The counterparty can redeem it until the expiry block with the
+// secret, which the initiator reveals when redeeming the swap on the other
+// chain.
+func (self *AtomicSwap) Initiate(pk *c_type.Uint512, to c_type.PKr, token assets.Token, expiry uint64, gasPrice *big.Int) (swap *Swap, e error) {
+	secret := c_type.RandUint256()
+	return self.create(RoleInitiator, pk, to, token, stx.PreimageToHash(&secret), &secret, expiry, gasPrice)
+}
+
+// Participate locks token in a package for the counterparty to, with the hash
+// of the counterparty's secret. The secret is learnt from the chain when the
+// counterparty redeems the package.
+func (self *AtomicSwap) Participate(pk *c_type.Uint512, to c_type.PKr, token assets.Token, hash c_type.Uint256, expiry uint64, gasPrice *big.Int) (swap *Swap, e error) {
+	return self.create(RoleParticipant, pk, to, token, hash, nil, expiry, gasPrice)
+}
+
+func (self *AtomicSwap) create(role string, pk *c_type.Uint512, to c_type.PKr, token assets.Token, hash c_type.Uint256, secret *c_type.Uint256, expiry uint64, gasPrice *big.Int) (swap *Swap, e error) {
+	if expiry <= self.chain.CurrentBlock().NumberU64() {
+		e = errors.New("swap expiry is already reached")
+		return
+	}
+	lock := stx.PkgLock{HashLock: hash, Expiry: expiry}
+	id, key, txhash, err := self.backend.CreatePkg(pk, to, token, &lock, gasPrice)
+	if err != nil {
+		e = err
+		return
+	}
+	swap = &Swap{
+		Id:       id,
+		Role:     role,
+		State:    StatePending,
+		Pk:       *pk,
+		Owner:    to,
+		Token:    token,
+		Key:      key,
+		Lock:     lock,
+		CreateTx: txhash,
+	}
+	if secret != nil {
+		swap.Secret = []c_type.Uint256{*secret}
+	}
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.putSwap(swap)
+	return
+}
+
+// Audit checks the package id created by the counterparty for pk against the
+// agreed hash, before locking the other side of the swap, and tracks it.
+func (self *AtomicSwap) Audit(pk *c_type.Uint512, id *c_type.Uint256, key *c_type.Uint256, hash c_type.Uint256) (swap *Swap, e error) {
+	zpkg := self.state.GetPkgById(id)
+	if zpkg == nil || zpkg.Closed {
+		e = errors.New("swap pkg is not found")
+		return
+	}
+	lock := zpkg.Pack.GetLock()
+	if lock == nil || lock.HashLock != hash {
+		e = errors.New("swap pkg is not locked by the hash")
+		return
+	}
+	if lock.Expiry <= self.chain.CurrentBlock().NumberU64() {
+		e = errors.New("swap pkg is expired")
+		return
+	}
+	opkg, err := pkg.DePkg(key, &zpkg.Pack.Pkg)
+	if err != nil {
+		e = err
+		return
+	}
+	if opkg.Asset.Tkn == nil {
+		e = errors.New("swap pkg has no token")
+		return
+	}
+	swap = &Swap{
+		Id:    *id,
+		Role:  RoleRecipient,
+		State: StateLocked,
+		Pk:    *pk,
+		Owner: zpkg.Pack.PKr,
+		Token: *opkg.Asset.Tkn,
+		Key:   *key,
+		Lock:  *lock,
+	}
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.putSwap(swap)
+	return
+}
+
+// Redeem closes the package of a swap received from the counterparty with the
+// secret, revealing it on the chain.
+func (self *AtomicSwap) Redeem(id *c_type.Uint256, secret c_type.Uint256, gasPrice *big.Int) (txhash c_type.Uint256, e error) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	swap := self.getSwap(id)
+	if swap == nil || swap.Creator() {
+		e = errors.New("swap is not received by the account")
+		return
+	}
+	if swap.Closed() {
+		e = errors.New("swap is already closed")
+		return
+	}
+	if stx.PreimageToHash(&secret) != swap.Lock.HashLock {
+		e = errors.New("swap secret does not match the hash")
+		return
+	}
+	if txhash, e = self.backend.ClosePkg(&swap.Pk, id, &swap.Key, &secret, gasPrice); e != nil {
+		return
+	}
+	swap.Secret = []c_type.Uint256{secret}
+	swap.CloseTx = txhash
+	self.putSwap(swap)
+	return
+}
+
+// Refund closes the package of a swap created by the account back to it once
+// the swap is expired.
+func (self *AtomicSwap) Refund(id *c_type.Uint256, gasPrice *big.Int) (txhash c_type.Uint256, e error) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	swap := self.getSwap(id)
+	if swap == nil || !swap.Creator() {
+		e = errors.New("swap is not created by the account")
+		return
+	}
+	if swap.Closed() {
+		e = errors.New("swap is already closed")
+		return
+	}
+	if self.chain.CurrentBlock().NumberU64()+1 < swap.Lock.Expiry {
+		e = errors.New("swap is not expired")
+		return
+	}
+	if txhash, e = self.backend.RefundPkg(&swap.Pk, id, &swap.Key, gasPrice); e != nil {
+		return
+	}
+	swap.CloseTx = txhash
+	self.putSwap(swap)
+	return
+}
+
+func (self *AtomicSwap) GetSwap(id *c_type.Uint256) *Swap {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	return self.getSwap(id)
+}
+
+// GetSwaps returns the swaps of the account pk, or all of them when pk is nil.
+func (self *AtomicSwap) GetSwaps(pk *c_type.Uint512) (swaps []Swap) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	iterator := self.db.NewIteratorWithPrefix(swapPrefix)
+	defer iterator.Release()
+	for iterator.Next() {
+		var swap Swap
+		if e := rlp.DecodeBytes(iterator.Value(), &swap); e != nil {
+			log.Error("AtomicSwap decode swap error", "error", e)
+			continue
+		}
+		if pk == nil || swap.Pk == *pk {
+			swaps = append(swaps, swap)
+		}
+	}
+	return
+}
+
+func (self *AtomicSwap) getSwap(id *c_type.Uint256) *Swap {
+	bs, err := self.db.Get(swapKey(id))
+	if err != nil {
+		return nil
+	}
+	var swap Swap
+	if e := rlp.DecodeBytes(bs, &swap); e != nil {
+		log.Error("AtomicSwap decode swap error", "id", id, "error", e)
+		return nil
+	}
+	return &swap
+}
+
+func (self *AtomicSwap) putSwap(swap *Swap) {
+	if bs, e := rlp.EncodeToBytes(swap); e != nil {
+		panic(e)
+	} else {
+		if e := self.db.Put(swapKey(&swap.Id), bs); e != nil {
+			panic(e)
+		}
+	}
+}
+
+// watch follows the packages of the open swaps in the new blocks, and learns
+// the secrets revealed by the counterparties redeeming them. Only the blocks
+// with enough confirmations are followed, the state of a swap never coming
+// from a block a reorganization could drop.
+func (self *AtomicSwap) watch() {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	var start uint64
+	if bs, err := self.db.Get(numKey); err == nil {
+		start = utils.DecodeNumber(bs) + 1
+	}
+	current := self.chain.CurrentBlock().NumberU64()
+	if current < start+self.confirmations {
+		return
+	}
+	current -= self.confirmations
+
+	open := make(map[c_type.Uint256]*Swap)
+	iterator := self.db.NewIteratorWithPrefix(swapPrefix)
+	for iterator.Next() {
+		var swap Swap
+		if e := rlp.DecodeBytes(iterator.Value(), &swap); e == nil && !swap.Closed() {
+			open[swap.Id] = &swap
+		}
+	}
+	iterator.Release()
+
+	for num := start; num <= current; num++ {
+		block := self.chain.GetBlockByNumber(num)
+		if block == nil {
+			break
+		}
+		for _, tx := range block.Transactions() {
+			ztx := tx.GetZZSTX()
+			if create := ztx.Desc_Pkg.Create; create != nil {
+				if swap, ok := open[create.Id]; ok && swap.State == StatePending {
+					swap.State = StateLocked
+					self.putSwap(swap)
+				}
+			}
+			if close := ztx.Desc_Pkg.Close; close != nil {
+				if swap, ok := open[close.Id]; ok {
+					if preimage := close.GetPreimage(); preimage != nil {
+						if stx.PreimageToHash(preimage) != swap.Lock.HashLock {
+							log.Error("AtomicSwap pkg closed with a wrong preimage", "id", swap.Id, "num", num)
+							continue
+						}
+						swap.State = StateRedeemed
+						swap.Secret = []c_type.Uint256{*preimage}
+						log.Info("AtomicSwap secret revealed", "id", swap.Id, "num", num)
+					} else {
+						swap.State = StateRefunded
+					}
+					copy(swap.CloseTx[:], tx.Hash().Bytes())
+					swap.CloseNum = num
+					self.putSwap(swap)
+					delete(open, close.Id)
+				}
+			}
+		}
+		self.db.Put(numKey, utils.EncodeNumber(num))
+	}
+}
+
+func AddJob(spec string, run RunFunc) *cron.Cron {
+	c := cron.New()
+	c.AddJob(spec, &RunJob{run: run})
+	c.Start()
+	return c
+}
+
+type (
+	RunFunc func()
+)
+
+type RunJob struct {
+	runing int32
+	run    RunFunc
+}
+
+func (r *RunJob) Run() {
+	x := atomic.LoadInt32(&r.runing)
+	if x == 1 {
+		return
+	}
+
+	atomic.StoreInt32(&r.runing, 1)
+	defer func() {
+		atomic.StoreInt32(&r.runing, 0)
+	}()
+
+	r.run()
+}
@@ -0,0 +1,303 @@
+package atomicswap
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dece-cash/go-dece/core/types"
+	"github.com/dece-cash/go-dece/czero/c_superzk"
+	"github.com/dece-cash/go-dece/czero/c_type"
+	"github.com/dece-cash/go-dece/zero/localdb"
+	"github.com/dece-cash/go-dece/zero/txs/assets"
+	"github.com/dece-cash/go-dece/zero/txs/stx"
+	"github.com/dece-cash/go-dece/zero/utils"
+)
+
+func TestMain(m *testing.M) {
+	c_superzk.InitParams_NoCircuit()
+	os.Exit(m.Run())
+}
+
+// testChain is the DECE chain, the package transactions sent by the backend
+// are mined in the next block.
+type testChain struct {
+	blocks  []*types.Block
+	pending []*types.Transaction
+	pkgs    map[c_type.Uint256]*localdb.ZPkg
+}
+
+func newTestChain() *testChain {
+	chain := &testChain{pkgs: make(map[c_type.Uint256]*localdb.ZPkg)}
+	chain.mine()
+	return chain
+}
+
+func (self *testChain) CurrentBlock() *types.Block {
+	return self.blocks[len(self.blocks)-1]
+}
+
+func (self *testChain) GetBlockByNumber(number uint64) *types.Block {
+	if number >= uint64(len(self.blocks)) {
+		return nil
+	}
+	return self.blocks[number]
+}
+
+func (self *testChain) GetPkgById(id *c_type.Uint256) *localdb.ZPkg {
+	return self.pkgs[*id]
+}
+
+func (self *testChain) send(desc stx.PkgDesc_Z) (txhash c_type.Uint256) {
+	tx := types.NewTxWithGTx(25000, big.NewInt(1), &stx.T{Desc_Pkg: desc})
+	self.pending = append(self.pending, tx)
+	copy(txhash[:], tx.Hash().Bytes())
+	return
+}
+
+func (self *testChain) mine() {
+	header := &types.Header{Number: big.NewInt(int64(len(self.blocks)))}
+	self.blocks = append(self.blocks, types.NewBlock(header, self.pending, nil))
+	self.pending = nil
+}
+
+// reorg replaces the last n blocks by empty ones, their transactions being
+// dropped.
+func (self *testChain) reorg(n int) {
+	self.blocks = self.blocks[:len(self.blocks)-n]
+	for i := 0; i < n; i++ {
+		self.mine()
+	}
+}
+
+func (self *testChain) CreatePkg(pk *c_type.Uint512, to c_type.PKr, token assets.Token, lock *stx.PkgLock, gasPrice *big.Int) (id c_type.Uint256, key c_type.Uint256, txhash c_type.Uint256, e error) {
+	id = c_type.RandUint256()
+	key = c_type.RandUint256()
+	create := stx.PkgCreate{Id: id, PKr: to, Lock: []stx.PkgLock{*lock}}
+	self.pkgs[id] = &localdb.ZPkg{High: uint64(len(self.blocks)), Pack: create}
+	txhash = self.send(stx.PkgDesc_Z{Create: &create})
+	return
+}
+
+func (self *testChain) ClosePkg(pk *c_type.Uint512, id *c_type.Uint256, key *c_type.Uint256, preimage *c_type.Uint256, gasPrice *big.Int) (txhash c_type.Uint256, e error) {
+	close := stx.PkgClose{Id: *id}
+	if preimage != nil {
+		close.Preimage = []c_type.Uint256{*preimage}
+	}
+	return self.send(stx.PkgDesc_Z{Close: &close}), nil
+}
+
+func (self *testChain) RefundPkg(pk *c_type.Uint512, id *c_type.Uint256, key *c_type.Uint256, gasPrice *big.Int) (txhash c_type.Uint256, e error) {
+	return self.send(stx.PkgDesc_Z{Close: &stx.PkgClose{Id: *id}}), nil
+}
+
+// otherChain stands in for the counterparty chain, holding hash time locked
+// contracts.
+type otherChain struct {
+	num       uint64
+	contracts map[c_type.Uint256]*htlc
+}
+
+type htlc struct {
+	value    uint64
+	expiry   uint64
+	redeemed bool
+	secret   c_type.Uint256
+}
+
+func (self *otherChain) lock(hash c_type.Uint256, value uint64, expiry uint64) {
+	self.contracts[hash] = &htlc{value: value, expiry: expiry}
+}
+
+func (self *otherChain) redeem(secret c_type.Uint256) bool {
+	contract := self.contracts[stx.PreimageToHash(&secret)]
+	if contract == nil || contract.redeemed || self.num >= contract.expiry {
+		return false
+	}
+	contract.redeemed = true
+	contract.secret = secret
+	return true
+}
+
+// newTestSwap creates the swaps of the chain, following the blocks with the
+// given confirmations, in a temporary directory to be removed by the caller.
+func newTestSwap(t *testing.T, chain *testChain, confirmations uint64) (*AtomicSwap, string) {
+	dir, err := ioutil.TempDir("", "atomicswap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newAtomicSwap(filepath.Join(dir, "swap"), chain, chain, chain, confirmations), dir
+}
+
+func testToken(value uint64) assets.Token {
+	return assets.Token{
+		Currency: utils.CurrencyToUint256("DECE"),
+		Value:    utils.U256(*new(big.Int).SetUint64(value)),
+	}
+}
+
+func TestParticipateLearnsSecret(t *testing.T) {
+	chain := newTestChain()
+	swaps, dir := newTestSwap(t, chain, 0)
+	defer os.RemoveAll(dir)
+	other := &otherChain{contracts: make(map[c_type.Uint256]*htlc)}
+	pk := c_type.Uint512{1}
+
+	// The counterparty initiates on the other chain
+	secret := c_type.RandUint256()
+	hash := stx.PreimageToHash(&secret)
+	other.lock(hash, 10, 100)
+
+	swap, err := swaps.Participate(&pk, c_type.PKr{2}, testToken(1000), hash, 50, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain.mine()
+	swaps.watch()
+	if s := swaps.GetSwap(&swap.Id); s.State != StateLocked || s.GetSecret() != nil {
+		t.Fatalf("swap after lock: state %v, secret %v", s.State, s.GetSecret())
+	}
+
+	// The counterparty redeems the package, revealing the secret
+	if _, err := swaps.Refund(&swap.Id, nil); err == nil {
+		t.Fatal("refunded before the expiry")
+	}
+	chain.ClosePkg(&pk, &swap.Id, &swap.Key, &secret, nil)
+	chain.mine()
+	swaps.watch()
+
+	s := swaps.GetSwap(&swap.Id)
+	if s.State != StateRedeemed || s.GetSecret() == nil || *s.GetSecret() != secret {
+		t.Fatalf("swap after redeem: state %v, secret %v", s.State, s.GetSecret())
+	}
+	if !other.redeem(*s.GetSecret()) {
+		t.Fatal("can not redeem the other chain with the learnt secret")
+	}
+	if _, err := swaps.Refund(&swap.Id, nil); err == nil {
+		t.Fatal("refunded a redeemed swap")
+	}
+}
+
+func TestInitiateRefund(t *testing.T) {
+	chain := newTestChain()
+	swaps, dir := newTestSwap(t, chain, 0)
+	defer os.RemoveAll(dir)
+	pk := c_type.Uint512{1}
+
+	swap, err := swaps.Initiate(&pk, c_type.PKr{2}, testToken(1000), 3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if swap.GetSecret() == nil || stx.PreimageToHash(swap.GetSecret()) != swap.Lock.HashLock {
+		t.Fatal("initiated swap is not locked by the hash of its secret")
+	}
+	chain.mine()
+	if _, err := swaps.Refund(&swap.Id, nil); err == nil {
+		t.Fatal("refunded before the expiry")
+	}
+	chain.mine()
+	if _, err := swaps.Refund(&swap.Id, nil); err != nil {
+		t.Fatalf("refund: %v", err)
+	}
+	chain.mine()
+	swaps.watch()
+	if s := swaps.GetSwap(&swap.Id); s.State != StateRefunded || s.CloseNum != 3 {
+		t.Fatalf("swap after refund: state %v, num %v", s.State, s.CloseNum)
+	}
+	if len(swaps.GetSwaps(&pk)) != 1 || len(swaps.GetSwaps(&c_type.Uint512{2})) != 0 {
+		t.Fatal("swaps are not listed by account")
+	}
+}
+
+func TestAuditRedeem(t *testing.T) {
+	chain := newTestChain()
+	swaps, dir := newTestSwap(t, chain, 0)
+	defer os.RemoveAll(dir)
+	pk := c_type.Uint512{1}
+
+	// The counterparty initiates on DECE
+	secret := c_type.RandUint256()
+	hash := stx.PreimageToHash(&secret)
+	token := testToken(1000)
+	id, key, _, _ := chain.CreatePkg(&c_type.Uint512{2}, c_type.PKr{1}, token, &stx.PkgLock{HashLock: hash, Expiry: 50}, nil)
+	ar := c_superzk.RandomFr()
+	memo := c_type.Uint512{}
+	asset := assets.Asset{Tkn: &token}
+	einfo, err := c_superzk.EncInfo(&key, asset.ToTypeAsset().NewRef(), &memo, &ar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain.pkgs[id].Pack.Pkg.EInfo = einfo
+	chain.mine()
+
+	if _, err := swaps.Audit(&pk, &id, &key, c_type.RandUint256()); err == nil {
+		t.Fatal("audited a pkg locked by another hash")
+	}
+	swap, err := swaps.Audit(&pk, &id, &key, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if swap.Token.Value.ToInt().Cmp(big.NewInt(1000)) != 0 {
+		t.Fatalf("audited value: have %v, want 1000", swap.Token.Value.ToInt())
+	}
+	if _, err := swaps.Redeem(&id, c_type.RandUint256(), nil); err == nil {
+		t.Fatal("redeemed with a wrong secret")
+	}
+	if _, err := swaps.Redeem(&id, secret, nil); err != nil {
+		t.Fatal(err)
+	}
+	chain.mine()
+	swaps.watch()
+	if s := swaps.GetSwap(&id); s.State != StateRedeemed {
+		t.Fatalf("swap after redeem: state %v", s.State)
+	}
+}
+
+func TestWatchReorg(t *testing.T) {
+	chain := newTestChain()
+	swaps, dir := newTestSwap(t, chain, 2)
+	defer os.RemoveAll(dir)
+	pk := c_type.Uint512{1}
+
+	secret := c_type.RandUint256()
+	hash := stx.PreimageToHash(&secret)
+	swap, err := swaps.Participate(&pk, c_type.PKr{2}, testToken(1000), hash, 50, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain.mine()
+	chain.mine()
+	swaps.watch()
+	if s := swaps.GetSwap(&swap.Id); s.State != StatePending {
+		t.Fatalf("swap locked before its confirmations: state %v", s.State)
+	}
+	chain.mine()
+	swaps.watch()
+	if s := swaps.GetSwap(&swap.Id); s.State != StateLocked {
+		t.Fatalf("swap after the confirmations of the lock: state %v", s.State)
+	}
+
+	// A redeem dropped by a reorganization is never seen
+	chain.ClosePkg(&pk, &swap.Id, &swap.Key, &secret, nil)
+	chain.mine()
+	chain.mine()
+	swaps.watch()
+	chain.reorg(2)
+	chain.mine()
+	swaps.watch()
+	if s := swaps.GetSwap(&swap.Id); s.State != StateLocked || s.GetSecret() != nil {
+		t.Fatalf("swap after the reorganized redeem: state %v, secret %v", s.State, s.GetSecret())
+	}
+
+	// The redeem mined again is followed once confirmed
+	chain.ClosePkg(&pk, &swap.Id, &swap.Key, &secret, nil)
+	chain.mine()
+	chain.mine()
+	chain.mine()
+	swaps.watch()
+	if s := swaps.GetSwap(&swap.Id); s.State != StateRedeemed || s.GetSecret() == nil || *s.GetSecret() != secret {
+		t.Fatalf("swap after the confirmed redeem: state %v, secret %v", s.State, s.GetSecret())
+	}
+}
@@ -0,0 +1,69 @@
+package atomicswap
+
+import (
+	"github.com/dece-cash/go-dece/czero/c_type"
+	"github.com/dece-cash/go-dece/zero/txs/assets"
+	"github.com/dece-cash/go-dece/zero/txs/stx"
+)
+
+// The roles of the local account in a swap.
+const (
+	// The package is created by the local account, locked by the hash of its
+	// secret
+	RoleInitiator = "initiator"
+	// The package is created by the local account, locked by the hash of the
+	// counterparty's secret
+	RoleParticipant = "participant"
+	// The package is created by the counterparty for the local account
+	RoleRecipient = "recipient"
+)
+
+// The states of a swap.
+const (
+	StatePending  = "pending"
+	StateLocked   = "locked"
+	StateRedeemed = "redeemed"
+	StateRefunded = "refunded"
+)
+
+// Swap is the DECE side of a hash time locked swap, a package locked by the
+// hash of the secret until its expiry.
+type Swap struct {
+	Id    c_type.Uint256
+	Role  string
+	State string
+	Pk    c_type.Uint512
+	Owner c_type.PKr
+	Token assets.Token
+	Key   c_type.Uint256
+	Lock  stx.PkgLock
+
+	CreateTx c_type.Uint256
+	CloseTx  c_type.Uint256
+	CloseNum uint64
+	Secret   []c_type.Uint256 `rlp:"tail"`
+}
+
+func (self *Swap) Creator() bool {
+	return self.Role != RoleRecipient
+}
+
+func (self *Swap) Closed() bool {
+	return self.State == StateRedeemed || self.State == StateRefunded
+}
+
+func (self *Swap) GetSecret() *c_type.Uint256 {
+	if len(self.Secret) == 0 {
+		return nil
+	}
+	return &self.Secret[0]
+}
+
+var (
+	swapPrefix = []byte("SWAP_ID")
+	numKey     = []byte("SWAP_NUM")
+)
+
+func swapKey(id *c_type.Uint256) []byte {
+	return append(append([]byte{}, swapPrefix...), id[:]...)
+}
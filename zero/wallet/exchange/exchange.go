@@ -29,6 +29,7 @@ import (
 	"github.com/dece-cash/go-dece/core/types"
 	"github.com/dece-cash/go-dece/event"
 	"github.com/dece-cash/go-dece/log"
+	"github.com/dece-cash/go-dece/params"
 	"github.com/dece-cash/go-dece/rlp"
 	"github.com/dece-cash/go-dece/decedb"
 	"github.com/dece-cash/go-dece/zero/txs/assets"
@@ -652,17 +653,20 @@ func (self *Exchange) genTx(account *Account, param *prepare.BeforeTxParam) (txP
 		return
 	}
 
-	var seed *address.Seed
-	if seed, e = account.wallet.GetSeed(); e != nil {
-		self.ClearTxParam(txParam)
-		return
+	var gtx txtool.GTx
+	if signer, ok := account.wallet.(accounts.TxSigner); ok {
+		gtx, e = signer.SignTx(txParam)
+	} else {
+		var seed *address.Seed
+		if seed, e = account.wallet.GetSeed(); e != nil {
+			self.ClearTxParam(txParam)
+			return
+		}
+		sk := superzk.Seed2Sk(seed.SeedToUint256())
+		gtx, e = flight.SignTx(&sk, txParam)
 	}
-
-	sk := superzk.Seed2Sk(seed.SeedToUint256())
-	gtx, err := flight.SignTx(&sk, txParam)
-	if err != nil {
+	if e != nil {
 		self.ClearTxParam(txParam)
-		e = err
 		return
 	} else {
 		tx = &gtx
@@ -831,6 +835,7 @@ func (self *Exchange) fetchBlockInfo() {
 	if txtool.Ref_inst.Bc == nil || !txtool.Ref_inst.Bc.IsValid() {
 		return
 	}
+	self.migratePkgs()
 	for {
 		indexs := map[uint64][]c_type.Uint512{}
 		orders := uint64Slice{}
@@ -1174,7 +1179,13 @@ type MergeUtxos struct {
 	//tickets map[c_type.Uint256]c_type.Uint256
 }
 
-var default_fee_value = new(big.Int).Mul(big.NewInt(25000), big.NewInt(1000000000))
+// The gas and gas price of the transactions sent by the exchange, unless the
+// caller sets its own price.
+var (
+	default_gas       = params.TxGas
+	default_gas_price = big.NewInt(1000000000)
+	default_fee_value = new(big.Int).Mul(new(big.Int).SetUint64(default_gas), default_gas_price)
+)
 
 func (self *Exchange) getMergeUtxos(from *c_type.Uint512, currency string, zcount int, left int, icount int) (mu MergeUtxos, e error) {
 	if zcount > 400 {
@@ -1325,10 +1336,12 @@ func (self *Exchange) Merge(pk *c_type.Uint512, currency string, force bool) (co
 		return
 	}
 
-	seed, err := account.wallet.GetSeed()
-	if err != nil || seed == nil {
-		e = errors.New("account is locked")
-		return
+	// The wallets signing by themselves, like the hardware ones, hold no seed
+	if _, ok := account.wallet.(accounts.TxSigner); !ok {
+		if seed, err := account.wallet.GetSeed(); err != nil || seed == nil {
+			e = errors.New("account is locked")
+			return
+		}
 	}
 
 	var mu MergeUtxos
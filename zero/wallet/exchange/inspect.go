@@ -0,0 +1,27 @@
+package exchange
+
+import (
+	"github.com/dece-cash/go-dece/decedb"
+)
+
+func group(name string, prefix []byte) decedb.KeyGroup {
+	return decedb.KeyGroup{Schema: "exchange", Name: name, Prefix: prefix}
+}
+
+// KeyGroups returns the key families of the exchange database.
+func KeyGroups() []decedb.KeyGroup {
+	return []decedb.KeyGroup{
+		group("Sync numbers", numPrefix),
+		group("Balance PKrs", balancPkrPrefix),
+		group("Utxos by PK", pkPrefix),
+		group("Utxos by block", utxoPrefix),
+		group("Roots", rootPrefix),
+		group("Nils", nilPrefix),
+		group("Blocks", blockPrefix),
+		group("Out utxos", outUtxoPrefix),
+		group("Txs", txPrefix),
+		group("Nil to root", nilRootPrefix),
+		group("Pkg ids by PK", pk_from_id_2_id_KeyPrefix),
+		group("Pkgs", id_2_pkg_KeyPrefix),
+	}
+}
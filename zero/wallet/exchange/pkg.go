@@ -1,21 +1,37 @@
 package exchange
 
 import (
+	"errors"
+	"math/big"
+
+	"github.com/dece-cash/go-dece/common"
 	"github.com/dece-cash/go-dece/czero/c_type"
+	"github.com/dece-cash/go-dece/decedb"
 	"github.com/dece-cash/go-dece/log"
 	"github.com/dece-cash/go-dece/rlp"
-	"github.com/dece-cash/go-dece/decedb"
 	"github.com/dece-cash/go-dece/zero/localdb"
+	"github.com/dece-cash/go-dece/zero/txs/assets"
+	"github.com/dece-cash/go-dece/zero/txs/pkg"
+	"github.com/dece-cash/go-dece/zero/txs/stx"
 	"github.com/dece-cash/go-dece/zero/txtool"
+	"github.com/dece-cash/go-dece/zero/txtool/prepare"
+	"github.com/dece-cash/go-dece/zero/utils"
 )
 
 var (
 	pk_from_id_2_id_KeyPrefix = []byte("PK_FROM_ID_2_ID")
 	id_2_pkg_KeyPrefix        = []byte("ID_2_PKG")
+	id_num_2_event_KeyPrefix  = []byte("ID_NUM_2_PKGEVENT")
+	pkg_version_Key           = []byte("PKG_VERSION")
 )
 
+// pkgVersion prefixes the encoding of the stored packages. The packages stored
+// before it hid their fields from rlp and were written as empty lists.
+const pkgVersion = byte(1)
+
 func pk_from_id_2_id_Key(pk *c_type.Uint512, from *bool, id *c_type.Uint256) []byte {
-	ret := append(pk_from_id_2_id_KeyPrefix, pk[:]...)
+	ret := append([]byte{}, pk_from_id_2_id_KeyPrefix...)
+	ret = append(ret, pk[:]...)
 	if from != nil {
 		f := byte(0)
 		if *from {
@@ -29,20 +45,52 @@ func pk_from_id_2_id_Key(pk *c_type.Uint512, from *bool, id *c_type.Uint256) []b
 	return ret
 }
 
+// id_num_2_event_Key orders the events of a package by block, then by seq
+// within the block.
+func id_num_2_event_Key(id *c_type.Uint256, num *uint64, seq byte) []byte {
+	ret := append([]byte{}, id_num_2_event_KeyPrefix...)
+	ret = append(ret, id[:]...)
+	if num != nil {
+		ret = append(ret, utils.EncodeNumber(*num)...)
+		ret = append(ret, seq)
+	}
+	return ret
+}
+
+// Pkg is a package created by or sent to one of the accounts of the exchange.
+// To and From are the accounts owning the package and having created it.
 type Pkg struct {
-	z    localdb.ZPkg
-	to   *c_type.Uint512 `rlp: nil`
-	from *c_type.Uint512 `rlp: nil`
+	Z    localdb.ZPkg
+	To   *c_type.Uint512 `rlp:"nil"`
+	From *c_type.Uint512 `rlp:"nil"`
+}
+
+// The lifecycle events of a package.
+const (
+	PkgCreated     = "create"
+	PkgTransferred = "transfer"
+	PkgClosed      = "close"
+)
+
+// PkgEvent is a change of a package recorded in the block Num.
+type PkgEvent struct {
+	Num    uint64
+	Action string
+	PKr    c_type.PKr
 }
 
 func id_2_pkg_key(id *c_type.Uint256) []byte {
-	ret := append(id_2_pkg_KeyPrefix, id[:]...)
+	ret := append([]byte{}, id_2_pkg_KeyPrefix...)
+	ret = append(ret, id[:]...)
 	return ret
 }
 
+// FindPkgs returns the packages sent to pk, or created by pk when from is set,
+// including the closed ones.
 func (self *Exchange) FindPkgs(pk *c_type.Uint512, from bool) (pkgs []Pkg) {
 	prefix := pk_from_id_2_id_Key(pk, &from, nil)
 	iterator := self.db.NewIteratorWithPrefix(prefix)
+	defer iterator.Release()
 	for iterator.Next() {
 		if id := iterator.Value(); len(id) == 32 {
 			i := c_type.Uint256{}
@@ -63,65 +111,312 @@ func (self *Exchange) FindPkgById(id *c_type.Uint256) (pkg *Pkg) {
 	if bs, e := self.db.Get(id_2_pkg_key(id)); e != nil {
 		return
 	} else {
-		pkg := Pkg{}
-		if e := rlp.DecodeBytes(bs, &pkg); e == nil {
-			return &pkg
+		if pkg, e := decodePkg(bs); e == nil {
+			return pkg
 		} else {
+			log.Error("decode pkg error", "id", id, "error", e)
+			return nil
+		}
+	}
+}
+
+func encodePkg(p *Pkg) []byte {
+	bs, e := rlp.EncodeToBytes(p)
+	if e != nil {
+		panic(e)
+	}
+	return append([]byte{pkgVersion}, bs...)
+}
+
+func decodePkg(bs []byte) (*Pkg, error) {
+	if len(bs) == 0 || bs[0] != pkgVersion {
+		return nil, errors.New("pkg stored before the versioned encoding")
+	}
+	pkg := Pkg{}
+	if e := rlp.DecodeBytes(bs[1:], &pkg); e != nil {
+		return nil, e
+	}
+	return &pkg, nil
+}
+
+// migratePkgs rebuilds from the current state of the chain the package indexes
+// written before the packages were versioned. Their records were empty and the
+// created packages were filed under their owner instead of their creator.
+func (self *Exchange) migratePkgs() {
+	if version, e := self.db.Get(pkg_version_Key); e == nil && len(version) == 1 && version[0] >= pkgVersion {
+		return
+	}
+
+	batch := self.db.NewBatch()
+	ids := map[c_type.Uint256]struct{}{}
+	iterator := self.db.NewIteratorWithPrefix(pk_from_id_2_id_KeyPrefix)
+	for iterator.Next() {
+		if id := iterator.Value(); len(id) == 32 {
+			i := c_type.Uint256{}
+			copy(i[:], id)
+			ids[i] = struct{}{}
+		}
+		batch.Delete(common.CopyBytes(iterator.Key()))
+	}
+	iterator.Release()
+	iterator = self.db.NewIteratorWithPrefix(id_2_pkg_KeyPrefix)
+	for iterator.Next() {
+		batch.Delete(common.CopyBytes(iterator.Key()))
+	}
+	iterator.Release()
+
+	pks := []c_type.Uint512{}
+	self.accounts.Range(func(key, value interface{}) bool {
+		pks = append(pks, key.(c_type.Uint512))
+		return true
+	})
+	state := txtool.Ref_inst.CurrentState()
+	for id := range ids {
+		zpkg := state.Pkgs.GetPkgById(&id)
+		if zpkg == nil {
+			continue
+		}
+		p := Pkg{Z: *zpkg}
+		if account, ok := self.ownPkr(pks, zpkg.Pack.PKr); ok {
+			p.To = account.pk
+		}
+		if account, ok := self.ownPkr(pks, zpkg.From); ok {
+			p.From = account.pk
+		}
+		if p.To == nil && p.From == nil {
+			continue
+		}
+		self.putPkgKeys(batch, &p)
+		if e := batch.Put(id_2_pkg_key(&id), encodePkg(&p)); e != nil {
 			panic(e)
 		}
 	}
+	if e := batch.Put(pkg_version_Key, []byte{pkgVersion}); e != nil {
+		panic(e)
+	}
+	if e := batch.Write(); e != nil {
+		log.Error("Exchange migrate pkgs", "error", e)
+		return
+	}
+	log.Info("Exchange migrated pkgs", "count", len(ids))
+}
+
+// GetPkgHistory returns the recorded lifecycle events of a package, oldest
+// first.
+func (self *Exchange) GetPkgHistory(id *c_type.Uint256) (events []PkgEvent) {
+	iterator := self.db.NewIteratorWithPrefix(id_num_2_event_Key(id, nil, 0))
+	defer iterator.Release()
+	for iterator.Next() {
+		var event PkgEvent
+		if e := rlp.DecodeBytes(iterator.Value(), &event); e != nil {
+			log.Error("decode pkg event error", "id", id, "error", e)
+			continue
+		}
+		events = append(events, event)
+	}
+	return
 }
 
-type pkgIndexes struct {
-	deleteKeys      [][]byte
-	pk_from_id_maps map[string]c_type.Uint256
+// OpenPkg decrypts the content of a package with the key shared by its
+// creator.
+func (self *Exchange) OpenPkg(id *c_type.Uint256, key *c_type.Uint256) (opkg pkg.Pkg_O, e error) {
+	p := self.FindPkgById(id)
+	if p == nil {
+		e = errors.New("pkg is not indexed by the exchange")
+		return
+	}
+	return pkg.DePkg(key, &p.Z.Pack.Pkg)
+}
+
+// CreatePkg sends the transaction creating a package of token for the owner
+// to, locked by lock when it is set. It returns the id of the package and the
+// key the owner needs to open it.
+func (self *Exchange) CreatePkg(pk *c_type.Uint512, to c_type.PKr, token assets.Token, lock *stx.PkgLock, gasPrice *big.Int) (id c_type.Uint256, key c_type.Uint256, txhash c_type.Uint256, e error) {
+	var account *Account
+	if value, ok := self.accounts.Load(*pk); ok {
+		account = value.(*Account)
+	} else {
+		e = errors.New("not found Pk")
+		return
+	}
+	id = c_type.RandUint256()
+	pretx, gtx, err := self.sendPkgTx(*pk, prepare.Cmds{
+		PkgCreate: &prepare.PkgCreateCmd{
+			Id:    id,
+			PKr:   to,
+			Asset: assets.Asset{Tkn: &token},
+			Lock:  lock,
+		},
+	}, gasPrice)
+	if err != nil {
+		e = err
+		return
+	}
+	key = pkg.GetKey(&pretx.From.PKr, account.tk)
+	txhash = gtx.Hash
+	return
+}
+
+// ClosePkg opens a package owned by pk with its key and sends the transaction
+// releasing its content to the owner. Hash-locked packages need the preimage
+// of their hash.
+func (self *Exchange) ClosePkg(pk *c_type.Uint512, id *c_type.Uint256, key *c_type.Uint256, preimage *c_type.Uint256, gasPrice *big.Int) (txhash c_type.Uint256, e error) {
+	p := self.FindPkgById(id)
+	if p == nil || p.To == nil || *p.To != *pk {
+		e = errors.New("pkg is not owned by the account")
+		return
+	}
+	return self.closePkg(p, &prepare.PkgCloseCmd{Id: *id, Key: *key, Preimage: preimage}, gasPrice)
+}
+
+// RefundPkg sends back the content of an expired locked package to pk, its
+// creator.
+func (self *Exchange) RefundPkg(pk *c_type.Uint512, id *c_type.Uint256, key *c_type.Uint256, gasPrice *big.Int) (txhash c_type.Uint256, e error) {
+	p := self.FindPkgById(id)
+	if p == nil || p.From == nil || *p.From != *pk {
+		e = errors.New("pkg is not created by the account")
+		return
+	}
+	if p.Z.Pack.GetLock() == nil {
+		e = errors.New("pkg is not locked")
+		return
+	}
+	return self.closePkg(p, &prepare.PkgCloseCmd{Id: *id, Key: *key, Refund: true}, gasPrice)
+}
+
+func (self *Exchange) closePkg(p *Pkg, cmd *prepare.PkgCloseCmd, gasPrice *big.Int) (txhash c_type.Uint256, e error) {
+	if p.Z.Closed {
+		e = errors.New("pkg is already closed")
+		return
+	}
+	if _, e = pkg.DePkg(&cmd.Key, &p.Z.Pack.Pkg); e != nil {
+		return
+	}
+	pk := p.To
+	if cmd.Refund {
+		pk = p.From
+	}
+	_, gtx, err := self.sendPkgTx(*pk, prepare.Cmds{PkgClose: cmd}, gasPrice)
+	if err != nil {
+		e = err
+		return
+	}
+	txhash = gtx.Hash
+	return
+}
+
+func (self *Exchange) sendPkgTx(pk c_type.Uint512, cmds prepare.Cmds, gasPrice *big.Int) (pretx *txtool.GTxParam, gtx *txtool.GTx, e error) {
+	if gasPrice == nil {
+		gasPrice = new(big.Int).Set(default_gas_price)
+	}
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(default_gas), gasPrice)
+	param := prepare.PreTxParam{
+		From: pk,
+		Fee: assets.Token{
+			Currency: utils.CurrencyToUint256("DECE"),
+			Value:    utils.U256(*fee),
+		},
+		GasPrice: gasPrice,
+		Cmds:     cmds,
+	}
+	if pretx, gtx, e = self.GenTxWithSign(param); e != nil {
+		return
+	}
+	if e = self.commitTx(gtx); e != nil {
+		self.ClearTxParam(pretx)
+		return
+	}
+	return
+}
+
+func (self *Exchange) putPkgKeys(batch decedb.Batch, p *Pkg) {
+	if p.To != nil {
+		from := false
+		if e := batch.Put(pk_from_id_2_id_Key(p.To, &from, &p.Z.Pack.Id), p.Z.Pack.Id[:]); e != nil {
+			panic(e)
+		}
+	}
+	if p.From != nil {
+		from := true
+		if e := batch.Put(pk_from_id_2_id_Key(p.From, &from, &p.Z.Pack.Id), p.Z.Pack.Id[:]); e != nil {
+			panic(e)
+		}
+	}
+}
+
+func (self *Exchange) deletePkgKeys(batch decedb.Batch, p *Pkg) {
+	if p.To != nil {
+		from := false
+		batch.Delete(pk_from_id_2_id_Key(p.To, &from, &p.Z.Pack.Id))
+	}
+	if p.From != nil {
+		from := true
+		batch.Delete(pk_from_id_2_id_Key(p.From, &from, &p.Z.Pack.Id))
+	}
 }
 
 func (self *Exchange) indexPkgs(pks []c_type.Uint512, batch decedb.Batch, blocks []txtool.Block) {
+	// The batch is only written after all the blocks, keep the packages
+	// changed in between
+	changed := make(map[c_type.Uint256]*Pkg)
+
 	for _, block := range blocks {
-		for _, pkg := range block.Pkgs {
-			if p := self.FindPkgById(&pkg.Pack.Id); p != nil {
-				if p.to != nil {
-					from := false
-					batch.Delete(pk_from_id_2_id_Key(p.to, &from, &p.z.Pack.Id))
-				}
-				if p.from != nil {
-					from := true
-					batch.Delete(pk_from_id_2_id_Key(p.to, &from, &p.z.Pack.Id))
-				}
-				batch.Delete(id_2_pkg_key(&p.z.Pack.Id))
+		num := uint64(block.Num)
+		for _, zpkg := range block.Pkgs {
+			id := zpkg.Pack.Id
+			old, ok := changed[id]
+			if !ok {
+				old = self.FindPkgById(&id)
 			}
+
 			var p Pkg
-			if account, ok := self.ownPkr(pks, pkg.Pack.PKr); ok {
-				p.to = account.pk
+			p.Z = zpkg
+			if account, ok := self.ownPkr(pks, zpkg.Pack.PKr); ok {
+				p.To = account.pk
 			}
-			if account, ok := self.ownPkr(pks, pkg.From); ok {
-				p.from = account.pk
+			if account, ok := self.ownPkr(pks, zpkg.From); ok {
+				p.From = account.pk
+			}
+			if old == nil && p.From == nil && p.To == nil {
+				continue
+			}
+
+			// The state only keeps the last change of a package in a block, a
+			// package created and closed in the same block has both events
+			var events []PkgEvent
+			if old == nil && zpkg.High == num {
+				events = append(events, PkgEvent{Num: num, Action: PkgCreated, PKr: zpkg.Pack.PKr})
+			}
+			if zpkg.Closed {
+				events = append(events, PkgEvent{Num: num, Action: PkgClosed, PKr: zpkg.Pack.PKr})
+			} else if len(events) == 0 {
+				events = append(events, PkgEvent{Num: num, Action: PkgTransferred, PKr: zpkg.Pack.PKr})
+			}
+			if old != nil {
+				self.deletePkgKeys(batch, old)
+				// A closed package keeps its last owner
+				if zpkg.Closed && p.To == nil {
+					p.To = old.To
+				}
+				if p.From == nil {
+					p.From = old.From
+				}
+			}
+			self.putPkgKeys(batch, &p)
+
+			if e := batch.Put(id_2_pkg_key(&id), encodePkg(&p)); e != nil {
+				panic(e)
 			}
-			if p.from != nil || p.to != nil {
-				if !pkg.Closed {
-					p.z = pkg
-					if bs, e := rlp.EncodeToBytes(&p); e == nil {
-						if p.to != nil {
-							from := false
-							if e := batch.Put(pk_from_id_2_id_Key(p.to, &from, &p.z.Pack.Id), p.z.Pack.Id[:]); e != nil {
-								panic(e)
-							}
-						}
-						if p.from != nil {
-							from := true
-							if e := batch.Put(pk_from_id_2_id_Key(p.to, &from, &p.z.Pack.Id), p.z.Pack.Id[:]); e != nil {
-								panic(e)
-							}
-						}
-						if e := batch.Put(id_2_pkg_key(&p.z.Pack.Id), bs); e != nil {
-							panic(e)
-						}
-					} else {
+			for seq := range events {
+				if bs, e := rlp.EncodeToBytes(&events[seq]); e == nil {
+					if e := batch.Put(id_num_2_event_Key(&id, &num, byte(seq)), bs); e != nil {
 						panic(e)
 					}
+				} else {
+					panic(e)
 				}
 			}
+			changed[id] = &p
 		}
 	}
 	return
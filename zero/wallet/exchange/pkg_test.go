@@ -0,0 +1,199 @@
+package exchange
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/dece-cash/go-dece/common"
+	"github.com/dece-cash/go-dece/common/hexutil"
+	"github.com/dece-cash/go-dece/core/state"
+	"github.com/dece-cash/go-dece/core/types"
+	"github.com/dece-cash/go-dece/czero/c_type"
+	"github.com/dece-cash/go-dece/decedb"
+	"github.com/dece-cash/go-dece/zero/localdb"
+	"github.com/dece-cash/go-dece/zero/txs/stx"
+	"github.com/dece-cash/go-dece/zero/txs/zstate"
+	"github.com/dece-cash/go-dece/zero/txtool"
+)
+
+// pkgChain serves the zero state of its head to txtool.Ref_inst.
+type pkgChain struct {
+	txtool.BlockChain
+	state *zstate.ZState
+}
+
+func (c *pkgChain) GetCurrenHeader() *types.Header {
+	return &types.Header{Number: new(big.Int)}
+}
+
+func (c *pkgChain) GetBlockByNumber(num uint64) *types.Block {
+	return types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(num)})
+}
+
+func (c *pkgChain) CurrentState(hash *common.Hash) *zstate.ZState {
+	return c.state
+}
+
+// newPkgExchange returns an exchange over a temporary database whose accounts
+// are the creator and the owner of the test packages.
+func newPkgExchange(t *testing.T) (*Exchange, func()) {
+	dir, err := ioutil.TempDir("", "exchange-pkg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := decedb.NewLDBDatabase(dir, 0, 0)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	exchange := &Exchange{db: db}
+	for _, pk := range []c_type.Uint512{creatorPk, ownerPk} {
+		pk := pk
+		pkr := c_type.PKr{pk[0]}
+		exchange.accounts.Store(pk, &Account{pk: &pk, balancePkr: &pkr})
+	}
+	return exchange, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+var (
+	creatorPk  = c_type.Uint512{1}
+	ownerPk    = c_type.Uint512{2}
+	creatorPkr = c_type.PKr{1}
+	ownerPkr   = c_type.PKr{2}
+	otherPkr   = c_type.PKr{3}
+)
+
+func indexPkgBlocks(exchange *Exchange, blocks ...txtool.Block) {
+	batch := exchange.db.NewBatch()
+	exchange.indexPkgs([]c_type.Uint512{creatorPk, ownerPk}, batch, blocks)
+	if err := batch.Write(); err != nil {
+		panic(err)
+	}
+}
+
+func pkgBlock(num uint64, pkgs ...localdb.ZPkg) txtool.Block {
+	return txtool.Block{Num: hexutil.Uint64(num), Pkgs: pkgs}
+}
+
+func zpkg(id byte, high uint64, to c_type.PKr, closed bool) localdb.ZPkg {
+	return localdb.ZPkg{High: high, From: creatorPkr, Pack: stx.PkgCreate{Id: c_type.Uint256{id}, PKr: to}, Closed: closed}
+}
+
+func pkgActions(events []PkgEvent) (actions []string) {
+	for _, event := range events {
+		actions = append(actions, event.Action)
+	}
+	return
+}
+
+// Tests that the history of a package records its creation, transfers and
+// closing in order, and that the package is listed under its creator and
+// current owner.
+func TestIndexPkgs(t *testing.T) {
+	exchange, done := newPkgExchange(t)
+	defer done()
+
+	id := c_type.Uint256{1}
+	indexPkgBlocks(exchange, pkgBlock(1, zpkg(1, 1, ownerPkr, false)))
+	if pkgs := exchange.FindPkgs(&ownerPk, false); len(pkgs) != 1 || pkgs[0].Z.Pack.Id != id {
+		t.Fatalf("owner pkgs mismatch: have %v", pkgs)
+	}
+	if pkgs := exchange.FindPkgs(&creatorPk, true); len(pkgs) != 1 || pkgs[0].Z.Pack.Id != id {
+		t.Fatalf("creator pkgs mismatch: have %v", pkgs)
+	}
+
+	// Sent away, then closed by its new owner in the same batch of blocks
+	indexPkgBlocks(exchange, pkgBlock(2, zpkg(1, 1, otherPkr, false)), pkgBlock(3, zpkg(1, 1, otherPkr, true)))
+	if pkgs := exchange.FindPkgs(&ownerPk, false); len(pkgs) != 0 {
+		t.Errorf("transferred pkg still listed for its previous owner: %v", pkgs)
+	}
+	p := exchange.FindPkgById(&id)
+	if p == nil || !p.Z.Closed || p.From == nil || *p.From != creatorPk || p.To != nil {
+		t.Errorf("closed pkg mismatch: have %+v", p)
+	}
+
+	want := []PkgEvent{
+		{Num: 1, Action: PkgCreated, PKr: ownerPkr},
+		{Num: 2, Action: PkgTransferred, PKr: otherPkr},
+		{Num: 3, Action: PkgClosed, PKr: otherPkr},
+	}
+	if history := exchange.GetPkgHistory(&id); !reflect.DeepEqual(history, want) {
+		t.Errorf("history mismatch: have %+v, want %+v", history, want)
+	}
+}
+
+// Tests that a package created and closed in the same block keeps both events.
+func TestIndexPkgsClosedAtCreation(t *testing.T) {
+	exchange, done := newPkgExchange(t)
+	defer done()
+
+	id := c_type.Uint256{2}
+	indexPkgBlocks(exchange, pkgBlock(5, zpkg(2, 5, ownerPkr, true)))
+
+	if history := exchange.GetPkgHistory(&id); !reflect.DeepEqual(pkgActions(history), []string{PkgCreated, PkgClosed}) {
+		t.Errorf("history mismatch: have %+v", history)
+	}
+	if p := exchange.FindPkgById(&id); p == nil || !p.Z.Closed || p.To == nil || *p.To != ownerPk {
+		t.Errorf("closed pkg mismatch: have %+v", p)
+	}
+}
+
+// Tests that the packages indexed before the versioned encoding are rebuilt
+// from the chain state and filed under their creator and owner.
+func TestMigratePkgs(t *testing.T) {
+	exchange, done := newPkgExchange(t)
+	defer done()
+
+	statedb, _ := state.New(state.NewDatabase(decedb.NewMemDatabase()), nil)
+	zst := statedb.NextZState()
+	ids := []c_type.Uint256{{1}, {2}}
+	for _, id := range ids {
+		if err := zst.Pkgs.Force_add(&creatorPkr, &stx.PkgCreate{Id: id, PKr: ownerPkr}); err != nil {
+			t.Fatalf("failed to create pkg %x: %v", id, err)
+		}
+	}
+	defer func(bc txtool.BlockChain) { txtool.Ref_inst.Bc = bc }(txtool.Ref_inst.Bc)
+	txtool.Ref_inst.Bc = &pkgChain{state: zst}
+
+	// The old indexes filed the created packages under their owner, with an
+	// empty record
+	from := false
+	for _, id := range ids {
+		id := id
+		exchange.db.Put(pk_from_id_2_id_Key(&ownerPk, &from, &id), id[:])
+		exchange.db.Put(id_2_pkg_key(&id), []byte{0xc0})
+	}
+	if p := exchange.FindPkgById(&ids[0]); p != nil {
+		t.Fatalf("old record decoded: %+v", p)
+	}
+
+	exchange.migratePkgs()
+	for _, id := range ids {
+		p := exchange.FindPkgById(&id)
+		if p == nil {
+			t.Fatalf("pkg %x not migrated", id)
+		}
+		if p.Z.Pack.Id != id || p.To == nil || *p.To != ownerPk || p.From == nil || *p.From != creatorPk {
+			t.Errorf("pkg %x mismatch: have %+v", id, p)
+		}
+	}
+	if pkgs := exchange.FindPkgs(&creatorPk, true); len(pkgs) != len(ids) {
+		t.Errorf("creator pkgs mismatch: have %d, want %d", len(pkgs), len(ids))
+	}
+	if pkgs := exchange.FindPkgs(&ownerPk, false); len(pkgs) != len(ids) {
+		t.Errorf("owner pkgs mismatch: have %d, want %d", len(pkgs), len(ids))
+	}
+
+	// Migrated once: the records written afterwards are kept
+	exchange.db.Delete(id_2_pkg_key(&ids[1]))
+	exchange.migratePkgs()
+	if p := exchange.FindPkgById(&ids[1]); p != nil {
+		t.Errorf("pkgs migrated twice")
+	}
+}
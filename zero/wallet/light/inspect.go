@@ -0,0 +1,18 @@
+package light
+
+import (
+	"github.com/dece-cash/go-dece/decedb"
+)
+
+func group(name string, prefix []byte) decedb.KeyGroup {
+	return decedb.KeyGroup{Schema: "light", Name: name, Prefix: prefix}
+}
+
+// KeyGroups returns the key families of the light wallet database.
+func KeyGroups() []decedb.KeyGroup {
+	return []decedb.KeyGroup{
+		group("Outs by PKr", pkrPrefix),
+		group("Nils", nilPrefix),
+		group("Sync number", numKey()),
+	}
+}
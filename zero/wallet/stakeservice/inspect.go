@@ -0,0 +1,31 @@
+package stakeservice
+
+import (
+	"github.com/dece-cash/go-dece/common"
+	"github.com/dece-cash/go-dece/czero/c_type"
+	"github.com/dece-cash/go-dece/decedb"
+)
+
+func group(name string, prefix []byte, match func(k []byte) bool) decedb.KeyGroup {
+	return decedb.KeyGroup{Schema: "stakeservice", Name: name, Prefix: prefix, Match: match}
+}
+
+func keyLen(n int) func(k []byte) bool {
+	return func(k []byte) bool {
+		return len(k) == n
+	}
+}
+
+// KeyGroups returns the key families of the stake service database. The
+// share indexes are keyed by the bare PK or PKr followed by the share id.
+func KeyGroups() []decedb.KeyGroup {
+	return []decedb.KeyGroup{
+		group("Sync numbers", numPrefix, nil),
+		group("Shares", sharePrefix, nil),
+		group("Pools", poolPrefix, nil),
+		group("PKr infos", pkrInfoPrefix, nil),
+		group("PK infos", pkInfoPrefix, nil),
+		group("Shares by PK", nil, keyLen(len(c_type.Uint512{})+common.HashLength)),
+		group("Shares by PKr", nil, keyLen(len(c_type.PKr{})+common.HashLength)),
+	}
+}